@@ -0,0 +1,323 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// The cache is degraded when it cannot be reached. While degraded, the cache
+// is bypassed: lookups miss and files are not cached, so that requests are
+// served from the database without waiting for the cache to time out.
+// Invalidations cannot be skipped without leaving stale entries behind, so
+// they are deferred and replayed once the cache can be reached again, before
+// the cache is used again. A failed invalidation degrades the cache
+// immediately for the same reason.
+const (
+	defaultDegradedFailureThreshold = 3
+	defaultDegradedRetryInterval    = (time.Second * 5)
+
+	// Maximum number of deferred invalidations. Further invalidations are
+	// dropped, and the affected entries may be stale until they expire.
+	maxPendingInvalidations = 10000
+)
+
+// Cache entry written to invalidate a file once the cache recovers.
+type pendingFileEntry struct {
+	version int64
+	data    string
+	ttl     time.Duration
+}
+
+// Identifies a device whose cached file lists are to be invalidated.
+type deviceKey struct {
+	tenantID string
+	deviceID string
+}
+
+var (
+	degradedFailureThreshold int32
+	degradedRetryInterval    time.Duration
+
+	degraded            atomic.Bool
+	consecutiveFailures atomic.Int32
+	recoveryWg          sync.WaitGroup
+
+	// Invalidations deferred until the cache recovers.
+	pendingLock     sync.Mutex
+	pendingFiles    map[uint64]pendingFileEntry
+	pendingDevices  map[deviceKey]bool
+	pendingOverflow bool
+)
+
+func initDegradedMode(cacheConfig *config.Cache) {
+	degradedFailureThreshold = defaultDegradedFailureThreshold
+	if cacheConfig.DegradedFailureThreshold > 0 {
+		degradedFailureThreshold = int32(cacheConfig.DegradedFailureThreshold)
+	}
+	degradedRetryInterval = getDuration(cacheConfig.DegradedRetryIntervalSeconds,
+		time.Second, defaultDegradedRetryInterval)
+
+	degraded.Store(false)
+	consecutiveFailures.Store(0)
+	pendingFiles = make(map[uint64]pendingFileEntry)
+	pendingDevices = make(map[deviceKey]bool)
+	pendingOverflow = false
+	metrics.MetricCacheDegraded.Set(0)
+}
+
+// Wait for the recovery of a degraded cache to be abandoned on shutdown.
+func shutdownDegradedMode() {
+	recoveryWg.Wait()
+}
+
+// IsDegraded returns true if the cache cannot be reached and is bypassed.
+func IsDegraded() bool {
+	return isEnabled && degraded.Load()
+}
+
+// Returns true if the cache can be used. Lookups and additions to the cache
+// are bypassed otherwise.
+func isAvailable() bool {
+	if !isEnabled {
+		return false
+	}
+	if degraded.Load() {
+		metrics.MetricCacheDegradedBypasses.Inc()
+		return false
+	}
+	return true
+}
+
+// Bypass the cache and periodically check whether it can be reached again.
+func enterDegradedMode() {
+	if !degraded.CompareAndSwap(false, true) {
+		return
+	}
+
+	fsLogger.Error("The file cache is degraded! Requests are served from the database until it recovers.",
+		zap.Duration("Retry interval: ", degradedRetryInterval),
+	)
+	metrics.MetricCacheDegraded.Set(1)
+
+	recoveryWg.Add(1)
+	go recoverCache()
+}
+
+// Periodically check whether the cache can be reached and, once it can,
+// replay the deferred invalidations and stop bypassing the cache.
+func recoverCache() {
+	defer recoveryWg.Done()
+
+	ticker := time.NewTicker(degradedRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gCtx.Done():
+			return
+
+		case <-ticker.C:
+			ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
+			err := cacheClient.Ping(ctx).Err()
+			cancelFunc()
+			if err != nil {
+				continue
+			}
+
+			if replayPendingInvalidations() {
+				fsLogger.Info("The file cache has recovered!")
+				metrics.MetricCacheDegraded.Set(0)
+				return
+			}
+		}
+	}
+}
+
+// Replay the deferred invalidations. The cache stops being bypassed once
+// there are no more deferred invalidations. Returns false if the cache could
+// not be reached.
+func replayPendingInvalidations() bool {
+	for {
+		pendingLock.Lock()
+		if len(pendingFiles) == 0 && len(pendingDevices) == 0 {
+			if pendingOverflow {
+				fsLogger.Error("Too many invalidations were deferred while the file cache was degraded! Some cached files may be stale until they expire.")
+				pendingOverflow = false
+			}
+			consecutiveFailures.Store(0)
+			degraded.Store(false)
+			pendingLock.Unlock()
+			return true
+		}
+
+		files, devices := pendingFiles, pendingDevices
+		pendingFiles = make(map[uint64]pendingFileEntry)
+		pendingDevices = make(map[deviceKey]bool)
+		pendingLock.Unlock()
+
+		// Stop at the first failure to reach the cache and defer the
+		// remaining invalidations again. Invalidations rejected by the cache
+		// cannot be replayed and are dropped.
+		var err error
+		for fileID, entry := range files {
+			if err == nil {
+				err = dropRejected(setFileEntry(gCtx, fileID, entry.version,
+					entry.data, entry.ttl))
+			}
+			if err != nil {
+				deferFileEntry(fileID, entry.version, entry.data, entry.ttl, true)
+			}
+		}
+		for device := range devices {
+			if err == nil {
+				err = dropRejected(incrementDeviceGeneration(gCtx,
+					device.tenantID, device.deviceID))
+			}
+			if err != nil {
+				deferFileListInvalidation(device.tenantID, device.deviceID, true)
+			}
+		}
+		if err != nil {
+			fsLogger.Error("Failed to replay the deferred cache invalidations!",
+				zap.Error(err),
+			)
+			return false
+		}
+	}
+}
+
+// Returns the specified error if it indicates that the cache could not be
+// reached. Other errors are logged and dropped.
+func dropRejected(err error) error {
+	if err != nil && !isUnavailableError(err) {
+		fsLogger.Error("The file cache rejected a deferred invalidation!",
+			zap.Error(err),
+		)
+		metrics.MetricCacheDroppedInvalidations.Inc()
+		return nil
+	}
+	return err
+}
+
+// Defer writing the specified cache entry for a file until the cache
+// recovers. Unless forced, the entry is only deferred if the cache is
+// degraded. Returns true if the entry was deferred.
+func deferFileEntry(fileID uint64, version int64, data string,
+	ttl time.Duration, force bool) bool {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+
+	if !force && !degraded.Load() {
+		return false
+	}
+
+	// Keep the newest entry for each file.
+	current, found := pendingFiles[fileID]
+	if found && current.version > version {
+		return true
+	}
+	if !found && !hasPendingCapacity() {
+		return true
+	}
+	pendingFiles[fileID] = pendingFileEntry{version: version, data: data,
+		ttl: ttl}
+	reportPendingInvalidations()
+	return true
+}
+
+// Defer invalidating the cached file lists of the specified device until the
+// cache recovers. Unless forced, the invalidation is only deferred if the
+// cache is degraded. Returns true if the invalidation was deferred.
+func deferFileListInvalidation(tenantID string, deviceID string,
+	force bool) bool {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+
+	if !force && !degraded.Load() {
+		return false
+	}
+
+	device := deviceKey{tenantID: tenantID, deviceID: deviceID}
+	if !pendingDevices[device] && !hasPendingCapacity() {
+		return true
+	}
+	pendingDevices[device] = true
+	reportPendingInvalidations()
+	return true
+}
+
+// Must be called with the pending lock held.
+func hasPendingCapacity() bool {
+	if len(pendingFiles)+len(pendingDevices) < maxPendingInvalidations {
+		return true
+	}
+	pendingOverflow = true
+	metrics.MetricCacheDroppedInvalidations.Inc()
+	return false
+}
+
+// Must be called with the pending lock held.
+func reportPendingInvalidations() {
+	metrics.MetricCachePendingInvalidations.Set(
+		float64(len(pendingFiles) + len(pendingDevices)))
+}
+
+// availabilityMonitor is a Redis client hook that counts consecutive cache
+// operations that failed because the cache could not be reached, and
+// degrades the cache once there are too many of them.
+type availabilityMonitor struct{}
+
+func (m availabilityMonitor) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (m availabilityMonitor) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		reportAvailability(err)
+		return err
+	}
+}
+
+func (m availabilityMonitor) ProcessPipelineHook(
+	next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		reportAvailability(err)
+		return err
+	}
+}
+
+func reportAvailability(err error) {
+	if !isUnavailableError(err) {
+		consecutiveFailures.Store(0)
+		return
+	}
+	if consecutiveFailures.Add(1) >= degradedFailureThreshold {
+		enterDegradedMode()
+	}
+}
+
+// Returns true if the specified error indicates that the cache could not be
+// reached. Cache misses, errors returned by Redis and operations cancelled on
+// shutdown do not.
+func isUnavailableError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) ||
+		errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
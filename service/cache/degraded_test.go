@@ -0,0 +1,89 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIsUnavailableError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"no error", nil, false},
+		{"cache miss", redis.Nil, false},
+		{"cancelled", context.Canceled, false},
+		{"timeout", context.DeadlineExceeded, true},
+		{"connection refused", &net.OpError{Op: "dial",
+			Err: errors.New("connection refused")}, true},
+		{"wrapped connection error", fmt.Errorf("lookup: %w",
+			&net.OpError{Op: "read", Err: errors.New("reset")}), true},
+		{"closed client", redis.ErrClosed, true},
+	}
+
+	for _, tc := range tests {
+		if actual := isUnavailableError(tc.err); actual != tc.expected {
+			t.Fatalf("%s: Expected %v, Got: %v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestDeferredInvalidations(t *testing.T) {
+	initDegradedMode(&config.Cache{})
+
+	// Invalidations are not deferred unless the cache is degraded.
+	if deferFileEntry(1, 10, invalidatedFileEntry, ttlInvalidatedFile, false) {
+		t.Fatalf("Expected the file entry not to be deferred")
+	}
+	if deferFileListInvalidation("tenant", "device", false) {
+		t.Fatalf("Expected the file list invalidation not to be deferred")
+	}
+
+	// Only the newest entry of each file is replayed.
+	degraded.Store(true)
+	defer degraded.Store(false)
+	deferFileEntry(1, 20, invalidatedFileEntry, ttlInvalidatedFile, false)
+	deferFileEntry(1, 10, invalidatedFileEntry, ttlInvalidatedFile, false)
+	deferFileEntry(1, deletedFileVersion, missingFileEntry, ttlFile, false)
+	deferFileEntry(1, 30, invalidatedFileEntry, ttlInvalidatedFile, false)
+	if entry := pendingFiles[1]; entry.version != deletedFileVersion ||
+		entry.data != missingFileEntry {
+		t.Fatalf("Expected the deleted file entry, Got: %+v", entry)
+	}
+
+	// Each device is invalidated once.
+	deferFileListInvalidation("tenant", "device", false)
+	deferFileListInvalidation("tenant", "device", false)
+	if len(pendingDevices) != 1 {
+		t.Fatalf("Expected a single device, Got: %d", len(pendingDevices))
+	}
+
+	// Invalidations are dropped once there are too many of them.
+	for i := uint64(2); len(pendingFiles)+len(pendingDevices) <
+		maxPendingInvalidations; i++ {
+		deferFileEntry(i, 1, invalidatedFileEntry, ttlInvalidatedFile, false)
+	}
+	if !deferFileEntry(0, 1, invalidatedFileEntry, ttlInvalidatedFile, false) ||
+		!pendingOverflow {
+		t.Fatalf("Expected the invalidation to be dropped")
+	}
+	if _, found := pendingFiles[0]; found {
+		t.Fatalf("Expected the dropped invalidation not to be pending")
+	}
+
+	// Newer entries for pending files are still recorded.
+	deferFileEntry(2, 5, invalidatedFileEntry, ttlInvalidatedFile, false)
+	if entry := pendingFiles[2]; entry.version != 5 {
+		t.Fatalf("Expected the newer entry to be pending, Got: %+v", entry)
+	}
+}
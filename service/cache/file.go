@@ -7,18 +7,60 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-// AddFile - cache information about the specified file. This function is
-// typically called from a goroutine and errors adding to the cache are not
-// surfaced to the caller.
-func AddFile(requestID string, fileID uint64, file interface{}) {
-	if !isEnabled {
+// Cached files are versioned. The version of a file is its last update time,
+// which increases with every change to the file. A cache entry is only written
+// if it is not older than the entry in the cache, so that a slow write of a
+// file read before a change cannot replace the entry written for the change.
+// Changes to a file replace its entry with an invalidated entry carrying the
+// new version; the invalidated entry is treated as a cache miss, but prevents
+// older versions of the file from being cached.
+const (
+	// Fields of the hash holding a cached file.
+	fieldVersion = "version"
+	fieldData    = "data"
+
+	// Data cached for invalidated entries and for files that do not exist
+	// (negative cache entries).
+	invalidatedFileEntry = ""
+	missingFileEntry     = "-"
+
+	// Version of files that were deleted. File IDs are never reused, so the
+	// entries of deleted files are never replaced.
+	deletedFileVersion = math.MaxInt64
+)
+
+// LUA script to write a versioned cache entry. The entry is only written if
+// the key does not exist or holds an entry whose version is not newer than
+// the version being written. Returns 1 if the entry was written, 0 otherwise.
+var setVersionedEntryScript = redis.NewScript(`
+local current = redis.call("hget", KEYS[1], "version")
+if current and tonumber(current) > tonumber(ARGV[1]) then
+  return 0
+end
+redis.call("hset", KEYS[1], "version", ARGV[1], "data", ARGV[2])
+redis.call("pexpire", KEYS[1], ARGV[3])
+return 1
+`)
+
+// AddFile - cache information about the specified version of a file. The file
+// is not cached if a newer version of the file has already been cached or
+// invalidated. This function is typically called from a goroutine and errors
+// adding to the cache are not surfaced to the caller. Cache operations are
+// traced as part of the span in the specified context, but are not cancelled
+// along with it.
+func AddFile(ctx context.Context, requestID string, fileID uint64,
+	version time.Time, file interface{}) {
+	if !isAvailable() {
 		return
 	}
 
@@ -27,6 +69,7 @@ func AddFile(requestID string, fileID uint64, file interface{}) {
 	if err != nil {
 		fsLogger.Error("Failed to marshal file for caching!",
 			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
 			zap.Uint64("File ID: ", fileID),
 			zap.Error(err),
 		)
@@ -34,17 +77,12 @@ func AddFile(requestID string, fileID uint64, file interface{}) {
 	}
 
 	// Add the file to the cache.
-	ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
-	defer cancelFunc()
-
-	start := time.Now()
-	err = cacheClient.Set(ctx, fmt.Sprintf(filePrefix, fileID),
-		cacheEntry, ttlFile).Err()
-	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
-		operationCacheSet)
+	err = setFileEntry(ctx, fileID, version.UnixMicro(), string(cacheEntry),
+		ttlFile)
 	if err != nil {
 		fsLogger.Error("Failed to add the file to the cache!",
 			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
 			zap.Uint64("File ID: ", fileID),
 			zap.Error(err),
 		)
@@ -52,29 +90,54 @@ func AddFile(requestID string, fileID uint64, file interface{}) {
 	}
 }
 
-// GetFile - retrieve information about a file object from the cache.
-func GetFile(requestID string, fileID uint64) ([]byte, error) {
-	if !isEnabled {
+// AddMissingFile - cache the fact that the specified file does not exist, so
+// that repeated lookups of the file do not reach the database. The negative
+// cache entry is short lived and is never written over a cached version of
+// the file. Errors adding to the cache are not surfaced to the caller.
+func AddMissingFile(ctx context.Context, requestID string, fileID uint64) {
+	if !isAvailable() {
+		return
+	}
+
+	err := setFileEntry(ctx, fileID, 0, missingFileEntry, ttlMissingFile)
+	if err != nil {
+		fsLogger.Error("Failed to add the missing file to the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID: ", fileID),
+			zap.Error(err),
+		)
+		metrics.MetricCacheSetFileFailures.Inc()
+	}
+}
+
+// GetFile - retrieve information about a file object from the cache. If the
+// file is cached as not existing, ErrCacheMissing is returned.
+func GetFile(ctx context.Context, requestID string, fileID uint64) ([]byte, error) {
+	if !isAvailable() {
 		return nil, ErrCacheNotFound
 	}
 
 	// Get the requested file object from the cache.
-	ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
 	defer cancelFunc()
 
 	start := time.Now()
-	cacheEntry, err := cacheClient.Get(ctx,
-		fmt.Sprintf(filePrefix, fileID)).Result()
+	cacheEntry, err := cacheClient.HGet(ctx, fmt.Sprintf(filePrefix, fileID),
+		fieldData).Result()
 	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
 		operationCacheGet)
 	if err != nil {
 		if err == redis.Nil {
 			metrics.MetricCacheGetFileCacheMisses.Inc()
+			metrics.MetricCacheMisses.WithLabelValues(TypeFile).Inc()
 			return nil, ErrCacheNotFound
 		}
 
 		fsLogger.Error("Error while looking up the file in the cache!",
 			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
 			zap.Uint64("File ID: ", fileID),
 			zap.Error(err),
 		)
@@ -82,32 +145,104 @@ func GetFile(requestID string, fileID uint64) ([]byte, error) {
 		return nil, err
 	}
 
+	switch cacheEntry {
+	case invalidatedFileEntry:
+		metrics.MetricCacheGetFileCacheMisses.Inc()
+		metrics.MetricCacheMisses.WithLabelValues(TypeFile).Inc()
+		return nil, ErrCacheNotFound
+
+	case missingFileEntry:
+		metrics.MetricCacheHits.WithLabelValues(TypeMissingFile).Inc()
+		return nil, ErrCacheMissing
+	}
+
 	metrics.MetricCacheGetFileCacheHits.Inc()
+	metrics.MetricCacheHits.WithLabelValues(TypeFile).Inc()
 	return []byte(cacheEntry), nil
 }
 
-// RemoveFile - remove cached information about the specified file. This
-// function is typically called from within a goroutine and errors removing
-// from the cache are not surfaced to the caller.
-func RemoveFile(requestID string, fileID uint64) {
+// InvalidateFile - invalidate cached information about the specified file,
+// which was changed and now has the specified version. Older versions of the
+// file can no longer be cached. This function is called synchronously from
+// the paths that change files, once the change is committed. Errors are not
+// surfaced to the caller; invalidations that fail are retried once the cache
+// recovers.
+func InvalidateFile(ctx context.Context, requestID string, fileID uint64,
+	version time.Time) {
 	if !isEnabled {
 		return
 	}
 
-	// Delete the requested file object from the cache.
-	ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
-	defer cancelFunc()
+	err := writeFileInvalidation(ctx, fileID, version.UnixMicro(),
+		invalidatedFileEntry, ttlInvalidatedFile)
+	if err != nil {
+		fsLogger.Error("Failed to invalidate the file in the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID: ", fileID),
+			zap.Error(err),
+		)
+		metrics.MetricCacheDelFileFailures.Inc()
+	}
+}
 
-	start := time.Now()
-	err := cacheClient.Del(ctx, fmt.Sprintf(filePrefix, fileID)).Err()
-	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
-		operationCacheDel)
+// RemoveFile - remove cached information about the specified file, which was
+// deleted. The file is cached as not existing, and can no longer be cached.
+// Errors removing from the cache are not surfaced to the caller.
+func RemoveFile(ctx context.Context, requestID string, fileID uint64) {
+	if !isEnabled {
+		return
+	}
+
+	err := writeFileInvalidation(ctx, fileID, deletedFileVersion,
+		missingFileEntry, ttlFile)
 	if err != nil {
 		fsLogger.Error("Failed to remove the file from the cache!",
 			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
 			zap.Uint64("File ID: ", fileID),
 			zap.Error(err),
 		)
 		metrics.MetricCacheDelFileFailures.Inc()
 	}
 }
+
+// Write the specified cache entry invalidating a file. If the cache is
+// degraded, or the entry cannot be written, the entry is deferred until the
+// cache recovers and the cache is bypassed until then.
+func writeFileInvalidation(ctx context.Context, fileID uint64, version int64,
+	data string, ttl time.Duration) error {
+	if deferFileEntry(fileID, version, data, ttl, false) {
+		return nil
+	}
+
+	err := setFileEntry(ctx, fileID, version, data, ttl)
+	if err != nil {
+		deferFileEntry(fileID, version, data, ttl, true)
+		enterDegradedMode()
+	}
+	return err
+}
+
+// Write the specified versioned cache entry for a file, unless a newer
+// version of the file is already cached.
+func setFileEntry(ctx context.Context, fileID uint64, version int64,
+	data string, ttl time.Duration) error {
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	written, err := setVersionedEntryScript.Run(ctx, cacheClient,
+		[]string{fmt.Sprintf(filePrefix, fileID)},
+		strconv.FormatInt(version, 10), data, ttl.Milliseconds()).Int()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheSet)
+	if err != nil {
+		return err
+	}
+	if written == 0 {
+		metrics.MetricCacheStaleWritesRejected.WithLabelValues(TypeFile).Inc()
+	}
+	return nil
+}
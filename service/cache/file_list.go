@@ -0,0 +1,192 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Lists of the files belonging to a device are cached under the current
+// generation of the device. The generation is incremented whenever a file of
+// the device is created, updated or deleted, which invalidates all lists
+// cached for the previous generation without having to find and remove them.
+// Lists cached for older generations expire on their own.
+
+// GetDeviceGeneration - retrieve the current generation of the files of the
+// specified device. The generation must be retrieved before the list of files
+// is read from the database, so that a list read concurrently with a change
+// to the files is cached under the generation preceding the change.
+func GetDeviceGeneration(ctx context.Context, requestID string, tenantID string,
+	deviceID string) (int64, error) {
+	if !isEnabled {
+		return 0, ErrCacheDisabled
+	}
+	if !isAvailable() {
+		return 0, ErrCacheDegraded
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	generation, err := cacheClient.Get(ctx,
+		fmt.Sprintf(deviceGenerationPrefix, tenantID, deviceID)).Int64()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheGet)
+	if err != nil {
+		// Devices whose files have not changed recently have no generation.
+		if err == redis.Nil {
+			return 0, nil
+		}
+
+		fsLogger.Error("Error while looking up the device generation in the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID: ", tenantID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		metrics.MetricCacheDeviceGenerationFailures.Inc()
+		return 0, err
+	}
+	return generation, nil
+}
+
+// InvalidateFileList - increment the generation of the files of the specified
+// device, invalidating any cached lists of its files. Errors are not surfaced
+// to the caller; invalidations that fail are retried once the cache recovers.
+func InvalidateFileList(ctx context.Context, requestID string, tenantID string,
+	deviceID string) {
+	if !isEnabled {
+		return
+	}
+
+	// If the cache is degraded, invalidate the lists once it recovers.
+	if deferFileListInvalidation(tenantID, deviceID, false) {
+		return
+	}
+
+	err := incrementDeviceGeneration(ctx, tenantID, deviceID)
+	if err != nil {
+		deferFileListInvalidation(tenantID, deviceID, true)
+		enterDegradedMode()
+
+		fsLogger.Error("Failed to invalidate the cached file lists for the device!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID: ", tenantID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		metrics.MetricCacheDeviceGenerationFailures.Inc()
+	}
+}
+
+// Increment the generation of the files of the specified device.
+func incrementDeviceGeneration(ctx context.Context, tenantID string,
+	deviceID string) error {
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	key := fmt.Sprintf(deviceGenerationPrefix, tenantID, deviceID)
+	start := time.Now()
+	_, err := cacheClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, ttlDeviceGeneration)
+		return nil
+	})
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheIncr)
+	return err
+}
+
+// AddFileList - cache the list of files of the specified device under the
+// specified generation. Errors adding to the cache are not surfaced to the
+// caller.
+func AddFileList(ctx context.Context, requestID string, tenantID string,
+	deviceID string, generation int64, files interface{}) {
+	if !isAvailable() {
+		return
+	}
+
+	// Marshal the list of files for caching.
+	cacheEntry, err := json.Marshal(files)
+	if err != nil {
+		fsLogger.Error("Failed to marshal the file list for caching!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	err = cacheClient.Set(ctx, fmt.Sprintf(fileListPrefix, tenantID, deviceID,
+		generation), cacheEntry, ttlFileList).Err()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheSet)
+	if err != nil {
+		fsLogger.Error("Failed to add the file list to the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID: ", tenantID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		metrics.MetricCacheSetFileListFailures.Inc()
+	}
+}
+
+// GetFileList - retrieve the list of files of the specified device cached
+// under the specified generation.
+func GetFileList(ctx context.Context, requestID string, tenantID string,
+	deviceID string, generation int64) ([]byte, error) {
+	if !isAvailable() {
+		return nil, ErrCacheNotFound
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	cacheEntry, err := cacheClient.Get(ctx, fmt.Sprintf(fileListPrefix,
+		tenantID, deviceID, generation)).Result()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheGet)
+	if err != nil {
+		if err == redis.Nil {
+			metrics.MetricCacheMisses.WithLabelValues(TypeFileList).Inc()
+			return nil, ErrCacheNotFound
+		}
+
+		fsLogger.Error("Error while looking up the file list in the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID: ", tenantID),
+			zap.String("Device ID: ", deviceID),
+			zap.Error(err),
+		)
+		metrics.MetricCacheGetFileListFailures.Inc()
+		return nil, err
+	}
+
+	metrics.MetricCacheHits.WithLabelValues(TypeFileList).Inc()
+	return []byte(cacheEntry), nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"go.uber.org/zap"
+)
+
+// Address (host:port) of the Redis server used to run the cache tests. The
+// tests are skipped if no server is specified.
+const envTestCacheServer = "FS_TEST_CACHE_SERVER"
+
+type testFile struct {
+	FileID uint64 `json:"file_id"`
+	Status string `json:"status"`
+}
+
+// Connects to the Redis server used for the cache tests and returns a file ID
+// that is not used by any other test.
+func setupCacheTest(t *testing.T) uint64 {
+	address := os.Getenv(envTestCacheServer)
+	if address == "" {
+		t.Skipf("%s is not set - skipping the cache test", envTestCacheServer)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", envTestCacheServer, err)
+	}
+	cacheConfig := config.Cache{Enabled: true, Host: host}
+	cacheConfig.Port, err = strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", envTestCacheServer, err)
+	}
+
+	if err = Init(zap.NewNop(), &cacheConfig); err != nil {
+		t.Fatalf("Failed to connect to the cache: %v", err)
+	}
+
+	fileID := uint64(time.Now().UnixNano())
+	t.Cleanup(func() {
+		cacheClient.Del(context.Background(), fmt.Sprintf(filePrefix, fileID))
+		Shutdown()
+	})
+	return fileID
+}
+
+// Returns the status of the specified file in the cache.
+func getCachedStatus(t *testing.T, fileID uint64) (string, error) {
+	cacheEntry, err := GetFile(context.Background(), "", fileID)
+	if err != nil {
+		return "", err
+	}
+
+	var file testFile
+	if err = json.Unmarshal(cacheEntry, &file); err != nil {
+		t.Fatalf("Failed to unmarshal the cached file: %v", err)
+	}
+	return file.Status, nil
+}
+
+// A file is read from the database while it is being updated. The update is
+// committed and invalidates the cache entry before the stale read adds the
+// file to the cache. The stale file must not be cached.
+func TestStaleFileIsNotCachedAfterUpdate(t *testing.T) {
+	fileID := setupCacheTest(t)
+	ctx := context.Background()
+	readAt := time.Now()
+	updatedAt := readAt.Add(time.Millisecond)
+
+	tests := []struct {
+		name     string
+		steps    func()
+		expected string
+		err      error
+	}{
+		{"stale write after invalidation", func() {
+			InvalidateFile(ctx, "", fileID, updatedAt)
+			AddFile(ctx, "", fileID, readAt, testFile{fileID, "new"})
+		}, "", ErrCacheNotFound},
+		{"stale write before invalidation", func() {
+			AddFile(ctx, "", fileID, readAt, testFile{fileID, "new"})
+			InvalidateFile(ctx, "", fileID, updatedAt)
+		}, "", ErrCacheNotFound},
+		{"fresh write after invalidation", func() {
+			InvalidateFile(ctx, "", fileID, updatedAt)
+			AddFile(ctx, "", fileID, updatedAt, testFile{fileID, "uploaded"})
+		}, "uploaded", nil},
+		{"stale write after fresh write", func() {
+			AddFile(ctx, "", fileID, updatedAt, testFile{fileID, "uploaded"})
+			AddFile(ctx, "", fileID, readAt, testFile{fileID, "new"})
+		}, "uploaded", nil},
+		{"missing file over cached file", func() {
+			AddFile(ctx, "", fileID, updatedAt, testFile{fileID, "uploaded"})
+			AddMissingFile(ctx, "", fileID)
+		}, "uploaded", nil},
+		{"created file over missing file", func() {
+			AddMissingFile(ctx, "", fileID)
+			AddFile(ctx, "", fileID, readAt, testFile{fileID, "new"})
+		}, "new", nil},
+		{"stale write after deletion", func() {
+			RemoveFile(ctx, "", fileID)
+			AddFile(ctx, "", fileID, updatedAt, testFile{fileID, "uploaded"})
+		}, "", ErrCacheMissing},
+	}
+
+	for _, tc := range tests {
+		cacheClient.Del(ctx, fmt.Sprintf(filePrefix, fileID))
+		tc.steps()
+
+		status, err := getCachedStatus(t, fileID)
+		if err != tc.err || status != tc.expected {
+			t.Fatalf("%s: Expected status %q (error: %v), Got: %q (error: %v)",
+				tc.name, tc.expected, tc.err, status, err)
+		}
+	}
+}
+
+// Many reads of different versions of a file race to add the file to the
+// cache. The newest version must win, regardless of the order of the writes.
+func TestConcurrentFileWritesKeepNewestVersion(t *testing.T) {
+	fileID := setupCacheTest(t)
+	ctx := context.Background()
+	base := time.Now()
+
+	const versions = 50
+	var wg sync.WaitGroup
+	for i := 0; i < versions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			AddFile(ctx, "", fileID, base.Add(time.Duration(i)*time.Millisecond),
+				testFile{fileID, strconv.Itoa(i)})
+		}(i)
+	}
+	wg.Wait()
+
+	status, err := getCachedStatus(t, fileID)
+	if err != nil || status != strconv.Itoa(versions-1) {
+		t.Fatalf("Expected the newest version %d, Got: %q (error: %v)",
+			versions-1, status, err)
+	}
+}
@@ -5,8 +5,12 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/config"
@@ -18,39 +22,72 @@ var (
 	// Structured logging using Uber Zap.
 	fsLogger *zap.Logger
 
-	cacheClient *redis.Client
+	cacheClient redis.UniversalClient
 	isEnabled   bool
+
+	// Timeout for requests to the Redis cache.
+	cacheTimeout = defaultCacheTimeout
+
+	// Global context for the package. It is cancelled on shutdown to abort
+	// outstanding cache operations.
 	gCtx        context.Context
+	gCancelFunc context.CancelFunc
 
 	// Errors
 	ErrCacheNotFound = errors.New("item not found in cache")
+	ErrCacheMissing  = errors.New("item is cached as not existing")
+	ErrCacheDisabled = errors.New("caching is disabled")
+	ErrCacheDegraded = errors.New("cache is degraded")
+
+	ErrInvalidCaCertificate = errors.New("failed to parse the CA certificate")
 )
 
 const (
 	// Cache connection string.
 	cacheConnStr = "%s:%d"
 
-	// Timeout for requests to the Redis cache.
-	cacheTimeout = (time.Second * 1)
-	dialTimeout  = (time.Second * 5)
-	readTimeout  = (time.Second * 3)
-	writeTimeout = (time.Second * 3)
-	poolSize     = 10
-	poolTimeout  = (time.Second * 4)
+	// Default timeouts and connection pool size for the Redis cache.
+	defaultCacheTimeout = (time.Second * 1)
+	dialTimeout         = (time.Second * 5)
+	readTimeout         = (time.Second * 3)
+	writeTimeout        = (time.Second * 3)
+	poolSize            = 10
+	poolTimeout         = (time.Second * 4)
 
 	// Cache key prefix strings.
-	filePrefix = "file:%d"
+	filePrefix             = "file_entry:%d"
+	fileListPrefix         = "file_list:%s:%s:%d"
+	deviceGenerationPrefix = "device_generation:%s:%s"
+	redirectUrlPrefix      = "redirect_url:%s"
+
+	// TTLs for cache entries. Invalidated files must remain cached for longer
+	// than a file can take to be read from the database and cached, so that
+	// versions read before the file was changed are not cached. File lists
+	// must expire before the device generation keys, so that a list cached
+	// for a generation can never be read again after the generation key
+	// expires and is counted again.
+	ttlFile             = (time.Hour * 2)
+	ttlMissingFile      = (time.Second * 30)
+	ttlInvalidatedFile  = (time.Minute * 5)
+	ttlFileList         = (time.Minute * 10)
+	ttlDeviceGeneration = (time.Hour * 24)
 
-	// TTLs for cache entries.
-	ttlFile = (time.Hour * 2)
+	// Types of cache entries, used to report cache hit rates.
+	TypeFile        = "file"
+	TypeMissingFile = "missing_file"
+	TypeFileList    = "file_list"
 
 	// Caching operation names.
-	operationCacheSet = "set"
-	operationCacheGet = "get"
-	operationCacheDel = "del"
+	operationCacheSet  = "set"
+	operationCacheGet  = "get"
+	operationCacheDel  = "del"
+	operationCacheIncr = "incr"
+	operationCacheLock = "lock"
 )
 
-// Init - initialize a connection to the Redis based file cache.
+// Init - initialize a connection to the Redis based file cache. If the cache
+// cannot be reached, the service starts with the cache in degraded mode and
+// requests are served from the database until the cache recovers.
 func Init(logger *zap.Logger, cacheConfig *config.Cache) error {
 	fsLogger = logger
 	isEnabled = cacheConfig.Enabled
@@ -61,37 +98,153 @@ func Init(logger *zap.Logger, cacheConfig *config.Cache) error {
 	}
 
 	// Initialize the cache client with appropriate connection options.
-	cacheClient = redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf(cacheConnStr, cacheConfig.Host, cacheConfig.Port),
-		Password:     cacheConfig.Password,
-		DB:           cacheConfig.CacheDatabase,
-		DialTimeout:  dialTimeout,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		PoolSize:     poolSize,
-		PoolTimeout:  poolTimeout,
-	})
+	var err error
+	cacheClient, err = newClient(cacheConfig)
+	if err != nil {
+		isEnabled = false
+		return err
+	}
+	cacheClient.AddHook(commandTracer{})
+	cacheClient.AddHook(availabilityMonitor{})
+
+	cacheTimeout = getDuration(cacheConfig.OperationTimeoutMs, time.Millisecond,
+		defaultCacheTimeout)
+	initDegradedMode(cacheConfig)
 
 	// Attempt to connect to the file cache.
-	gCtx = context.Background()
+	gCtx, gCancelFunc = context.WithCancel(context.Background())
 	ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
 	defer cancelFunc()
 
-	_, err := cacheClient.Ping(ctx).Result()
+	_, err = cacheClient.Ping(ctx).Result()
 	if err != nil {
-		fsLogger.Error("Failed to connect to the file cache!",
-			zap.String("Cache address: ", cacheClient.Options().Addr),
+		fsLogger.Error("Failed to connect to the file cache! Requests will be served from the database until it recovers.",
+			zap.String("Cache mode: ", getMode(cacheConfig)),
+			zap.Strings("Cache addresses: ", getAddresses(cacheConfig)),
 			zap.Error(err),
 		)
-		return err
+		enterDegradedMode()
+		return nil
 	}
 
 	fsLogger.Info("Successfully initialized the file cache!",
-		zap.String("Cache address: ", cacheClient.Options().Addr),
+		zap.String("Cache mode: ", getMode(cacheConfig)),
+		zap.Strings("Cache addresses: ", getAddresses(cacheConfig)),
 	)
 	return nil
 }
 
+// Create a client for the file cache in the configured deployment mode.
+func newClient(cacheConfig *config.Cache) (redis.UniversalClient, error) {
+	options := &redis.UniversalOptions{
+		Addrs:            getAddresses(cacheConfig),
+		MasterName:       cacheConfig.MasterName,
+		DB:               cacheConfig.CacheDatabase,
+		Username:         cacheConfig.Username,
+		Password:         cacheConfig.Password,
+		SentinelPassword: cacheConfig.SentinelPassword,
+		DialTimeout: getDuration(cacheConfig.DialTimeoutMs, time.Millisecond,
+			dialTimeout),
+		ReadTimeout: getDuration(cacheConfig.ReadTimeoutMs, time.Millisecond,
+			readTimeout),
+		WriteTimeout: getDuration(cacheConfig.WriteTimeoutMs, time.Millisecond,
+			writeTimeout),
+		PoolTimeout: getDuration(cacheConfig.PoolTimeoutMs, time.Millisecond,
+			poolTimeout),
+		PoolSize:     poolSize,
+		MinIdleConns: cacheConfig.MinIdleConnections,
+	}
+	if cacheConfig.PoolSize > 0 {
+		options.PoolSize = cacheConfig.PoolSize
+	}
+
+	// Load the CA certificates and initialize TLS configuration.
+	if cacheConfig.TlsEnabled {
+		options.TLSConfig = &tls.Config{
+			ServerName: cacheConfig.TlsServerName,
+			MinVersion: tls.VersionTLS12,
+		}
+		if cacheConfig.TlsCaCertificate != "" {
+			certs, err := loadCaCertificate(cacheConfig.TlsCaCertificate)
+			if err != nil {
+				return nil, err
+			}
+			options.TLSConfig.RootCAs = certs
+		}
+	}
+
+	switch getMode(cacheConfig) {
+	case config.CacheModeSentinel:
+		return redis.NewFailoverClient(options.Failover()), nil
+	case config.CacheModeCluster:
+		return redis.NewClusterClient(options.Cluster()), nil
+	default:
+		return redis.NewClient(options.Simple()), nil
+	}
+}
+
+// Returns the deployment mode of the file cache. Configuration files that
+// predate the cache modes use standalone mode.
+func getMode(cacheConfig *config.Cache) string {
+	if cacheConfig.Mode == "" {
+		return config.CacheModeStandalone
+	}
+	return cacheConfig.Mode
+}
+
+// Returns the addresses used to connect to the file cache.
+func getAddresses(cacheConfig *config.Cache) []string {
+	if getMode(cacheConfig) == config.CacheModeStandalone {
+		return []string{fmt.Sprintf(cacheConnStr, cacheConfig.Host,
+			cacheConfig.Port)}
+	}
+	return cacheConfig.Addresses
+}
+
+// Returns the specified number of units as a duration, or the default
+// duration if not specified.
+func getDuration(value int, unit time.Duration,
+	defaultDuration time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(value) * unit
+}
+
+func loadCaCertificate(caCertPath string) (*x509.CertPool, error) {
+	certs := x509.NewCertPool()
+
+	pemData, err := os.ReadFile(filepath.Clean(caCertPath))
+	if err != nil {
+		fsLogger.Error("Failed to read the CA certificate file for the file cache!",
+			zap.String("CA certificate path", caCertPath),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	if !certs.AppendCertsFromPEM(pemData) {
+		fsLogger.Error("Failed to parse the CA certificate file for the file cache!",
+			zap.String("CA certificate path", caCertPath),
+		)
+		return nil, ErrInvalidCaCertificate
+	}
+
+	return certs, nil
+}
+
+// IsEnabled returns true if file caching is enabled.
+func IsEnabled() bool {
+	return isEnabled
+}
+
+// Ping checks whether the file cache can be reached.
+func Ping(ctx context.Context) error {
+	if !isEnabled {
+		return ErrCacheDisabled
+	}
+	return cacheClient.Ping(ctx).Err()
+}
+
 // Shutdown the file cache and cleanup Redis connections.
 func Shutdown() {
 	if !isEnabled {
@@ -99,8 +252,9 @@ func Shutdown() {
 		return
 	}
 
-	gCtx.Done()
 	isEnabled = false
+	gCancelFunc()
+	shutdownDegradedMode()
 
 	// Close the client connection to the cache.
 	err := cacheClient.Close()
@@ -5,87 +5,230 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
-	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/metrics"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 const (
-	// Name of the Redis key to be used as a leader lock.
-	leaderLockName = "krypton-fs-leader"
+	// Name of the Redis key holding the leader lease. The key and the fencing
+	// token counter share a hash tag so that the scripts operating on both can
+	// run in cluster mode.
+	leaderLeaseName = "{krypton-fs-leader}:lease"
 
-	// TTL set for the leader lock. It should expire after this duration.
-	LeaderLockLifetime = (time.Second) * 10
+	// Name of the Redis key holding the last fencing token issued to a leader.
+	// It is never expired, so that fencing tokens keep increasing.
+	leaderFencingTokenName = "{krypton-fs-leader}:fencing_token"
 
-	// Length of the random value to be set on the leader lock. This
-	// value will vary for each of the nodes/pods of the FS service. It
-	// provides a way for the node to identify itself as the owner of the
-	// lock before releasing it.
-	randomStringLength = 10
+	// Fields of the hash holding the leader lease.
+	fieldHolder       = "holder"
+	fieldFencingToken = "fencing_token"
+	fieldAcquiredAt   = "acquired_at"
+	fieldRenewedAt    = "renewed_at"
 )
 
-var leaderLockValue = ""
+// LeaderLease describes the leader lease held in the cache.
+type LeaderLease struct {
+	// Identity of the node holding the lease.
+	Holder string
 
-// LUA script to release the leader lock. It checks to see if the value set for
-// the leader lock key is the same random value determined for this node. If so,
-// it proceeds to release the lock by deleting the key. If not, it fails to
-// release the leader lock.
-var releaseLeaderLockScript = redis.NewScript(`
-if redis.call("get",KEYS[1]) == ARGV[1] then
-  return redis.call("del",KEYS[1])
-else
+	// Fencing token issued to the holder when the lease was acquired.
+	FencingToken int64
+
+	// Times at which the lease was acquired, last renewed and expires.
+	AcquiredAt time.Time
+	RenewedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// ErrLeaderLeaseNotHeld is returned when the leader lease is not held by the
+// caller.
+var ErrLeaderLeaseNotHeld = errors.New("leader lease is not held")
+
+// LUA script to acquire the leader lease. If the lease is not held by another
+// node, a new fencing token is issued and the lease is written with the
+// specified lifetime. Returns the fencing token, or 0 if the lease is held.
+var acquireLeaderLeaseScript = redis.NewScript(`
+if redis.call("exists", KEYS[1]) == 1 then
   return 0
 end
+local token = redis.call("incr", KEYS[2])
+redis.call("hset", KEYS[1], "holder", ARGV[1], "fencing_token", token,
+  "acquired_at", ARGV[3], "renewed_at", ARGV[3])
+redis.call("pexpire", KEYS[1], ARGV[2])
+return token
 `)
 
-// AcquireLeaderLock attempts to acquire a lock for this FS node by writing
-// a random string value to the leader lock key in the FS Redis cache. If
-// the leader lock key is already set in the cache, the lock cannot be
-// acquired. The caller can sleep for a while and retry acquiring the leader
-// lock.
-func AcquireLeaderLock() bool {
-	ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
-	defer cancelFunc()
+// LUA script to renew the leader lease. The lease is only extended if it is
+// still held with the specified fencing token. Returns 1 if renewed.
+var renewLeaderLeaseScript = redis.NewScript(`
+if redis.call("hget", KEYS[1], "fencing_token") ~= ARGV[1] then
+  return 0
+end
+redis.call("hset", KEYS[1], "renewed_at", ARGV[3])
+redis.call("pexpire", KEYS[1], ARGV[2])
+return 1
+`)
+
+// LUA script to release the leader lease. The lease is only deleted if it is
+// still held with the specified fencing token, so that a node whose lease
+// expired cannot release the lease of the next leader.
+var releaseLeaderLeaseScript = redis.NewScript(`
+if redis.call("hget", KEYS[1], "fencing_token") == ARGV[1] then
+  return redis.call("del", KEYS[1])
+end
+return 0
+`)
 
-	// If not already set, assign a new random value to be set for the
-	// leader lock.
-	if leaderLockValue == "" {
-		leaderLockValue = common.NewRandomString(randomStringLength)
+// AcquireLeaderLease attempts to acquire the leader lease for the specified
+// node for the specified lifetime. Returns the fencing token issued to the
+// node, or 0 if the lease is held by another node. The lease must be renewed
+// before it expires.
+func AcquireLeaderLease(ctx context.Context, holder string,
+	lifetime time.Duration) (int64, error) {
+	if !isAvailable() {
+		return 0, ErrCacheDegraded
 	}
 
-	// Set key to hold string value if key does not exist. In that case,
-	// it is equal to SET. When key already holds a value, no operation
-	// is performed. SETNX is short for "SET if Not eXists".
-	acquired, err := cacheClient.SetNX(ctx, leaderLockName, leaderLockValue,
-		LeaderLockLifetime).Result()
-	if (err != nil) || (!acquired) {
-		fsLogger.Error("Failed to acquire the leader lock!",
-			zap.Bool("Lock acquisition status:", acquired),
+	ctx, cancelFunc := context.WithTimeout(ctx, cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	token, err := acquireLeaderLeaseScript.Run(ctx, cacheClient,
+		[]string{leaderLeaseName, leaderFencingTokenName}, holder,
+		lifetime.Milliseconds(), start.UnixMilli()).Int64()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheLock)
+	if err != nil {
+		fsLogger.Error("Failed to execute the LUA script to acquire the leader lease!",
+			zap.String("Holder: ", holder),
 			zap.Error(err),
 		)
-		return false
+		return 0, err
+	}
+	return token, nil
+}
+
+// RenewLeaderLease extends the leader lease held with the specified fencing
+// token by the specified lifetime. Returns ErrLeaderLeaseNotHeld if the lease
+// expired or is held by another node.
+func RenewLeaderLease(ctx context.Context, fencingToken int64,
+	lifetime time.Duration) error {
+	if !isEnabled {
+		return ErrCacheDisabled
 	}
 
-	return true
+	ctx, cancelFunc := context.WithTimeout(ctx, cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	renewed, err := renewLeaderLeaseScript.Run(ctx, cacheClient,
+		[]string{leaderLeaseName}, strconv.FormatInt(fencingToken, 10),
+		lifetime.Milliseconds(), start.UnixMilli()).Int()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheLock)
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return ErrLeaderLeaseNotHeld
+	}
+	return nil
 }
 
-// ReleaseLeaderLock releases the leader lock if it is currently held by this
-// FS node. Ownership of the leader lock is determined by checking to see if the
-// leader lock key in the FS redis cache is set to the expected random string for
-// this node. If the key is set to any other value, the lock cannot be released
-// since this node doesn't own it.
-func ReleaseLeaderLock() {
-	ctx, cancelFunc := context.WithTimeout(gCtx, cacheTimeout)
+// CheckLeaderLease returns nil if the leader lease is still held with the
+// specified fencing token, and ErrLeaderLeaseNotHeld otherwise.
+func CheckLeaderLease(ctx context.Context, fencingToken int64) error {
+	if !isEnabled {
+		return ErrCacheDisabled
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, cacheTimeout)
 	defer cancelFunc()
 
-	// Release the leader lock.
-	_, err := releaseLeaderLockScript.Run(ctx, cacheClient,
-		[]string{leaderLockName}, leaderLockValue).Int()
+	start := time.Now()
+	token, err := cacheClient.HGet(ctx, leaderLeaseName,
+		fieldFencingToken).Int64()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheLock)
+	if err == redis.Nil || (err == nil && token != fencingToken) {
+		return ErrLeaderLeaseNotHeld
+	}
+	return err
+}
+
+// ReleaseLeaderLease releases the leader lease if it is still held with the
+// specified fencing token. If the lease is held by another node, it is left
+// untouched.
+func ReleaseLeaderLease(ctx context.Context, fencingToken int64) {
+	if !isEnabled {
+		return
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	_, err := releaseLeaderLeaseScript.Run(ctx, cacheClient,
+		[]string{leaderLeaseName}, strconv.FormatInt(fencingToken, 10)).Int()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheLock)
 	if err != nil {
-		fsLogger.Error("Failed to execute the LUA script to release the leader lock!",
+		fsLogger.Error("Failed to execute the LUA script to release the leader lease!",
+			zap.Int64("Fencing token: ", fencingToken),
 			zap.Error(err),
 		)
 	}
 }
+
+// GetLeaderLease returns the leader lease currently held in the cache, or
+// ErrLeaderLeaseNotHeld if no node holds the lease.
+func GetLeaderLease(ctx context.Context) (*LeaderLease, error) {
+	if !isEnabled {
+		return nil, ErrCacheDisabled
+	}
+
+	ctx, cancelFunc := context.WithTimeout(ctx, cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	var fieldsCmd *redis.MapStringStringCmd
+	var ttlCmd *redis.DurationCmd
+	_, err := cacheClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		fieldsCmd = pipe.HGetAll(ctx, leaderLeaseName)
+		ttlCmd = pipe.PTTL(ctx, leaderLeaseName)
+		return nil
+	})
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheLock)
+	if err != nil {
+		return nil, err
+	}
+	fields, ttl := fieldsCmd.Val(), ttlCmd.Val()
+	if len(fields) == 0 {
+		return nil, ErrLeaderLeaseNotHeld
+	}
+
+	lease := LeaderLease{
+		Holder:     fields[fieldHolder],
+		AcquiredAt: parseUnixMilli(fields[fieldAcquiredAt]),
+		RenewedAt:  parseUnixMilli(fields[fieldRenewedAt]),
+	}
+	lease.FencingToken, _ = strconv.ParseInt(fields[fieldFencingToken], 10, 64)
+	if ttl > 0 {
+		lease.ExpiresAt = start.Add(ttl)
+	}
+	return &lease, nil
+}
+
+func parseUnixMilli(value string) time.Time {
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(millis)
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// One-time redirect URLs are identified by a random nonce, under which the
+// cache holds the entry describing the URL until it is redeemed or expires.
+// Unlike cached files, these entries only exist in the cache, so redirect
+// URLs can neither be issued nor redeemed while the cache is unavailable.
+
+// ErrRedirectUrlExists is returned if an entry already exists for the nonce
+// of a new redirect URL.
+var ErrRedirectUrlExists = errors.New("redirect url nonce is already in use")
+
+// LUA script to redeem a redirect URL. The entry is only deleted if it still
+// holds the data that was checked by the caller, so that each redirect URL is
+// redeemed at most once. Returns 1 if the URL was redeemed, 0 otherwise.
+var redeemRedirectUrlScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+  return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// AddRedirectUrl - add the entry of a new redirect URL with the specified
+// nonce, which expires after the specified lifetime.
+func AddRedirectUrl(ctx context.Context, requestID string, nonce string,
+	entry []byte, lifetime time.Duration) error {
+	if err := checkRedirectUrlsAvailable(); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	added, err := cacheClient.SetNX(ctx, fmt.Sprintf(redirectUrlPrefix, nonce),
+		entry, lifetime).Result()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheSet)
+	if err != nil {
+		fsLogger.Error("Failed to add the redirect URL to the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return err
+	}
+	if !added {
+		return ErrRedirectUrlExists
+	}
+	return nil
+}
+
+// GetRedirectUrl - retrieve the entry of the redirect URL with the specified
+// nonce. If the URL was redeemed or has expired, ErrCacheNotFound is returned.
+func GetRedirectUrl(ctx context.Context, requestID string,
+	nonce string) ([]byte, error) {
+	if err := checkRedirectUrlsAvailable(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	entry, err := cacheClient.Get(ctx,
+		fmt.Sprintf(redirectUrlPrefix, nonce)).Bytes()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheGet)
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrCacheNotFound
+		}
+
+		fsLogger.Error("Error while looking up the redirect URL in the cache!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RedeemRedirectUrl - remove the entry of the redirect URL with the specified
+// nonce, if it still holds the specified entry. Returns ErrCacheNotFound if
+// the URL was already redeemed or has expired.
+func RedeemRedirectUrl(ctx context.Context, requestID string, nonce string,
+	entry []byte) error {
+	if err := checkRedirectUrlsAvailable(); err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		cacheTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	redeemed, err := redeemRedirectUrlScript.Run(ctx, cacheClient,
+		[]string{fmt.Sprintf(redirectUrlPrefix, nonce)}, entry).Int()
+	metrics.ReportLatencyMetric(metrics.MetricCacheLatency, start,
+		operationCacheDel)
+	if err != nil {
+		fsLogger.Error("Failed to execute the LUA script to redeem the redirect URL!",
+			zap.String("Request ID: ", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return err
+	}
+	if redeemed == 0 {
+		return ErrCacheNotFound
+	}
+	return nil
+}
+
+// Returns an error if redirect URLs cannot be used because the cache is
+// disabled or degraded.
+func checkRedirectUrlsAvailable() error {
+	if !isEnabled {
+		return ErrCacheDisabled
+	}
+	if degraded.Load() {
+		return ErrCacheDegraded
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Many callers race to redeem the same redirect URL. Exactly one of them must
+// succeed, and the URL can no longer be looked up afterwards.
+func TestRedirectUrlIsRedeemedOnce(t *testing.T) {
+	nonce := strconv.FormatUint(setupCacheTest(t), 10)
+	ctx := context.Background()
+	entry := []byte(`{"file_id":1,"method":"get"}`)
+
+	if err := AddRedirectUrl(ctx, "", nonce, entry, time.Minute); err != nil {
+		t.Fatalf("Failed to add the redirect URL: %v", err)
+	}
+	err := AddRedirectUrl(ctx, "", nonce, entry, time.Minute)
+	if !errors.Is(err, ErrRedirectUrlExists) {
+		t.Fatalf("Expected %v for a reused nonce, got: %v",
+			ErrRedirectUrlExists, err)
+	}
+	if err = RedeemRedirectUrl(ctx, "", nonce, []byte(`{}`)); !errors.Is(err, ErrCacheNotFound) {
+		t.Fatalf("Expected a changed entry not to be redeemed, got: %v", err)
+	}
+
+	var redeemed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if RedeemRedirectUrl(ctx, "", nonce, entry) == nil {
+				redeemed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if redeemed.Load() != 1 {
+		t.Fatalf("Expected the redirect URL to be redeemed once, got: %d",
+			redeemed.Load())
+	}
+	if _, err = GetRedirectUrl(ctx, "", nonce); !errors.Is(err, ErrCacheNotFound) {
+		t.Fatalf("Expected the redeemed URL not to be found, got: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandTracer is a Redis client hook that creates a span for every command
+// issued to the file cache. The span is a child of the span in the context
+// used to issue the command.
+type commandTracer struct{}
+
+func (t commandTracer) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (t commandTracer) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := tracing.StartClientSpan(ctx, "cache."+cmd.Name(),
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", cmd.Name()),
+		)
+		err := next(ctx, cmd)
+		endCommandSpan(span, err)
+		return err
+	}
+}
+
+func (t commandTracer) ProcessPipelineHook(
+	next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.StartClientSpan(ctx, "cache.pipeline",
+			attribute.String("db.system", "redis"),
+			attribute.Int("db.redis.num_cmd", len(cmds)),
+		)
+		err := next(ctx, cmds)
+		endCommandSpan(span, err)
+		return err
+	}
+}
+
+// Cache misses are expected and are not recorded as errors on the span.
+func endCommandSpan(span trace.Span, err error) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+}
@@ -19,6 +19,13 @@ type (
 		// Name of the file.
 		Name string `json:"name"`
 
+		// Namespace of the file. Files with the same name and namespace are
+		// versions of the same logical file.
+		Namespace string `json:"namespace,omitempty"`
+
+		// Version of the logical file this file represents.
+		Version int64 `json:"version,omitempty"`
+
 		// Checksum of the file.
 		Checksum string `json:"checksum,omitempty"`
 
@@ -32,6 +39,9 @@ type (
 		// A time-limited signed URL which can be used to access the file.
 		SignedUrl string `json:"url,omitempty"`
 
+		// Time at which the signed URL expires.
+		SignedUrlExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+
 		// Creation and modification timestamps for the file.
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at,omitempty"`
@@ -40,11 +50,15 @@ type (
 	// CreateFileRequest - defines the input request structure used to create a
 	// new file.
 	CreateFileRequest struct {
-		Name     string `json:"name"`      // Name of the file being created
-		TenantID string `json:"tenant_id"` // Tenant ID to which file belongs
-		DeviceID string `json:"device_id"` // Device to which file belongs
-		Checksum string `json:"checksum"`  // Checksum of file data
-		Size     int64  `json:"size"`      // Size of the file
+		Name      string `json:"name"`                // Name of the file being created
+		Namespace string `json:"namespace,omitempty"` // Optional namespace for the name
+		TenantID  string `json:"tenant_id"`           // Tenant ID to which file belongs
+		DeviceID  string `json:"device_id"`           // Device to which file belongs
+		Checksum  string `json:"checksum"`            // Checksum of file data
+		Size      int64  `json:"size"`                // Size of the file
+
+		// Optional duration, in seconds, for which the upload URL is valid.
+		UrlDurationSeconds int64 `json:"url_duration_seconds,omitempty"`
 	}
 
 	// CommonFileResponse - defines the response structure for create file, update file
@@ -69,5 +83,284 @@ type (
 		ResponseTime time.Time `json:"response_time"`
 		FileName     string    `json:"file_name,omitempty"`
 		SignedUrl    string    `json:"url,omitempty"`
+		ExpiresAt    time.Time `json:"url_expires_at"`
+	}
+
+	// BatchCreateFilesRequest - defines the input request structure used to
+	// create a batch of new files.
+	BatchCreateFilesRequest struct {
+		Files []CreateFileRequest `json:"files"`
+	}
+
+	// BatchSignedUrlRequest - defines the input request structure used to get
+	// signed download URLs for a batch of files.
+	BatchSignedUrlRequest struct {
+		FileIDs []uint64 `json:"file_ids"`
+
+		// Optional duration, in seconds, for which the download URLs are
+		// valid.
+		UrlDurationSeconds int64 `json:"url_duration_seconds,omitempty"`
+	}
+
+	// BatchItemError - describes why an item of a batch request failed.
+	BatchItemError struct {
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+		Details map[string]string `json:"details,omitempty"`
+	}
+
+	// BatchFileResult - describes the result for an item of a batch request.
+	// Index is the position of the item in the request. Either the file or
+	// the error is set.
+	BatchFileResult struct {
+		Index int              `json:"index"`
+		File  *FileInformation `json:"file,omitempty"`
+		Error *BatchItemError  `json:"error,omitempty"`
+	}
+
+	// BatchFilesResponse - defines the response structure for batch requests.
+	// Results are reported for every item of the request, in order.
+	BatchFilesResponse struct {
+		RequestID    string            `json:"request_id"`
+		ResponseTime time.Time         `json:"response_time"`
+		Succeeded    int               `json:"succeeded"`
+		Failed       int               `json:"failed"`
+		Results      []BatchFileResult `json:"results"`
+	}
+
+	// ScanActionRequest - defines the input request structure used to request
+	// a rescan of a file, or to release or confirm a quarantined file.
+	ScanActionRequest struct {
+		Actor  string `json:"actor"`  // Who is performing the action
+		Reason string `json:"reason"` // Why the action is being performed
+	}
+
+	// FileScanInformation - describes a verdict recorded for a file.
+	FileScanInformation struct {
+		Scanner   string    `json:"scanner"`
+		Verdict   string    `json:"verdict"`
+		Reason    string    `json:"reason,omitempty"`
+		ScannedAt time.Time `json:"scanned_at"`
+	}
+
+	// ListFileScansResponse - defines the response structure for list file
+	// scan requests.
+	ListFileScansResponse struct {
+		RequestID    string                `json:"request_id"`
+		ResponseTime time.Time             `json:"response_time"`
+		FileID       uint64                `json:"file_id"`
+		Scans        []FileScanInformation `json:"scans,omitempty"`
+	}
+
+	// DependencyHealth - describes the health of a dependency of the service.
+	DependencyHealth struct {
+		Status    string `json:"status"`
+		Required  bool   `json:"required"`
+		LatencyMs int64  `json:"latency_ms"`
+		Error     string `json:"error,omitempty"`
+	}
+
+	// HealthResponse - defines the response structure for health requests.
+	HealthResponse struct {
+		Status       string                      `json:"status"`
+		CheckedAt    time.Time                   `json:"checked_at"`
+		Dependencies map[string]DependencyHealth `json:"dependencies,omitempty"`
+	}
+
+	// UsageInformation - describes the usage of the service over a period.
+	UsageInformation struct {
+		Date            string `json:"date,omitempty"`
+		BytesUploaded   int64  `json:"bytes_uploaded"`
+		FilesCreated    int64  `json:"files_created"`
+		DownloadsIssued int64  `json:"downloads_issued"`
+	}
+
+	// UsageResponse - defines the response structure for get usage requests.
+	UsageResponse struct {
+		RequestID    string             `json:"request_id"`
+		ResponseTime time.Time          `json:"response_time"`
+		TenantID     string             `json:"tenant_id"`
+		DeviceID     string             `json:"device_id,omitempty"`
+		From         string             `json:"from"`
+		To           string             `json:"to"`
+		Total        UsageInformation   `json:"total"`
+		Days         []UsageInformation `json:"days,omitempty"`
+	}
+
+	// AuditEventInformation - describes an access to a file or an
+	// administrative action taken on files.
+	AuditEventInformation struct {
+		EventID    uint64    `json:"event_id"`
+		OccurredAt time.Time `json:"occurred_at"`
+		TenantID   string    `json:"tenant_id,omitempty"`
+		Actor      string    `json:"actor,omitempty"`
+		Action     string    `json:"action"`
+		FileID     uint64    `json:"file_id,omitempty"`
+		RequestID  string    `json:"request_id,omitempty"`
+		SourceIP   string    `json:"source_ip,omitempty"`
+		Outcome    string    `json:"outcome"`
+	}
+
+	// AuditEventsResponse - defines the response structure for list audit
+	// events requests.
+	AuditEventsResponse struct {
+		RequestID    string                  `json:"request_id"`
+		ResponseTime time.Time               `json:"response_time"`
+		Count        int                     `json:"count"`
+		Events       []AuditEventInformation `json:"events,omitempty"`
+	}
+
+	// LogLevelRequest - defines the request structure for requests to change
+	// the log level of the service.
+	LogLevelRequest struct {
+		Level string `json:"level"`
+	}
+
+	// LogLevelResponse - defines the response structure for log level
+	// requests.
+	LogLevelResponse struct {
+		RequestID    string    `json:"request_id"`
+		ResponseTime time.Time `json:"response_time"`
+		Level        string    `json:"level"`
+	}
+
+	// LeaderInformation - describes the node holding the leader lease.
+	LeaderInformation struct {
+		NodeID       string     `json:"node_id"`
+		FencingToken int64      `json:"fencing_token"`
+		AcquiredAt   time.Time  `json:"acquired_at"`
+		RenewedAt    time.Time  `json:"renewed_at"`
+		ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	}
+
+	// LeaderStatusResponse - defines the response structure for leader status
+	// requests. The leader is omitted if no node holds the leader lease.
+	LeaderStatusResponse struct {
+		RequestID    string             `json:"request_id"`
+		ResponseTime time.Time          `json:"response_time"`
+		Backend      string             `json:"backend"`
+		NodeID       string             `json:"node_id"`
+		IsLeader     bool               `json:"is_leader"`
+		Leader       *LeaderInformation `json:"leader,omitempty"`
+	}
+
+	// ScavengerRunRequest - defines the optional request structure for
+	// requests to run the database scavenger.
+	ScavengerRunRequest struct {
+		DryRun bool `json:"dry_run"`
+	}
+
+	// ScavengerRunInformation - describes a run of the database scavenger and
+	// its progress. For dry runs, the counts are the number of rows that
+	// would have been deleted.
+	ScavengerRunInformation struct {
+		RunID           uint64     `json:"run_id"`
+		Trigger         string     `json:"trigger"`
+		DryRun          bool       `json:"dry_run"`
+		Status          string     `json:"status"`
+		NodeID          string     `json:"node_id,omitempty"`
+		FencingToken    int64      `json:"fencing_token,omitempty"`
+		CreatedAt       time.Time  `json:"created_at"`
+		StartedAt       *time.Time `json:"started_at,omitempty"`
+		CompletedAt     *time.Time `json:"completed_at,omitempty"`
+		ExpiredFiles    int64      `json:"expired_files"`
+		OldFileVersions int64      `json:"old_file_versions"`
+		AuditEvents     int64      `json:"audit_events"`
+		Batches         int        `json:"batches"`
+		HeldFiles       int64      `json:"held_files"`
+		Error           string     `json:"error,omitempty"`
+	}
+
+	// PurgeJobInformation - describes a job deleting all files of a tenant,
+	// or of a single device of the tenant, and its progress.
+	PurgeJobInformation struct {
+		JobID          uint64     `json:"job_id"`
+		TenantID       string     `json:"tenant_id"`
+		DeviceID       string     `json:"device_id,omitempty"`
+		Status         string     `json:"status"`
+		Phase          string     `json:"phase"`
+		NodeID         string     `json:"node_id,omitempty"`
+		Attempts       int        `json:"attempts"`
+		FilesDeleted   int64      `json:"files_deleted"`
+		ObjectsDeleted int64      `json:"objects_deleted"`
+		HeldFiles      int64      `json:"held_files"`
+		CreatedAt      time.Time  `json:"created_at"`
+		StartedAt      *time.Time `json:"started_at,omitempty"`
+		UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+		CompletedAt    *time.Time `json:"completed_at,omitempty"`
+		Error          string     `json:"error,omitempty"`
+	}
+
+	// PurgeJobResponse - defines the response structure for purge requests
+	// and requests to get the status of a purge job.
+	PurgeJobResponse struct {
+		RequestID    string              `json:"request_id"`
+		ResponseTime time.Time           `json:"response_time"`
+		Job          PurgeJobInformation `json:"job"`
+	}
+
+	// LegalHoldRequest - defines the request structure for requests to place
+	// a legal hold. The hold applies to the specified file, to all files of
+	// the specified device, or to all files of the specified tenant. The
+	// tenant and device of a file are those of the file.
+	LegalHoldRequest struct {
+		TenantID string `json:"tenant_id,omitempty"`
+		DeviceID string `json:"device_id,omitempty"`
+		FileID   uint64 `json:"file_id,omitempty"`
+		Reason   string `json:"reason"`
+		Actor    string `json:"actor,omitempty"`
+	}
+
+	// LegalHoldInformation - describes a legal hold.
+	LegalHoldInformation struct {
+		HoldID    uint64    `json:"hold_id"`
+		TenantID  string    `json:"tenant_id"`
+		DeviceID  string    `json:"device_id,omitempty"`
+		FileID    uint64    `json:"file_id,omitempty"`
+		Reason    string    `json:"reason"`
+		Actor     string    `json:"actor,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	// LegalHoldResponse - defines the response structure for requests to
+	// place, get or release a legal hold. ObjectLock reports whether the hold
+	// of a file was mirrored to the object lock of its stored object. Holds
+	// on tenants and devices are mirrored in the background, so it is false
+	// for them.
+	LegalHoldResponse struct {
+		RequestID    string               `json:"request_id"`
+		ResponseTime time.Time            `json:"response_time"`
+		Hold         LegalHoldInformation `json:"hold"`
+		ObjectLock   bool                 `json:"object_lock"`
+	}
+
+	// LegalHoldsResponse - defines the response structure for requests to
+	// list the legal holds of a tenant.
+	LegalHoldsResponse struct {
+		RequestID    string                 `json:"request_id"`
+		ResponseTime time.Time              `json:"response_time"`
+		Count        int                    `json:"count"`
+		Holds        []LegalHoldInformation `json:"holds"`
+	}
+
+	// ErrorResponse - defines the response structure for failed requests.
+	// Code is a stable, machine-readable error code and Message describes it.
+	// Details optionally carries more information about the error, and
+	// RetryAfter is the number of seconds after which the request may be
+	// retried, if the error is transient.
+	ErrorResponse struct {
+		Code       string            `json:"code"`
+		Message    string            `json:"message"`
+		RequestID  string            `json:"request_id"`
+		Details    map[string]string `json:"details,omitempty"`
+		RetryAfter int               `json:"retry_after,omitempty"`
+	}
+
+	// ScavengerRunResponse - defines the response structure for requests to
+	// run the database scavenger or to get the status of a run.
+	ScavengerRunResponse struct {
+		RequestID    string                  `json:"request_id"`
+		ResponseTime time.Time               `json:"response_time"`
+		Run          ScavengerRunInformation `json:"run"`
 	}
 )
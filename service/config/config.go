@@ -4,12 +4,14 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"gopkg.in/yaml.v2"
 )
 
@@ -20,7 +22,12 @@ const (
 	defaultLogLevel   = "info"
 )
 
-var Settings Config
+var (
+	Settings Config
+
+	// Path to the configuration file from which settings were loaded.
+	configFile string
+)
 
 func Init() {
 	initFlags()
@@ -28,9 +35,13 @@ func Init() {
 }
 
 func initFlags() {
+	Settings.Flags.ConfigFile = flag.String("config_file", "",
+		"Specify the path to the configuration file.")
 	Settings.Flags.LogLevel = flag.String("log_level", "", "Specify the logging level.")
 	Settings.Flags.Version = flag.Bool("version", false,
 		"Print the version of the service and exit!")
+	Settings.Flags.CheckConfig = flag.Bool("check-config", false,
+		"Validate the configuration and exit!")
 
 	// Parse the command line flags.
 	flag.Parse()
@@ -49,7 +60,40 @@ func printVersionInformation() {
 }
 
 func Load(testModeEnabled bool) bool {
-	filename := getConfigFile()
+	configFile = getConfigFile()
+	err := loadConfigFile(configFile, &Settings)
+	if err == nil {
+		err = Settings.Validate()
+	}
+
+	// When only asked to check the configuration, report the result and exit.
+	if Settings.Flags.CheckConfig != nil && *Settings.Flags.CheckConfig {
+		checkConfiguration(err)
+	}
+	if err != nil {
+		logValidationErrors(err)
+		return false
+	}
+
+	// Apply the log level specified on the command line, if any.
+	if *Settings.Flags.LogLevel != "" {
+		setLogLevel(*Settings.Flags.LogLevel)
+	}
+
+	testModeEnvVar := os.Getenv("TEST_MODE")
+	if (testModeEnvVar == "enabled") || (testModeEnabled) {
+		Settings.TestMode = true
+		fmt.Println("FS service is running in test mode with test hooks enabled.")
+		InitTestLogger()
+	}
+
+	displayConfiguration()
+	return true
+}
+
+// Parses the specified configuration file into the specified settings and
+// applies overrides from environment variables.
+func loadConfigFile(filename string, settings *Config) error {
 	// Open the configuration file for parsing.
 	fh, err := os.Open(filepath.Clean(filename))
 	if err != nil {
@@ -57,43 +101,74 @@ func Load(testModeEnabled bool) bool {
 			zap.String("Configuration file:", filename),
 			zap.Error(err),
 		)
-		return false
+		return err
 	}
+	defer fh.Close()
 
 	// Read the configuration file and unmarshal the YAML.
 	decoder := yaml.NewDecoder(fh)
-	err = decoder.Decode(&Settings)
+	err = decoder.Decode(settings)
 	if err != nil {
 		fsLogger.Error("Failed to parse configuration file!",
 			zap.String("Configuration file:", filename),
 			zap.Error(err),
 		)
-		return false
+		return err
 	}
 
-	_ = fh.Close()
 	fsLogger.Info("Parsed configuration from the configuration file!",
 		zap.String("Configuration file:", filename),
 	)
 
 	// override config from environment variables
 	// note this only happens if environment variables are specified
-	Settings.OverrideFromEnvironment()
+	settings.OverrideFromEnvironment()
+	return nil
+}
 
-	testModeEnvVar := os.Getenv("TEST_MODE")
-	if (testModeEnvVar == "enabled") || (testModeEnabled) {
-		Settings.TestMode = true
-		fmt.Println("FS service is running in test mode with test hooks enabled.")
-		InitTestLogger()
+// Prints the result of validating the configuration for the --check-config
+// flag and exits the service.
+func checkConfiguration(err error) {
+	if err == nil {
+		fmt.Printf("%s: configuration file %s is valid\n", ServiceName, configFile)
+		os.Exit(0)
 	}
 
-	displayConfiguration()
-	return true
+	fmt.Fprintf(os.Stderr, "%s: configuration file %s is invalid\n", ServiceName, configFile)
+	var problems ValidationErrors
+	if errors.As(err, &problems) {
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, " - %s\n", problem.Error())
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, " - %s\n", err)
+	}
+	os.Exit(1)
+}
+
+// Logs each problem found by validating the configuration.
+func logValidationErrors(err error) {
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		return
+	}
+	for _, problem := range problems {
+		fsLogger.Error("Invalid configuration setting!",
+			zap.String("Setting:", problem.Path),
+			zap.String("Environment variable:", problem.EnvVar),
+			zap.String("Problem:", problem.Message),
+		)
+	}
 }
 
-// if FS_CONFIG_FILE env var is specified, return value
+// if the --config_file flag is specified, return its value
+// else if FS_CONFIG_FILE env var is specified, return value
 // if env value is empty, use default
 func getConfigFile() string {
+	if Settings.Flags.ConfigFile != nil && *Settings.Flags.ConfigFile != "" {
+		return *Settings.Flags.ConfigFile
+	}
+
 	configFile := os.Getenv(envConfigFile)
 	if configFile != "" {
 		fsLogger.Info("Using config file override!",
@@ -116,13 +191,16 @@ func GetLogger() *zap.Logger {
 func displayConfiguration() {
 	fsLogger.Info("HP Files Service - current configuration",
 		zap.Bool(" - Test mode enabled:", Settings.TestMode),
-		zap.String(" - Log level:", *Settings.Flags.LogLevel),
+		zap.String(" - Configuration file:", configFile),
+		zap.String(" - Log level:", GetLogLevel()),
 	)
 	fsLogger.Info("Server settings",
 		zap.String(" - Hostname:", Settings.Server.Host),
 		zap.Int(" - Rest Port:", Settings.Server.Port),
 		zap.Int(" - Retry after (seconds):", Settings.Server.RetryAfterSeconds),
 		zap.Int(" - Max Retry after (seconds):", Settings.Server.MaxRetryAfterSeconds),
+		zap.Int(" - Shutdown timeout (seconds):", Settings.Server.ShutdownTimeoutSeconds),
+		zap.Int(" - Config watch interval (seconds):", Settings.Server.ConfigWatchIntervalSeconds),
 	)
 	fsLogger.Info("Database settings",
 		zap.String(" - Host:", Settings.Database.Host),
@@ -132,20 +210,51 @@ func displayConfiguration() {
 		zap.String(" - Database migration scripts:", Settings.Database.SchemaMigrationScripts),
 		zap.Bool(" - Database migration enabled:", Settings.Database.SchemaMigrationEnabled),
 		zap.Bool(" - Debug logging enabled:", Settings.Database.DebugLoggingEnabled),
+		zap.Bool(" - Scavenger enabled:", Settings.Database.ScavengerEnabled),
+		zap.String(" - Scavenger schedule:", Settings.Database.ScavengerSchedule),
+		zap.Int(" - Scavenger batch size:", Settings.Database.ScavengerBatchSize),
+		zap.Int(" - Scavenger time budget minutes:", Settings.Database.ScavengerTimeBudgetMinutes),
+		zap.Bool(" - Scavenger dry run:", Settings.Database.ScavengerDryRun),
+		zap.Int(" - Retained file versions:", Settings.Database.RetainedFileVersions),
+		zap.Int(" - Audit retention days:", Settings.Database.AuditRetentionDays),
+		zap.Int(" - Leader lease seconds:", Settings.Database.LeaderLeaseSeconds),
 	)
 	fsLogger.Info("Cache settings",
 		zap.Bool(" - Caching enabled:", Settings.Cache.Enabled),
+		zap.String(" - Mode:", Settings.Cache.Mode),
 		zap.String(" - Host:", Settings.Cache.Host),
 		zap.Int(" - Port:", Settings.Cache.Port),
+		zap.Strings(" - Addresses:", Settings.Cache.Addresses),
+		zap.String(" - Master name:", Settings.Cache.MasterName),
 		zap.Int(" - Database:", Settings.Cache.CacheDatabase),
+		zap.String(" - User name:", Settings.Cache.Username),
+		zap.Bool(" - TLS enabled:", Settings.Cache.TlsEnabled),
+		zap.String(" - TLS CA certificate:", Settings.Cache.TlsCaCertificate),
+		zap.Int(" - Pool size:", Settings.Cache.PoolSize),
+		zap.Int(" - Min idle connections:", Settings.Cache.MinIdleConnections),
+		zap.Int(" - Operation timeout (ms):", Settings.Cache.OperationTimeoutMs),
+		zap.Int(" - Degraded failure threshold:", Settings.Cache.DegradedFailureThreshold),
+		zap.Int(" - Degraded retry interval (seconds):", Settings.Cache.DegradedRetryIntervalSeconds),
 	)
 	fsLogger.Info("Notification settings",
 		zap.String(" - Endpoint:", Settings.Notification.Endpoint),
 		zap.String(" - Name:", Settings.Notification.Name),
 		zap.Int(" - Watch delay:", Settings.Notification.WatchDelay),
 	)
+	fsLogger.Info("Scanning settings",
+		zap.Bool(" - Scanning enabled:", Settings.Scanning.Enabled),
+		zap.Bool(" - Block unscanned downloads:", Settings.Scanning.BlockUnscannedDownloads),
+		zap.String(" - Default scanner name:", Settings.Scanning.DefaultScannerName),
+		zap.String(" - Rescan queue name:", Settings.Scanning.RescanQueueName),
+	)
+	fsLogger.Info("Tracing settings",
+		zap.Bool(" - Tracing enabled:", Settings.Tracing.Enabled),
+		zap.String(" - Endpoint:", Settings.Tracing.Endpoint),
+		zap.Bool(" - Insecure:", Settings.Tracing.Insecure),
+		zap.Float64(" - Sample ratio:", Settings.Tracing.SampleRatio),
+	)
 }
 
 func IsLogLevelDebug() bool {
-	return *Settings.Flags.LogLevel == "debug"
+	return logLevel.Level() == zapcore.DebugLevel
 }
@@ -16,17 +16,68 @@ type Cache struct {
 	// Whether file caching is enabled.
 	Enabled bool `yaml:"enabled"`
 
-	// The hostname/IP address of the file cache.
+	// Deployment mode of the file cache - supported values are "standalone",
+	// "sentinel" & "cluster".
+	Mode string `yaml:"mode"`
+
+	// The hostname/IP address of the file cache in standalone mode.
 	Host string `yaml:"cache_hostname"`
 
-	// The port at which the cache is available.
+	// The port at which the cache is available in standalone mode.
 	Port int `yaml:"cache_port"`
 
-	// The Redis database number to be used for the file cache.
+	// Addresses (host:port) of the sentinels, or of the cluster nodes used to
+	// discover the cluster.
+	Addresses []string `yaml:"addresses"`
+
+	// Name of the master monitored by the sentinels.
+	MasterName string `yaml:"master_name"`
+
+	// The Redis database number to be used for the file cache. Must be 0 in
+	// cluster mode.
 	CacheDatabase int `yaml:"cache_db"`
 
+	// ACL user name used to connect to the file cache.
+	Username string `yaml:"username"`
+
 	// Password used to connect to the file cache.
 	Password string
+
+	// Password used to connect to the sentinels - not exposed in
+	// configuration file.
+	SentinelPassword string
+
+	// Whether to connect to the file cache using TLS.
+	TlsEnabled bool `yaml:"tls_enabled"`
+
+	// Name of the PEM file containing the CA certificates used to verify the
+	// file cache. The system CA certificates are used if not specified.
+	TlsCaCertificate string `yaml:"tls_ca_cert"`
+
+	// Server name expected in the certificate of the file cache. Defaults to
+	// the host name of the address being connected to.
+	TlsServerName string `yaml:"tls_server_name"`
+
+	// Maximum number of connections to each cache node. 0 -> default (10)
+	PoolSize int `yaml:"pool_size"`
+
+	// Minimum number of idle connections kept to each cache node.
+	MinIdleConnections int `yaml:"min_idle_connections"`
+
+	// Timeouts for connecting to, reading from and writing to the cache, for
+	// waiting for a free connection, and for each cache operation issued by
+	// the service. 0 -> default
+	DialTimeoutMs      int `yaml:"dial_timeout_ms"`
+	ReadTimeoutMs      int `yaml:"read_timeout_ms"`
+	WriteTimeoutMs     int `yaml:"write_timeout_ms"`
+	PoolTimeoutMs      int `yaml:"pool_timeout_ms"`
+	OperationTimeoutMs int `yaml:"operation_timeout_ms"`
+
+	// Number of consecutive failed cache operations after which the cache is
+	// bypassed, and interval at which it is checked for recovery.
+	// 0 -> default
+	DegradedFailureThreshold     int `yaml:"degraded_failure_threshold"`
+	DegradedRetryIntervalSeconds int `yaml:"degraded_retry_interval_seconds"`
 }
 
 // Database server settings
@@ -61,6 +112,35 @@ type Database struct {
 	// Specifies whether the database scavenger should be enabled.
 	ScavengerEnabled bool `yaml:"scavenger_enabled"`
 
+	// Cron expression (minute hour day-of-month month day-of-week, or a
+	// descriptor such as @daily) specifying when the scavenger runs. The
+	// expression may be prefixed with CRON_TZ=<zone> to use a time zone other
+	// than local time.
+	ScavengerSchedule string `yaml:"scavenger_schedule"`
+
+	// Number of rows deleted by the scavenger in each batch. Zero uses the
+	// default of 1000.
+	ScavengerBatchSize int `yaml:"scavenger_batch_size"`
+
+	// Time after which a scavenger run stops deleting further batches. Zero
+	// uses the default of 60 minutes.
+	ScavengerTimeBudgetMinutes int `yaml:"scavenger_time_budget_min"`
+
+	// Whether scheduled scavenger runs only count the rows they would delete.
+	ScavengerDryRun bool `yaml:"scavenger_dry_run"`
+
+	// Number of most recent uploaded versions of each logical file retained
+	// by the database scavenger. Zero retains all versions.
+	RetainedFileVersions int `yaml:"retained_file_versions"`
+
+	// Number of days for which file audit events are retained by the database
+	// scavenger. Zero retains all audit events.
+	AuditRetentionDays int `yaml:"audit_retention_days"`
+
+	// Lifetime of the lease held by the node elected leader to run the
+	// database scavenger. Zero uses the default of 30 seconds.
+	LeaderLeaseSeconds int `yaml:"leader_lease_seconds"`
+
 	// Maximum number of open SQL connections
 	MaxOpenConnections int `yaml:"max_open_connections"`
 
@@ -78,6 +158,33 @@ type Auth struct {
 	AllowedAppIds []string `yaml:"allowed_app_ids"`
 }
 
+// Settings of resumable uploads using the tus protocol, which are stored in
+// storage as multipart uploads.
+type ResumableUploads struct {
+	// Whether files can be uploaded using the tus resumable upload protocol.
+	Enabled bool `yaml:"enabled"`
+
+	// Largest chunk of a file that can be uploaded by a single request. Zero
+	// uses the default of 64 MB.
+	MaxChunkSizeInMB int `yaml:"max_chunk_size_mb"`
+
+	// Time allowed to receive a chunk and store it. Zero uses the default of
+	// 300 seconds.
+	ChunkTimeoutSeconds int `yaml:"chunk_timeout_seconds"`
+}
+
+// Settings of the content proxy, which streams the files of devices that
+// cannot reach storage through the service.
+type ContentProxy struct {
+	// Tenants whose devices upload and download files through the service
+	// instead of using signed URLs.
+	TenantIDs []string `yaml:"tenant_ids"`
+
+	// Time allowed to transfer a file through the service. Zero uses the
+	// default of 600 seconds.
+	TransferTimeoutSeconds int `yaml:"transfer_timeout_seconds"`
+}
+
 // Configuration settings for the REST server.
 type Server struct {
 	Host string `yaml:"host"`
@@ -85,6 +192,13 @@ type Server struct {
 	// Port on which the REST service is available.
 	Port int `yaml:"port"`
 
+	// Whether the gRPC server for internal service-to-service calls is
+	// enabled.
+	GrpcEnabled bool `yaml:"grpc_enabled"`
+
+	// Port on which the gRPC service is available.
+	GrpcPort int `yaml:"grpc_port"`
+
 	// Max Retry-After default value
 	MaxRetryAfterSeconds int `yaml:"max_retry_after_seconds"`
 
@@ -94,6 +208,39 @@ type Server struct {
 	// Debug rest requests
 	DebugRestRequests bool `yaml:"debug_rest_requests"`
 
+	// Whether requests are validated against the OpenAPI specification of
+	// the service before they are handled.
+	ValidateRequests bool `yaml:"validate_requests"`
+
+	// Largest number of files that can be created or signed in a single
+	// batch request. Zero uses the default of 100.
+	MaxBatchSize int `yaml:"max_batch_size"`
+
+	// Externally reachable base URL of the service, such as
+	// https://files.example.com, at which one-time redirect URLs and the
+	// content proxy are served. One-time URLs cannot be issued if it is not
+	// set.
+	RedirectBaseUrl string `yaml:"redirect_base_url"`
+
+	// IP addresses or CIDR ranges of the proxies in front of the service.
+	// The X-Forwarded-For hops added by these proxies are trusted when the IP
+	// address of a client is checked, such as for one-time URLs restricted to
+	// a client IP. If none are configured, the peer address is used.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// Resumable upload settings.
+	ResumableUploads ResumableUploads `yaml:"resumable_uploads"`
+
+	// Content proxy settings.
+	ContentProxy ContentProxy `yaml:"content_proxy"`
+
+	// Time allowed for in-flight requests to complete on shutdown.
+	ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds"`
+
+	// Interval at which the configuration file is checked for changes, which
+	// are then reloaded. Zero disables watching the configuration file.
+	ConfigWatchIntervalSeconds int `yaml:"config_watch_interval_seconds"`
+
 	Auth Auth `yaml:"auth"`
 }
 
@@ -103,6 +250,18 @@ type Storage struct {
 	// removing config driven end point to env only
 	Endpoint                   string
 	SignedUrlDurationInMinutes int `yaml:"signed_url_duration_min"`
+
+	// Durations of signed URLs for specific access methods (get, head, put),
+	// overriding the signed URL duration.
+	SignedUrlMethodDurations map[string]int `yaml:"signed_url_method_durations_min"`
+
+	// Durations of signed URLs for specific tenants, keyed by tenant ID and
+	// then by access method, overriding the method durations.
+	SignedUrlTenantDurations map[string]map[string]int `yaml:"signed_url_tenant_durations_min"`
+
+	// Longest duration callers may request for a signed URL. If zero, callers
+	// may only request durations shorter than the configured duration.
+	SignedUrlMaxDurationInMinutes int `yaml:"signed_url_max_duration_min"`
 }
 
 // Notification configuration settings
@@ -116,6 +275,38 @@ type Notification struct {
 	WatchDelay int    `yaml:"watch_delay"`
 }
 
+// Malware scanning configuration settings
+type Scanning struct {
+	// Whether uploaded files are scanned by the malware scanning pipeline. If
+	// enabled, uploaded files remain pending until a scan verdict is received.
+	Enabled bool `yaml:"enabled"`
+
+	// Whether download URLs are refused for files that have not been scanned.
+	BlockUnscannedDownloads bool `yaml:"block_unscanned_downloads"`
+
+	// Scanner name recorded for verdicts that do not identify the scanner.
+	DefaultScannerName string `yaml:"default_scanner_name"`
+
+	// Name of the queue to which rescan requests are sent.
+	RescanQueueName string `yaml:"rescan_queue_name"`
+}
+
+// Distributed tracing configuration settings
+type Tracing struct {
+	// Whether spans are exported to an OpenTelemetry collector.
+	Enabled bool `yaml:"enabled"`
+
+	// The OTLP/HTTP endpoint (host:port) of the collector.
+	Endpoint string `yaml:"endpoint"`
+
+	// Whether to connect to the collector without TLS.
+	Insecure bool `yaml:"insecure"`
+
+	// Fraction of new traces that are sampled, between 0 and 1. Traces
+	// started by callers are sampled according to the caller's decision.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
 type Config struct {
 	// Rest server settings
 	Server Server
@@ -132,6 +323,12 @@ type Config struct {
 	// Storage settings
 	Storage Storage
 
+	// Malware scanning settings
+	Scanning Scanning
+
+	// Distributed tracing settings
+	Tracing Tracing
+
 	// Command line switches/flags.
 	Flags struct {
 		// --config_file: specifies the path to the configuration file.
@@ -140,6 +337,8 @@ type Config struct {
 		LogLevel *string
 		// --version: displays versioning information.
 		Version *bool
+		// --check-config: validates the configuration and exits.
+		CheckConfig *bool
 		//
 		gitCommitHash string
 		builtAt       string
@@ -152,4 +351,7 @@ type Config struct {
 
 	// Whether the service is running in test mode.
 	TestMode bool
+
+	// Environment variables whose values could not be applied.
+	envErrors ValidationErrors
 }
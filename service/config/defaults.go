@@ -9,4 +9,9 @@ const (
 	AccessMethodGet  = "get"
 
 	StorageVerifyPrefix = "storage_verify"
+
+	// Deployment modes of the file cache.
+	CacheModeStandalone = "standalone"
+	CacheModeSentinel   = "sentinel"
+	CacheModeCluster    = "cluster"
 )
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -11,6 +13,13 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	ErrInvalidBool     = errors.New("not a valid boolean")
+	ErrInvalidInteger  = errors.New("not a valid integer")
+	ErrInvalidFloat    = errors.New("not a valid number")
+	ErrUnsupportedType = errors.New("unsupported setting type")
+)
+
 type value struct {
 	secret bool
 	v      interface{}
@@ -18,36 +27,89 @@ type value struct {
 
 // loadEnvironmentVariableOverrides - check values specified for supported
 // environment variables. These can be used to override configuration settings
-// specified in the config file.
+// specified in the config file. Values that cannot be parsed are left
+// unchanged and reported by Validate.
 func (c *Config) OverrideFromEnvironment() {
-	m := map[string]value{
+	c.envErrors = nil
+	for k, v := range c.environmentVariables() {
+		e := os.Getenv(k)
+		if e != "" {
+			fsLogger.Info("Overriding configuration from environment variable.",
+				zap.String("variable: ", k),
+				zap.String("value: ", getLoggableValue(v.secret, e)))
+			val := v
+			err := replaceConfigValue(e, &val)
+			if err != nil {
+				c.envErrors = append(c.envErrors, ValidationError{
+					Path:   c.yamlPath(v.v),
+					EnvVar: k,
+					Message: fmt.Sprintf("invalid value %q: %v",
+						getLoggableValue(v.secret, e), err),
+				})
+			}
+		}
+	}
+}
+
+// Returns the supported environment variables, mapped to the configuration
+// settings they override.
+func (c *Config) environmentVariables() map[string]value {
+	return map[string]value{
 		//Server
-		"FS_SERVER":                  {v: &c.Server.Host},
-		"FS_PORT":                    {v: &c.Server.Port},
-		"FS_MAX_RETRY_AFTER_SECONDS": {v: &c.Server.MaxRetryAfterSeconds},
-		"FS_RETRY_AFTER_SECONDS":     {v: &c.Server.RetryAfterSeconds},
-		"FS_SERVER_AUTH_JWKS_URL":    {v: &c.Server.Auth.JwksUrl},
-		"FS_SERVER_AUTH_ISSUER":      {v: &c.Server.Auth.Issuer},
+		"FS_SERVER":                        {v: &c.Server.Host},
+		"FS_PORT":                          {v: &c.Server.Port},
+		"FS_GRPC_ENABLED":                  {v: &c.Server.GrpcEnabled},
+		"FS_GRPC_PORT":                     {v: &c.Server.GrpcPort},
+		"FS_MAX_RETRY_AFTER_SECONDS":       {v: &c.Server.MaxRetryAfterSeconds},
+		"FS_RETRY_AFTER_SECONDS":           {v: &c.Server.RetryAfterSeconds},
+		"FS_VALIDATE_REQUESTS":             {v: &c.Server.ValidateRequests},
+		"FS_MAX_BATCH_SIZE":                {v: &c.Server.MaxBatchSize},
+		"FS_REDIRECT_BASE_URL":             {v: &c.Server.RedirectBaseUrl},
+		"FS_RESUMABLE_UPLOADS_ENABLED":     {v: &c.Server.ResumableUploads.Enabled},
+		"FS_SHUTDOWN_TIMEOUT_SECONDS":      {v: &c.Server.ShutdownTimeoutSeconds},
+		"FS_CONFIG_WATCH_INTERVAL_SECONDS": {v: &c.Server.ConfigWatchIntervalSeconds},
+		"FS_SERVER_AUTH_JWKS_URL":          {v: &c.Server.Auth.JwksUrl},
+		"FS_SERVER_AUTH_ISSUER":            {v: &c.Server.Auth.Issuer},
 		// allowed app ids (comma separated)
 		"FS_SERVER_AUTH_ALLOWED_APP_IDS": {v: &c.Server.Auth.AllowedAppIds},
+		// trusted proxy addresses (comma separated)
+		"FS_TRUSTED_PROXIES": {v: &c.Server.TrustedProxies},
+		// content proxy tenant ids (comma separated)
+		"FS_CONTENT_PROXY_TENANT_IDS": {v: &c.Server.ContentProxy.TenantIDs},
 
 		// Cache configuration settings
-		"FS_CACHE_SERVER":   {v: &c.Cache.Host},
-		"FS_CACHE_PORT":     {v: &c.Cache.Port},
-		"FS_CACHE_PASSWORD": {secret: true, v: &c.Cache.Password},
+		"FS_CACHE_ENABLED":           {v: &c.Cache.Enabled},
+		"FS_CACHE_MODE":              {v: &c.Cache.Mode},
+		"FS_CACHE_SERVER":            {v: &c.Cache.Host},
+		"FS_CACHE_PORT":              {v: &c.Cache.Port},
+		"FS_CACHE_ADDRESSES":         {v: &c.Cache.Addresses},
+		"FS_CACHE_MASTER_NAME":       {v: &c.Cache.MasterName},
+		"FS_CACHE_USERNAME":          {v: &c.Cache.Username},
+		"FS_CACHE_PASSWORD":          {secret: true, v: &c.Cache.Password},
+		"FS_CACHE_SENTINEL_PASSWORD": {secret: true, v: &c.Cache.SentinelPassword},
+		"FS_CACHE_TLS_ENABLED":       {v: &c.Cache.TlsEnabled},
+		"FS_CACHE_TLS_CA_CERT":       {v: &c.Cache.TlsCaCertificate},
+		"FS_CACHE_TLS_SERVER_NAME":   {v: &c.Cache.TlsServerName},
+		"FS_CACHE_POOL_SIZE":         {v: &c.Cache.PoolSize},
 
 		// Database configuration settings
-		"FS_DB_SERVER":            {v: &c.Database.Host},
-		"FS_DB_PORT":              {v: &c.Database.Port},
-		"FS_DB_USER":              {v: &c.Database.Username},
-		"FS_DB_PASSWORD":          {secret: true, v: &c.Database.Password},
-		"FS_DB_NAME":              {v: &c.Database.DatabaseName},
-		"FS_DB_TYPE":              {v: &c.Database.DatabaseType},
-		"FS_DB_SCHEMA":            {v: &c.Database.SchemaMigrationScripts},
-		"FS_DB_SCAVENGER_ENABLED": {v: &c.Database.ScavengerEnabled},
-		"FS_DB_MAX_CONNECTIONS":   {v: &c.Database.MaxOpenConnections},
-		"FS_DB_SSL_MODE":          {v: &c.Database.SslMode},
-		"FS_DB_SSL_ROOT_CERT":     {v: &c.Database.SslRootCertificate},
+		"FS_DB_SERVER":                 {v: &c.Database.Host},
+		"FS_DB_PORT":                   {v: &c.Database.Port},
+		"FS_DB_USER":                   {v: &c.Database.Username},
+		"FS_DB_PASSWORD":               {secret: true, v: &c.Database.Password},
+		"FS_DB_NAME":                   {v: &c.Database.DatabaseName},
+		"FS_DB_TYPE":                   {v: &c.Database.DatabaseType},
+		"FS_DB_SCHEMA":                 {v: &c.Database.SchemaMigrationScripts},
+		"FS_DB_SCAVENGER_ENABLED":      {v: &c.Database.ScavengerEnabled},
+		"FS_DB_RETAINED_FILE_VERSIONS": {v: &c.Database.RetainedFileVersions},
+		"FS_DB_AUDIT_RETENTION_DAYS":   {v: &c.Database.AuditRetentionDays},
+		"FS_DB_LEADER_LEASE_SECONDS":   {v: &c.Database.LeaderLeaseSeconds},
+		"FS_DB_SCAVENGER_SCHEDULE":     {v: &c.Database.ScavengerSchedule},
+		"FS_DB_SCAVENGER_BATCH_SIZE":   {v: &c.Database.ScavengerBatchSize},
+		"FS_DB_SCAVENGER_DRY_RUN":      {v: &c.Database.ScavengerDryRun},
+		"FS_DB_MAX_CONNECTIONS":        {v: &c.Database.MaxOpenConnections},
+		"FS_DB_SSL_MODE":               {v: &c.Database.SslMode},
+		"FS_DB_SSL_ROOT_CERT":          {v: &c.Database.SslRootCertificate},
 
 		// Notification configuration settings
 		"FS_NOTIFICATION_ENDPOINT":    {v: &c.Notification.Endpoint},
@@ -55,23 +117,26 @@ func (c *Config) OverrideFromEnvironment() {
 		"FS_NOTIFICATION_WATCH_DELAY": {v: &c.Notification.WatchDelay},
 
 		// Storage configuration settings.
-		"FS_STORAGE_ENDPOINT":     {v: &c.Storage.Endpoint},
-		"FS_STORAGE_BUCKET_NAMES": {v: &c.Storage.BucketNames},
-	}
-	for k, v := range m {
-		e := os.Getenv(k)
-		if e != "" {
-			fsLogger.Info("Overriding configuration from environment variable.",
-				zap.String("variable: ", k),
-				zap.String("value: ", getLoggableValue(v.secret, e)))
-			val := v
-			replaceConfigValue(os.Getenv(k), &val)
-		}
+		"FS_STORAGE_ENDPOINT":                    {v: &c.Storage.Endpoint},
+		"FS_STORAGE_BUCKET_NAMES":                {v: &c.Storage.BucketNames},
+		"FS_STORAGE_SIGNED_URL_MAX_DURATION_MIN": {v: &c.Storage.SignedUrlMaxDurationInMinutes},
+
+		// Malware scanning configuration settings.
+		"FS_SCANNING_ENABLED":                   {v: &c.Scanning.Enabled},
+		"FS_SCANNING_BLOCK_UNSCANNED_DOWNLOADS": {v: &c.Scanning.BlockUnscannedDownloads},
+		"FS_SCANNING_DEFAULT_SCANNER_NAME":      {v: &c.Scanning.DefaultScannerName},
+		"FS_SCANNING_RESCAN_QUEUE_NAME":         {v: &c.Scanning.RescanQueueName},
+
+		// Tracing configuration settings.
+		"FS_TRACING_ENABLED":      {v: &c.Tracing.Enabled},
+		"FS_TRACING_ENDPOINT":     {v: &c.Tracing.Endpoint},
+		"FS_TRACING_INSECURE":     {v: &c.Tracing.Insecure},
+		"FS_TRACING_SAMPLE_RATIO": {v: &c.Tracing.SampleRatio},
 	}
 }
 
 // envValue will be non empty as this function is private to file
-func replaceConfigValue(envValue string, t *value) {
+func replaceConfigValue(envValue string, t *value) error {
 	switch t.v.(type) {
 	case *string:
 		*t.v.(*string) = envValue
@@ -84,22 +149,33 @@ func replaceConfigValue(envValue string, t *value) {
 	case *bool:
 		b, err := strconv.ParseBool(envValue)
 		if err != nil {
-			fsLogger.Error("Bad bool value in env")
-		} else {
-			*t.v.(*bool) = b
+			fsLogger.Error("Bad bool value in env",
+				zap.Error(err))
+			return ErrInvalidBool
 		}
+		*t.v.(*bool) = b
 	case *int:
 		i, err := strconv.Atoi(envValue)
 		if err != nil {
 			fsLogger.Error("Bad integer value in env",
 				zap.Error(err))
-		} else {
-			*t.v.(*int) = i
+			return ErrInvalidInteger
+		}
+		*t.v.(*int) = i
+	case *float64:
+		f, err := strconv.ParseFloat(envValue, 64)
+		if err != nil {
+			fsLogger.Error("Bad float value in env",
+				zap.Error(err))
+			return ErrInvalidFloat
 		}
+		*t.v.(*float64) = f
 	default:
 		fsLogger.Error("There was a bad type map in env override",
 			zap.String("value", envValue))
+		return ErrUnsupportedType
 	}
+	return nil
 }
 
 func getLoggableValue(secret bool, value string) string {
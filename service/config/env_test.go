@@ -4,7 +4,6 @@
 package config
 
 import (
-	"os"
 	"strconv"
 	"testing"
 )
@@ -27,7 +26,7 @@ func TestDoesNotOverrideWhenNoEnvVariablePresent(t *testing.T) {
 func TestOverridesStringEnvVariable(t *testing.T) {
 	c := Config{}
 	expected := "localhost"
-	os.Setenv("FS_SERVER", expected)
+	t.Setenv("FS_SERVER", expected)
 	c.OverrideFromEnvironment()
 	if c.Server.Host != expected {
 		t.Fatalf(
@@ -39,7 +38,7 @@ func TestOverridesStringEnvVariable(t *testing.T) {
 func TestOverridesIntEnvVariable(t *testing.T) {
 	c := Config{}
 	expected := 123
-	os.Setenv("FS_PORT", strconv.Itoa(expected))
+	t.Setenv("FS_PORT", strconv.Itoa(expected))
 	c.OverrideFromEnvironment()
 	if c.Server.Port != expected {
 		t.Fatalf(
@@ -52,7 +51,7 @@ func TestOverridesSkipsBadEnvVariable(t *testing.T) {
 	c := Config{}
 	expected := 123
 	c.Server.Port = expected
-	os.Setenv("FS_PORT", "not_an_int")
+	t.Setenv("FS_PORT", "not_an_int")
 	c.OverrideFromEnvironment()
 	if c.Server.Port != expected {
 		t.Fatalf(
@@ -60,3 +59,15 @@ func TestOverridesSkipsBadEnvVariable(t *testing.T) {
 			expected, c.Server.Port)
 	}
 }
+
+func TestOverridesFloatEnvVariable(t *testing.T) {
+	c := Config{}
+	expected := 0.25
+	t.Setenv("FS_TRACING_SAMPLE_RATIO", "0.25")
+	c.OverrideFromEnvironment()
+	if c.Tracing.SampleRatio != expected {
+		t.Fatalf(
+			`OverrideFromEnvironment:, Expected Tracing.SampleRatio = %f,  found: %f`,
+			expected, c.Tracing.SampleRatio)
+	}
+}
@@ -4,6 +4,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -14,6 +15,9 @@ import (
 var (
 	fsLogger *zap.Logger
 	logLevel zap.AtomicLevel
+
+	// Errors
+	ErrInvalidLogLevel = errors.New("log level was not specified")
 )
 
 func InitTestLogger() {
@@ -36,6 +40,30 @@ func shutdownLogger() {
 	_ = fsLogger.Sync()
 }
 
+// SetLogLevel changes the level at which the service logs at runtime.
+func SetLogLevel(level string) error {
+	if level == "" {
+		return ErrInvalidLogLevel
+	}
+	parsedLevel, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	previousLevel := logLevel.Level()
+	logLevel.SetLevel(parsedLevel)
+	fsLogger.Info("Changed the log level of the service!",
+		zap.String("Previous log level:", previousLevel.String()),
+		zap.String("Log level:", parsedLevel.String()),
+	)
+	return nil
+}
+
+// GetLogLevel returns the level at which the service currently logs.
+func GetLogLevel() string {
+	return logLevel.Level().String()
+}
+
 func setLogLevel(level string) {
 	parsedLevel, err := zapcore.ParseLevel(level)
 	if err != nil {
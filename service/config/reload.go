@@ -0,0 +1,234 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"go.uber.org/zap"
+)
+
+// ReloadHandler applies reloaded settings to a component of the service.
+type ReloadHandler struct {
+	// Name of the component to which the settings are applied.
+	Name string
+
+	// Optional check that the reloaded settings can be applied to the
+	// component. If any check fails, the reload is rejected and none of the
+	// reloaded settings are applied.
+	Check func(settings *Config) error
+
+	// Applies the reloaded settings to the component.
+	Apply func(settings *Config) error
+}
+
+var (
+	// Handlers invoked to apply reloaded settings, in order of registration.
+	reloadHandlers []ReloadHandler
+
+	// Serializes reloads of the configuration.
+	reloadLock sync.Mutex
+
+	// Modification time of the configuration file when it was last loaded.
+	configModTime time.Time
+
+	watcherStopChannel chan bool
+	watcherDone        chan bool
+
+	// Errors
+	ErrInvalidReloadedConfig = errors.New("reloaded configuration is invalid")
+)
+
+// RegisterReloadHandler registers a handler to apply the settings that can be
+// changed without restarting the service when the configuration is reloaded.
+func RegisterReloadHandler(handler ReloadHandler) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	reloadHandlers = append(reloadHandlers, handler)
+}
+
+// Reload re-reads the configuration file and applies the settings that can
+// be changed without restarting the service. The reloaded configuration is
+// validated first; if it is invalid, the reload is rejected and the
+// running configuration is left unchanged.
+func Reload() error {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+
+	// Record the version of the configuration file being loaded, so that the
+	// watcher does not reload it again unless it is changed.
+	configModTime, _ = getConfigModTime()
+
+	var reloaded Config
+	err := loadConfigFile(configFile, &reloaded)
+	if err != nil {
+		metrics.MetricConfigReloadFailures.Inc()
+		return err
+	}
+
+	err = reloaded.Validate()
+	if err != nil {
+		logValidationErrors(err)
+		fsLogger.Error("Rejected the reloaded configuration!",
+			zap.String("Configuration file:", configFile),
+		)
+		metrics.MetricConfigReloadFailures.Inc()
+		return errors.Join(ErrInvalidReloadedConfig, err)
+	}
+
+	// Build the new running configuration from the current one, with the
+	// settings that can be changed without restarting the service replaced.
+	updated := Settings
+	copyReloadableSettings(&updated, &reloaded)
+
+	for _, handler := range reloadHandlers {
+		if handler.Check == nil {
+			continue
+		}
+		if err = handler.Check(&updated); err != nil {
+			fsLogger.Error("Rejected the reloaded configuration!",
+				zap.String("Component:", handler.Name),
+				zap.Error(err),
+			)
+			metrics.MetricConfigReloadFailures.Inc()
+			return err
+		}
+	}
+
+	// Settings that require a restart are not applied. Let the operator know
+	// that they were changed. Settings that are not read from the
+	// configuration file are ignored.
+	reloaded.Flags, reloaded.Logger, reloaded.TestMode =
+		updated.Flags, updated.Logger, updated.TestMode
+	reloaded.Database.Password = updated.Database.Password
+	reloaded.envErrors = updated.envErrors
+	if !reflect.DeepEqual(updated, reloaded) {
+		fsLogger.Warn("The configuration file contains changes that require a restart of the service and were not applied!",
+			zap.String("Configuration file:", configFile),
+		)
+	}
+
+	copyReloadableSettings(&Settings, &updated)
+	for _, handler := range reloadHandlers {
+		if err = handler.Apply(&updated); err != nil {
+			fsLogger.Error("Failed to apply the reloaded configuration!",
+				zap.String("Component:", handler.Name),
+				zap.Error(err),
+			)
+		}
+	}
+
+	fsLogger.Info("Reloaded the configuration!",
+		zap.String("Configuration file:", configFile),
+		zap.Strings(" - Bucket names:", Settings.Storage.BucketNames),
+		zap.Int(" - Signed URL duration (minutes):", Settings.Storage.SignedUrlDurationInMinutes),
+		zap.Any(" - Signed URL method durations (minutes):", Settings.Storage.SignedUrlMethodDurations),
+		zap.Int(" - Signed URL tenant overrides:", len(Settings.Storage.SignedUrlTenantDurations)),
+		zap.Int(" - Max signed URL duration (minutes):", Settings.Storage.SignedUrlMaxDurationInMinutes),
+		zap.Strings(" - Allowed app IDs:", Settings.Server.Auth.AllowedAppIds),
+		zap.Strings(" - Content proxy tenant IDs:", Settings.Server.ContentProxy.TenantIDs),
+		zap.Int(" - Retry after (seconds):", Settings.Server.RetryAfterSeconds),
+		zap.Int(" - Max Retry after (seconds):", Settings.Server.MaxRetryAfterSeconds),
+		zap.Int(" - Retained file versions:", Settings.Database.RetainedFileVersions),
+		zap.Int(" - Audit retention days:", Settings.Database.AuditRetentionDays),
+	)
+	metrics.MetricConfigReloads.Inc()
+	return nil
+}
+
+// Copies the settings that can be changed without restarting the service.
+func copyReloadableSettings(dst *Config, src *Config) {
+	dst.Storage.BucketNames = src.Storage.BucketNames
+	dst.Storage.SignedUrlDurationInMinutes = src.Storage.SignedUrlDurationInMinutes
+	dst.Storage.SignedUrlMethodDurations = src.Storage.SignedUrlMethodDurations
+	dst.Storage.SignedUrlTenantDurations = src.Storage.SignedUrlTenantDurations
+	dst.Storage.SignedUrlMaxDurationInMinutes = src.Storage.SignedUrlMaxDurationInMinutes
+	dst.Server.Auth.AllowedAppIds = src.Server.Auth.AllowedAppIds
+	dst.Server.ContentProxy.TenantIDs = src.Server.ContentProxy.TenantIDs
+	dst.Server.RetryAfterSeconds = src.Server.RetryAfterSeconds
+	dst.Server.MaxRetryAfterSeconds = src.Server.MaxRetryAfterSeconds
+	dst.Database.RetainedFileVersions = src.Database.RetainedFileVersions
+	dst.Database.AuditRetentionDays = src.Database.AuditRetentionDays
+}
+
+// StartWatcher reloads the configuration when the service receives a SIGHUP
+// signal or, if configured, when the configuration file is changed.
+func StartWatcher() {
+	if configModTime.IsZero() {
+		configModTime, _ = getConfigModTime()
+	}
+
+	hangupChannel := make(chan os.Signal, 1)
+	signal.Notify(hangupChannel, syscall.SIGHUP)
+
+	// Check the configuration file for changes at the configured interval.
+	var watchTicker *time.Ticker
+	var watchChannel <-chan time.Time
+	if Settings.Server.ConfigWatchIntervalSeconds > 0 {
+		watchTicker = time.NewTicker(time.Duration(
+			Settings.Server.ConfigWatchIntervalSeconds) * time.Second)
+		watchChannel = watchTicker.C
+	}
+
+	watcherStopChannel = make(chan bool)
+	watcherDone = make(chan bool, 1)
+
+	go func() {
+		defer signal.Stop(hangupChannel)
+		if watchTicker != nil {
+			defer watchTicker.Stop()
+		}
+		for {
+			select {
+			case <-hangupChannel:
+				fsLogger.Info("Received SIGHUP. Reloading the configuration ...")
+				_ = Reload()
+
+			case <-watchChannel:
+				modTime, err := getConfigModTime()
+				if err != nil || modTime.Equal(configModTime) {
+					continue
+				}
+				fsLogger.Info("The configuration file has changed. Reloading the configuration ...",
+					zap.String("Configuration file:", configFile),
+				)
+				_ = Reload()
+
+			case <-watcherStopChannel:
+				watcherDone <- true
+				return
+			}
+		}
+	}()
+
+	fsLogger.Info("Watching for configuration changes!",
+		zap.String("Configuration file:", configFile),
+		zap.Int("Watch interval (seconds):", Settings.Server.ConfigWatchIntervalSeconds),
+	)
+}
+
+// StopWatcher stops watching for configuration changes.
+func StopWatcher() {
+	if watcherStopChannel != nil {
+		close(watcherStopChannel)
+		<-watcherDone
+	}
+}
+
+// Returns the modification time of the configuration file.
+func getConfigModTime() (time.Time, error) {
+	info, err := os.Stat(filepath.Clean(configFile))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
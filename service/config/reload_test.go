@@ -0,0 +1,233 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	reloadTestConfig = `
+server:
+  port: 1234
+  retry_after_seconds: 2
+  max_retry_after_seconds: 60
+  auth:
+    jwks_url: http://localhost:7001/api/v1/keys
+    issuer: HP Device Token Service
+notification:
+  name: fs-notification
+storage:
+  bucket_names:
+  - bucket-1
+  - bucket-2
+  signed_url_duration_min: 30
+  signed_url_method_durations_min:
+    put: 120
+database:
+  db_hostname: localhost
+  db_port: 5432
+  user: krypton
+  db_name: files
+  deployment_type: postgres
+  ssl_mode: disable
+  retained_file_versions: 5
+  audit_retention_days: 30
+`
+
+	reloadTestInvalidConfig = `
+server:
+  port: 1234
+  auth:
+    jwks_url: http://localhost:7001/api/v1/keys
+    issuer: HP Device Token Service
+notification:
+  name: fs-notification
+storage:
+  bucket_names: []
+  signed_url_duration_min: 0
+database:
+  db_hostname: localhost
+  db_port: 5432
+  user: krypton
+  db_name: files
+  deployment_type: postgres
+  ssl_mode: disable
+  retained_file_versions: -1
+`
+
+	reloadTestRestartConfig = `
+server:
+  port: 4321
+  auth:
+    jwks_url: http://localhost:7001/api/v1/keys
+    issuer: HP Device Token Service
+notification:
+  name: fs-notification
+storage:
+  bucket_names:
+  - bucket-1
+  signed_url_duration_min: 15
+database:
+  db_hostname: localhost
+  db_port: 5432
+  user: krypton
+  db_name: files
+  deployment_type: postgres
+  ssl_mode: disable
+`
+)
+
+// Prepares the running configuration and a configuration file with the
+// specified contents for a reload test.
+func setupReloadTest(t *testing.T, contents string) {
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(contents), 0600); err != nil {
+		t.Fatalf("Failed to write the configuration file: %v", err)
+	}
+
+	Settings = Config{}
+	Settings.Server.Port = 1234
+	Settings.Storage.BucketNames = []string{"bucket-1"}
+	Settings.Storage.SignedUrlDurationInMinutes = 15
+
+	savedHandlers := reloadHandlers
+	reloadHandlers = nil
+	t.Cleanup(func() {
+		reloadHandlers = savedHandlers
+		Settings = Config{}
+	})
+}
+
+func TestReloadAppliesReloadableSettings(t *testing.T) {
+	setupReloadTest(t, reloadTestConfig)
+
+	var applied *Config
+	RegisterReloadHandler(ReloadHandler{
+		Name: "test",
+		Apply: func(settings *Config) error {
+			applied = settings
+			return nil
+		},
+	})
+
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: Expected success, Got error: %v", err)
+	}
+	if applied == nil {
+		t.Fatalf("Reload: Expected the reload handler to be invoked")
+	}
+	if len(Settings.Storage.BucketNames) != 2 ||
+		applied.Storage.BucketNames[1] != "bucket-2" {
+		t.Fatalf("Reload: Bucket names were not reloaded, found: %v",
+			Settings.Storage.BucketNames)
+	}
+	if Settings.Storage.SignedUrlDurationInMinutes != 30 ||
+		Settings.Storage.SignedUrlMethodDurations[AccessMethodPut] != 120 ||
+		Settings.Database.RetainedFileVersions != 5 ||
+		Settings.Database.AuditRetentionDays != 30 ||
+		Settings.Server.MaxRetryAfterSeconds != 60 {
+		t.Fatalf("Reload: Settings were not reloaded, found: %+v", Settings)
+	}
+}
+
+func TestReloadRejectsInvalidSettings(t *testing.T) {
+	setupReloadTest(t, reloadTestInvalidConfig)
+
+	RegisterReloadHandler(ReloadHandler{
+		Name: "test",
+		Apply: func(settings *Config) error {
+			t.Fatalf("Reload: Invalid settings must not be applied")
+			return nil
+		},
+	})
+
+	err := Reload()
+	if !errors.Is(err, ErrInvalidReloadedConfig) {
+		t.Fatalf("Reload: Expected %v, Got: %v", ErrInvalidReloadedConfig, err)
+	}
+	if len(Settings.Storage.BucketNames) != 1 ||
+		Settings.Storage.SignedUrlDurationInMinutes != 15 {
+		t.Fatalf("Reload: Running settings were changed, found: %+v", Settings)
+	}
+}
+
+func TestReloadRejectsFailedCheck(t *testing.T) {
+	setupReloadTest(t, reloadTestConfig)
+
+	errCheck := errors.New("bucket cannot be reached")
+	RegisterReloadHandler(ReloadHandler{
+		Name: "test",
+		Check: func(settings *Config) error {
+			return errCheck
+		},
+		Apply: func(settings *Config) error {
+			t.Fatalf("Reload: Rejected settings must not be applied")
+			return nil
+		},
+	})
+
+	err := Reload()
+	if !errors.Is(err, errCheck) {
+		t.Fatalf("Reload: Expected %v, Got: %v", errCheck, err)
+	}
+	if len(Settings.Storage.BucketNames) != 1 {
+		t.Fatalf("Reload: Running settings were changed, found: %+v", Settings)
+	}
+}
+
+func TestReloadIgnoresSettingsRequiringRestart(t *testing.T) {
+	setupReloadTest(t, reloadTestRestartConfig)
+
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: Expected success, Got error: %v", err)
+	}
+	if Settings.Server.Port != 1234 {
+		t.Fatalf("Reload: Expected Server.Port = 1234, found: %d",
+			Settings.Server.Port)
+	}
+}
+
+func TestReloadRejectsMissingFile(t *testing.T) {
+	setupReloadTest(t, reloadTestConfig)
+	configFile = filepath.Join(t.TempDir(), "missing.yaml")
+
+	if err := Reload(); err == nil {
+		t.Fatalf("Reload: Expected an error for a missing configuration file")
+	}
+	if len(Settings.Storage.BucketNames) != 1 {
+		t.Fatalf("Reload: Running settings were changed, found: %+v", Settings)
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer setLogLevel("debug")
+
+	tests := []struct {
+		level    string
+		expected string
+		isValid  bool
+	}{
+		{"warn", "warn", true},
+		{"DEBUG", "debug", true},
+		{"error", "error", true},
+		{"verbose", "error", false},
+		{"", "error", false},
+	}
+
+	for _, tc := range tests {
+		err := SetLogLevel(tc.level)
+		if (err == nil) != tc.isValid {
+			t.Fatalf("SetLogLevel(%q): Expected valid: %v, Got error: %v",
+				tc.level, tc.isValid, err)
+		}
+		if GetLogLevel() != tc.expected {
+			t.Fatalf("SetLogLevel(%q): Expected level: %s, Got: %s",
+				tc.level, tc.expected, GetLogLevel())
+		}
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// Maximum duration for which S3 signed URLs can be valid (7 days).
+	maxSignedUrlDurationInMinutes = 7 * 24 * 60
+
+	// Maximum time an SQS receive can wait for messages to arrive.
+	maxWatchDelaySeconds = 20
+
+	// Number of databases supported by a Redis server by default.
+	maxCacheDatabases = 16
+
+	// Shortest leader lease, which is renewed every second.
+	minLeaderLeaseSeconds = 3
+
+	// Largest batch of rows deleted by the scavenger in a single statement.
+	maxScavengerBatchSize = 100000
+
+	// Largest number of files in a batch request.
+	maxRequestBatchSize = 1000
+
+	// Longest time allowed to transfer a file through the content proxy.
+	maxProxyTransferTimeoutSeconds = 3600
+
+	// Sizes of the parts of S3 multipart uploads, to which the chunks of
+	// resumable uploads are mapped.
+	minUploadChunkSizeInMB = 5
+	maxUploadChunkSizeInMB = 5 * 1024
+
+	// Longest time allowed to receive a chunk of a resumable upload.
+	maxUploadChunkTimeoutSeconds = 3600
+)
+
+var (
+	supportedDatabaseTypes = []string{"postgres", "aws-rds"}
+	supportedSslModes      = []string{"disable", "verify-ca", "verify-full"}
+	supportedCacheModes    = []string{CacheModeStandalone, CacheModeSentinel,
+		CacheModeCluster}
+	supportedAccessMethods = []string{AccessMethodGet, AccessMethodHead,
+		AccessMethodPut}
+
+	// Bucket naming rules for S3 buckets.
+	bucketNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+)
+
+// ValidationError describes a problem with a configuration setting.
+type ValidationError struct {
+	// Path of the setting in the configuration file, if any.
+	Path string
+
+	// Environment variable that overrides the setting, if any.
+	EnvVar string
+
+	// Description of the problem.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	var setting []string
+	if e.Path != "" {
+		setting = append(setting, e.Path)
+	}
+	if e.EnvVar != "" {
+		setting = append(setting, e.EnvVar)
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(setting, " / "), e.Message)
+}
+
+// ValidationErrors describes every problem found in the configuration.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	problems := make([]string, 0, len(e))
+	for _, problem := range e {
+		problems = append(problems, problem.Error())
+	}
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+// Validate checks the configuration settings, including the overrides from
+// environment variables, and returns ValidationErrors describing every
+// problem found, or nil if the configuration is valid.
+func (c *Config) Validate() error {
+	v := validator{config: c}
+
+	// Environment variables that could not be applied.
+	v.problems = append(v.problems, c.envErrors...)
+
+	// Server settings.
+	v.checkPort(&c.Server.Port)
+	if c.Server.GrpcEnabled {
+		v.checkPort(&c.Server.GrpcPort)
+		v.check(c.Server.GrpcPort != c.Server.Port, &c.Server.GrpcPort,
+			"must differ from port (%d)", c.Server.Port)
+	}
+	v.check(c.Server.RetryAfterSeconds >= 0, &c.Server.RetryAfterSeconds,
+		"must not be negative, got %d", c.Server.RetryAfterSeconds)
+	v.check(c.Server.MaxRetryAfterSeconds >= c.Server.RetryAfterSeconds,
+		&c.Server.MaxRetryAfterSeconds,
+		"must not be less than retry_after_seconds (%d), got %d",
+		c.Server.RetryAfterSeconds, c.Server.MaxRetryAfterSeconds)
+	v.check(c.Server.MaxBatchSize >= 0 &&
+		c.Server.MaxBatchSize <= maxRequestBatchSize,
+		&c.Server.MaxBatchSize, "must be between 0 and %d, got %d",
+		maxRequestBatchSize, c.Server.MaxBatchSize)
+	if c.Server.RedirectBaseUrl != "" {
+		v.checkUrl(&c.Server.RedirectBaseUrl)
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		v.check(isValidAddressOrPrefix(proxy), &c.Server.TrustedProxies,
+			"invalid IP address or CIDR range %q", proxy)
+	}
+	uploads := &c.Server.ResumableUploads
+	v.check(uploads.MaxChunkSizeInMB == 0 ||
+		(uploads.MaxChunkSizeInMB >= minUploadChunkSizeInMB &&
+			uploads.MaxChunkSizeInMB <= maxUploadChunkSizeInMB),
+		&uploads.MaxChunkSizeInMB, "must be 0 or between %d and %d, got %d",
+		minUploadChunkSizeInMB, maxUploadChunkSizeInMB, uploads.MaxChunkSizeInMB)
+	v.check(uploads.ChunkTimeoutSeconds >= 0 &&
+		uploads.ChunkTimeoutSeconds <= maxUploadChunkTimeoutSeconds,
+		&uploads.ChunkTimeoutSeconds, "must be between 0 and %d, got %d",
+		maxUploadChunkTimeoutSeconds, uploads.ChunkTimeoutSeconds)
+	proxy := &c.Server.ContentProxy
+	for _, tenantID := range proxy.TenantIDs {
+		v.check(uuid.Validate(tenantID) == nil, &proxy.TenantIDs,
+			"invalid tenant ID %q", tenantID)
+	}
+	v.check(len(proxy.TenantIDs) == 0 || c.Server.RedirectBaseUrl != "",
+		&proxy.TenantIDs, "requires server.redirect_base_url to be specified")
+	v.check(proxy.TransferTimeoutSeconds >= 0 &&
+		proxy.TransferTimeoutSeconds <= maxProxyTransferTimeoutSeconds,
+		&proxy.TransferTimeoutSeconds, "must be between 0 and %d, got %d",
+		maxProxyTransferTimeoutSeconds, proxy.TransferTimeoutSeconds)
+	v.check(c.Server.ShutdownTimeoutSeconds >= 0, &c.Server.ShutdownTimeoutSeconds,
+		"must not be negative, got %d", c.Server.ShutdownTimeoutSeconds)
+	v.check(c.Server.ConfigWatchIntervalSeconds >= 0,
+		&c.Server.ConfigWatchIntervalSeconds,
+		"must not be negative, got %d", c.Server.ConfigWatchIntervalSeconds)
+	v.checkUrl(&c.Server.Auth.JwksUrl)
+	v.check(c.Server.Auth.Issuer != "", &c.Server.Auth.Issuer,
+		"must be specified")
+	for _, appID := range c.Server.Auth.AllowedAppIds {
+		v.check(strings.TrimSpace(appID) != "", &c.Server.Auth.AllowedAppIds,
+			"must not contain empty app IDs")
+	}
+
+	// Database settings.
+	v.check(c.Database.Host != "", &c.Database.Host, "must be specified")
+	v.checkPort(&c.Database.Port)
+	v.check(c.Database.Username != "", &c.Database.Username, "must be specified")
+	v.check(c.Database.DatabaseName != "", &c.Database.DatabaseName,
+		"must be specified")
+	v.checkOneOf(&c.Database.DatabaseType, supportedDatabaseTypes)
+	v.check(!c.Database.SchemaMigrationEnabled ||
+		c.Database.SchemaMigrationScripts != "",
+		&c.Database.SchemaMigrationScripts,
+		"must be specified when schema migration is enabled")
+	v.check(c.Database.RetainedFileVersions >= 0,
+		&c.Database.RetainedFileVersions,
+		"must not be negative, got %d", c.Database.RetainedFileVersions)
+	v.check(c.Database.AuditRetentionDays >= 0, &c.Database.AuditRetentionDays,
+		"must not be negative, got %d", c.Database.AuditRetentionDays)
+	if c.Database.ScavengerSchedule != "" {
+		_, err := cron.ParseStandard(c.Database.ScavengerSchedule)
+		v.check(err == nil, &c.Database.ScavengerSchedule,
+			"must be a valid cron expression, got %q: %v",
+			c.Database.ScavengerSchedule, err)
+	}
+	v.check(c.Database.ScavengerBatchSize >= 0 &&
+		c.Database.ScavengerBatchSize <= maxScavengerBatchSize,
+		&c.Database.ScavengerBatchSize, "must be between 0 and %d, got %d",
+		maxScavengerBatchSize, c.Database.ScavengerBatchSize)
+	v.check(c.Database.ScavengerTimeBudgetMinutes >= 0,
+		&c.Database.ScavengerTimeBudgetMinutes,
+		"must not be negative, got %d", c.Database.ScavengerTimeBudgetMinutes)
+	v.check(c.Database.LeaderLeaseSeconds == 0 ||
+		c.Database.LeaderLeaseSeconds >= minLeaderLeaseSeconds,
+		&c.Database.LeaderLeaseSeconds,
+		"must be 0 (default) or at least %d, got %d", minLeaderLeaseSeconds,
+		c.Database.LeaderLeaseSeconds)
+	v.check(c.Database.MaxOpenConnections >= 0, &c.Database.MaxOpenConnections,
+		"must not be negative, got %d", c.Database.MaxOpenConnections)
+	v.checkOneOf(&c.Database.SslMode, supportedSslModes)
+	v.check(c.Database.SslMode == "disable" ||
+		!slices.Contains(supportedSslModes, c.Database.SslMode) ||
+		c.Database.SslRootCertificate != "",
+		&c.Database.SslRootCertificate,
+		"must be specified when ssl_mode is %q", c.Database.SslMode)
+
+	// Cache settings.
+	// Configuration files that predate the cache modes use standalone mode.
+	cacheMode := c.Cache.Mode
+	if cacheMode == "" {
+		cacheMode = CacheModeStandalone
+	}
+	v.check(slices.Contains(supportedCacheModes, cacheMode), &c.Cache.Mode,
+		"must be one of %s, got %q", strings.Join(supportedCacheModes, ", "),
+		cacheMode)
+	if c.Cache.Enabled {
+		switch cacheMode {
+		case CacheModeStandalone:
+			v.check(c.Cache.Host != "", &c.Cache.Host,
+				"must be specified when caching is enabled")
+			v.checkPort(&c.Cache.Port)
+
+		case CacheModeSentinel, CacheModeCluster:
+			v.check(len(c.Cache.Addresses) != 0, &c.Cache.Addresses,
+				"must be specified in %s mode", cacheMode)
+			for _, address := range c.Cache.Addresses {
+				_, port, err := net.SplitHostPort(address)
+				v.check(err == nil && port != "", &c.Cache.Addresses,
+					"invalid address %q, expected host:port", address)
+			}
+		}
+		v.check(cacheMode != CacheModeSentinel || c.Cache.MasterName != "",
+			&c.Cache.MasterName, "must be specified in sentinel mode")
+		v.check(cacheMode != CacheModeCluster || c.Cache.CacheDatabase == 0,
+			&c.Cache.CacheDatabase, "must be 0 in cluster mode, got %d",
+			c.Cache.CacheDatabase)
+	}
+	v.check(c.Cache.CacheDatabase >= 0 && c.Cache.CacheDatabase < maxCacheDatabases,
+		&c.Cache.CacheDatabase, "must be between 0 and %d, got %d",
+		maxCacheDatabases-1, c.Cache.CacheDatabase)
+	for _, setting := range []*int{&c.Cache.PoolSize, &c.Cache.MinIdleConnections,
+		&c.Cache.DialTimeoutMs, &c.Cache.ReadTimeoutMs, &c.Cache.WriteTimeoutMs,
+		&c.Cache.PoolTimeoutMs, &c.Cache.OperationTimeoutMs,
+		&c.Cache.DegradedFailureThreshold, &c.Cache.DegradedRetryIntervalSeconds} {
+		v.check(*setting >= 0, setting, "must not be negative, got %d", *setting)
+	}
+
+	// Storage settings.
+	v.check(len(c.Storage.BucketNames) != 0, &c.Storage.BucketNames,
+		"at least one bucket must be configured")
+	for _, bucket := range c.Storage.BucketNames {
+		v.check(bucketNameRegex.MatchString(bucket), &c.Storage.BucketNames,
+			"invalid bucket name %q", bucket)
+	}
+	v.check(c.Storage.SignedUrlDurationInMinutes > 0 &&
+		c.Storage.SignedUrlDurationInMinutes <= maxSignedUrlDurationInMinutes,
+		&c.Storage.SignedUrlDurationInMinutes,
+		"must be between 1 and %d minutes, got %d",
+		maxSignedUrlDurationInMinutes, c.Storage.SignedUrlDurationInMinutes)
+	v.checkSignedUrlDurations(c.Storage.SignedUrlMethodDurations,
+		&c.Storage.SignedUrlMethodDurations)
+	for tenantID, durations := range c.Storage.SignedUrlTenantDurations {
+		v.check(uuid.Validate(tenantID) == nil, &c.Storage.SignedUrlTenantDurations,
+			"invalid tenant ID %q", tenantID)
+		v.checkSignedUrlDurations(durations, &c.Storage.SignedUrlTenantDurations)
+	}
+	v.check(c.Storage.SignedUrlMaxDurationInMinutes >= 0 &&
+		c.Storage.SignedUrlMaxDurationInMinutes <= maxSignedUrlDurationInMinutes,
+		&c.Storage.SignedUrlMaxDurationInMinutes,
+		"must be between 0 and %d minutes, got %d",
+		maxSignedUrlDurationInMinutes, c.Storage.SignedUrlMaxDurationInMinutes)
+
+	// Notification settings.
+	v.check(c.Notification.Name != "", &c.Notification.Name, "must be specified")
+	v.check(c.Notification.WatchDelay >= 0 &&
+		c.Notification.WatchDelay <= maxWatchDelaySeconds,
+		&c.Notification.WatchDelay, "must be between 0 and %d seconds, got %d",
+		maxWatchDelaySeconds, c.Notification.WatchDelay)
+
+	// Malware scanning settings.
+	v.check(!c.Scanning.Enabled || c.Scanning.DefaultScannerName != "",
+		&c.Scanning.DefaultScannerName,
+		"must be specified when scanning is enabled")
+
+	// Tracing settings.
+	v.check(!c.Tracing.Enabled || c.Tracing.Endpoint != "", &c.Tracing.Endpoint,
+		"must be specified when tracing is enabled")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		&c.Tracing.SampleRatio, "must be between 0 and 1, got %g",
+		c.Tracing.SampleRatio)
+
+	if len(v.problems) != 0 {
+		return v.problems
+	}
+	return nil
+}
+
+// validator collects the problems found in a configuration.
+type validator struct {
+	config   *Config
+	problems ValidationErrors
+}
+
+// Records a problem with the specified setting unless the condition holds.
+func (v *validator) check(condition bool, setting any, format string,
+	args ...any) {
+	if condition {
+		return
+	}
+	v.problems = append(v.problems, ValidationError{
+		Path:    v.config.yamlPath(setting),
+		EnvVar:  v.config.envVarName(setting),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) checkPort(port *int) {
+	v.check(*port > 0 && *port <= 65535, port,
+		"must be between 1 and 65535, got %d", *port)
+}
+
+func (v *validator) checkOneOf(setting *string, values []string) {
+	v.check(slices.Contains(values, *setting), setting,
+		"must be one of %s, got %q", strings.Join(values, ", "), *setting)
+}
+
+// Checks the durations of signed URLs configured for specific access methods,
+// which are recorded as problems with the specified setting.
+func (v *validator) checkSignedUrlDurations(durations map[string]int,
+	setting any) {
+	for method, duration := range durations {
+		v.check(slices.Contains(supportedAccessMethods, method), setting,
+			"access method must be one of %s, got %q",
+			strings.Join(supportedAccessMethods, ", "), method)
+		v.check(duration > 0 && duration <= maxSignedUrlDurationInMinutes,
+			setting, "%s duration must be between 1 and %d minutes, got %d",
+			method, maxSignedUrlDurationInMinutes, duration)
+	}
+}
+
+func (v *validator) checkUrl(setting *string) {
+	if *setting == "" {
+		v.check(false, setting, "must be specified")
+		return
+	}
+	parsed, err := url.Parse(*setting)
+	v.check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") &&
+		parsed.Host != "", setting, "must be an http or https URL, got %q",
+		*setting)
+}
+
+// Returns whether the value is an IP address or a CIDR range.
+func isValidAddressOrPrefix(value string) bool {
+	if _, err := netip.ParseAddr(value); err == nil {
+		return true
+	}
+	_, err := netip.ParsePrefix(value)
+	return err == nil
+}
+
+// Returns the path, in the configuration file, of the setting at the
+// specified address.
+func (c *Config) yamlPath(setting any) string {
+	path, _ := findYamlPath(reflect.ValueOf(c).Elem(),
+		reflect.ValueOf(setting).Pointer(), "")
+	return path
+}
+
+func findYamlPath(v reflect.Value, target uintptr, prefix string) (string, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Fields without a YAML tag are keyed by their lower cased name.
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if path, ok := findYamlPath(v.Field(i), target, name); ok {
+				return path, true
+			}
+			continue
+		}
+		if v.Field(i).Addr().Pointer() == target {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Returns the environment variable that overrides the setting at the
+// specified address.
+func (c *Config) envVarName(setting any) string {
+	for name, value := range c.environmentVariables() {
+		if value.v == setting {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,292 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Returns a configuration that passes validation.
+func newValidTestConfig() Config {
+	var c Config
+	c.Server.Port = 1234
+	c.Server.RetryAfterSeconds = 2
+	c.Server.MaxRetryAfterSeconds = 60
+	c.Server.ShutdownTimeoutSeconds = 30
+	c.Server.ConfigWatchIntervalSeconds = 30
+	c.Server.Auth.JwksUrl = "http://localhost:7001/api/v1/keys"
+	c.Server.Auth.Issuer = "HP Device Token Service"
+	c.Server.Auth.AllowedAppIds = []string{"8f5fafe3-a443-42a1-8ad5-e583935fbdd6"}
+	c.Database.Host = "localhost"
+	c.Database.Port = 5432
+	c.Database.Username = "krypton"
+	c.Database.DatabaseName = "files"
+	c.Database.DatabaseType = "postgres"
+	c.Database.SchemaMigrationScripts = "/go/bin/schema"
+	c.Database.SchemaMigrationEnabled = true
+	c.Database.AuditRetentionDays = 90
+	c.Database.SslMode = "disable"
+	c.Cache.Enabled = true
+	c.Cache.Mode = CacheModeStandalone
+	c.Cache.Host = "fs-cache"
+	c.Cache.Port = 6379
+	c.Storage.BucketNames = []string{"mytestkrypton20221130"}
+	c.Storage.SignedUrlDurationInMinutes = 15
+	c.Notification.Name = "fs-notification"
+	c.Notification.WatchDelay = 2
+	c.Scanning.DefaultScannerName = "default"
+	c.Tracing.Endpoint = "localhost:4318"
+	c.Tracing.SampleRatio = 1.0
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		expected string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"zero port", func(c *Config) { c.Server.Port = 0 },
+			"server.port / FS_PORT"},
+		{"port out of range", func(c *Config) { c.Server.Port = 70000 },
+			"server.port / FS_PORT"},
+		{"invalid grpc port", func(c *Config) {
+			c.Server.GrpcEnabled = true
+			c.Server.GrpcPort = 0
+		}, "server.grpc_port / FS_GRPC_PORT"},
+		{"grpc port same as port", func(c *Config) {
+			c.Server.GrpcEnabled = true
+			c.Server.GrpcPort = c.Server.Port
+		}, "server.grpc_port / FS_GRPC_PORT"},
+		{"negative retry after", func(c *Config) { c.Server.RetryAfterSeconds = -1 },
+			"server.retry_after_seconds / FS_RETRY_AFTER_SECONDS"},
+		{"max retry after too small", func(c *Config) { c.Server.MaxRetryAfterSeconds = 1 },
+			"server.max_retry_after_seconds / FS_MAX_RETRY_AFTER_SECONDS"},
+		{"batch size too large", func(c *Config) { c.Server.MaxBatchSize = 1001 },
+			"server.max_batch_size / FS_MAX_BATCH_SIZE"},
+		{"invalid redirect base url", func(c *Config) { c.Server.RedirectBaseUrl = "files.example.com" },
+			"server.redirect_base_url / FS_REDIRECT_BASE_URL"},
+		{"invalid trusted proxy", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/33"} },
+			"server.trusted_proxies / FS_TRUSTED_PROXIES"},
+		{"upload chunk size too small", func(c *Config) { c.Server.ResumableUploads.MaxChunkSizeInMB = 4 },
+			"server.resumable_uploads.max_chunk_size_mb"},
+		{"upload chunk timeout too long", func(c *Config) { c.Server.ResumableUploads.ChunkTimeoutSeconds = 3601 },
+			"server.resumable_uploads.chunk_timeout_seconds"},
+		{"invalid proxy tenant id", func(c *Config) {
+			c.Server.RedirectBaseUrl = "https://files.example.com"
+			c.Server.ContentProxy.TenantIDs = []string{"tenant"}
+		}, "server.content_proxy.tenant_ids / FS_CONTENT_PROXY_TENANT_IDS"},
+		{"proxy without base url", func(c *Config) {
+			c.Server.ContentProxy.TenantIDs = []string{"9b2c8c1e-4a44-4d9c-9a5f-2d8f3f5d6a01"}
+		}, "server.content_proxy.tenant_ids / FS_CONTENT_PROXY_TENANT_IDS"},
+		{"proxy timeout too long", func(c *Config) { c.Server.ContentProxy.TransferTimeoutSeconds = 3601 },
+			"server.content_proxy.transfer_timeout_seconds"},
+		{"negative shutdown timeout", func(c *Config) { c.Server.ShutdownTimeoutSeconds = -1 },
+			"server.shutdown_timeout_seconds / FS_SHUTDOWN_TIMEOUT_SECONDS"},
+		{"negative watch interval", func(c *Config) { c.Server.ConfigWatchIntervalSeconds = -1 },
+			"server.config_watch_interval_seconds / FS_CONFIG_WATCH_INTERVAL_SECONDS"},
+		{"empty jwks url", func(c *Config) { c.Server.Auth.JwksUrl = "" },
+			"server.auth.jwks_url / FS_SERVER_AUTH_JWKS_URL"},
+		{"invalid jwks url", func(c *Config) { c.Server.Auth.JwksUrl = "localhost:7001" },
+			"server.auth.jwks_url / FS_SERVER_AUTH_JWKS_URL"},
+		{"empty issuer", func(c *Config) { c.Server.Auth.Issuer = "" },
+			"server.auth.issuer / FS_SERVER_AUTH_ISSUER"},
+		{"empty app id", func(c *Config) { c.Server.Auth.AllowedAppIds = []string{" "} },
+			"server.auth.allowed_app_ids / FS_SERVER_AUTH_ALLOWED_APP_IDS"},
+		{"empty database host", func(c *Config) { c.Database.Host = "" },
+			"database.db_hostname / FS_DB_SERVER"},
+		{"invalid database port", func(c *Config) { c.Database.Port = -5 },
+			"database.db_port / FS_DB_PORT"},
+		{"empty database user", func(c *Config) { c.Database.Username = "" },
+			"database.user / FS_DB_USER"},
+		{"empty database name", func(c *Config) { c.Database.DatabaseName = "" },
+			"database.db_name / FS_DB_NAME"},
+		{"unknown deployment type", func(c *Config) { c.Database.DatabaseType = "mysql" },
+			"database.deployment_type / FS_DB_TYPE"},
+		{"empty schema", func(c *Config) { c.Database.SchemaMigrationScripts = "" },
+			"database.schema / FS_DB_SCHEMA"},
+		{"empty schema without migration", func(c *Config) {
+			c.Database.SchemaMigrationScripts = ""
+			c.Database.SchemaMigrationEnabled = false
+		}, ""},
+		{"negative retained versions", func(c *Config) { c.Database.RetainedFileVersions = -1 },
+			"database.retained_file_versions / FS_DB_RETAINED_FILE_VERSIONS"},
+		{"negative audit retention", func(c *Config) { c.Database.AuditRetentionDays = -1 },
+			"database.audit_retention_days / FS_DB_AUDIT_RETENTION_DAYS"},
+		{"scavenger schedule", func(c *Config) { c.Database.ScavengerSchedule = "CRON_TZ=UTC 30 2 * * *" }, ""},
+		{"scavenger schedule descriptor", func(c *Config) { c.Database.ScavengerSchedule = "@daily" }, ""},
+		{"invalid scavenger schedule", func(c *Config) { c.Database.ScavengerSchedule = "59 23 * *" },
+			"database.scavenger_schedule / FS_DB_SCAVENGER_SCHEDULE"},
+		{"scavenger batch too large", func(c *Config) { c.Database.ScavengerBatchSize = 100001 },
+			"database.scavenger_batch_size / FS_DB_SCAVENGER_BATCH_SIZE"},
+		{"negative scavenger time budget", func(c *Config) { c.Database.ScavengerTimeBudgetMinutes = -1 },
+			"database.scavenger_time_budget_min"},
+		{"leader lease too short", func(c *Config) { c.Database.LeaderLeaseSeconds = 2 },
+			"database.leader_lease_seconds / FS_DB_LEADER_LEASE_SECONDS"},
+		{"negative max connections", func(c *Config) { c.Database.MaxOpenConnections = -1 },
+			"database.max_open_connections / FS_DB_MAX_CONNECTIONS"},
+		{"unknown ssl mode", func(c *Config) { c.Database.SslMode = "prefer" },
+			"database.ssl_mode / FS_DB_SSL_MODE"},
+		{"ssl without root cert", func(c *Config) { c.Database.SslMode = "verify-full" },
+			"database.ssl_root_cert / FS_DB_SSL_ROOT_CERT"},
+		{"ssl with root cert", func(c *Config) {
+			c.Database.SslMode = "verify-full"
+			c.Database.SslRootCertificate = "/certs/root.pem"
+		}, ""},
+		{"empty cache host", func(c *Config) { c.Cache.Host = "" },
+			"cache.cache_hostname / FS_CACHE_SERVER"},
+		{"invalid cache port", func(c *Config) { c.Cache.Port = 0 },
+			"cache.cache_port / FS_CACHE_PORT"},
+		{"cache disabled", func(c *Config) {
+			c.Cache.Enabled = false
+			c.Cache.Host = ""
+			c.Cache.Port = 0
+		}, ""},
+		{"invalid cache database", func(c *Config) { c.Cache.CacheDatabase = 16 },
+			"cache.cache_db"},
+		{"unknown cache mode", func(c *Config) { c.Cache.Mode = "replicated" },
+			"cache.mode / FS_CACHE_MODE"},
+		{"default cache mode", func(c *Config) { c.Cache.Mode = "" }, ""},
+		{"sentinel mode", func(c *Config) {
+			c.Cache.Mode = CacheModeSentinel
+			c.Cache.Addresses = []string{"sentinel-1:26379", "sentinel-2:26379"}
+			c.Cache.MasterName = "fs-cache"
+		}, ""},
+		{"sentinel without addresses", func(c *Config) {
+			c.Cache.Mode = CacheModeSentinel
+			c.Cache.MasterName = "fs-cache"
+		}, "cache.addresses / FS_CACHE_ADDRESSES"},
+		{"sentinel without master", func(c *Config) {
+			c.Cache.Mode = CacheModeSentinel
+			c.Cache.Addresses = []string{"sentinel-1:26379"}
+		}, "cache.master_name / FS_CACHE_MASTER_NAME"},
+		{"cluster mode", func(c *Config) {
+			c.Cache.Mode = CacheModeCluster
+			c.Cache.Addresses = []string{"node-1:6379"}
+		}, ""},
+		{"invalid cluster address", func(c *Config) {
+			c.Cache.Mode = CacheModeCluster
+			c.Cache.Addresses = []string{"node-1"}
+		}, "cache.addresses / FS_CACHE_ADDRESSES"},
+		{"cluster with database", func(c *Config) {
+			c.Cache.Mode = CacheModeCluster
+			c.Cache.Addresses = []string{"node-1:6379"}
+			c.Cache.CacheDatabase = 1
+		}, "cache.cache_db"},
+		{"negative pool size", func(c *Config) { c.Cache.PoolSize = -1 },
+			"cache.pool_size / FS_CACHE_POOL_SIZE"},
+		{"negative operation timeout", func(c *Config) { c.Cache.OperationTimeoutMs = -1 },
+			"cache.operation_timeout_ms"},
+		{"no buckets", func(c *Config) { c.Storage.BucketNames = nil },
+			"storage.bucket_names / FS_STORAGE_BUCKET_NAMES"},
+		{"invalid bucket name", func(c *Config) { c.Storage.BucketNames = []string{"My_Bucket"} },
+			"storage.bucket_names / FS_STORAGE_BUCKET_NAMES"},
+		{"zero signed url duration", func(c *Config) { c.Storage.SignedUrlDurationInMinutes = 0 },
+			"storage.signed_url_duration_min"},
+		{"signed url duration too long", func(c *Config) { c.Storage.SignedUrlDurationInMinutes = 10081 },
+			"storage.signed_url_duration_min"},
+		{"invalid signed url method", func(c *Config) {
+			c.Storage.SignedUrlMethodDurations = map[string]int{"post": 60}
+		}, "storage.signed_url_method_durations_min"},
+		{"zero signed url method duration", func(c *Config) {
+			c.Storage.SignedUrlMethodDurations = map[string]int{AccessMethodPut: 0}
+		}, "storage.signed_url_method_durations_min"},
+		{"invalid signed url tenant", func(c *Config) {
+			c.Storage.SignedUrlTenantDurations = map[string]map[string]int{
+				"tenant": {AccessMethodGet: 5}}
+		}, "storage.signed_url_tenant_durations_min"},
+		{"signed url tenant duration too long", func(c *Config) {
+			c.Storage.SignedUrlTenantDurations = map[string]map[string]int{
+				"4ae7ea41-8a9f-4f3e-a1b6-0c0b7a2d5f11": {AccessMethodGet: 10081}}
+		}, "storage.signed_url_tenant_durations_min"},
+		{"max signed url duration too long", func(c *Config) { c.Storage.SignedUrlMaxDurationInMinutes = 10081 },
+			"storage.signed_url_max_duration_min / FS_STORAGE_SIGNED_URL_MAX_DURATION_MIN"},
+		{"empty notification name", func(c *Config) { c.Notification.Name = "" },
+			"notification.name / FS_NOTIFICATION_NAME"},
+		{"watch delay too long", func(c *Config) { c.Notification.WatchDelay = 21 },
+			"notification.watch_delay / FS_NOTIFICATION_WATCH_DELAY"},
+		{"empty scanner name", func(c *Config) {
+			c.Scanning.Enabled = true
+			c.Scanning.DefaultScannerName = ""
+		}, "scanning.default_scanner_name / FS_SCANNING_DEFAULT_SCANNER_NAME"},
+		{"empty tracing endpoint", func(c *Config) {
+			c.Tracing.Enabled = true
+			c.Tracing.Endpoint = ""
+		}, "tracing.endpoint / FS_TRACING_ENDPOINT"},
+		{"invalid sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 },
+			"tracing.sample_ratio / FS_TRACING_SAMPLE_RATIO"},
+	}
+
+	for _, tc := range tests {
+		c := newValidTestConfig()
+		tc.modify(&c)
+		err := c.Validate()
+		if tc.expected == "" {
+			if err != nil {
+				t.Fatalf("%s: Expected success, Got error: %v", tc.name, err)
+			}
+			continue
+		}
+
+		var problems ValidationErrors
+		if !errors.As(err, &problems) {
+			t.Fatalf("%s: Expected validation errors, Got: %v", tc.name, err)
+		}
+		if len(problems) != 1 {
+			t.Fatalf("%s: Expected a single problem, Got: %v", tc.name, err)
+		}
+		if !strings.HasPrefix(problems[0].Error(), tc.expected+": ") {
+			t.Fatalf("%s: Expected a problem with %s, Got: %v",
+				tc.name, tc.expected, problems[0])
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	var c Config
+	err := c.Validate()
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Validate: Expected validation errors, Got: %v", err)
+	}
+	for _, expected := range []string{"server.port", "server.auth.jwks_url",
+		"database.deployment_type", "storage.bucket_names",
+		"storage.signed_url_duration_min"} {
+		found := false
+		for _, problem := range problems {
+			found = found || problem.Path == expected
+		}
+		if !found {
+			t.Fatalf("Validate: Expected a problem with %s, Got: %v", expected, err)
+		}
+	}
+}
+
+func TestValidateReportsBadEnvVariable(t *testing.T) {
+	c := newValidTestConfig()
+	t.Setenv("FS_PORT", "not_an_int")
+	t.Setenv("FS_SCANNING_ENABLED", "maybe")
+	c.OverrideFromEnvironment()
+
+	var problems ValidationErrors
+	if !errors.As(c.Validate(), &problems) || len(problems) != 2 {
+		t.Fatalf("Validate: Expected two problems, Got: %v", problems)
+	}
+	for _, expected := range []string{
+		"server.port / FS_PORT: invalid value \"not_an_int\"",
+		"scanning.enabled / FS_SCANNING_ENABLED: invalid value \"maybe\"",
+	} {
+		found := false
+		for _, problem := range problems {
+			found = found || strings.HasPrefix(problem.Error(), expected)
+		}
+		if !found {
+			t.Fatalf("Validate: Expected a problem with %s, Got: %v", expected, problems)
+		}
+	}
+}
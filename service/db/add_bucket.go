@@ -4,17 +4,17 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/metrics"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
 )
 
 func (b *Bucket) AddBucketIfNotExists() error {
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(context.Background(), operationDbAddBucket)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbAddBucket)
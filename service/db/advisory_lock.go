@@ -0,0 +1,216 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// Key of the Postgres advisory lock held by the leader. Advisory locks are
+	// held by database sessions, so the leader holds the lock on a connection
+	// dedicated to it until the lease is released.
+	leaderAdvisoryLockKey int64 = 0x6b726673
+
+	// Database operations.
+	operationDbAcquireLeaderLease = "AcquireLeaderLease"
+	operationDbRenewLeaderLease   = "RenewLeaderLease"
+	operationDbCheckLeaderLease   = "CheckLeaderLease"
+	operationDbReleaseLeaderLease = "ReleaseLeaderLease"
+	operationDbGetLeaderLease     = "GetLeaderLease"
+)
+
+// databaseLeaderBackend holds the leader lease using a Postgres advisory lock,
+// when caching is disabled. The lease of the leader and its fencing token are
+// recorded in the leader_leases table.
+type databaseLeaderBackend struct {
+	// Protects the connection holding the advisory lock, which cannot be used
+	// concurrently.
+	lock sync.Mutex
+	conn *pgxpool.Conn
+}
+
+var databaseLeader databaseLeaderBackend
+
+func (b *databaseLeaderBackend) name() string {
+	return LeaderBackendDatabase
+}
+
+func (b *databaseLeaderBackend) acquire(ctx context.Context,
+	holder string) (int64, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbAcquireLeaderLease)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbAcquireLeaderLease)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.conn != nil {
+		return 0, nil
+	}
+
+	conn, err := gDbPool.Acquire(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to acquire a connection to hold the leader lock!",
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	var acquired bool
+	err = conn.QueryRow(ctx, queryTryAdvisoryLock,
+		leaderAdvisoryLockKey).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Release()
+		return 0, err
+	}
+
+	// Record the lease and issue the next fencing token.
+	var fencingToken int64
+	err = conn.QueryRow(ctx, queryClaimLeaderLease, leaderLeaseName,
+		holder).Scan(&fencingToken)
+	if err != nil {
+		fsLogger.Error("Failed to record the leader lease in the database!",
+			zap.Error(err),
+		)
+		closeLeaderConnection(conn)
+		return 0, err
+	}
+
+	b.conn = conn
+	return fencingToken, nil
+}
+
+func (b *databaseLeaderBackend) renew(ctx context.Context,
+	fencingToken int64) error {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbRenewLeaderLease)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbRenewLeaderLease)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.conn == nil {
+		return ErrNotLeader
+	}
+
+	// Renewing the lease on the connection holding the advisory lock checks
+	// that the session holding the lock is still alive.
+	tag, err := b.conn.Exec(ctx, queryRenewLeaderLease, leaderLeaseName,
+		fencingToken)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+func (b *databaseLeaderBackend) check(ctx context.Context,
+	fencingToken int64) error {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbCheckLeaderLease)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbCheckLeaderLease)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.conn == nil {
+		return ErrNotLeader
+	}
+
+	var currentToken int64
+	err := b.conn.QueryRow(ctx, queryLeaderFencingToken,
+		leaderLeaseName).Scan(&currentToken)
+	if err != nil {
+		return err
+	}
+	if currentToken != fencingToken {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+func (b *databaseLeaderBackend) release(ctx context.Context,
+	fencingToken int64) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbReleaseLeaderLease)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbReleaseLeaderLease)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.conn == nil {
+		return
+	}
+
+	var released bool
+	err := b.conn.QueryRow(ctx, queryAdvisoryUnlock,
+		leaderAdvisoryLockKey).Scan(&released)
+	if err != nil || !released {
+		// Close the session to make sure the advisory lock is released.
+		fsLogger.Error("Failed to release the leader lock!",
+			zap.Int64("Fencing token:", fencingToken),
+			zap.Bool("Released:", released),
+			zap.Error(err),
+		)
+		closeLeaderConnection(b.conn)
+	} else {
+		b.conn.Release()
+	}
+	b.conn = nil
+}
+
+func (b *databaseLeaderBackend) status(ctx context.Context) (*LeaderStatus,
+	error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetLeaderLease)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetLeaderLease)
+
+	// The lease recorded in the database is only current while the advisory
+	// lock is held. Big integer advisory lock keys are reported with the low
+	// half of the key as the object ID.
+	var leader LeaderStatus
+	var held bool
+	err := gDbPool.QueryRow(ctx, queryLeaderLease, leaderLeaseName,
+		uint32(leaderAdvisoryLockKey)).Scan(&leader.Holder,
+		&leader.FencingToken, &leader.AcquiredAt, &leader.RenewedAt, &held)
+	if err == pgx.ErrNoRows || (err == nil && !held) {
+		return nil, ErrNoLeader
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &leader, nil
+}
+
+// Close the session holding the leader lock, which releases the lock, and
+// return the connection to the pool, which discards it.
+func closeLeaderConnection(conn *pgxpool.Conn) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(),
+		dbOperationTimeout)
+	defer cancelFunc()
+
+	_ = conn.Conn().Close(ctx)
+	conn.Release()
+}
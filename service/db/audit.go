@@ -0,0 +1,306 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Actions recorded in the file audit log. Actions may not be longer than
+// auditActionLength characters.
+const (
+	AuditActionCreate      = "create"
+	AuditActionGet         = "get"
+	AuditActionList        = "list"
+	AuditActionSignGet     = "sign-get"
+	AuditActionSignPut     = "sign-put"
+	AuditActionSignHead    = "sign-head"
+	AuditActionDelete      = "delete"
+	AuditActionQuarantine  = "quarantine"
+	AuditActionRelease     = "release"
+	AuditActionRescan      = "rescan"
+	AuditActionScavenge    = "scavenge"
+	AuditActionPurge       = "purge"
+	AuditActionLegalHold   = "legal-hold"
+	AuditActionHoldRelease = "hold-release"
+	AuditActionRedeem      = "redeem"
+	AuditActionUpload      = "upload"
+	AuditActionUploadAbort = "upload-abort"
+	AuditActionDownload    = "download"
+)
+
+// Outcomes of the actions recorded in the file audit log.
+const (
+	AuditOutcomeSuccess  = "success"
+	AuditOutcomeDenied   = "denied"
+	AuditOutcomeNotFound = "not_found"
+	AuditOutcomeRejected = "rejected"
+	AuditOutcomeFailure  = "failure"
+)
+
+const (
+	// Actor recorded for actions taken by the database scavenger.
+	AuditActorScavenger = "scavenger"
+
+	// Actor recorded for files deleted by purge jobs.
+	AuditActorPurge = "purge"
+
+	// Number of audit events that can be queued for writing to the database.
+	// Audit events are dropped if the queue is full.
+	auditQueueSize = 4096
+
+	// Maximum number of audit events written to the database at once.
+	auditBatchSize = 256
+
+	// Interval at which queued audit events are written to the database.
+	auditFlushInterval = time.Second
+
+	// Maximum number of audit events returned by a query.
+	MaxAuditEventsPerQuery = 1000
+
+	// Length of the action column of the file audit events table.
+	auditActionLength = 16
+)
+
+var (
+	auditEvents        chan AuditEvent
+	auditWriterCtx     context.Context
+	auditWriterCancel  context.CancelFunc
+	auditWriterDone    chan bool
+	auditRetentionDays atomic.Int64
+	auditEventsColumns = []string{"occurred_at", "tenant_id", "actor",
+		"action", "file_id", "request_id", "source_ip", "outcome"}
+	auditEventsTable = pgx.Identifier{"file_audit_events"}
+)
+
+// Represents an access to a file or an administrative action taken on files,
+// as recorded in the append-only file audit log.
+type AuditEvent struct {
+	// The unique identifier assigned to the audit event.
+	EventID uint64 `json:"event_id"`
+
+	// When the action was taken.
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// The tenant to which the file belongs, if known.
+	TenantID string `json:"tenant_id,omitempty"`
+
+	// The device or app that took the action.
+	Actor string `json:"actor,omitempty"`
+
+	// The action that was taken.
+	Action string `json:"action"`
+
+	// The file on which the action was taken, if any.
+	FileID uint64 `json:"file_id,omitempty"`
+
+	// The request in which the action was taken, if any.
+	RequestID string `json:"request_id,omitempty"`
+
+	// The IP address from which the request was received, if any.
+	SourceIP string `json:"source_ip,omitempty"`
+
+	// The outcome of the action.
+	Outcome string `json:"outcome"`
+}
+
+// Filter for queries of the file audit log. Empty fields are not filtered on.
+type AuditEventFilter struct {
+	TenantID string
+	FileID   uint64
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// RecordAuditEvent - queue the specified audit event to be written to the
+// file audit log. Audit events are written asynchronously and this function
+// never blocks; if the audit queue is full, the event is dropped.
+func RecordAuditEvent(event AuditEvent) {
+	if auditEvents == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	select {
+	case auditEvents <- event:
+	default:
+		fsLogger.Error("The audit queue is full. Dropping audit event!",
+			zap.String("Request ID:", event.RequestID),
+			zap.String("Action:", event.Action),
+			zap.Uint64("File ID:", event.FileID),
+		)
+		metrics.MetricAuditEventsDropped.Inc()
+	}
+}
+
+// Start the goroutine that writes queued audit events to the database.
+func startAuditWriter() {
+	auditEvents = make(chan AuditEvent, auditQueueSize)
+	auditWriterCtx, auditWriterCancel = context.WithCancel(context.Background())
+	auditWriterDone = make(chan bool, 1)
+
+	go runAuditWriter()
+}
+
+// Stop the audit writer goroutine and wait for it to write all queued audit
+// events to the database.
+func stopAuditWriter() {
+	if auditWriterCancel != nil {
+		auditWriterCancel()
+		<-auditWriterDone
+	}
+}
+
+func runAuditWriter() {
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]AuditEvent, 0, auditBatchSize)
+	for {
+		select {
+		case event := <-auditEvents:
+			batch = append(batch, event)
+			if len(batch) >= auditBatchSize {
+				batch = writeAuditEvents(batch)
+			}
+
+		case <-ticker.C:
+			batch = writeAuditEvents(batch)
+
+		case <-auditWriterCtx.Done():
+			// Drain the audit queue before shutting down.
+		drain:
+			for {
+				select {
+				case event := <-auditEvents:
+					batch = append(batch, event)
+					if len(batch) >= auditBatchSize {
+						batch = writeAuditEvents(batch)
+					}
+				default:
+					break drain
+				}
+			}
+			writeAuditEvents(batch)
+			fsLogger.Info("Audit writer has received shutdown signal and is stopping!")
+			auditWriterDone <- true
+			return
+		}
+	}
+}
+
+// Write the specified batch of audit events to the database. Returns the
+// batch emptied, so that it can be reused.
+func writeAuditEvents(batch []AuditEvent) []AuditEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(), operationDbRecordAudit)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbRecordAudit)
+
+	count, err := gDbPool.CopyFrom(ctx, auditEventsTable, auditEventsColumns,
+		pgx.CopyFromSlice(len(batch), func(i int) ([]any, error) {
+			var fileID any
+			if batch[i].FileID != 0 {
+				fileID = int64(batch[i].FileID)
+			}
+			return []any{batch[i].OccurredAt, batch[i].TenantID,
+				batch[i].Actor, batch[i].Action, fileID, batch[i].RequestID,
+				batch[i].SourceIP, batch[i].Outcome}, nil
+		}))
+	if err != nil {
+		fsLogger.Error("Failed to write audit events to the database!",
+			zap.Int("Number of audit events:", len(batch)),
+			zap.Error(err),
+		)
+		metrics.MetricAuditEventWriteFailures.Add(float64(len(batch)))
+		return batch[:0]
+	}
+
+	metrics.MetricAuditEventsRecorded.Add(float64(count))
+	return batch[:0]
+}
+
+// ListAuditEvents - retrieve audit events matching the specified filter, most
+// recent first.
+func ListAuditEvents(ctx context.Context, requestID string,
+	filter *AuditEventFilter) ([]AuditEvent, error) {
+	var events []AuditEvent
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbListAudit)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbListAudit)
+
+	if filter.Limit <= 0 || filter.Limit > MaxAuditEventsPerQuery {
+		filter.Limit = MaxAuditEventsPerQuery
+	}
+
+	response, err := gDbPool.Query(ctx, queryAuditEvents, filter.TenantID,
+		int64(filter.FileID), filter.From, filter.To, filter.Limit)
+	if err != nil {
+		fsLogger.Error("Failed to get audit events from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	defer response.Close()
+
+	for response.Next() {
+		var event AuditEvent
+		err = response.Scan(&event.EventID, &event.OccurredAt, &event.TenantID,
+			&event.Actor, &event.Action, &event.FileID, &event.RequestID,
+			&event.SourceIP, &event.Outcome)
+		if err != nil {
+			fsLogger.Error("Failed to get audit events from the database!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
+				zap.Error(err),
+			)
+			return nil, ErrInternalError
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// Delete a batch of audit events recorded before the specified cutoff. Returns
+// the number of audit events deleted.
+func deleteExpiredAuditEvents(cutoff time.Time, batchSize int) (int64, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbDeleteExpiredAudit)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbDeleteExpiredAudit)
+
+	ct, err := gDbPool.Exec(ctx, queryDeleteExpiredAuditEvents, cutoff,
+		batchSize)
+	if err != nil {
+		fsLogger.Error("Failed to delete expired audit events from the database!",
+			zap.Error(err),
+		)
+		return 0, ErrInternalError
+	}
+	return ct.RowsAffected(), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// validate that every audit action fits the action column of the audit log
+func TestAuditActionLength(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "audit.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse the audit actions: %v", err)
+	}
+
+	count := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 ||
+			!strings.HasPrefix(spec.Names[0].Name, "AuditAction") {
+			return true
+		}
+		literal, ok := spec.Values[0].(*ast.BasicLit)
+		if !ok || literal.Kind != token.STRING {
+			t.Fatalf("Audit action %s is not a string literal",
+				spec.Names[0].Name)
+		}
+		action, _ := strconv.Unquote(literal.Value)
+		if action == "" || len(action) > auditActionLength {
+			t.Fatalf("Audit action %s does not fit the action column, expected at most %d characters, got: %q",
+				spec.Names[0].Name, auditActionLength, action)
+		}
+		count++
+		return true
+	})
+	if count == 0 {
+		t.Fatalf("No audit actions found")
+	}
+}
@@ -4,21 +4,22 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/cache"
 	"github.com/HPInc/krypton-fs/service/common"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
 )
 
 // CreateFile new record allocating new ID
-func CreateFile(requestID string, request *common.CreateFileRequest) (*File, error) {
-	var newFile File
+func CreateFile(ctx context.Context, requestID string, request *common.CreateFileRequest) (*File, error) {
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(ctx, operationDbCreateFile)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbCreateFile)
@@ -31,31 +32,136 @@ func CreateFile(requestID string, request *common.CreateFileRequest) (*File, err
 		return nil, err
 	}
 
+	newFile, err := insertNewFile(ctx, tx, requestID, request)
+	if err != nil {
+		rollback(tx, ctx)
+		return nil, err
+	}
+
+	// Record the creation of the file in the usage rollup for the tenant.
+	usage := Usage{FilesCreated: 1}
+	err = recordUsage(ctx, tx, request.TenantID, request.DeviceID, usage)
+	if err != nil {
+		rollback(tx, ctx)
+		return nil, err
+	}
+	commit(tx, ctx)
+	reportUsage(usage)
+
+	// Add the file to the cache, replacing any negative cache entry, and
+	// invalidate the cached lists of files of the device.
+	cache.AddFile(ctx, requestID, newFile.FileID, newFile.UpdatedAt, newFile)
+	cache.InvalidateFileList(ctx, requestID, newFile.TenantID, newFile.DeviceID)
+
+	return &newFile, nil
+}
+
+// CreateFiles - create records for a batch of new files in a single
+// transaction, allocating new IDs. Either all of the files are created or
+// none of them are. The created files are returned in the order of the
+// requests.
+func CreateFiles(ctx context.Context, requestID string,
+	requests []common.CreateFileRequest) ([]File, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbCreateFiles)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbCreateFiles)
+
+	tx, err := gDbPool.Begin(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to acquire transaction to create files!",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	type device struct {
+		tenantID string
+		deviceID string
+	}
+	newFiles := make([]File, 0, len(requests))
+	filesCreated := make(map[device]int64)
+	for i := range requests {
+		newFile, err := insertNewFile(ctx, tx, requestID, &requests[i])
+		if err != nil {
+			rollback(tx, ctx)
+			return nil, err
+		}
+		newFiles = append(newFiles, newFile)
+		filesCreated[device{newFile.TenantID, newFile.DeviceID}]++
+	}
+
+	// Record the creation of the files in the usage rollups of the devices
+	// to which they belong.
+	var usage Usage
+	for d, count := range filesCreated {
+		err = recordUsage(ctx, tx, d.tenantID, d.deviceID,
+			Usage{FilesCreated: count})
+		if err != nil {
+			rollback(tx, ctx)
+			return nil, err
+		}
+		usage.FilesCreated += count
+	}
+	commit(tx, ctx)
+	reportUsage(usage)
+
+	// Add the files to the cache, replacing any negative cache entries, and
+	// invalidate the cached lists of files of the devices.
+	for _, newFile := range newFiles {
+		cache.AddFile(ctx, requestID, newFile.FileID, newFile.UpdatedAt, newFile)
+	}
+	for d := range filesCreated {
+		cache.InvalidateFileList(ctx, requestID, d.tenantID, d.deviceID)
+	}
+
+	return newFiles, nil
+}
+
+// Insert a record for a new file using the specified transaction. The caller
+// rolls back the transaction if the file could not be inserted.
+func insertNewFile(ctx context.Context, tx pgx.Tx, requestID string,
+	request *common.CreateFileRequest) (File, error) {
+	var newFile File
+
+	// Allocate the next version of the logical file identified by the tenant,
+	// device, namespace and name of the file.
+	var version int64
+	err := tx.QueryRow(ctx, queryAllocateFileVersion, request.TenantID,
+		request.DeviceID, request.Namespace, request.Name).Scan(&version)
+	if err != nil {
+		fsLogger.Error("Failed to allocate a version for the new file.",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return newFile, err
+	}
+
 	response := tx.QueryRow(ctx, queryInsertNewFile, request.TenantID, request.DeviceID, request.Name,
-		request.Checksum, request.Size, FileStatusNew, selectBucket())
+		request.Checksum, request.Size, FileStatusNew, selectBucket(), request.Namespace, version,
+		tracing.TraceParent(ctx))
 	err = response.Scan(&newFile.FileID, &newFile.TenantID, &newFile.DeviceID, &newFile.Name,
 		&newFile.Checksum, &newFile.Size, &newFile.Status, &newFile.CreatedAt, &newFile.UpdatedAt,
-		&newFile.BucketName)
+		&newFile.BucketName, &newFile.Namespace, &newFile.Version)
 	if err != nil {
-		rollback(tx, ctx)
 		if isDuplicateKeyError(err) {
 			fsLogger.Error("Failed to create a new file. Duplicate exists!",
 				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
 				zap.Error(err),
 			)
-			return nil, ErrDuplicateEntry
+			return newFile, ErrDuplicateEntry
 		}
 
 		fsLogger.Error("Failed to create a new file.",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
 			zap.Error(err),
 		)
-		return nil, err
+		return newFile, err
 	}
-	commit(tx, ctx)
-
-	// Add the file to the cache on a separate goroutine.
-	go cache.AddFile(requestID, newFile.FileID, newFile)
-
-	return &newFile, nil
+	return newFile, nil
 }
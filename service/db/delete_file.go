@@ -4,34 +4,38 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
 
 	"github.com/HPInc/krypton-fs/service/cache"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
 )
 
 // DeleteFile deletes the specified file from the files table. It creates an
-// entry for the file in the tombstoned_files table.
-func DeleteFile(requestID string, id string) error {
+// entry for the file in the tombstoned_files table. The identifiers of the
+// deleted file and its tenant and device are returned.
+func DeleteFile(ctx context.Context, requestID string, id string) (*File, error) {
 	// Check the parameters
 	fileID, err := strconv.ParseUint(id, 10, 64)
 	if err != nil {
 		fsLogger.Error("Failed to parse the specified file ID",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
 			zap.Error(err),
 		)
-		return err
+		return nil, err
 	}
 
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(ctx, operationDbDeleteFile)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbDeleteFile)
@@ -41,79 +45,261 @@ func DeleteFile(requestID string, id string) error {
 		fsLogger.Error("Failed to acquire transaction to delete file!",
 			zap.Error(err),
 		)
-		return err
+		return nil, err
 	}
 
-	_, err = tx.Exec(ctx, deleteFileByID, fileID)
+	var deletedFile File
+	err = tx.QueryRow(ctx, deleteFileByID, fileID).Scan(&deletedFile.FileID,
+		&deletedFile.TenantID, &deletedFile.DeviceID)
 	if err != nil {
 		rollback(tx, ctx)
 
 		fsLogger.Error("Failed to delete the requested file from the database!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
 			zap.Uint64("File ID: ", fileID),
 			zap.Error(err),
 		)
 		if errors.Is(err, pgx.ErrNoRows) {
+			// The file is either missing or under legal hold.
+			var exists bool
+			err = gDbPool.QueryRow(ctx, queryFileExists, fileID).Scan(&exists)
+			switch {
+			case err != nil:
+				fsLogger.Error("Failed to check whether the requested file exists!",
+					zap.String("Request ID:", requestID),
+					tracing.TraceID(ctx),
+					zap.Uint64("File ID: ", fileID),
+					zap.Error(err),
+				)
+				metrics.MetricDatabaseDeleteFileFailures.Inc()
+				return nil, ErrInternalError
+
+			case exists:
+				fsLogger.Error("The requested file is under legal hold and cannot be deleted!",
+					zap.String("Request ID:", requestID),
+					tracing.TraceID(ctx),
+					zap.Uint64("File ID: ", fileID),
+				)
+				reportHeldFiles(heldFilesDelete, 1)
+				return nil, ErrLegalHold
+			}
+
 			fsLogger.Error("No matching file was found in the database!",
 				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
 				zap.Uint64("File ID: ", fileID),
 			)
 			metrics.MetricDatabaseFileNotFoundErrors.Inc()
-			return ErrNotFound
+			return nil, ErrNotFound
 		}
 
 		metrics.MetricDatabaseDeleteFileFailures.Inc()
-		return ErrInternalError
+		return nil, ErrInternalError
 	}
 	commit(tx, ctx)
 	metrics.MetricDatabaseFilesDeleted.Inc()
 
-	// Remove the device from the cache on a separate goroutine.
-	go cache.RemoveFile(requestID, fileID)
+	// Remove the file from the cache and invalidate the cached lists of files
+	// of the device before returning, so that the deletion is visible to
+	// subsequent reads.
+	cache.RemoveFile(ctx, requestID, fileID)
+	cache.InvalidateFileList(ctx, requestID, deletedFile.TenantID,
+		deletedFile.DeviceID)
 
-	return nil
+	return &deletedFile, nil
 }
 
-// Delete candidate expired files from the files table.
-func deleteExpiredFiles() error {
+// Delete a batch of expired files, created before the specified threshold,
+// from the files table. Returns the number of files deleted.
+func deleteExpiredFiles(threshold time.Time, batchSize int) (int64, error) {
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(context.Background(), operationDbDeleteExpiredFiles)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbDeleteExpiredFiles)
 
 	tx, err := gDbPool.Begin(ctx)
 	if err != nil {
-		fsLogger.Error("Failed to acquire transaction to add bucket!",
+		fsLogger.Error("Failed to acquire transaction to delete expired files!",
 			zap.Error(err),
 		)
-		return err
+		return 0, err
 	}
 
-	ct, err := tx.Exec(ctx, queryDeleteExpiredFiles,
-		time.Now().AddDate(0, 0, scavengeExpiredFilesThreshold))
+	files, err := queryScavengedFiles(ctx, tx, queryDeleteExpiredFiles,
+		threshold, batchSize)
 	if err != nil {
 		rollback(tx, ctx)
 
 		fsLogger.Error("Failed to delete the expired files from the database!",
 			zap.Error(err),
 		)
-		if errors.Is(err, pgx.ErrNoRows) {
-			fsLogger.Error("No matching files were found in the database!")
-			metrics.MetricDatabaseFileNotFoundErrors.Inc()
-			return ErrNotFound
-		}
-
-		metrics.MetricScavengeExpiredFileFailures.Inc()
-		return ErrInternalError
+		return 0, ErrInternalError
 	}
 	commit(tx, ctx)
+	recordDeletedFiles(files, AuditActorScavenger, AuditActionScavenge)
 
 	fsLogger.Info("Deleted expired files from the the database!",
-		zap.Int("Number of files deleted:", int(ct.RowsAffected())),
+		zap.Int("Number of files deleted:", len(files)),
 	)
-	metrics.MetricScavengeExpiredFiles.Add(float64(ct.RowsAffected()))
+	metrics.MetricScavengeExpiredFiles.Add(float64(len(files)))
+
+	return int64(len(files)), nil
+}
 
-	return nil
+// Delete a batch of uploaded versions of logical files, other than the
+// specified number of newest uploaded versions and the version each logical
+// file points at, from the files table. The deleted versions are tombstoned
+// and their objects are deleted from storage. Returns the number of files
+// deleted.
+func deleteOldFileVersions(retain int, batchSize int) (int64, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(), operationDbDeleteOldVersions)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbDeleteOldVersions)
+
+	tx, err := gDbPool.Begin(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to acquire transaction to delete old file versions!",
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	var files []File
+	rows, err := tx.Query(ctx, queryDeleteOldFileVersions, retain, batchSize)
+	if err == nil {
+		files, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (File,
+			error) {
+			var file File
+			err := row.Scan(&file.FileID, &file.TenantID, &file.DeviceID,
+				&file.BucketName)
+			return file, err
+		})
+	}
+	if err != nil {
+		rollback(tx, ctx)
+
+		fsLogger.Error("Failed to delete old file versions from the database!",
+			zap.Error(err),
+		)
+		metrics.MetricScavengeOldFileVersionFailures.Inc()
+		return 0, ErrInternalError
+	}
+	commit(tx, ctx)
+	recordDeletedFiles(files, AuditActorScavenger, AuditActionScavenge)
+
+	fsLogger.Info("Deleted old file versions from the the database!",
+		zap.Int("Number of files deleted:", len(files)),
+	)
+	metrics.MetricScavengeOldFileVersions.Add(float64(len(files)))
+
+	deleteFileObjects(files)
+	return int64(len(files)), nil
+}
+
+// Delete the objects stored for the specified deleted files from storage.
+// Failures are logged; the files remain recorded in the tombstoned_files
+// table.
+func deleteFileObjects(files []File) {
+	if storage.Provider == nil {
+		return
+	}
+
+	objectNames := make(map[string][]string)
+	for _, file := range files {
+		objectNames[file.BucketName] = append(objectNames[file.BucketName],
+			storage.GetObjectName(file.TenantID, file.DeviceID, file.FileID))
+	}
+
+	for bucketName, names := range objectNames {
+		for len(names) > 0 {
+			batch := names[:min(len(names), purgeObjectsBatchSize)]
+			names = names[len(batch):]
+
+			err := storage.Provider.DeleteObjects(context.Background(),
+				bucketName, batch)
+			if err != nil {
+				fsLogger.Error("Failed to delete the objects of old file versions!",
+					zap.String("Bucket name:", bucketName),
+					zap.Int("Number of objects:", len(batch)),
+					zap.Error(err),
+				)
+				metrics.MetricScavengeOldFileVersionFailures.Inc()
+			}
+		}
+	}
+}
+
+// Count the rows that would be deleted by the specified scavenger query, for
+// dry runs of the scavenger.
+func countScavengeableRows(query string, args ...any) (int64, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbCountScavengeable)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbCountScavengeable)
+
+	var count int64
+	err := gDbPool.QueryRow(ctx, query, args...).Scan(&count)
+	if err != nil {
+		fsLogger.Error("Failed to count the rows to be scavenged!",
+			zap.Error(err),
+		)
+		return 0, ErrInternalError
+	}
+	return count, nil
+}
+
+// Execute the specified scavenger query, which deletes files and returns the
+// identifiers of the deleted files and their tenants and devices.
+func queryScavengedFiles(ctx context.Context, tx pgx.Tx, query string,
+	args ...any) ([]File, error) {
+	var files []File
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var file File
+		if err = rows.Scan(&file.FileID, &file.TenantID, &file.DeviceID); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+// Record the deletion of the specified files by the scavenger or a purge job
+// in the file audit log, and remove them and the lists of files of their
+// devices from the cache.
+func recordDeletedFiles(files []File, actor string, action string) {
+	type device struct{ tenantID, deviceID string }
+	devices := make(map[device]bool)
+
+	for _, file := range files {
+		cache.RemoveFile(context.Background(), "", file.FileID)
+		devices[device{file.TenantID, file.DeviceID}] = true
+
+		RecordAuditEvent(AuditEvent{
+			TenantID: file.TenantID,
+			Actor:    actor,
+			Action:   action,
+			FileID:   file.FileID,
+			Outcome:  AuditOutcomeSuccess,
+		})
+	}
+
+	for d := range devices {
+		cache.InvalidateFileList(context.Background(), "", d.tenantID, d.deviceID)
+	}
 }
@@ -15,12 +15,15 @@ import (
 )
 
 var (
-	ErrNoBuckets      = errors.New("no buckets have configured for the service")
-	ErrDuplicateEntry = errors.New("a duplicate entry was found in the database")
-	ErrNotFound       = errors.New("the requested entry was not found in the database")
-	ErrNotAllowed     = errors.New("the requested operation is not allowed")
-	ErrInvalidRequest = errors.New("the request contained one or more invalid parameters")
-	ErrInternalError  = errors.New("an internal error occured while performing the database operation")
+	ErrNoBuckets            = errors.New("no buckets have configured for the service")
+	ErrDuplicateEntry       = errors.New("a duplicate entry was found in the database")
+	ErrNotFound             = errors.New("the requested entry was not found in the database")
+	ErrNotAllowed           = errors.New("the requested operation is not allowed")
+	ErrInvalidRequest       = errors.New("the request contained one or more invalid parameters")
+	ErrLegalHold            = errors.New("the file is under legal hold")
+	ErrUploadOffsetMismatch = errors.New("the offset does not match the offset of the upload")
+	ErrUploadClaimed        = errors.New("the upload is being appended to by another request")
+	ErrInternalError        = errors.New("an internal error occured while performing the database operation")
 )
 
 func isDuplicateKeyError(err error) bool {
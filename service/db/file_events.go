@@ -0,0 +1,231 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"go.uber.org/zap"
+)
+
+// Types of the events published for files.
+const (
+	// The file was created.
+	FileEventCreated = "created"
+
+	// The status of the file changed.
+	FileEventStatusChanged = "status_changed"
+
+	// The file was deleted.
+	FileEventDeleted = "deleted"
+)
+
+const (
+	// Notification channel on which file events are published by the files
+	// table triggers.
+	fileEventsChannel = "file_events"
+
+	// Number of file events that can be queued for each watcher. Events are
+	// dropped for watchers that do not keep up.
+	fileEventQueueSize = 256
+
+	// Interval after which the file event listener reconnects to the database
+	// if listening for file events fails.
+	fileEventListenerRetryInterval = time.Second * 5
+)
+
+// FileEvent represents the creation, a change of status or the deletion of a
+// file. Events are published by the database, so events for changes made by
+// any instance of the service are delivered to all instances.
+type FileEvent struct {
+	// The type of the event.
+	Type string `json:"type"`
+
+	// When the event occurred.
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// The file as of the event.
+	File File `json:"file"`
+}
+
+// Filter for watching file events. Empty fields are not filtered on.
+type FileEventFilter struct {
+	TenantID string
+	DeviceID string
+}
+
+type fileEventWatcher struct {
+	filter FileEventFilter
+	events chan FileEvent
+}
+
+var (
+	// Watchers of file events. Guarded by fileEventWatchersLock.
+	fileEventWatchersLock sync.Mutex
+	fileEventWatchers     = make(map[*fileEventWatcher]bool)
+
+	// The file event listener is started when the first watcher is added.
+	fileEventListenerCancel context.CancelFunc
+	fileEventListenerDone   chan bool
+)
+
+// WatchFileEvents - returns a channel on which the file events matching the
+// specified filter are delivered until the specified context is done, when
+// the channel is closed. Events are not replayed, and are dropped if the
+// caller does not keep up with them.
+func WatchFileEvents(ctx context.Context, filter FileEventFilter) <-chan FileEvent {
+	watcher := &fileEventWatcher{
+		filter: filter,
+		events: make(chan FileEvent, fileEventQueueSize),
+	}
+
+	fileEventWatchersLock.Lock()
+	if fileEventListenerCancel == nil {
+		startFileEventListener()
+	}
+	fileEventWatchers[watcher] = true
+	metrics.MetricFileEventWatchers.Inc()
+	fileEventWatchersLock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		removeFileEventWatcher(watcher)
+	}()
+	return watcher.events
+}
+
+// Remove the specified watcher and close its event channel, unless it has
+// already been removed.
+func removeFileEventWatcher(watcher *fileEventWatcher) {
+	fileEventWatchersLock.Lock()
+	defer fileEventWatchersLock.Unlock()
+
+	if fileEventWatchers[watcher] {
+		delete(fileEventWatchers, watcher)
+		close(watcher.events)
+		metrics.MetricFileEventWatchers.Dec()
+	}
+}
+
+// Start the goroutine that listens for file events published by the database
+// and delivers them to watchers. Must be called with the watchers lock held.
+func startFileEventListener() {
+	var ctx context.Context
+	ctx, fileEventListenerCancel = context.WithCancel(context.Background())
+	fileEventListenerDone = make(chan bool, 1)
+
+	go runFileEventListener(ctx, fileEventListenerDone)
+}
+
+// Stop the file event listener and close the event channels of all watchers.
+func stopFileEventListener() {
+	fileEventWatchersLock.Lock()
+	cancel, done := fileEventListenerCancel, fileEventListenerDone
+	fileEventWatchersLock.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+
+	fileEventWatchersLock.Lock()
+	defer fileEventWatchersLock.Unlock()
+	for watcher := range fileEventWatchers {
+		delete(fileEventWatchers, watcher)
+		close(watcher.events)
+		metrics.MetricFileEventWatchers.Dec()
+	}
+	fileEventListenerCancel = nil
+}
+
+func runFileEventListener(ctx context.Context, done chan bool) {
+	for {
+		err := listenForFileEvents(ctx)
+		if ctx.Err() != nil {
+			fsLogger.Info("File event listener has received shutdown signal and is stopping!")
+			done <- true
+			return
+		}
+
+		// Events published while the listener reconnects are not delivered.
+		fsLogger.Error("Failed to listen for file events. Retrying ...",
+			zap.Duration("Retry interval: ", fileEventListenerRetryInterval),
+			zap.Error(err),
+		)
+		metrics.MetricFileEventListenerErrors.Inc()
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(fileEventListenerRetryInterval):
+		}
+	}
+}
+
+// Listen for file events on a dedicated connection to the database and
+// dispatch them to watchers, until listening fails or the specified context
+// is done.
+func listenForFileEvents(ctx context.Context) error {
+	poolConn, err := gDbPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection is taken out of the pool, since it remains subscribed to
+	// the notification channel.
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+fileEventsChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event FileEvent
+		err = json.Unmarshal([]byte(notification.Payload), &event)
+		if err != nil {
+			fsLogger.Error("Failed to parse a file event!",
+				zap.String("Payload: ", notification.Payload),
+				zap.Error(err),
+			)
+			metrics.MetricFileEventListenerErrors.Inc()
+			continue
+		}
+		dispatchFileEvent(&event)
+	}
+}
+
+// Deliver the specified event to the watchers whose filter it matches.
+func dispatchFileEvent(event *FileEvent) {
+	fileEventWatchersLock.Lock()
+	defer fileEventWatchersLock.Unlock()
+
+	for watcher := range fileEventWatchers {
+		if !watcher.filter.matches(event) {
+			continue
+		}
+		select {
+		case watcher.events <- *event:
+		default:
+			metrics.MetricFileEventsDropped.Inc()
+		}
+	}
+}
+
+func (f *FileEventFilter) matches(event *FileEvent) bool {
+	if f.TenantID != "" && f.TenantID != event.File.TenantID {
+		return false
+	}
+	return f.DeviceID == "" || f.DeviceID == event.File.DeviceID
+}
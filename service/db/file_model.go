@@ -17,6 +17,28 @@ const (
 
 	// File is quarantined
 	FileStatusQuarantined = "quarantined"
+
+	// File has been uploaded to storage and is waiting for a verdict from the
+	// malware scanning pipeline.
+	FileStatusScanPending = "scan_pending"
+)
+
+// Possible values for verdicts recorded in the file scans table.
+const (
+	// The scanner found the file to be clean.
+	ScanVerdictClean = "clean"
+
+	// The scanner found the file to be infected and quarantined it.
+	ScanVerdictQuarantined = "quarantined"
+
+	// A rescan of the file was requested.
+	ScanVerdictRescanRequested = "rescan"
+
+	// A quarantined file was released by an administrator.
+	ScanVerdictReleased = "released"
+
+	// The quarantine of a file was confirmed by an administrator.
+	ScanVerdictConfirmed = "confirmed"
 )
 
 // S3 bucket file object handle with an access URL address.
@@ -38,6 +60,14 @@ type File struct {
 	// Name of the file.
 	Name string `json:"name,omitempty"`
 
+	// Optional namespace that, together with the tenant, device and name,
+	// identifies the logical file to which this file is a version.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Version of the logical file. Versions are allocated in increasing
+	// order each time a file is created with the same logical key.
+	Version int64 `json:"version"`
+
 	// Checksum of the file.
 	Checksum string `json:"checksum,omitempty"`
 
@@ -53,3 +83,25 @@ type File struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// Represents a verdict recorded for a file by a malware scanner or an
+// administrative action on the scan status of the file.
+type FileScan struct {
+	// The unique identifier assigned to the scan record.
+	ScanID uint64 `json:"scan_id"`
+
+	// The file to which the verdict applies.
+	FileID uint64 `json:"file_id"`
+
+	// Name of the scanner (or the administrator) that reported the verdict.
+	Scanner string `json:"scanner"`
+
+	// The verdict reported for the file.
+	Verdict string `json:"verdict"`
+
+	// Optional reason provided for the verdict.
+	Reason string `json:"reason,omitempty"`
+
+	// When the verdict was recorded.
+	ScannedAt time.Time `json:"scanned_at"`
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// Database operations.
+	operationDbCreateFileUpload = "CreateFileUpload"
+	operationDbGetFileUpload    = "GetFileUpload"
+	operationDbClaimFileUpload  = "ClaimFileUpload"
+	operationDbUpdateFileUpload = "UpdateFileUpload"
+	operationDbDeleteFileUpload = "DeleteFileUpload"
+)
+
+// Represents a resumable upload of a file, which is stored as a multipart
+// upload with a part for each chunk received.
+type FileUpload struct {
+	// The file being uploaded.
+	FileID uint64
+
+	// Identifier of the multipart upload in storage.
+	UploadID string
+
+	// Number of bytes of the file received so far.
+	Offset int64
+
+	// ETags of the parts uploaded so far, in order.
+	PartETags []string
+
+	// Marshalled state of the MD5 digest of the bytes received so far, if
+	// any have been received.
+	ChecksumState []byte
+
+	// When the upload was started and last appended to.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CreateFileUpload records the start of a resumable upload of a file. If an
+// upload of the file already exists, the existing upload is returned and
+// created is false.
+func CreateFileUpload(ctx context.Context, requestID string, fileID uint64,
+	uploadID string) (upload *FileUpload, created bool, err error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbCreateFileUpload)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbCreateFileUpload)
+
+	upload, err = scanFileUpload(gDbPool.QueryRow(ctx, queryInsertFileUpload,
+		fileID, uploadID))
+	if err == nil {
+		return upload, true, nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// An upload of the file was already started.
+		upload, err = scanFileUpload(gDbPool.QueryRow(ctx, queryFileUploadByID,
+			fileID))
+		if err == nil {
+			return upload, false, nil
+		}
+	}
+
+	fsLogger.Error("Failed to record the file upload in the database!",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.Uint64("File ID:", fileID),
+		zap.Error(err),
+	)
+	return nil, false, ErrInternalError
+}
+
+// GetFileUpload returns the resumable upload of the specified file.
+func GetFileUpload(ctx context.Context, requestID string,
+	fileID uint64) (*FileUpload, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetFileUpload)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetFileUpload)
+
+	upload, err := scanFileUpload(gDbPool.QueryRow(ctx, queryFileUploadByID,
+		fileID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to get the file upload from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", fileID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return upload, nil
+}
+
+// ClaimFileUpload claims the resumable upload of the specified file for a
+// request appending to it at the specified offset, and returns the claim
+// token to be presented when the upload is updated. Claims held for longer
+// than the specified timeout lapse. Returns ErrUploadOffsetMismatch if the
+// upload has reached another offset, or ErrUploadClaimed if it is claimed by
+// another request.
+func ClaimFileUpload(ctx context.Context, requestID string, fileID uint64,
+	offset int64, timeout time.Duration) (int64, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbClaimFileUpload)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbClaimFileUpload)
+
+	var claimToken int64
+	err := gDbPool.QueryRow(ctx, queryClaimFileUpload, fileID, offset,
+		int(timeout/time.Second)).Scan(&claimToken)
+	if err == nil {
+		return claimToken, nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Report why the upload could not be claimed.
+		var upload *FileUpload
+		upload, err = scanFileUpload(gDbPool.QueryRow(ctx, queryFileUploadByID,
+			fileID))
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return 0, ErrNotFound
+		case err == nil && upload.Offset != offset:
+			return 0, ErrUploadOffsetMismatch
+		case err == nil:
+			return 0, ErrUploadClaimed
+		}
+	}
+
+	fsLogger.Error("Failed to claim the file upload!",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.Uint64("File ID:", fileID),
+		zap.Error(err),
+	)
+	return 0, ErrInternalError
+}
+
+// AppendFileUploadPart records a part appended to the resumable upload of the
+// specified file by the request holding the specified claim, along with the
+// offset and checksum state reached by the upload, and releases the claim.
+// Returns ErrUploadClaimed if the claim has lapsed and the upload has been
+// claimed by another request.
+func AppendFileUploadPart(ctx context.Context, requestID string,
+	fileID uint64, claimToken int64, etag string, offset int64,
+	checksumState []byte) error {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbUpdateFileUpload)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbUpdateFileUpload)
+
+	result, err := gDbPool.Exec(ctx, queryAppendFileUploadPart, fileID,
+		claimToken, offset, etag, checksumState)
+	if err != nil {
+		fsLogger.Error("Failed to record the part appended to the file upload!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", fileID),
+			zap.Error(err),
+		)
+		return ErrInternalError
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUploadClaimed
+	}
+	return nil
+}
+
+// ReleaseFileUpload releases the claim on the resumable upload of the
+// specified file held by the request with the specified claim token, if it
+// has not lapsed.
+func ReleaseFileUpload(ctx context.Context, requestID string, fileID uint64,
+	claimToken int64) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbUpdateFileUpload)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbUpdateFileUpload)
+
+	_, err := gDbPool.Exec(ctx, queryReleaseFileUpload, fileID, claimToken)
+	if err != nil {
+		fsLogger.Error("Failed to release the claim on the file upload!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", fileID),
+			zap.Error(err),
+		)
+	}
+}
+
+// DeleteFileUpload deletes the resumable upload of the specified file once it
+// has been completed or abandoned, and returns the deleted upload.
+func DeleteFileUpload(ctx context.Context, requestID string,
+	fileID uint64) (*FileUpload, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbDeleteFileUpload)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbDeleteFileUpload)
+
+	upload, err := scanFileUpload(gDbPool.QueryRow(ctx, queryDeleteFileUpload,
+		fileID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to delete the file upload from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", fileID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return upload, nil
+}
+
+func scanFileUpload(row pgx.Row) (*FileUpload, error) {
+	var upload FileUpload
+	err := row.Scan(&upload.FileID, &upload.UploadID, &upload.Offset,
+		&upload.PartETags, &upload.ChecksumState, &upload.CreatedAt,
+		&upload.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
@@ -4,19 +4,19 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/metrics"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
 )
 
 func (b *Bucket) GetBucket(bucketName string) error {
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(context.Background(), operationDbGetBucket)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbGetBucket)
@@ -4,21 +4,35 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/cache"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
+	"golang.org/x/sync/singleflight"
 )
 
+const (
+	// Keys identifying lookups that are shared by concurrent callers.
+	fileLookupKey     = "file:%d"
+	fileListLookupKey = "file_list:%s:%s:%d"
+)
+
+// Coalesces concurrent database lookups of the same file or list of files
+// after they were not found in the cache.
+var lookupGroup singleflight.Group
+
 // GetFile - retrieve information about the file corresponding to the specified
 // file ID.
-func GetFile(requestID string, id string) (*File, error) {
+func GetFile(ctx context.Context, requestID string, id string) (*File, error) {
 	var foundFile File
 
 	// Check the parameters
@@ -26,71 +40,215 @@ func GetFile(requestID string, id string) (*File, error) {
 	if err != nil {
 		fsLogger.Error("Failed to parse the specified file ID",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
 			zap.Error(err),
 		)
 		return nil, err
 	}
 
 	// Find the file corresponding to the specified file ID in the files cache.
-	cacheEntry, err := cache.GetFile(requestID, fileID)
+	cacheEntry, err := cache.GetFile(ctx, requestID, fileID)
+	if err == cache.ErrCacheMissing {
+		fsLogger.Debug("GetFile - negative cache hit!")
+		metrics.MetricDatabaseFileNotFoundErrors.Inc()
+		return nil, ErrNotFound
+	}
 	if err == nil {
 		fsLogger.Debug("GetFile - cache hit!")
 		err = json.Unmarshal([]byte(cacheEntry), &foundFile)
 		if err != nil {
 			fsLogger.Error("Failed to unmarshal file from cache",
 				zap.String("Request ID: ", requestID),
+				tracing.TraceID(ctx),
 				zap.String("File ID: ", id),
 			)
 		}
 	}
 
 	// File was not found in the cache. Check to see if it is available in
-	// the database.
+	// the database. Concurrent lookups of the same file share a single
+	// database query.
+	if err != nil {
+		result, err, shared := lookupGroup.Do(fmt.Sprintf(fileLookupKey, fileID),
+			func() (interface{}, error) {
+				return getFileFromDatabase(ctx, requestID, fileID)
+			})
+		if shared {
+			metrics.MetricCacheCoalescedMisses.WithLabelValues(cache.TypeFile).Inc()
+		}
+		if err != nil {
+			return nil, err
+		}
+		foundFile = result.(File)
+	}
+
+	return &foundFile, nil
+}
+
+// Retrieve the file corresponding to the specified file ID from the database
+// and add it to the cache. Files that do not exist are cached as missing.
+func getFileFromDatabase(ctx context.Context, requestID string,
+	fileID uint64) (File, error) {
+	var foundFile File
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetFile)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetFile)
+
+	response := gDbPool.QueryRow(ctx, queryFileByID, fileID)
+	err := response.Scan(&foundFile.FileID, &foundFile.TenantID, &foundFile.DeviceID,
+		&foundFile.Name, &foundFile.Checksum, &foundFile.Size, &foundFile.Status,
+		&foundFile.CreatedAt, &foundFile.UpdatedAt, &foundFile.BucketName,
+		&foundFile.Namespace, &foundFile.Version)
 	if err != nil {
-		start := time.Now()
+		fsLogger.Error("Failed to find the specified file in the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID: ", fileID),
+			zap.Error(err),
+		)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			metrics.MetricDatabaseFileNotFoundErrors.Inc()
+
+			// Cache the missing file on a separate goroutine.
+			go cache.AddMissingFile(ctx, requestID, fileID)
+			return foundFile, ErrNotFound
+		}
+
+		metrics.MetricDatabaseGetFileFailures.Inc()
+		return foundFile, ErrInternalError
+	}
+
+	metrics.MetricDatabaseFilesRetrieved.Inc()
+
+	// Add the file to the cache on a separate goroutine. The file is not
+	// cached if it was changed after it was read.
+	go cache.AddFile(ctx, requestID, foundFile.FileID, foundFile.UpdatedAt,
+		foundFile)
+
+	return foundFile, nil
+}
+
+// GetFiles - retrieve information about the files corresponding to the
+// specified file IDs with a single database query, by file ID. Files that do
+// not exist are not included.
+func GetFiles(ctx context.Context, requestID string,
+	fileIDs []uint64) (map[uint64]File, error) {
+	foundFiles := make(map[uint64]File, len(fileIDs))
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetFiles)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetFiles)
 
-		ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
-		defer cancelFunc()
-		defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
-			operationDbGetFile)
+	response, err := gDbPool.Query(ctx, queryFilesByIDs, fileIDs)
+	if err != nil {
+		fsLogger.Error("Failed to get the specified files from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		metrics.MetricDatabaseGetFileFailures.Inc()
+		return nil, ErrInternalError
+	}
+	defer response.Close()
 
-		response := gDbPool.QueryRow(ctx, queryFileByID, fileID)
-		err = response.Scan(&foundFile.FileID, &foundFile.TenantID, &foundFile.DeviceID,
-			&foundFile.Name, &foundFile.Checksum, &foundFile.Size, &foundFile.Status,
-			&foundFile.CreatedAt, &foundFile.UpdatedAt, &foundFile.BucketName)
+	for response.Next() {
+		var foundFile File
+		err = response.Scan(&foundFile.FileID, &foundFile.TenantID,
+			&foundFile.DeviceID, &foundFile.Name, &foundFile.Checksum,
+			&foundFile.Size, &foundFile.Status, &foundFile.CreatedAt,
+			&foundFile.UpdatedAt, &foundFile.BucketName, &foundFile.Namespace,
+			&foundFile.Version)
 		if err != nil {
-			fsLogger.Error("Failed to find the specified file in the database!",
+			fsLogger.Error("Failed to get the specified files from the database!",
 				zap.String("Request ID:", requestID),
-				zap.Uint64("File ID: ", fileID),
+				tracing.TraceID(ctx),
 				zap.Error(err),
 			)
-
-			if errors.Is(err, pgx.ErrNoRows) {
-				metrics.MetricDatabaseFileNotFoundErrors.Inc()
-				return nil, ErrNotFound
-			}
-
 			metrics.MetricDatabaseGetFileFailures.Inc()
 			return nil, ErrInternalError
 		}
+		foundFiles[foundFile.FileID] = foundFile
+	}
 
-		metrics.MetricDatabaseFilesRetrieved.Inc()
-
-		// Add the file to the cache on a separate goroutine.
-		go cache.AddFile(requestID, foundFile.FileID, foundFile)
+	if response.Err() != nil {
+		fsLogger.Error("Failed to get the specified files from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(response.Err()),
+		)
+		metrics.MetricDatabaseGetFileFailures.Inc()
+		return nil, ErrInternalError
 	}
 
-	return &foundFile, nil
+	metrics.MetricDatabaseFilesRetrieved.Inc()
+	return foundFiles, nil
 }
 
 // ListFilesForDevice - list all files belonging to a specific device within
 // the specified tenant.
-func ListFilesForDevice(tenantID, deviceID string) ([]File, int64, error) {
+func ListFilesForDevice(ctx context.Context, requestID, tenantID,
+	deviceID string) ([]File, int64, error) {
+	// Find the list of files cached for the current generation of the device.
+	generation, err := cache.GetDeviceGeneration(ctx, requestID, tenantID,
+		deviceID)
+	cacheable := (err == nil)
+	if cacheable {
+		cacheEntry, err := cache.GetFileList(ctx, requestID, tenantID, deviceID,
+			generation)
+		if err == nil {
+			var fas []File
+			err = json.Unmarshal(cacheEntry, &fas)
+			if err == nil {
+				fsLogger.Debug("ListFilesForDevice - cache hit!")
+				return fas, int64(len(fas)), nil
+			}
+
+			fsLogger.Error("Failed to unmarshal file list from cache",
+				zap.String("Request ID: ", requestID),
+				tracing.TraceID(ctx),
+				zap.String("Device ID: ", deviceID),
+			)
+		}
+	}
+
+	// The list was not found in the cache. Read it from the database.
+	// Concurrent lookups of the same list share a single database query.
+	result, err, shared := lookupGroup.Do(fmt.Sprintf(fileListLookupKey,
+		tenantID, deviceID, generation), func() (interface{}, error) {
+		fas, err := listFilesFromDatabase(ctx, tenantID, deviceID)
+		if err == nil && cacheable {
+			// Add the list to the cache on a separate goroutine.
+			go cache.AddFileList(ctx, requestID, tenantID, deviceID,
+				generation, fas)
+		}
+		return fas, err
+	})
+	if shared {
+		metrics.MetricCacheCoalescedMisses.WithLabelValues(cache.TypeFileList).Inc()
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The list is shared with concurrent callers, each of whom gets a copy.
+	fas := slices.Clone(result.([]File))
+	return fas, int64(len(fas)), nil
+}
+
+// Read the list of files belonging to a specific device from the database.
+func listFilesFromDatabase(ctx context.Context, tenantID,
+	deviceID string) ([]File, error) {
 	// Read the entity by filter on non-key attributes
 	var fas []File
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(ctx, operationDbListFiles)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbListFiles)
@@ -101,7 +259,7 @@ func ListFilesForDevice(tenantID, deviceID string) ([]File, int64, error) {
 		fsLogger.Error("Failed to get a list of files from the database!",
 			zap.Error(err),
 		)
-		return nil, 0, err
+		return nil, err
 	}
 	defer response.Close()
 
@@ -110,12 +268,13 @@ func ListFilesForDevice(tenantID, deviceID string) ([]File, int64, error) {
 		err = response.Scan(&foundFile.FileID, &foundFile.TenantID,
 			&foundFile.DeviceID, &foundFile.Name, &foundFile.Checksum,
 			&foundFile.Size, &foundFile.Status, &foundFile.CreatedAt,
-			&foundFile.UpdatedAt, &foundFile.BucketName)
+			&foundFile.UpdatedAt, &foundFile.BucketName, &foundFile.Namespace,
+			&foundFile.Version)
 		if err != nil {
 			fsLogger.Error("Failed to get a list of files from the database!",
 				zap.Error(err),
 			)
-			return nil, 0, err
+			return nil, err
 		}
 		fas = append(fas, foundFile)
 	}
@@ -124,8 +283,54 @@ func ListFilesForDevice(tenantID, deviceID string) ([]File, int64, error) {
 		fsLogger.Error("Failed reading list of buckets from the database!",
 			zap.Error(response.Err()),
 		)
-		return nil, 0, response.Err()
+		return nil, response.Err()
 	}
 
-	return fas, int64(len(fas)), nil
+	return fas, nil
+}
+
+// GetLatestFileByName - retrieve information about the newest uploaded version
+// of the logical file identified by the specified tenant, device, namespace
+// and name.
+func GetLatestFileByName(ctx context.Context, requestID, tenantID, deviceID, namespace,
+	name string) (*File, error) {
+	var foundFile File
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetFileByName)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetFileByName)
+
+	response := gDbPool.QueryRow(ctx, queryLatestFileByName, tenantID, deviceID,
+		namespace, name, FileStatusUploaded)
+	err := response.Scan(&foundFile.FileID, &foundFile.TenantID, &foundFile.DeviceID,
+		&foundFile.Name, &foundFile.Checksum, &foundFile.Size, &foundFile.Status,
+		&foundFile.CreatedAt, &foundFile.UpdatedAt, &foundFile.BucketName,
+		&foundFile.Namespace, &foundFile.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			fsLogger.Debug("No uploaded version of the file was found in the database!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
+				zap.String("File name: ", name),
+				zap.String("Namespace: ", namespace),
+			)
+			metrics.MetricDatabaseFileNotFoundErrors.Inc()
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to find the latest version of the file in the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.String("File name: ", name),
+			zap.String("Namespace: ", namespace),
+			zap.Error(err),
+		)
+		metrics.MetricDatabaseGetFileFailures.Inc()
+		return nil, ErrInternalError
+	}
+
+	metrics.MetricDatabaseFilesRetrieved.Inc()
+	return &foundFile, nil
 }
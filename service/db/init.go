@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	cfg "github.com/aws/aws-sdk-go-v2/config"
@@ -28,7 +29,7 @@ const (
 	// Maximum number of connection retries using GORM.
 	maxDbConnectionRetries = 3
 
-	// Maximum number of retries to acquire the leader lock.
+	// Maximum number of retries to acquire the leader lease.
 	maxDbAcquireLeaderLockRetries = 3
 
 	// Database connection retry interval
@@ -43,16 +44,28 @@ const (
 
 	// Database operations.
 	operationDbCreateFile           = "CreateFile"
+	operationDbCreateFiles          = "CreateFiles"
 	operationDbGetFile              = "GetFile"
+	operationDbGetFiles             = "GetFiles"
+	operationDbGetFileByName        = "GetFileByName"
 	operationDbDeleteFile           = "DeleteFile"
 	operationDbUpdateFile           = "UpdateFile"
+	operationDbUpdateFileScan       = "UpdateFileScanStatus"
+	operationDbListFileScans        = "ListFileScans"
 	operationDbListFiles            = "ListFiles"
 	operationDbDeleteExpiredFiles   = "DeleteExpiredFiles"
+	operationDbDeleteOldVersions    = "DeleteOldFileVersions"
 	operationDbAddBucket            = "AddBucket"
 	operationDbGetBucket            = "GetBucket"
 	operationDbListBuckets          = "ListBuckets"
 	operationDbUpdateBucket         = "UpdateBucket"
 	operationDbDeleteTombstonedFile = "DeleteTombstonedFile"
+	operationDbRecordUsage          = "RecordUsage"
+	operationDbGetUsage             = "GetUsage"
+	operationDbRecordAudit          = "RecordAuditEvents"
+	operationDbListAudit            = "ListAuditEvents"
+	operationDbDeleteExpiredAudit   = "DeleteExpiredAuditEvents"
+	operationDbCountScavengeable    = "CountScavengeableRows"
 
 	// The scavenger will delete files older than these many days (also called
 	// expired files).
@@ -68,12 +81,17 @@ var (
 
 	// Connection string for the Postgres files database.
 	postgresDsn = "host=%s port=%d user=%s dbname=%s password=%s sslmode=%s"
+
+	// Number of versions of each logical file retained by the scavenger. It
+	// may be changed when the configuration is reloaded.
+	retainedFileVersions atomic.Int64
 )
 
 // Initialize the database and connection to the files cache.
 func Init(logger *zap.Logger, dbConfig *config.Database,
 	cacheConfig *config.Cache, bucketNames *[]string) error {
 	fsLogger = logger
+	retainedFileVersions.Store(int64(dbConfig.RetainedFileVersions))
 
 	// Connect to the database and initialize it.
 	err := loadFilesDatabase(dbConfig)
@@ -105,18 +123,42 @@ func Init(logger *zap.Logger, dbConfig *config.Database,
 		return err
 	}
 
-	// Start the periodic database scavenger routine.
-	if dbConfig.ScavengerEnabled {
-		go startScavenger()
-	}
+	// Start reporting the usage of the top tenants as metrics.
+	startUsageReporter()
 
-	return nil
+	// Start writing file audit events to the database.
+	auditRetentionDays.Store(int64(dbConfig.AuditRetentionDays))
+	startAuditWriter()
+	startPurgeJobs()
+	startLegalHoldMirrors()
+
+	// Initialize the database scavenger and, if enabled, start its periodic
+	// routine. It runs on the node elected leader.
+	initLeaderElection(dbConfig.LeaderLeaseSeconds)
+	return initScavenger(dbConfig)
+}
+
+// UpdateSettings applies the database settings and the bucket names that can
+// be changed without restarting the service. Newly configured buckets are
+// added to the database and used to store new files.
+func UpdateSettings(dbConfig *config.Database, bucketNames []string) error {
+	retainedFileVersions.Store(int64(dbConfig.RetainedFileVersions))
+	auditRetentionDays.Store(int64(dbConfig.AuditRetentionDays))
+
+	return updateBucketSelector(bucketNames)
 }
 
 // Shutdown - close the connection to the files database.
 func Shutdown() {
-	// Stop the scavenger goroutine.
+	// Stop the scavenger, purge job, legal hold mirroring and usage reporter
+	// goroutines and the file event listener. Then, stop the audit writer
+	// once it has written all queued audit events.
 	stopScavenger()
+	stopPurgeJobs()
+	stopLegalHoldMirrors()
+	stopUsageReporter()
+	stopFileEventListener()
+	stopAuditWriter()
 
 	// Shutdown the files database and close connections.
 	shutdownFilesDatabase()
@@ -128,6 +170,14 @@ func Shutdown() {
 	shutdownBucketSelector()
 }
 
+// Ping checks whether the files database can be reached.
+func Ping(ctx context.Context) error {
+	if gDbPool == nil {
+		return ErrInternalError
+	}
+	return gDbPool.Ping(ctx)
+}
+
 // Shutdown the connection to the files database.
 func shutdownFilesDatabase() {
 	gDbPool.Close()
@@ -154,6 +204,9 @@ func initPgxConfig(dbConfig *config.Database, connStr string) (*pgxpool.Config,
 	runtimeParams["statement_timeout"] =
 		strconv.Itoa(int(defaultStatementTimeout.Milliseconds()))
 
+	// Trace queries issued to the database.
+	pgxConfig.ConnConfig.Tracer = &queryTracer{}
+
 	return pgxConfig, nil
 }
 
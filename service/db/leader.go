@@ -0,0 +1,308 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/cache"
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"go.uber.org/zap"
+)
+
+// A single node of the service is elected leader to run the scavenger. The
+// leader holds a lease that is renewed in the background while it runs, and
+// is issued a fencing token that increases with every new leader. Before each
+// batch of work, the leader checks that the lease is still held with its
+// fencing token, so that a node that lost its lease (for example, because it
+// was paused for longer than the lease lifetime) stops before it races with
+// the next leader.
+//
+// The lease is held in the file cache. When caching is disabled, it is held
+// using a Postgres advisory lock instead.
+const (
+	// Name of the lease held by the leader.
+	leaderLeaseName = "scavenger"
+
+	// Default lifetime of the leader lease. The lease is renewed three times
+	// per lifetime.
+	defaultLeaderLeaseLifetime = (time.Second * 30)
+	leaderLeaseRenewalsPerLife = 3
+
+	// Length of the random suffix identifying this node among the nodes of
+	// the service running on the same host.
+	randomStringLength = 6
+	unknownHostname    = "unknown"
+
+	// Leader election backends.
+	LeaderBackendCache    = "cache"
+	LeaderBackendDatabase = "database"
+)
+
+var (
+	// Identity of this node when it holds the leader lease.
+	leaderNodeID string
+
+	// Lifetime of the leader lease.
+	leaderLeaseLifetime = defaultLeaderLeaseLifetime
+
+	// Errors
+	ErrNotLeader = errors.New("the leader lease is no longer held by this node")
+	ErrNoLeader  = errors.New("no node currently holds the leader lease")
+)
+
+// LeaderStatus describes the node currently holding the leader lease.
+type LeaderStatus struct {
+	// Identity of the node holding the lease.
+	Holder string
+
+	// Fencing token issued to the leader.
+	FencingToken int64
+
+	// Times at which the lease was acquired and last renewed.
+	AcquiredAt time.Time
+	RenewedAt  time.Time
+
+	// Time at which the lease expires unless renewed. Leases held using an
+	// advisory lock do not expire while the leader is connected.
+	ExpiresAt time.Time
+}
+
+// leaderBackend holds the leader lease.
+type leaderBackend interface {
+	// Returns the name of the backend.
+	name() string
+
+	// Attempts to acquire the lease for the specified node. Returns the
+	// fencing token issued to the node, or 0 if another node holds the lease.
+	acquire(ctx context.Context, holder string) (int64, error)
+
+	// Extends the lease held with the specified fencing token. Returns
+	// ErrNotLeader if the lease is no longer held.
+	renew(ctx context.Context, fencingToken int64) error
+
+	// Returns ErrNotLeader if the lease is no longer held with the specified
+	// fencing token.
+	check(ctx context.Context, fencingToken int64) error
+
+	// Releases the lease held with the specified fencing token.
+	release(ctx context.Context, fencingToken int64)
+
+	// Returns the current leader, or ErrNoLeader if there is none.
+	status(ctx context.Context) (*LeaderStatus, error)
+}
+
+// leadership is held by this node while it is the leader. Its context is
+// cancelled once the lease is lost or released.
+type leadership struct {
+	backend      leaderBackend
+	fencingToken int64
+
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	done       chan bool
+}
+
+func initLeaderElection(leaseSeconds int) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = unknownHostname
+	}
+	leaderNodeID = fmt.Sprintf("%s-%s", hostname,
+		common.NewRandomString(randomStringLength))
+
+	leaderLeaseLifetime = defaultLeaderLeaseLifetime
+	if leaseSeconds > 0 {
+		leaderLeaseLifetime = time.Duration(leaseSeconds) * time.Second
+	}
+}
+
+// Returns the backend holding the leader lease.
+func getLeaderBackend() leaderBackend {
+	if cache.IsEnabled() {
+		return cacheLeaderBackend{}
+	}
+	return &databaseLeader
+}
+
+// GetLeaderNodeID returns the identity of this node in leader elections.
+func GetLeaderNodeID() string {
+	return leaderNodeID
+}
+
+// GetLeaderBackend returns the name of the backend holding the leader lease.
+func GetLeaderBackend() string {
+	return getLeaderBackend().name()
+}
+
+// GetLeaderStatus returns the node currently holding the leader lease, or
+// ErrNoLeader if no node holds it.
+func GetLeaderStatus(ctx context.Context) (*LeaderStatus, error) {
+	return getLeaderBackend().status(ctx)
+}
+
+// Attempts to acquire the leader lease for this node. Returns nil if another
+// node holds the lease. The lease is renewed in the background until it is
+// released or the specified context is cancelled.
+func acquireLeadership(ctx context.Context) (*leadership, error) {
+	backend := getLeaderBackend()
+	fencingToken, err := backend.acquire(ctx, leaderNodeID)
+	if err != nil || fencingToken == 0 {
+		return nil, err
+	}
+
+	l := &leadership{
+		backend:      backend,
+		fencingToken: fencingToken,
+		done:         make(chan bool, 1),
+	}
+	l.ctx, l.cancelFunc = context.WithCancel(ctx)
+
+	fsLogger.Info("This node has been elected leader!",
+		zap.String("Node ID:", leaderNodeID),
+		zap.String("Backend:", backend.name()),
+		zap.Int64("Fencing token:", fencingToken),
+	)
+	metrics.MetricLeaderElections.Inc()
+	metrics.MetricIsLeader.Set(1)
+
+	go l.renewPeriodically()
+	return l, nil
+}
+
+// Renew the leader lease until it is released or lost. The lease is lost if
+// it is no longer held with the fencing token of this node, or if it cannot
+// be renewed before it expires.
+func (l *leadership) renewPeriodically() {
+	defer func() { l.done <- true }()
+
+	renewalInterval := leaderLeaseLifetime / leaderLeaseRenewalsPerLife
+	ticker := time.NewTicker(renewalInterval)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+
+		case <-ticker.C:
+			err := l.backend.renew(l.ctx, l.fencingToken)
+			if err == nil {
+				renewedAt = time.Now()
+				continue
+			}
+			if l.ctx.Err() != nil {
+				return
+			}
+
+			fsLogger.Error("Failed to renew the leader lease!",
+				zap.String("Node ID:", leaderNodeID),
+				zap.Int64("Fencing token:", l.fencingToken),
+				zap.Error(err),
+			)
+			// Give up before the lease expires if the next renewal would be
+			// too late.
+			if errors.Is(err, ErrNotLeader) ||
+				time.Since(renewedAt)+renewalInterval >= leaderLeaseLifetime {
+				fsLogger.Error("This node has lost the leader lease!",
+					zap.String("Node ID:", leaderNodeID),
+					zap.Int64("Fencing token:", l.fencingToken),
+				)
+				metrics.MetricLeaderLeasesLost.Inc()
+				l.cancelFunc()
+				return
+			}
+		}
+	}
+}
+
+// Returns ErrNotLeader if this node no longer holds the leader lease with its
+// fencing token. The leader calls this before each batch of work.
+func (l *leadership) check() error {
+	if l.ctx.Err() != nil {
+		return ErrNotLeader
+	}
+
+	err := l.backend.check(l.ctx, l.fencingToken)
+	if err != nil {
+		fsLogger.Error("The fencing token of this node was rejected!",
+			zap.String("Node ID:", leaderNodeID),
+			zap.Int64("Fencing token:", l.fencingToken),
+			zap.Error(err),
+		)
+		metrics.MetricLeaderFencingRejections.Inc()
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// Stop renewing the leader lease and release it.
+func (l *leadership) release() {
+	l.cancelFunc()
+	<-l.done
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(),
+		dbOperationTimeout)
+	defer cancelFunc()
+	l.backend.release(ctx, l.fencingToken)
+	metrics.MetricIsLeader.Set(0)
+}
+
+// cacheLeaderBackend holds the leader lease in the file cache.
+type cacheLeaderBackend struct{}
+
+func (b cacheLeaderBackend) name() string {
+	return LeaderBackendCache
+}
+
+func (b cacheLeaderBackend) acquire(ctx context.Context,
+	holder string) (int64, error) {
+	return cache.AcquireLeaderLease(ctx, holder, leaderLeaseLifetime)
+}
+
+func (b cacheLeaderBackend) renew(ctx context.Context,
+	fencingToken int64) error {
+	return toLeaderError(cache.RenewLeaderLease(ctx, fencingToken,
+		leaderLeaseLifetime))
+}
+
+func (b cacheLeaderBackend) check(ctx context.Context,
+	fencingToken int64) error {
+	return toLeaderError(cache.CheckLeaderLease(ctx, fencingToken))
+}
+
+func (b cacheLeaderBackend) release(ctx context.Context, fencingToken int64) {
+	cache.ReleaseLeaderLease(ctx, fencingToken)
+}
+
+func (b cacheLeaderBackend) status(ctx context.Context) (*LeaderStatus, error) {
+	lease, err := cache.GetLeaderLease(ctx)
+	if errors.Is(err, cache.ErrLeaderLeaseNotHeld) {
+		return nil, ErrNoLeader
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &LeaderStatus{
+		Holder:       lease.Holder,
+		FencingToken: lease.FencingToken,
+		AcquiredAt:   lease.AcquiredAt,
+		RenewedAt:    lease.RenewedAt,
+		ExpiresAt:    lease.ExpiresAt,
+	}, nil
+}
+
+// Translates errors returned by the cache for leases that are not held.
+func toLeaderError(err error) error {
+	if errors.Is(err, cache.ErrLeaderLeaseNotHeld) {
+		return ErrNotLeader
+	}
+	return err
+}
@@ -0,0 +1,472 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// Maximum length of the reason recorded for a legal hold.
+	MaxLegalHoldReasonLength = 256
+
+	// Maximum length of the actor recorded for a legal hold.
+	maxLegalHoldActorLength = 128
+
+	// Number of files whose objects are held or released at once when a hold
+	// on a tenant or device is mirrored to storage.
+	mirrorLegalHoldBatchSize = 100
+
+	// Operations that skip files under legal hold, used to report them.
+	heldFilesDelete    = "delete"
+	heldFilesScavenger = "scavenger"
+	heldFilesPurge     = "purge"
+
+	// Database operations.
+	operationDbPlaceLegalHold   = "PlaceLegalHold"
+	operationDbReleaseLegalHold = "ReleaseLegalHold"
+	operationDbGetLegalHold     = "GetLegalHold"
+	operationDbListLegalHolds   = "ListLegalHolds"
+	operationDbMirrorLegalHold  = "MirrorLegalHold"
+)
+
+var (
+	// Cancelled when the service shuts down, which interrupts the mirroring
+	// of holds on tenants and devices to storage.
+	holdMirrorsCtx        context.Context
+	holdMirrorsCancelFunc context.CancelFunc
+
+	// Tracks the holds being mirrored to storage.
+	holdMirrors sync.WaitGroup
+)
+
+// Represents a legal hold, which prevents files from being deleted. A hold
+// applies to all files of a tenant if no device is specified, to all files of
+// a device if no file is specified, or otherwise to a single file of the
+// device.
+type LegalHold struct {
+	// The unique identifier assigned to the hold.
+	HoldID uint64
+
+	// The tenant, device and file to which the hold applies.
+	TenantID string
+	DeviceID string
+	FileID   uint64
+
+	// Why the hold was placed, and who placed it.
+	Reason string
+	Actor  string
+
+	// When the hold was placed.
+	CreatedAt time.Time
+}
+
+// PlaceLegalHold records the specified legal hold. If a hold with the same
+// scope already exists, the existing hold is returned and created is false.
+func PlaceLegalHold(ctx context.Context, requestID string,
+	hold *LegalHold) (existing *LegalHold, created bool, err error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbPlaceLegalHold)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbPlaceLegalHold)
+
+	if len(hold.Actor) > maxLegalHoldActorLength {
+		hold.Actor = hold.Actor[:maxLegalHoldActorLength]
+	}
+	err = gDbPool.QueryRow(ctx, queryInsertLegalHold, hold.TenantID,
+		hold.DeviceID, hold.FileID, hold.Reason, hold.Actor).Scan(&hold.HoldID,
+		&hold.CreatedAt)
+	if err == nil {
+		fsLogger.Info("Placed a legal hold.",
+			zap.String("Request ID:", requestID),
+			zap.Uint64("Hold ID:", hold.HoldID),
+			zap.String("Tenant ID:", hold.TenantID),
+			zap.String("Device ID:", hold.DeviceID),
+			zap.Uint64("File ID:", hold.FileID),
+			zap.String("Actor:", hold.Actor),
+		)
+		metrics.MetricLegalHoldsPlaced.Inc()
+		return hold, true, nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The scope is already held.
+		existing, err = scanLegalHold(gDbPool.QueryRow(ctx,
+			queryLegalHoldByScope, hold.TenantID, hold.DeviceID, hold.FileID))
+		if err == nil {
+			return existing, false, nil
+		}
+	}
+
+	fsLogger.Error("Failed to record the legal hold in the database!",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.String("Tenant ID:", hold.TenantID),
+		zap.Error(err),
+	)
+	return nil, false, ErrInternalError
+}
+
+// ReleaseLegalHold deletes the legal hold with the specified identifier, and
+// returns the released hold. Files remain held by any other hold that applies
+// to them.
+func ReleaseLegalHold(ctx context.Context, requestID string,
+	holdID uint64) (*LegalHold, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbReleaseLegalHold)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbReleaseLegalHold)
+
+	hold, err := scanLegalHold(gDbPool.QueryRow(ctx, queryDeleteLegalHold,
+		holdID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to release the legal hold!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("Hold ID:", holdID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+
+	fsLogger.Info("Released a legal hold.",
+		zap.String("Request ID:", requestID),
+		zap.Uint64("Hold ID:", hold.HoldID),
+		zap.String("Tenant ID:", hold.TenantID),
+		zap.String("Device ID:", hold.DeviceID),
+		zap.Uint64("File ID:", hold.FileID),
+	)
+	metrics.MetricLegalHoldsReleased.Inc()
+	return hold, nil
+}
+
+// GetLegalHold returns the legal hold with the specified identifier.
+func GetLegalHold(ctx context.Context, requestID string,
+	holdID uint64) (*LegalHold, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetLegalHold)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetLegalHold)
+
+	hold, err := scanLegalHold(gDbPool.QueryRow(ctx, queryLegalHoldByID,
+		holdID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to get the legal hold from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("Hold ID:", holdID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return hold, nil
+}
+
+// ListLegalHolds returns the legal holds placed on the specified tenant, its
+// devices and its files. If a device is specified, only the holds that apply
+// to the files of the device are returned.
+func ListLegalHolds(ctx context.Context, requestID string, tenantID string,
+	deviceID string) ([]LegalHold, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbListLegalHolds)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbListLegalHolds)
+
+	holds, err := queryLegalHolds(ctx, tenantID, deviceID)
+	if err != nil {
+		fsLogger.Error("Failed to list the legal holds of the tenant!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID:", tenantID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return holds, nil
+}
+
+func queryLegalHolds(ctx context.Context, tenantID string,
+	deviceID string) ([]LegalHold, error) {
+	rows, err := gDbPool.Query(ctx, queryLegalHoldsOfTenant, tenantID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []LegalHold{}
+	for rows.Next() {
+		hold, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *hold)
+	}
+	return holds, rows.Err()
+}
+
+func scanLegalHold(row pgx.Row) (*LegalHold, error) {
+	var hold LegalHold
+	err := row.Scan(&hold.HoldID, &hold.TenantID, &hold.DeviceID, &hold.FileID,
+		&hold.Reason, &hold.Actor, &hold.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// IsFileHeld returns whether the specified file is under legal hold, by a hold
+// on the file, its device or its tenant.
+func IsFileHeld(ctx context.Context, requestID string,
+	fileID uint64) (bool, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetLegalHold)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetLegalHold)
+
+	var held bool
+	err := gDbPool.QueryRow(ctx, queryFileIsHeldByID, fileID).Scan(&held)
+	if err != nil {
+		fsLogger.Error("Failed to check whether the file is under legal hold!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", fileID),
+			zap.Error(err),
+		)
+		return false, ErrInternalError
+	}
+	return held, nil
+}
+
+// Report files skipped by the specified operation because they are under
+// legal hold.
+func reportHeldFiles(operation string, count int64) {
+	if count > 0 {
+		metrics.MetricHeldFilesSkipped.WithLabelValues(operation).Add(
+			float64(count))
+	}
+}
+
+// heldObjects describes the objects of a tenant or device that are under
+// legal hold, and must be kept when the objects of the tenant or device are
+// deleted.
+type heldObjects struct {
+	// All objects of the tenant or device are held.
+	all bool
+
+	// Prefixes of the devices whose objects are held.
+	prefixes []string
+
+	// Names of the held objects of individual files.
+	names map[string]bool
+}
+
+// Returns the objects of the specified tenant, or device if one is specified,
+// that are under legal hold.
+func getHeldObjects(tenantID string, deviceID string) (*heldObjects, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbListLegalHolds)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbListLegalHolds)
+
+	holds, err := queryLegalHolds(ctx, tenantID, deviceID)
+	if err != nil {
+		fsLogger.Error("Failed to list the legal holds of the tenant!",
+			zap.String("Tenant ID:", tenantID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+
+	held := heldObjects{names: make(map[string]bool)}
+	for _, hold := range holds {
+		switch {
+		case hold.DeviceID == "" || (hold.FileID == 0 && hold.DeviceID == deviceID):
+			held.all = true
+		case hold.FileID == 0:
+			held.prefixes = append(held.prefixes,
+				storage.GetObjectPrefix(hold.TenantID, hold.DeviceID))
+		default:
+			held.names[storage.GetObjectName(hold.TenantID, hold.DeviceID,
+				hold.FileID)] = true
+		}
+	}
+	return &held, nil
+}
+
+// Returns true if the object with the specified name is under legal hold.
+func (h *heldObjects) isHeld(objectName string) bool {
+	if h.all || h.names[objectName] {
+		return true
+	}
+	for _, prefix := range h.prefixes {
+		if strings.HasPrefix(objectName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func startLegalHoldMirrors() {
+	holdMirrorsCtx, holdMirrorsCancelFunc = context.WithCancel(
+		context.Background())
+}
+
+func stopLegalHoldMirrors() {
+	if holdMirrorsCancelFunc != nil {
+		holdMirrorsCancelFunc()
+		holdMirrors.Wait()
+	}
+}
+
+// MirrorObjectLegalHold places or removes the object lock legal hold of the
+// stored object of the specified file, if the bucket has object lock enabled.
+// Returns true if the hold was mirrored. Failures are logged; the holds
+// recorded in the database are authoritative.
+func MirrorObjectLegalHold(ctx context.Context, requestID string, file *File,
+	hold bool) bool {
+	if storage.Provider == nil {
+		return false
+	}
+
+	err := storage.Provider.SetObjectLegalHold(ctx, file.BucketName,
+		storage.GetObjectName(file.TenantID, file.DeviceID, file.FileID), hold)
+	if err != nil {
+		if !errors.Is(err, storage.ErrObjectLockNotSupported) {
+			fsLogger.Error("Failed to mirror the legal hold to the stored object!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
+				zap.Uint64("File ID:", file.FileID),
+				zap.Error(err),
+			)
+			metrics.MetricLegalHoldMirrorFailures.Inc()
+		}
+		return false
+	}
+	return true
+}
+
+// MirrorLegalHold mirrors the specified hold on a tenant or device to the
+// object lock legal holds of the stored objects of its files, in the
+// background. Objects stored later while the hold is in place are held when
+// they are stored. Once the hold has been released, the legal holds of the
+// objects of files that are no longer held by another hold are removed.
+func MirrorLegalHold(requestID string, hold *LegalHold, held bool) {
+	if storage.Provider == nil || holdMirrorsCtx == nil || hold.FileID != 0 {
+		return
+	}
+
+	holdMirrors.Add(1)
+	go func(hold LegalHold) {
+		defer holdMirrors.Done()
+		mirrorLegalHold(requestID, &hold, held)
+	}(*hold)
+}
+
+func mirrorLegalHold(requestID string, hold *LegalHold, held bool) {
+	var afterID uint64
+	var mirrored, skipped int
+	for {
+		if holdMirrorsCtx.Err() != nil {
+			fsLogger.Error("Mirroring of the legal hold to storage was interrupted!",
+				zap.String("Request ID:", requestID),
+				zap.Uint64("Hold ID:", hold.HoldID),
+				zap.Bool("Hold:", held),
+			)
+			return
+		}
+
+		files, err := getStoredFilesInHoldScope(hold, held, afterID)
+		if err != nil {
+			return
+		}
+		for i := range files {
+			if MirrorObjectLegalHold(holdMirrorsCtx, requestID, &files[i], held) {
+				mirrored++
+			} else {
+				skipped++
+			}
+		}
+
+		if len(files) < mirrorLegalHoldBatchSize {
+			fsLogger.Info("Mirrored the legal hold to the stored objects.",
+				zap.String("Request ID:", requestID),
+				zap.Uint64("Hold ID:", hold.HoldID),
+				zap.Bool("Hold:", held),
+				zap.Int("Objects mirrored:", mirrored),
+				zap.Int("Objects not mirrored:", skipped),
+			)
+			return
+		}
+		afterID = files[len(files)-1].FileID
+	}
+}
+
+// Returns a batch of the files of the tenant or device of the specified hold
+// whose objects have been stored, after the specified file. Once the hold has
+// been released, only the files that are no longer held are returned.
+func getStoredFilesInHoldScope(hold *LegalHold, held bool,
+	afterID uint64) ([]File, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(holdMirrorsCtx,
+		operationDbMirrorLegalHold)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbMirrorLegalHold)
+
+	rows, err := gDbPool.Query(ctx, queryStoredFilesInHoldScope,
+		hold.TenantID, hold.DeviceID, afterID, held, mirrorLegalHoldBatchSize)
+	if err == nil {
+		var files []File
+		files, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (File,
+			error) {
+			var file File
+			err := row.Scan(&file.FileID, &file.TenantID, &file.DeviceID,
+				&file.BucketName)
+			return file, err
+		})
+		if err == nil {
+			return files, nil
+		}
+	}
+
+	fsLogger.Error("Failed to get the files to which the legal hold applies!",
+		zap.Uint64("Hold ID:", hold.HoldID),
+		zap.String("Tenant ID:", hold.TenantID),
+		zap.String("Device ID:", hold.DeviceID),
+		zap.Error(err),
+	)
+	return nil, ErrInternalError
+}
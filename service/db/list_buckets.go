@@ -4,11 +4,11 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/metrics"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
 )
 
 func (b *Bucket) ListBuckets() (*[]Bucket, error) {
@@ -16,7 +16,7 @@ func (b *Bucket) ListBuckets() (*[]Bucket, error) {
 
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(context.Background(), operationDbListBuckets)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbListBuckets)
@@ -0,0 +1,721 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Status of a purge job.
+const (
+	// The job is waiting to be claimed by a node of the service. Jobs that
+	// were interrupted, or whose last attempt failed, are pending until they
+	// are claimed again.
+	PurgeJobPending = "pending"
+
+	// The job is in progress on the node that claimed it.
+	PurgeJobRunning = "running"
+
+	// All files and objects of the tenant or device have been deleted.
+	PurgeJobCompleted = "completed"
+
+	// Every attempt to run the job failed.
+	PurgeJobFailed = "failed"
+)
+
+// Phases of a purge job.
+const (
+	// Files are tombstoned in batches.
+	PurgeJobPhaseFiles = "files"
+
+	// Objects stored under the prefix of the tenant or device are deleted.
+	PurgeJobPhaseObjects = "objects"
+
+	// The job has completed.
+	PurgeJobPhaseDone = "done"
+)
+
+const (
+	// Number of files tombstoned, and objects deleted, by each batch.
+	purgeFilesBatchSize   = 500
+	purgeObjectsBatchSize = 1000
+
+	// Interval at which jobs are claimed to be resumed.
+	purgeJobPollInterval = time.Minute
+
+	// Jobs whose progress has not been recorded for this long are assumed to
+	// have been abandoned by the node that claimed them, and may be claimed
+	// by another node.
+	purgeJobLeaseTimeout = 2 * time.Minute
+
+	// Number of attempts made to run a job before it is marked failed.
+	maxPurgeJobAttempts = 10
+
+	// Maximum number of jobs run concurrently by a node.
+	maxConcurrentPurgeJobs = 4
+
+	// Maximum length of the error recorded for a purge job.
+	maxPurgeJobErrorLength = 256
+
+	// Database operations.
+	operationDbCreatePurgeJob = "CreatePurgeJob"
+	operationDbClaimPurgeJob  = "ClaimPurgeJob"
+	operationDbUpdatePurgeJob = "UpdatePurgeJob"
+	operationDbGetPurgeJob    = "GetPurgeJob"
+	operationDbTombstoneFiles = "TombstoneFiles"
+)
+
+var (
+	purgeJobsCtx        context.Context
+	purgeJobsCancelFunc context.CancelFunc
+	purgeJobsDone       chan bool
+
+	// Tracks the jobs running on this node, and limits their number.
+	purgeJobs     sync.WaitGroup
+	purgeJobSlots chan bool
+
+	errPurgeJobLost = errors.New("the purge job was claimed by another node")
+)
+
+// Represents a job deleting all files of a tenant, or of a single device of
+// the tenant, and the objects stored for them.
+type PurgeJob struct {
+	// The unique identifier assigned to the job.
+	JobID uint64
+
+	// The tenant whose files are deleted, and the device if only the files
+	// of a single device are deleted.
+	TenantID string
+	DeviceID string
+
+	// Status and current phase of the job.
+	Status string
+	Phase  string
+
+	// Node that claimed the job, and the number of times it was claimed.
+	NodeID   string
+	Attempts int
+
+	// Number of files tombstoned, and objects deleted, by the job.
+	FilesDeleted   int64
+	ObjectsDeleted int64
+
+	// Number of files that were not deleted because they are under legal
+	// hold. Their objects are not deleted either.
+	HeldFiles int64
+
+	// When the job was requested, first started, last recorded progress and
+	// completed.
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	HeartbeatAt *time.Time
+	CompletedAt *time.Time
+
+	// Reason the last attempt to run the job failed, if it did.
+	Error string
+
+	// Claim token issued to the node running the job. Progress is only
+	// recorded while the claim is current.
+	claimToken int64
+}
+
+// Start the goroutine resuming purge jobs that were interrupted, or whose last
+// attempt failed. Jobs are first resumed once the poll interval elapses, by
+// which time the storage provider has been initialized.
+func startPurgeJobs() {
+	purgeJobsCtx, purgeJobsCancelFunc = context.WithCancel(context.Background())
+	purgeJobsDone = make(chan bool, 1)
+	purgeJobSlots = make(chan bool, maxConcurrentPurgeJobs)
+	go runPurgeJobResumer()
+}
+
+func runPurgeJobResumer() {
+	ticker := time.NewTicker(purgeJobPollInterval)
+	for {
+		select {
+		case <-ticker.C:
+			resumePurgeJobs()
+			continue
+
+		case <-purgeJobsCtx.Done():
+			fsLogger.Info("Purge job resumer has received shutdown signal and is stopping!")
+			ticker.Stop()
+			purgeJobsDone <- true
+			return
+		}
+	}
+}
+
+// Stop the purge job resumer and wait for the jobs running on this node to
+// record their progress. Interrupted jobs are resumed by the next node to
+// claim them.
+func stopPurgeJobs() {
+	if purgeJobsCancelFunc != nil {
+		purgeJobsCancelFunc()
+		<-purgeJobsDone
+		purgeJobs.Wait()
+	}
+}
+
+// StartPurgeJob records a job deleting all files of the specified tenant, or
+// of the specified device if one is specified, and starts it in the
+// background. If a job for the tenant or device is already pending or
+// running, that job is returned instead.
+func StartPurgeJob(ctx context.Context, requestID string, tenantID string,
+	deviceID string) (*PurgeJob, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbCreatePurgeJob)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbCreatePurgeJob)
+
+	var jobID uint64
+	err := gDbPool.QueryRow(ctx, queryInsertPurgeJob, tenantID, deviceID,
+		PurgeJobPending, PurgeJobPhaseFiles).Scan(&jobID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A job is already active for the tenant or device.
+		job, err := scanPurgeJob(gDbPool.QueryRow(ctx, queryActivePurgeJob,
+			tenantID, deviceID))
+		if err == nil {
+			return job, nil
+		}
+	}
+	if err != nil {
+		fsLogger.Error("Failed to record the purge job in the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID:", tenantID),
+			zap.String("Device ID:", deviceID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+
+	job, err := scanPurgeJob(gDbPool.QueryRow(ctx, queryPurgeJobByID, jobID))
+	if err != nil {
+		fsLogger.Error("Failed to get the purge job from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("Job ID:", jobID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+
+	fsLogger.Info("Recorded a new purge job.",
+		zap.String("Request ID:", requestID),
+		zap.Uint64("Job ID:", jobID),
+		zap.String("Tenant ID:", tenantID),
+		zap.String("Device ID:", deviceID),
+	)
+	startPurgeJob(jobID)
+	return job, nil
+}
+
+// GetPurgeJob returns the purge job with the specified identifier.
+func GetPurgeJob(ctx context.Context, requestID string,
+	jobID uint64) (*PurgeJob, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetPurgeJob)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetPurgeJob)
+
+	job, err := scanPurgeJob(gDbPool.QueryRow(ctx, queryPurgeJobByID, jobID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to get the purge job from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("Job ID:", jobID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return job, nil
+}
+
+func scanPurgeJob(row pgx.Row) (*PurgeJob, error) {
+	var job PurgeJob
+	err := row.Scan(&job.JobID, &job.TenantID, &job.DeviceID, &job.Status,
+		&job.Phase, &job.NodeID, &job.claimToken, &job.Attempts,
+		&job.FilesDeleted, &job.ObjectsDeleted, &job.CreatedAt, &job.StartedAt,
+		&job.HeartbeatAt, &job.CompletedAt, &job.Error, &job.HeldFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim the jobs that are pending, or were abandoned by the node that claimed
+// them, and run them on this node.
+func resumePurgeJobs() {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(purgeJobsCtx, operationDbClaimPurgeJob)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbClaimPurgeJob)
+
+	rows, err := gDbPool.Query(ctx, queryClaimablePurgeJobs,
+		int(purgeJobLeaseTimeout.Seconds()), maxConcurrentPurgeJobs)
+	if err != nil {
+		fsLogger.Error("Failed to get the purge jobs to be resumed!",
+			zap.Error(err),
+		)
+		return
+	}
+	jobIDs, err := pgx.CollectRows(rows, pgx.RowTo[uint64])
+	if err != nil {
+		fsLogger.Error("Failed to get the purge jobs to be resumed!",
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, jobID := range jobIDs {
+		if !startPurgeJob(jobID) {
+			break
+		}
+	}
+}
+
+// Run the specified job in the background, if fewer than the maximum number
+// of jobs are running on this node. Otherwise, the job is left to be resumed
+// later. Returns false if the job could not be started.
+func startPurgeJob(jobID uint64) bool {
+	select {
+	case purgeJobSlots <- true:
+	default:
+		fsLogger.Info("Too many purge jobs are running. The job will be resumed later.",
+			zap.Uint64("Job ID:", jobID),
+		)
+		return false
+	}
+
+	purgeJobs.Add(1)
+	go func() {
+		defer purgeJobs.Done()
+		defer func() { <-purgeJobSlots }()
+		runPurgeJob(jobID)
+	}()
+	return true
+}
+
+// Claim and run the specified job. Each phase records its progress after every
+// batch, and stops if the job was claimed by another node in the meantime.
+// Phases can be repeated, so a job resumes from its current phase.
+func runPurgeJob(jobID uint64) {
+	job, err := claimPurgeJob(jobID)
+	if err != nil || job == nil {
+		return
+	}
+
+	metrics.MetricPurgeJobsInProgress.Inc()
+	defer metrics.MetricPurgeJobsInProgress.Dec()
+
+	fsLogger.Info("Running the purge job ...",
+		zap.Uint64("Job ID:", job.JobID),
+		zap.String("Tenant ID:", job.TenantID),
+		zap.String("Device ID:", job.DeviceID),
+		zap.String("Phase:", job.Phase),
+		zap.Int("Attempt:", job.Attempts),
+	)
+
+	for err == nil && job.Phase != PurgeJobPhaseDone {
+		switch job.Phase {
+		case PurgeJobPhaseFiles:
+			err = purgeFiles(job)
+		case PurgeJobPhaseObjects:
+			err = purgeObjects(job)
+		default:
+			err = errors.New("unknown purge job phase: " + job.Phase)
+		}
+	}
+
+	switch {
+	case err == nil:
+		job.Status = PurgeJobCompleted
+		job.Error = ""
+		metrics.MetricPurgeJobsCompleted.Inc()
+		fsLogger.Info("The purge job has completed.",
+			zap.Uint64("Job ID:", job.JobID),
+			zap.Int64("Files deleted:", job.FilesDeleted),
+			zap.Int64("Objects deleted:", job.ObjectsDeleted),
+			zap.Int64("Held files:", job.HeldFiles),
+		)
+
+	case errors.Is(err, errPurgeJobLost):
+		fsLogger.Error("Abandoning the purge job. It was claimed by another node!",
+			zap.Uint64("Job ID:", job.JobID),
+		)
+		return
+
+	case purgeJobsCtx.Err() != nil:
+		// The service is shutting down. Leave the job to be resumed.
+		fsLogger.Info("The purge job was interrupted and will be resumed.",
+			zap.Uint64("Job ID:", job.JobID),
+		)
+		job.Status = PurgeJobPending
+		job.Error = ""
+
+	default:
+		fsLogger.Error("The purge job failed!",
+			zap.Uint64("Job ID:", job.JobID),
+			zap.Int("Attempt:", job.Attempts),
+			zap.Error(err),
+		)
+		metrics.MetricPurgeJobFailures.Inc()
+		job.Status = PurgeJobPending
+		if job.Attempts >= maxPurgeJobAttempts {
+			job.Status = PurgeJobFailed
+		}
+		job.Error = err.Error()
+	}
+
+	_ = updatePurgeJob(job)
+}
+
+// Claim the specified job for this node. Returns nil if the job was claimed by
+// another node, or is no longer active.
+func claimPurgeJob(jobID uint64) (*PurgeJob, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(purgeJobsCtx, operationDbClaimPurgeJob)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbClaimPurgeJob)
+
+	job, err := scanPurgeJob(gDbPool.QueryRow(ctx, queryPurgeJobByID, jobID))
+	if err != nil {
+		fsLogger.Error("Failed to get the purge job from the database!",
+			zap.Uint64("Job ID:", jobID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	job.NodeID = leaderNodeID
+	err = gDbPool.QueryRow(ctx, queryClaimPurgeJob, jobID, job.NodeID,
+		int(purgeJobLeaseTimeout.Seconds())).Scan(&job.claimToken,
+		&job.Attempts, &job.Phase, &job.FilesDeleted, &job.ObjectsDeleted,
+		&job.StartedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		fsLogger.Error("Failed to claim the purge job!",
+			zap.Uint64("Job ID:", jobID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	job.Status = PurgeJobRunning
+	return job, nil
+}
+
+// Record the progress of the specified job, or its outcome once it has
+// stopped. Returns errPurgeJobLost if the job was claimed by another node.
+func updatePurgeJob(job *PurgeJob) error {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbUpdatePurgeJob)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbUpdatePurgeJob)
+
+	if len(job.Error) > maxPurgeJobErrorLength {
+		job.Error = job.Error[:maxPurgeJobErrorLength]
+	}
+	done := job.Status == PurgeJobCompleted || job.Status == PurgeJobFailed
+	tag, err := gDbPool.Exec(ctx, queryUpdatePurgeJob, job.JobID,
+		job.claimToken, job.Status, job.Phase, job.FilesDeleted,
+		job.ObjectsDeleted, job.Error, done, job.HeldFiles)
+	if err != nil {
+		fsLogger.Error("Failed to record the progress of the purge job!",
+			zap.Uint64("Job ID:", job.JobID),
+			zap.String("Status:", job.Status),
+			zap.Error(err),
+		)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errPurgeJobLost
+	}
+	return nil
+}
+
+// ////////////////////////  Phase 1 purge  ////////////////////////////////////
+// In this phase, files of the tenant or device are moved from the files table
+// to the tombstoned_files table in batches. Files under legal hold are skipped.
+// Once only held files remain, the names of the files that were deleted are
+// deleted from the file_names table.
+// /////////////////////////////////////////////////////////////////////////////
+func purgeFiles(job *PurgeJob) error {
+	for {
+		if err := purgeJobsCtx.Err(); err != nil {
+			return err
+		}
+
+		files, err := tombstoneFiles(job.TenantID, job.DeviceID,
+			purgeFilesBatchSize)
+		if err != nil {
+			return err
+		}
+		recordDeletedFiles(files, AuditActorPurge, AuditActionPurge)
+		job.FilesDeleted += int64(len(files))
+		metrics.MetricPurgedFiles.Add(float64(len(files)))
+
+		if len(files) < purgeFilesBatchSize {
+			if err = deleteFileNames(job.TenantID, job.DeviceID); err != nil {
+				return err
+			}
+
+			// The files that remain are under legal hold.
+			job.HeldFiles, err = countScavengeableRows(queryCountFilesOfTenant,
+				job.TenantID, job.DeviceID)
+			if err != nil {
+				return err
+			}
+			reportHeldFiles(heldFilesPurge, job.HeldFiles)
+			job.Phase = PurgeJobPhaseObjects
+		}
+		if err = updatePurgeJob(job); err != nil {
+			return err
+		}
+		if job.Phase != PurgeJobPhaseFiles {
+			return nil
+		}
+	}
+}
+
+// ////////////////////////  Phase 2 purge  ////////////////////////////////////
+// In this phase, objects stored under the prefix of the tenant or device are
+// deleted from every bucket in batches. Objects under legal hold are kept, as
+// are the objects of files created after the files were purged, which still
+// have a row in the files table.
+// /////////////////////////////////////////////////////////////////////////////
+func purgeObjects(job *PurgeJob) error {
+	if storage.Provider == nil {
+		return storage.ErrNotInitialized
+	}
+
+	held, err := getHeldObjects(job.TenantID, job.DeviceID)
+	if err != nil {
+		return err
+	}
+	if held.all {
+		// The tenant or device is under legal hold. Keep all of its objects.
+		job.Phase = PurgeJobPhaseDone
+		return nil
+	}
+
+	bucketNames, err := getAllBucketNames()
+	if err != nil {
+		return err
+	}
+
+	prefix := storage.GetObjectPrefix(job.TenantID, job.DeviceID)
+	for _, bucketName := range bucketNames {
+		var startAfter string
+		for {
+			if err := purgeJobsCtx.Err(); err != nil {
+				return err
+			}
+
+			objectNames, err := storage.Provider.ListObjects(purgeJobsCtx,
+				bucketName, prefix, startAfter, purgeObjectsBatchSize)
+			if err != nil {
+				return err
+			}
+			if len(objectNames) == 0 {
+				break
+			}
+			startAfter = objectNames[len(objectNames)-1]
+
+			existing, err := getExistingFileIDs(objectNames)
+			if err != nil {
+				return err
+			}
+
+			deleted := make([]string, 0, len(objectNames))
+			for _, objectName := range objectNames {
+				fileID, ok := getObjectFileID(objectName)
+				if ok && existing[fileID] {
+					continue
+				}
+				if !held.isHeld(objectName) {
+					deleted = append(deleted, objectName)
+				}
+			}
+			if len(deleted) > 0 {
+				err = storage.Provider.DeleteObjects(purgeJobsCtx, bucketName,
+					deleted)
+				if err != nil {
+					return err
+				}
+				job.ObjectsDeleted += int64(len(deleted))
+				metrics.MetricPurgedObjects.Add(float64(len(deleted)))
+			}
+
+			if err = updatePurgeJob(job); err != nil {
+				return err
+			}
+			if len(objectNames) < purgeObjectsBatchSize {
+				break
+			}
+		}
+	}
+
+	job.Phase = PurgeJobPhaseDone
+	return nil
+}
+
+// Returns the ID of the file whose contents are stored in the object with the
+// specified name, which is the last element of the name.
+func getObjectFileID(objectName string) (uint64, bool) {
+	fileID, err := strconv.ParseUint(
+		objectName[strings.LastIndex(objectName, "/")+1:], 10, 64)
+	return fileID, err == nil
+}
+
+// Returns the IDs of the files stored in the objects with the specified names
+// that are in the files table.
+func getExistingFileIDs(objectNames []string) (map[uint64]bool, error) {
+	start := time.Now()
+
+	fileIDs := make([]uint64, 0, len(objectNames))
+	for _, objectName := range objectNames {
+		if fileID, ok := getObjectFileID(objectName); ok {
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbGetFiles)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetFiles)
+
+	rows, err := gDbPool.Query(ctx, queryExistingFileIDs, fileIDs)
+	if err == nil {
+		var existingIDs []uint64
+		existingIDs, err = pgx.CollectRows(rows, pgx.RowTo[uint64])
+		if err == nil {
+			existing := make(map[uint64]bool, len(existingIDs))
+			for _, fileID := range existingIDs {
+				existing[fileID] = true
+			}
+			return existing, nil
+		}
+	}
+
+	fsLogger.Error("Failed to check which files of the purged objects exist!",
+		zap.Error(err),
+	)
+	return nil, ErrInternalError
+}
+
+// Move a batch of files of the specified tenant, or device if one is
+// specified, to the tombstoned_files table. Returns the files that were moved.
+func tombstoneFiles(tenantID string, deviceID string,
+	batchSize int) ([]File, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbTombstoneFiles)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbTombstoneFiles)
+
+	tx, err := gDbPool.Begin(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to acquire transaction to tombstone files!",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	files, err := queryScavengedFiles(ctx, tx, queryTombstoneFiles, tenantID,
+		deviceID, batchSize)
+	if err != nil {
+		rollback(tx, ctx)
+
+		fsLogger.Error("Failed to tombstone the files of the tenant!",
+			zap.String("Tenant ID:", tenantID),
+			zap.String("Device ID:", deviceID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	commit(tx, ctx)
+	return files, nil
+}
+
+// Delete the names of the files of the specified tenant, or device if one is
+// specified, once all of its files have been tombstoned.
+func deleteFileNames(tenantID string, deviceID string) error {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbTombstoneFiles)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbTombstoneFiles)
+
+	_, err := gDbPool.Exec(ctx, queryDeleteFileNames, tenantID, deviceID)
+	if err != nil {
+		fsLogger.Error("Failed to delete the file names of the tenant!",
+			zap.String("Tenant ID:", tenantID),
+			zap.String("Device ID:", deviceID),
+			zap.Error(err),
+		)
+		return ErrInternalError
+	}
+	return nil
+}
+
+// Returns the names of all buckets, including archived buckets, in which
+// objects may have been stored.
+func getAllBucketNames() ([]string, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbListBuckets)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbListBuckets)
+
+	rows, err := gDbPool.Query(ctx, queryGetAllBucketNames)
+	if err == nil {
+		var bucketNames []string
+		bucketNames, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err == nil {
+			return bucketNames, nil
+		}
+	}
+
+	fsLogger.Error("Failed to get a list of buckets from the database!",
+		zap.Error(err),
+	)
+	return nil, err
+}
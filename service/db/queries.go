@@ -5,6 +5,12 @@ package db
 
 // Database queries.
 const (
+	// Condition met by files, aliased as f, that are under legal hold. Holds
+	// apply to all files of a tenant, all files of a device or a single file.
+	queryFileIsHeld = `EXISTS(SELECT 1 FROM legal_holds h
+		WHERE h.tenant_id=f.tenant_id AND (h.device_id='' OR
+		h.device_id=f.device_id) AND (h.file_id=0 OR h.file_id=f.file_id))`
+
 	// Bucket lifecycle management queries
 	queryInsertNewBucket = `INSERT INTO buckets(bucket_name,is_archived,
 		created_at,updated_at) VALUES($1,$2,now(),now())`
@@ -19,21 +25,324 @@ const (
 
 	// File lifecycle management queries
 	queryInsertNewFile = `INSERT INTO files(tenant_id,device_id,name,checksum,
-		size,status,created_at,updated_at,bucket_name) 
-		VALUES($1,$2,$3,$4,$5,$6,now(),now(),$7)
+		size,status,created_at,updated_at,bucket_name,namespace,version,
+		trace_parent)
+		VALUES($1,$2,$3,$4,$5,$6,now(),now(),$7,$8,$9,$10)
 		RETURNING file_id,tenant_id,device_id,name,checksum,size,status,
-		created_at,updated_at,bucket_name`
+		created_at,updated_at,bucket_name,namespace,version`
 
 	queryFileByID = `SELECT file_id,tenant_id,device_id,name,checksum,size,status,
-	created_at,updated_at,bucket_name FROM files WHERE files.file_id=$1`
+	created_at,updated_at,bucket_name,namespace,version FROM files
+	WHERE files.file_id=$1`
+
+	queryFilesByIDs = `SELECT file_id,tenant_id,device_id,name,checksum,size,
+	status,created_at,updated_at,bucket_name,namespace,version FROM files
+	WHERE files.file_id=ANY($1)`
+
+	// The update time of a file versions its cache entries and must increase
+	// with every change, even if concurrent transactions commit out of order.
+	// Files are only updated if their status allows the change.
+	queryUpdateFileStatus = `UPDATE files f
+	SET updated_at=GREATEST(now(), p.updated_at + interval '1 microsecond'),
+	size=$2, status=$3
+	FROM (SELECT file_id,status,updated_at FROM files
+		WHERE file_id=$1 AND status=ANY($4) FOR UPDATE) p
+	WHERE f.file_id=p.file_id RETURNING f.file_id,f.tenant_id,f.device_id,
+	f.namespace,f.name,f.version,f.updated_at,f.trace_parent,p.status,
+	f.bucket_name,` + queryFileIsHeld
+
+	queryFilesForSpecificDevice = `SELECT file_id,tenant_id,device_id,name,
+	checksum,size,status,created_at,updated_at,bucket_name,namespace,version
+	FROM files WHERE files.tenant_id=$1 and files.device_id=$2`
+
+	queryUpdateFileScanStatus = `UPDATE files
+	SET updated_at=GREATEST(now(), updated_at + interval '1 microsecond'), status=$2
+	WHERE file_id=$1 AND status=ANY($3)
+	RETURNING file_id,tenant_id,device_id,name,checksum,size,status,
+	created_at,updated_at,bucket_name,namespace,version`
+
+	queryFileStatusByID = `SELECT status FROM files WHERE files.file_id=$1`
+
+	queryDeleteExpiredFiles = `DELETE FROM files WHERE file_id IN (
+		SELECT f.file_id FROM files f WHERE f.created_at <= $1
+		AND NOT ` + queryFileIsHeld + `
+		ORDER BY f.created_at LIMIT $2 FOR UPDATE SKIP LOCKED)
+	RETURNING file_id,tenant_id,device_id`
+
+	queryCountExpiredFiles = `SELECT COUNT(*) FROM files f
+	WHERE f.created_at <= $1 AND NOT ` + queryFileIsHeld
+
+	queryCountHeldExpiredFiles = `SELECT COUNT(*) FROM files f
+	WHERE f.created_at <= $1 AND ` + queryFileIsHeld
+
+	deleteFileByID = `DELETE FROM files f WHERE f.file_id=$1
+	AND NOT ` + queryFileIsHeld + `
+	RETURNING file_id,tenant_id,device_id`
+
+	queryFileExists = `SELECT EXISTS(SELECT 1 FROM files WHERE file_id=$1)`
+
+	queryExistingFileIDs = `SELECT file_id FROM files WHERE file_id=ANY($1)`
+
+	queryFileIsHeldByID = `SELECT EXISTS(SELECT 1 FROM files f
+	WHERE f.file_id=$1 AND ` + queryFileIsHeld + `)`
+
+	// File version management queries
+	queryAllocateFileVersion = `INSERT INTO file_names(tenant_id,device_id,
+	namespace,name,last_version,created_at,updated_at)
+	VALUES($1,$2,$3,$4,1,now(),now())
+	ON CONFLICT(tenant_id,device_id,namespace,name) DO UPDATE
+	SET last_version=file_names.last_version+1, updated_at=now()
+	RETURNING last_version`
+
+	queryUpdateLatestFileVersion = `UPDATE file_names SET latest_file_id=$5,
+	latest_version=$6, updated_at=now() WHERE tenant_id=$1 AND device_id=$2
+	AND namespace=$3 AND name=$4
+	AND (latest_version IS NULL OR latest_version<=$6)`
+
+	// The version a logical file points at is only returned while it is
+	// uploaded, otherwise the newest uploaded version is returned.
+	queryLatestFileByName = `SELECT f.file_id,f.tenant_id,f.device_id,f.name,
+	f.checksum,f.size,f.status,f.created_at,f.updated_at,f.bucket_name,
+	f.namespace,f.version FROM files f WHERE f.file_id=(
+		SELECT COALESCE((
+			SELECT l.file_id FROM files l WHERE l.file_id=n.latest_file_id
+			AND l.status=$5), (
+			SELECT u.file_id FROM files u WHERE u.tenant_id=n.tenant_id
+			AND u.device_id=n.device_id AND u.namespace=n.namespace
+			AND u.name=n.name AND u.status=$5
+			ORDER BY u.version DESC LIMIT 1))
+		FROM file_names n WHERE n.tenant_id=$1 AND n.device_id=$2
+		AND n.namespace=$3 AND n.name=$4)`
+
+	// Only uploaded versions are ranked, so versions that are still being
+	// uploaded or scanned, or are quarantined, are never counted as newer
+	// versions. The version a logical file points at is never old.
+	queryOldFileVersions = `SELECT r.file_id,r.tenant_id,r.device_id,r.rn
+		FROM (SELECT file_id,tenant_id,device_id,
+			ROW_NUMBER() OVER (
+				PARTITION BY tenant_id,device_id,namespace,name
+				ORDER BY version DESC) AS rn
+			FROM files WHERE status='` + FileStatusUploaded + `') r
+		WHERE NOT EXISTS (SELECT 1 FROM file_names n
+			WHERE n.latest_file_id=r.file_id)`
+
+	// Old versions are moved to the tombstoned_files table, and the objects
+	// stored for them are deleted once the transaction commits.
+	queryDeleteOldFileVersions = `WITH deleted AS (
+		DELETE FROM files WHERE file_id IN (
+			SELECT f.file_id FROM (` + queryOldFileVersions + `) f
+			WHERE f.rn > $1 AND NOT ` + queryFileIsHeld + ` LIMIT $2)
+		RETURNING file_id,tenant_id,device_id,bucket_name
+	), tombstoned AS (
+		INSERT INTO tombstoned_files(file_id,tenant_id,device_id,deleted_at,
+		bucket_name) SELECT file_id,tenant_id,device_id,now(),bucket_name
+		FROM deleted ON CONFLICT(file_id) DO UPDATE
+		SET deleted_at=EXCLUDED.deleted_at
+	)
+	SELECT file_id,tenant_id,device_id,bucket_name FROM deleted`
+
+	queryCountOldFileVersions = `SELECT COUNT(*) FROM (` +
+		queryOldFileVersions + `) f WHERE f.rn > $1 AND NOT ` + queryFileIsHeld
+
+	queryCountHeldOldFileVersions = `SELECT COUNT(*) FROM (` +
+		queryOldFileVersions + `) f WHERE f.rn > $1 AND ` + queryFileIsHeld
+
+	// File scan queries
+	queryInsertFileScan = `INSERT INTO file_scans(file_id,scanner,verdict,
+	reason,scanned_at) VALUES($1,$2,$3,$4,now())`
+
+	queryFileScansByID = `SELECT scan_id,file_id,scanner,verdict,reason,
+	scanned_at FROM file_scans WHERE file_scans.file_id=$1 ORDER BY scan_id`
+
+	// Usage rollup queries
+	queryRecordUsage = `INSERT INTO usage_rollups(tenant_id,device_id,
+	usage_date,bytes_uploaded,files_created,downloads_issued,updated_at)
+	VALUES($1,$2,CURRENT_DATE,$3,$4,$5,now())
+	ON CONFLICT(tenant_id,usage_date,device_id) DO UPDATE
+	SET bytes_uploaded=usage_rollups.bytes_uploaded+EXCLUDED.bytes_uploaded,
+	files_created=usage_rollups.files_created+EXCLUDED.files_created,
+	downloads_issued=usage_rollups.downloads_issued+EXCLUDED.downloads_issued,
+	updated_at=now()`
+
+	queryTenantUsage = `SELECT usage_date,SUM(bytes_uploaded),
+	SUM(files_created),SUM(downloads_issued) FROM usage_rollups
+	WHERE tenant_id=$1 AND ($2='' OR device_id=$2)
+	AND usage_date>=$3 AND usage_date<=$4
+	GROUP BY usage_date ORDER BY usage_date`
+
+	queryTopTenantUsage = `SELECT tenant_id,SUM(bytes_uploaded),
+	SUM(files_created),SUM(downloads_issued) FROM usage_rollups
+	WHERE usage_date=CURRENT_DATE GROUP BY tenant_id
+	ORDER BY SUM(bytes_uploaded) DESC, SUM(files_created) DESC LIMIT $1`
+
+	// File audit event queries
+	queryAuditEvents = `SELECT event_id,occurred_at,tenant_id,actor,action,
+	COALESCE(file_id,0),request_id,source_ip,outcome FROM file_audit_events
+	WHERE ($1='' OR tenant_id=$1) AND ($2=0 OR file_id=$2)
+	AND occurred_at>=$3 AND occurred_at<=$4
+	ORDER BY occurred_at DESC, event_id DESC LIMIT $5`
+
+	queryDeleteExpiredAuditEvents = `DELETE FROM file_audit_events
+	WHERE ctid = ANY(ARRAY(SELECT ctid FROM file_audit_events
+		WHERE occurred_at<$1 LIMIT $2))`
+
+	queryCountExpiredAuditEvents = `SELECT COUNT(*) FROM file_audit_events
+	WHERE occurred_at<$1`
+
+	// Scavenger run queries
+	queryInsertScavengerRun = `INSERT INTO scavenger_runs(trigger,dry_run,
+	status,created_at) VALUES($1,$2,$3,now()) RETURNING run_id,created_at`
+
+	queryStartScavengerRun = `UPDATE scavenger_runs SET status=$2,node_id=$3,
+	fencing_token=$4,started_at=now() WHERE run_id=$1 RETURNING started_at`
+
+	queryUpdateScavengerRun = `UPDATE scavenger_runs SET status=$2,
+	expired_files=$3,old_file_versions=$4,audit_events=$5,batches=$6,
+	error=$7,completed_at=CASE WHEN $8::boolean THEN now() END,
+	held_files=$9 WHERE run_id=$1`
+
+	queryScavengerRunByID = `SELECT run_id,trigger,dry_run,status,node_id,
+	fencing_token,created_at,started_at,completed_at,expired_files,
+	old_file_versions,audit_events,batches,error,held_files
+	FROM scavenger_runs WHERE run_id=$1`
+
+	queryScheduledScavengerRunSince = `SELECT EXISTS(SELECT 1 FROM
+	scavenger_runs WHERE trigger=$1 AND started_at>=$2::timestamptz)`
+
+	// Purge job queries
+	queryInsertPurgeJob = `INSERT INTO purge_jobs(tenant_id,device_id,status,
+	phase,created_at) VALUES($1,$2,$3,$4,now())
+	ON CONFLICT(tenant_id,device_id) WHERE status IN ('pending','running')
+	DO NOTHING RETURNING job_id`
+
+	queryPurgeJobColumns = `SELECT job_id,tenant_id,device_id,status,phase,
+	node_id,claim_token,attempts,files_deleted,objects_deleted,created_at,
+	started_at,heartbeat_at,completed_at,error,held_files FROM purge_jobs`
+
+	queryPurgeJobByID = queryPurgeJobColumns + ` WHERE job_id=$1`
+
+	queryActivePurgeJob = queryPurgeJobColumns + ` WHERE tenant_id=$1
+	AND device_id=$2 AND status IN ('pending','running')`
+
+	queryClaimPurgeJob = `UPDATE purge_jobs SET status='running',node_id=$2,
+	claim_token=claim_token+1,attempts=attempts+1,heartbeat_at=now(),
+	started_at=COALESCE(started_at,now()),error=''
+	WHERE job_id=$1 AND (status='pending' OR (status='running' AND
+	heartbeat_at<now()-$3::integer*interval '1 second'))
+	RETURNING claim_token,attempts,phase,files_deleted,objects_deleted,
+	started_at`
+
+	queryUpdatePurgeJob = `UPDATE purge_jobs SET status=$3,phase=$4,
+	files_deleted=$5,objects_deleted=$6,error=$7,held_files=$9,
+	heartbeat_at=now(),
+	node_id=CASE WHEN $3='pending' THEN '' ELSE node_id END,
+	completed_at=CASE WHEN $8::boolean THEN now() END
+	WHERE job_id=$1 AND claim_token=$2`
+
+	queryClaimablePurgeJobs = `SELECT job_id FROM purge_jobs
+	WHERE status='pending' OR (status='running' AND
+	heartbeat_at<now()-$1::integer*interval '1 second') ORDER BY job_id LIMIT $2`
+
+	queryTombstoneFiles = `WITH deleted AS (
+		DELETE FROM files WHERE file_id IN (
+			SELECT f.file_id FROM files f WHERE f.tenant_id=$1 AND
+			($2='' OR f.device_id=$2) AND NOT ` + queryFileIsHeld + `
+			ORDER BY f.file_id LIMIT $3 FOR UPDATE)
+		RETURNING file_id,tenant_id,device_id,bucket_name
+	), tombstoned AS (
+		INSERT INTO tombstoned_files(file_id,tenant_id,device_id,deleted_at,
+		bucket_name) SELECT file_id,tenant_id,device_id,now(),bucket_name
+		FROM deleted ON CONFLICT(file_id) DO UPDATE
+		SET deleted_at=EXCLUDED.deleted_at
+	)
+	SELECT file_id,tenant_id,device_id FROM deleted`
+
+	queryCountFilesOfTenant = `SELECT COUNT(*) FROM files WHERE tenant_id=$1
+	AND ($2='' OR device_id=$2)`
+
+	queryDeleteFileNames = `DELETE FROM file_names n WHERE n.tenant_id=$1 AND
+	($2='' OR n.device_id=$2) AND NOT EXISTS(SELECT 1 FROM files f
+		WHERE f.tenant_id=n.tenant_id AND f.device_id=n.device_id
+		AND f.namespace=n.namespace AND f.name=n.name)`
+
+	queryGetAllBucketNames = `SELECT bucket_name FROM buckets
+	ORDER BY bucket_name`
+
+	// Legal hold queries
+	queryInsertLegalHold = `INSERT INTO legal_holds(tenant_id,device_id,
+	file_id,reason,actor,created_at) VALUES($1,$2,$3,$4,$5,now())
+	ON CONFLICT(tenant_id,device_id,file_id) DO NOTHING
+	RETURNING hold_id,created_at`
+
+	queryLegalHoldColumns = `SELECT hold_id,tenant_id,device_id,file_id,
+	reason,actor,created_at FROM legal_holds`
+
+	queryLegalHoldByScope = queryLegalHoldColumns + ` WHERE tenant_id=$1
+	AND device_id=$2 AND file_id=$3`
+
+	queryLegalHoldByID = queryLegalHoldColumns + ` WHERE hold_id=$1`
+
+	queryLegalHoldsOfTenant = queryLegalHoldColumns + ` WHERE tenant_id=$1
+	AND ($2='' OR device_id='' OR device_id=$2) ORDER BY hold_id`
+
+	queryDeleteLegalHold = `DELETE FROM legal_holds WHERE hold_id=$1
+	RETURNING hold_id,tenant_id,device_id,file_id,reason,actor,created_at`
+
+	// Files of a tenant or device whose objects have been stored, after the
+	// specified file. Unless $4 is set, only files that are not held are
+	// returned.
+	queryStoredFilesInHoldScope = `SELECT f.file_id,f.tenant_id,f.device_id,
+	f.bucket_name FROM files f WHERE f.tenant_id=$1 AND ($2='' OR
+	f.device_id=$2) AND f.status<>'` + FileStatusNew + `' AND f.file_id>$3
+	AND ($4 OR NOT ` + queryFileIsHeld + `) ORDER BY f.file_id LIMIT $5`
+
+	// Resumable upload queries
+	queryFileUploadColumns = `file_id,upload_id,upload_offset,part_etags,
+	checksum_state,created_at,updated_at`
+
+	queryInsertFileUpload = `INSERT INTO file_uploads(file_id,upload_id,
+	created_at,updated_at) VALUES($1,$2,now(),now())
+	ON CONFLICT(file_id) DO NOTHING RETURNING ` + queryFileUploadColumns
+
+	queryFileUploadByID = `SELECT ` + queryFileUploadColumns + `
+	FROM file_uploads WHERE file_id=$1`
+
+	queryClaimFileUpload = `UPDATE file_uploads
+	SET claim_token=claim_token+1,claimed_at=now()
+	WHERE file_id=$1 AND upload_offset=$2 AND (claimed_at IS NULL OR
+	claimed_at<now()-$3::integer*interval '1 second')
+	RETURNING claim_token`
+
+	queryAppendFileUploadPart = `UPDATE file_uploads SET upload_offset=$3,
+	part_etags=array_append(part_etags,$4),checksum_state=$5,claimed_at=NULL,
+	updated_at=now()
+	WHERE file_id=$1 AND claim_token=$2 AND claimed_at IS NOT NULL`
+
+	queryReleaseFileUpload = `UPDATE file_uploads SET claimed_at=NULL
+	WHERE file_id=$1 AND claim_token=$2`
+
+	queryDeleteFileUpload = `DELETE FROM file_uploads WHERE file_id=$1
+	RETURNING ` + queryFileUploadColumns
+
+	// Leader election queries
+	queryTryAdvisoryLock = `SELECT pg_try_advisory_lock($1)`
+
+	queryAdvisoryUnlock = `SELECT pg_advisory_unlock($1)`
 
-	queryUpdateFileStatus = `UPDATE files SET updated_at=now(), size=$2, status=$3 
-	WHERE file_id=$1 RETURNING file_id`
+	queryClaimLeaderLease = `INSERT INTO leader_leases(name,holder,
+	fencing_token,acquired_at,renewed_at) VALUES($1,$2,1,now(),now())
+	ON CONFLICT(name) DO UPDATE SET holder=$2,
+	fencing_token=leader_leases.fencing_token+1, acquired_at=now(),
+	renewed_at=now() RETURNING fencing_token`
 
-	queryFilesForSpecificDevice = `SELECT * FROM files WHERE files.tenant_id=$1 and 
-	files.device_id=$2`
+	queryRenewLeaderLease = `UPDATE leader_leases SET renewed_at=now()
+	WHERE name=$1 AND fencing_token=$2`
 
-	queryDeleteExpiredFiles = "DELETE FROM files WHERE files.created_at <= $1 LIMIT 100"
+	queryLeaderFencingToken = `SELECT fencing_token FROM leader_leases
+	WHERE name=$1`
 
-	deleteFileByID = `DELETE FROM files WHERE files.file_id=$1`
+	queryLeaderLease = `SELECT l.holder,l.fencing_token,l.acquired_at,
+	l.renewed_at,EXISTS(SELECT 1 FROM pg_locks WHERE locktype='advisory'
+	AND classid=0 AND objid=$2 AND objsubid=1 AND granted)
+	FROM leader_leases l WHERE l.name=$1`
 )
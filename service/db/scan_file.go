@@ -0,0 +1,212 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/cache"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// RequestFileRescan marks the specified file as pending a scan and records the
+// rescan request. Files that have not yet been uploaded cannot be rescanned.
+// The rescan is queued with the specified function before the status change
+// is committed, and the status is left unchanged if it cannot be queued.
+func RequestFileRescan(ctx context.Context, requestID, id, actor, reason string,
+	queueRescan func(file *File) error) (*File, error) {
+	return changeScanStatus(ctx, requestID, id, FileStatusScanPending,
+		[]string{FileStatusUploaded, FileStatusQuarantined, FileStatusScanPending},
+		&FileScan{Scanner: actor, Verdict: ScanVerdictRescanRequested, Reason: reason},
+		queueRescan)
+}
+
+// ReleaseQuarantinedFile releases a quarantined file, making it available for
+// download again.
+func ReleaseQuarantinedFile(ctx context.Context, requestID, id, actor, reason string) (*File, error) {
+	return changeScanStatus(ctx, requestID, id, FileStatusUploaded,
+		[]string{FileStatusQuarantined},
+		&FileScan{Scanner: actor, Verdict: ScanVerdictReleased, Reason: reason},
+		nil)
+}
+
+// ConfirmQuarantinedFile confirms the quarantine of a quarantined file.
+func ConfirmQuarantinedFile(ctx context.Context, requestID, id, actor, reason string) (*File, error) {
+	return changeScanStatus(ctx, requestID, id, FileStatusQuarantined,
+		[]string{FileStatusQuarantined},
+		&FileScan{Scanner: actor, Verdict: ScanVerdictConfirmed, Reason: reason},
+		nil)
+}
+
+// Changes the status of the specified file if its current status is one of
+// the allowed statuses and records the specified scan verdict. If specified,
+// beforeCommit is called with the updated file before the change is committed,
+// and the change is rolled back if it fails.
+func changeScanStatus(ctx context.Context, requestID, id, status string, allowedFrom []string,
+	scan *FileScan, beforeCommit func(file *File) error) (*File, error) {
+	var updatedFile File
+
+	// Check the parameters
+	fileID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		fsLogger.Error("Failed to parse the specified file ID",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbUpdateFileScan)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbUpdateFileScan)
+
+	tx, err := gDbPool.Begin(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to acquire transaction to update file scan status!",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	response := tx.QueryRow(ctx, queryUpdateFileScanStatus, fileID, status,
+		allowedFrom)
+	err = response.Scan(&updatedFile.FileID, &updatedFile.TenantID,
+		&updatedFile.DeviceID, &updatedFile.Name, &updatedFile.Checksum,
+		&updatedFile.Size, &updatedFile.Status, &updatedFile.CreatedAt,
+		&updatedFile.UpdatedAt, &updatedFile.BucketName, &updatedFile.Namespace,
+		&updatedFile.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either the file does not exist or it is not in a state that
+			// allows the requested transition.
+			var currentStatus string
+			err = tx.QueryRow(ctx, queryFileStatusByID, fileID).Scan(&currentStatus)
+			rollback(tx, ctx)
+			if errors.Is(err, pgx.ErrNoRows) {
+				metrics.MetricDatabaseFileNotFoundErrors.Inc()
+				return nil, ErrNotFound
+			}
+			if err == nil {
+				fsLogger.Error("The file scan status change is not allowed!",
+					zap.String("Request ID:", requestID),
+					tracing.TraceID(ctx),
+					zap.Uint64("File ID: ", fileID),
+					zap.String("Current status: ", currentStatus),
+					zap.String("Requested status: ", status),
+				)
+				return nil, ErrNotAllowed
+			}
+		} else {
+			rollback(tx, ctx)
+		}
+
+		fsLogger.Error("Failed to update the file scan status in the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID: ", fileID),
+			zap.Error(err),
+		)
+		metrics.MetricDatabaseUpdateFileFailures.Inc()
+		return nil, ErrInternalError
+	}
+
+	err = recordStatusChange(ctx, tx, &updatedFile, status, scan)
+	if err != nil {
+		rollback(tx, ctx)
+		metrics.MetricDatabaseUpdateFileFailures.Inc()
+		return nil, ErrInternalError
+	}
+
+	// The file remains locked until the transaction ends, so a verdict
+	// reported before the change is committed is applied after it.
+	if beforeCommit != nil {
+		if err = beforeCommit(&updatedFile); err != nil {
+			rollback(tx, ctx)
+			return nil, err
+		}
+	}
+
+	commit(tx, ctx)
+	metrics.MetricDatabaseFilesUpdated.Inc()
+
+	// Invalidate the cache entry and the cached lists of files of the device
+	// before returning, so that the change is visible to subsequent reads.
+	// The next subsequent read of this file will refresh the cache entry.
+	cache.InvalidateFile(ctx, requestID, fileID, updatedFile.UpdatedAt)
+	cache.InvalidateFileList(ctx, requestID, updatedFile.TenantID,
+		updatedFile.DeviceID)
+
+	return &updatedFile, nil
+}
+
+// ListFileScans - list all scan verdicts recorded for the specified file, in
+// the order in which they were recorded.
+func ListFileScans(ctx context.Context, requestID, id string) ([]FileScan, error) {
+	var scans []FileScan
+
+	// Check the parameters
+	fileID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		fsLogger.Error("Failed to parse the specified file ID",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbListFileScans)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbListFileScans)
+
+	response, err := gDbPool.Query(ctx, queryFileScansByID, fileID)
+	if err != nil {
+		fsLogger.Error("Failed to get a list of file scans from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer response.Close()
+
+	for response.Next() {
+		var scan FileScan
+		err = response.Scan(&scan.ScanID, &scan.FileID, &scan.Scanner,
+			&scan.Verdict, &scan.Reason, &scan.ScannedAt)
+		if err != nil {
+			fsLogger.Error("Failed to get a list of file scans from the database!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+		scans = append(scans, scan)
+	}
+
+	if response.Err() != nil {
+		fsLogger.Error("Failed reading list of file scans from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(response.Err()),
+		)
+		return nil, response.Err()
+	}
+
+	return scans, nil
+}
@@ -5,75 +5,115 @@ package db
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
-	"github.com/HPInc/krypton-fs/service/cache"
 	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
+const (
+	// Defaults for the scavenger settings. By default, the scavenger runs at
+	// 23:59 local time.
+	defaultScavengerSchedule   = "59 23 * * *"
+	defaultScavengerBatchSize  = 1000
+	defaultScavengerTimeBudget = time.Hour
+
+	// Phases of a scavenger run, used to report progress.
+	scavengerPhaseExpiredFiles    = "expired_files"
+	scavengerPhaseOldFileVersions = "old_file_versions"
+	scavengerPhaseAuditEvents     = "audit_events"
+)
+
 var (
 	scavengerCtx        context.Context
 	scavengerCancelFunc context.CancelFunc
 	scavengerDone       chan bool
+
+	// Tracks scavenger runs started at the request of callers.
+	scavengerRequests sync.WaitGroup
+
+	// Scavenger settings.
+	scavengerSchedule   cron.Schedule
+	scavengerBatchSize  = defaultScavengerBatchSize
+	scavengerTimeBudget = defaultScavengerTimeBudget
+	scavengerDryRun     bool
+
+	errTimeBudgetExhausted = errors.New("the time budget of the scavenger run was exhausted")
 )
 
-// roundToMidnight truncates time to midnight
-func roundToMidnight() time.Time {
-	timeNow := time.Now()
-	return time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 23, 59, 59,
-		0, timeNow.Location())
+// scavengerPass holds the state of a scavenger run on the leader.
+type scavengerPass struct {
+	leader    *leadership
+	run       *ScavengerRun
+	deadline  time.Time
+	batchSize int
 }
 
-// Start the periodic scavenger goroutine.
-func startScavenger() {
-	var leaderLockAcquired bool
+// Initialize the scavenger settings and, if enabled, start the periodic
+// scavenger goroutine. The scavenger can be run at the request of callers
+// even if it does not run periodically.
+func initScavenger(dbConfig *config.Database) error {
+	spec := dbConfig.ScavengerSchedule
+	if spec == "" {
+		spec = defaultScavengerSchedule
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		fsLogger.Error("Failed to parse the scavenger schedule!",
+			zap.String("Schedule:", spec),
+			zap.Error(err),
+		)
+		return err
+	}
+	scavengerSchedule = schedule
 
-	// Initialize the scavenger timer to fire at midnight.
-	nextRun := time.Until(roundToMidnight())
-	scavengerTimer := time.NewTimer(nextRun)
+	scavengerBatchSize = defaultScavengerBatchSize
+	if dbConfig.ScavengerBatchSize > 0 {
+		scavengerBatchSize = dbConfig.ScavengerBatchSize
+	}
+	scavengerTimeBudget = defaultScavengerTimeBudget
+	if dbConfig.ScavengerTimeBudgetMinutes > 0 {
+		scavengerTimeBudget = time.Duration(
+			dbConfig.ScavengerTimeBudgetMinutes) * time.Minute
+	}
+	scavengerDryRun = dbConfig.ScavengerDryRun
 
 	// Create the context used to listen for cancellation of scavenger runs or
-	// service shutdown signals. Also create a channel for the scavenger
-	// goroutine to signal when it is done shutting down.
+	// service shutdown signals.
 	scavengerCtx, scavengerCancelFunc = context.WithCancel(context.Background())
-	scavengerDone = make(chan bool, 1)
+
+	if dbConfig.ScavengerEnabled {
+		// Create a channel for the scavenger goroutine to signal when it is
+		// done shutting down.
+		scavengerDone = make(chan bool, 1)
+		go startScavenger()
+	}
+	return nil
+}
+
+// Start the periodic scavenger goroutine.
+func startScavenger() {
+	// Initialize the scavenger timer to fire at the next scheduled time.
+	scheduledAt := scavengerSchedule.Next(time.Now())
+	scavengerTimer := time.NewTimer(time.Until(scheduledAt))
 
 	for {
 		fsLogger.Info("Scavenger has been configured for its next execution.",
-			zap.Duration("Duration:", nextRun),
+			zap.Time("Scheduled at:", scheduledAt),
 		)
 
 		select {
 		case <-scavengerTimer.C:
-			leaderLockAcquired = false
-
-			// Give ourselves a few retry attempts to acquire the leader lock.
-			for i := maxDbAcquireLeaderLockRetries; i > 0; i-- {
-				if !cache.AcquireLeaderLock() {
-					// We failed to acquire the leader lock. Wait for as long as
-					// the leader lock's lifetime before retrying.
-					time.Sleep(cache.LeaderLockLifetime)
-				} else {
-					// Run the periodic scavenger.
-					leaderLockAcquired = true
-					RunScavenger()
-					metrics.MetricScavengerRuns.Inc()
-
-					// Release the leader lock. The scavenger run has completed.
-					cache.ReleaseLeaderLock()
-				}
-			}
-
-			if !leaderLockAcquired {
-				fsLogger.Error("All attempts to acquire leader lock have failed. Bailing on this scavenger run ...")
-				metrics.MetricAbandonedScavengerRun.Inc()
-			}
+			runScavengerWhenLeader(nil, scheduledAt)
 
-			// Reset the scavenger timer to run the next day.
-			nextRun = time.Until(roundToMidnight())
-			_ = scavengerTimer.Reset(nextRun)
+			// Reset the scavenger timer to fire at the next scheduled time.
+			scheduledAt = scavengerSchedule.Next(time.Now())
+			_ = scavengerTimer.Reset(time.Until(scheduledAt))
 			continue
 
 		case <-scavengerCtx.Done():
@@ -85,56 +125,351 @@ func startScavenger() {
 	}
 }
 
-// Stop the scavenger goroutine and wait for it to be done.
+// Stop the scavenger goroutine and any requested scavenger runs, and wait for
+// them to be done.
 func stopScavenger() {
-	if scavengerCancelFunc != nil {
-		scavengerCancelFunc()
+	if scavengerCancelFunc == nil {
+		return
+	}
+
+	scavengerCancelFunc()
+	if scavengerDone != nil {
 		<-scavengerDone
 	}
+	scavengerRequests.Wait()
+}
+
+// StartScavengerRun records a new run of the database scavenger, which starts
+// in the background once this node is elected leader. Returns the run, whose
+// progress can be followed using GetScavengerRun.
+func StartScavengerRun(ctx context.Context, requestID string,
+	dryRun bool) (*ScavengerRun, error) {
+	run, err := createScavengerRun(ctx, requestID, ScavengerTriggerRequest,
+		dryRun)
+	if err != nil {
+		return nil, err
+	}
+	pending := *run
+
+	scavengerRequests.Add(1)
+	go func() {
+		defer scavengerRequests.Done()
+		runScavengerWhenLeader(run, time.Time{})
+	}()
+	return &pending, nil
 }
 
-func RunScavenger() {
+// Run the database scavenger once this node is elected leader. If another
+// node is the leader, the election is retried a few times before the run is
+// abandoned. Scheduled runs are only recorded once this node is elected, and
+// are skipped if another node already ran the scavenger since the scheduled
+// time.
+func runScavengerWhenLeader(run *ScavengerRun, scheduledAt time.Time) {
+	// Give ourselves a few retry attempts to acquire the leader lease.
+	for i := maxDbAcquireLeaderLockRetries; i > 0; i-- {
+		leader, err := acquireLeadership(scavengerCtx)
+		if err != nil {
+			fsLogger.Error("Failed to acquire the leader lease!",
+				zap.Error(err),
+			)
+		}
+
+		if leader != nil {
+			runScavengerAsLeader(leader, run, scheduledAt)
+
+			// Release the leader lease. The scavenger run has completed.
+			leader.release()
+			return
+		}
+
+		// We failed to acquire the leader lease. Wait for as long as the
+		// lifetime of the lease before retrying.
+		if !waitForScavengerRetry() {
+			break
+		}
+	}
+
+	fsLogger.Error("All attempts to acquire leader lock have failed. Bailing on this scavenger run ...")
+	metrics.MetricAbandonedScavengerRun.Inc()
+	if run != nil {
+		run.Status = ScavengerRunAbandoned
+		updateScavengerRun(run)
+	}
+}
+
+// Wait before retrying the election of this node as leader. Returns false if
+// the service is shutting down.
+func waitForScavengerRetry() bool {
+	select {
+	case <-scavengerCtx.Done():
+		return false
+	case <-time.After(leaderLeaseLifetime):
+		return true
+	}
+}
+
+// Record and execute the specified scavenger run while this node is the
+// leader. A scheduled run is recorded here.
+func runScavengerAsLeader(leader *leadership, run *ScavengerRun,
+	scheduledAt time.Time) {
+	if run == nil {
+		found, err := hasScheduledRunSince(scheduledAt)
+		if err == nil && found {
+			fsLogger.Info("The scheduled scavenger run has already been executed by another node.",
+				zap.Time("Scheduled at:", scheduledAt),
+			)
+			return
+		}
+
+		run, err = createScavengerRun(context.Background(), "",
+			ScavengerTriggerSchedule, scavengerDryRun)
+		if err != nil {
+			return
+		}
+	}
+
+	if err := startScavengerRun(run, leader); err != nil {
+		run.Status = ScavengerRunFailed
+		run.Error = err.Error()
+		updateScavengerRun(run)
+		return
+	}
+
+	runScavenger(&scavengerPass{
+		leader:    leader,
+		run:       run,
+		deadline:  time.Now().Add(scavengerTimeBudget),
+		batchSize: scavengerBatchSize,
+	})
+	if run.Status == ScavengerRunCompleted {
+		metrics.MetricScavengerRuns.Inc()
+	}
+	updateScavengerRun(run)
+}
+
+// Run each phase of the database scavenger while this node is the leader,
+// and record the outcome of the run.
+func runScavenger(p *scavengerPass) {
 	startTime := time.Now()
 	defer common.TimeIt(fsLogger, startTime, "DB Scavenger")
 
 	fsLogger.Info("Executing the database scavenger ...",
 		zap.Time("Start time:", startTime),
+		zap.Uint64("Run ID:", p.run.RunID),
+		zap.Bool("Dry run:", p.run.DryRun),
+		zap.Int64("Fencing token:", p.leader.fencingToken),
 	)
+	metrics.MetricScavengerRunInProgress.Set(1)
+	defer metrics.MetricScavengerRunInProgress.Set(0)
+
+	phases := []func(*scavengerPass) error{
+		// Scavenge files older than the configured threshold (expired files).
+		scavengeExpiredFiles,
+
+		// Scavenge versions of files beyond the configured retention count.
+		scavengeOldFileVersions,
 
-	// Scavenge files older than the configured threshold (expired files).
-	if err := scavengeExpiredFiles(); err != nil {
-		fsLogger.Info("Scavenger run failed", zap.Error(err))
+		// Scavenge audit events older than the configured retention period.
+		scavengeExpiredAuditEvents,
 	}
+	for _, phase := range phases {
+		err := phase(p)
+		switch {
+		case err == nil:
+			continue
+
+		case errors.Is(err, ErrNotLeader):
+			fsLogger.Error("Abandoning the scavenger run. This node is no longer the leader!",
+				zap.Uint64("Run ID:", p.run.RunID),
+			)
+			p.run.Status = ScavengerRunInterrupted
+			p.run.Error = err.Error()
+			return
 
-	fsLogger.Info("The database scavenger run has completed.")
+		case errors.Is(err, errTimeBudgetExhausted):
+			fsLogger.Error("Stopping the scavenger run. Its time budget has been exhausted!",
+				zap.Uint64("Run ID:", p.run.RunID),
+				zap.Duration("Time budget:", scavengerTimeBudget),
+			)
+			metrics.MetricScavengerBudgetExhausted.Inc()
+			p.run.Status = ScavengerRunIncomplete
+			p.run.Error = err.Error()
+			return
+
+		default:
+			fsLogger.Info("Scavenger run failed", zap.Error(err))
+			p.run.Status = ScavengerRunFailed
+			p.run.Error = err.Error()
+		}
+	}
+
+	if p.run.Status == ScavengerRunRunning {
+		p.run.Status = ScavengerRunCompleted
+	}
+	fsLogger.Info("The database scavenger run has completed.",
+		zap.Uint64("Run ID:", p.run.RunID),
+		zap.String("Status:", p.run.Status),
+		zap.Int64("Expired files:", p.run.ExpiredFiles),
+		zap.Int64("Old file versions:", p.run.OldFileVersions),
+		zap.Int64("Audit events:", p.run.AuditEvents),
+		zap.Int64("Held files:", p.run.HeldFiles),
+	)
+}
+
+// Delete the rows removed by a phase of the scavenger in batches, until there
+// are no more rows to delete. The leader checks that it still holds the
+// leader lease before each batch, and stops once the time budget of the run
+// is exhausted. In a dry run, the rows that would be deleted are counted
+// instead. The number of rows is recorded as the progress of the phase.
+func (p *scavengerPass) scavenge(phase string, progress *int64,
+	count func() (int64, error),
+	deleteBatch func(batchSize int) (int64, error)) error {
+	for {
+		// Check that this node is still the leader. If not, the lease was
+		// lost or the service is shutting down.
+		if err := p.leader.check(); err != nil {
+			return err
+		}
+		if time.Now().After(p.deadline) {
+			return errTimeBudgetExhausted
+		}
+
+		if p.run.DryRun {
+			rows, err := count()
+			if err != nil {
+				return err
+			}
+			*progress = rows
+			metrics.MetricScavengerRunProgress.WithLabelValues(phase).Set(
+				float64(rows))
+			return nil
+		}
+
+		rows, err := deleteBatch(p.batchSize)
+		if err != nil {
+			return err
+		}
+		*progress += rows
+		p.run.Batches++
+		metrics.MetricScavengerBatches.WithLabelValues(phase).Inc()
+		metrics.MetricScavengerRunProgress.WithLabelValues(phase).Set(
+			float64(*progress))
+		updateScavengerRun(p.run)
+
+		if rows < int64(p.batchSize) {
+			return nil
+		}
+	}
+}
+
+// Count the files that a phase of the scavenger skips because they are under
+// legal hold, and record them as part of the run. Failures to count are
+// logged, and do not stop the run.
+func (p *scavengerPass) countHeldFiles(query string, args ...any) {
+	held, err := countScavengeableRows(query, args...)
+	if err != nil {
+		return
+	}
+
+	p.run.HeldFiles += held
+	if held > 0 {
+		fsLogger.Info("Files under legal hold are skipped by the scavenger.",
+			zap.Uint64("Run ID:", p.run.RunID),
+			zap.Int64("Held files:", held),
+		)
+		if !p.run.DryRun {
+			reportHeldFiles(heldFilesScavenger, held)
+		}
+	}
 }
 
 // ////////////////////////  Phase 1 scavenge  /////////////////////////////////
 // In this phase, files that have been created before the configured time
-// threshold are tombstoned. An entry for the file is created in the
-// tombstoned_files table and its entry is deleted from the files table.
+// threshold are deleted from the files table. Files under legal hold are
+// skipped.
 // /////////////////////////////////////////////////////////////////////////////
-func scavengeExpiredFiles() error {
+func scavengeExpiredFiles(p *scavengerPass) error {
 	startTime := time.Now()
 	defer common.TimeIt(fsLogger, startTime, "scavengeExpiredFiles")
 
-	// Check if the scavenger's context is no longer valid. This means we've been
-	// instructed to stop and likely the service is shutting down or encountered
-	// an error.
-	if scavengerCtx.Err() != nil {
-		fsLogger.Info("Aborting expired files scavenger run. Context has been cancelled.")
-		return scavengerCtx.Err()
+	threshold := time.Now().AddDate(0, 0, scavengeExpiredFilesThreshold)
+	p.countHeldFiles(queryCountHeldExpiredFiles, threshold)
+	err := p.scavenge(scavengerPhaseExpiredFiles, &p.run.ExpiredFiles,
+		func() (int64, error) {
+			return countScavengeableRows(queryCountExpiredFiles, threshold)
+		},
+		func(batchSize int) (int64, error) {
+			return deleteExpiredFiles(threshold, batchSize)
+		})
+	if err != nil && !errors.Is(err, ErrNotLeader) &&
+		!errors.Is(err, errTimeBudgetExhausted) {
+		fsLogger.Error("Failed to scavenge expired files!",
+			zap.Error(err),
+		)
+		metrics.MetricScavengeExpiredFileFailures.Inc()
 	}
+	return err
+}
 
-	// Get a list of candidate files from the files table.
-	err := deleteExpiredFiles()
-	if err != nil {
-		fsLogger.Error("Failed to query for scavengeable expired files!",
+// ////////////////////////  Phase 2 scavenge  /////////////////////////////////
+// In this phase, versions of each logical file older than the configured number
+// of retained versions are moved to the tombstoned_files table, and their
+// objects are deleted from storage. Versions under legal hold are skipped.
+// /////////////////////////////////////////////////////////////////////////////
+func scavengeOldFileVersions(p *scavengerPass) error {
+	startTime := time.Now()
+	defer common.TimeIt(fsLogger, startTime, "scavengeOldFileVersions")
+
+	// Retention of file versions is disabled - keep all versions.
+	retain := int(retainedFileVersions.Load())
+	if retain <= 0 {
+		return nil
+	}
+	p.countHeldFiles(queryCountHeldOldFileVersions, retain)
+
+	err := p.scavenge(scavengerPhaseOldFileVersions, &p.run.OldFileVersions,
+		func() (int64, error) {
+			return countScavengeableRows(queryCountOldFileVersions, retain)
+		},
+		func(batchSize int) (int64, error) {
+			return deleteOldFileVersions(retain, batchSize)
+		})
+	if err != nil && !errors.Is(err, ErrNotLeader) &&
+		!errors.Is(err, errTimeBudgetExhausted) {
+		fsLogger.Error("Failed to scavenge old file versions!",
 			zap.Error(err),
 		)
-		metrics.MetricScavengeExpiredFileFailures.Inc()
-		return err
 	}
+	return err
+}
 
-	return nil
+// ////////////////////////  Phase 3 scavenge  /////////////////////////////////
+// In this phase, file audit events recorded before the configured retention
+// period are deleted from the file_audit_events table.
+// /////////////////////////////////////////////////////////////////////////////
+func scavengeExpiredAuditEvents(p *scavengerPass) error {
+	startTime := time.Now()
+	defer common.TimeIt(fsLogger, startTime, "scavengeExpiredAuditEvents")
+
+	// Retention of audit events is disabled - keep all audit events.
+	if auditRetentionDays.Load() <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -int(auditRetentionDays.Load()))
+	err := p.scavenge(scavengerPhaseAuditEvents, &p.run.AuditEvents,
+		func() (int64, error) {
+			return countScavengeableRows(queryCountExpiredAuditEvents, cutoff)
+		},
+		func(batchSize int) (int64, error) {
+			return deleteExpiredAuditEvents(cutoff, batchSize)
+		})
+	if err != nil && !errors.Is(err, ErrNotLeader) &&
+		!errors.Is(err, errTimeBudgetExhausted) {
+		fsLogger.Error("Failed to scavenge expired audit events!",
+			zap.Error(err),
+		)
+		metrics.MetricScavengeAuditEventFailures.Inc()
+	}
+	return err
 }
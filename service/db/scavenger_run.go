@@ -0,0 +1,231 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Events that trigger a scavenger run.
+const (
+	ScavengerTriggerSchedule = "schedule"
+	ScavengerTriggerRequest  = "request"
+)
+
+// Status of a scavenger run.
+const (
+	// The run is waiting for this node to be elected leader.
+	ScavengerRunPending = "pending"
+
+	// The run is in progress on the leader.
+	ScavengerRunRunning = "running"
+
+	// Every phase of the run has completed.
+	ScavengerRunCompleted = "completed"
+
+	// The run stopped before it was done because its time budget was
+	// exhausted. The remaining rows are deleted by the next run.
+	ScavengerRunIncomplete = "incomplete"
+
+	// The run stopped because the leader lease was lost or the service was
+	// shut down.
+	ScavengerRunInterrupted = "interrupted"
+
+	// A phase of the run failed.
+	ScavengerRunFailed = "failed"
+
+	// The run never started because this node could not be elected leader.
+	ScavengerRunAbandoned = "abandoned"
+)
+
+const (
+	// Maximum length of the error recorded for a scavenger run.
+	maxScavengerRunErrorLength = 256
+
+	// Database operations.
+	operationDbCreateScavengerRun = "CreateScavengerRun"
+	operationDbUpdateScavengerRun = "UpdateScavengerRun"
+	operationDbGetScavengerRun    = "GetScavengerRun"
+)
+
+// Represents a run of the database scavenger and its progress. In a dry run,
+// the counts are the number of rows that would have been deleted.
+type ScavengerRun struct {
+	// The unique identifier assigned to the run.
+	RunID uint64
+
+	// What triggered the run (schedule or request).
+	Trigger string
+
+	// Whether the run only counts the rows it would delete.
+	DryRun bool
+
+	// Status of the run.
+	Status string
+
+	// Node that was elected leader for the run, and its fencing token.
+	NodeID       string
+	FencingToken int64
+
+	// When the run was requested, started and completed.
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+
+	// Number of rows deleted by each phase of the run.
+	ExpiredFiles    int64
+	OldFileVersions int64
+	AuditEvents     int64
+
+	// Number of batches of rows deleted.
+	Batches int
+
+	// Number of files that were not deleted because they are under legal
+	// hold.
+	HeldFiles int64
+
+	// Reason the run failed, if it did.
+	Error string
+}
+
+// Returns true once the run has stopped.
+func (r *ScavengerRun) isDone() bool {
+	return r.Status != ScavengerRunPending && r.Status != ScavengerRunRunning
+}
+
+// Record a new scavenger run, pending the election of this node as leader.
+func createScavengerRun(ctx context.Context, requestID string, trigger string,
+	dryRun bool) (*ScavengerRun, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbCreateScavengerRun)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbCreateScavengerRun)
+
+	run := ScavengerRun{
+		Trigger: trigger,
+		DryRun:  dryRun,
+		Status:  ScavengerRunPending,
+	}
+	err := gDbPool.QueryRow(ctx, queryInsertScavengerRun, trigger, dryRun,
+		run.Status).Scan(&run.RunID, &run.CreatedAt)
+	if err != nil {
+		fsLogger.Error("Failed to record the scavenger run in the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return &run, nil
+}
+
+// Record that the specified run was started by the leader.
+func startScavengerRun(run *ScavengerRun, leader *leadership) error {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbUpdateScavengerRun)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbUpdateScavengerRun)
+
+	run.Status = ScavengerRunRunning
+	run.NodeID = leaderNodeID
+	run.FencingToken = leader.fencingToken
+	err := gDbPool.QueryRow(ctx, queryStartScavengerRun, run.RunID, run.Status,
+		run.NodeID, run.FencingToken).Scan(&run.StartedAt)
+	if err != nil {
+		fsLogger.Error("Failed to record the start of the scavenger run!",
+			zap.Uint64("Run ID:", run.RunID),
+			zap.Error(err),
+		)
+		return ErrInternalError
+	}
+	return nil
+}
+
+// Record the progress of the specified run, or its outcome once it is done.
+// Failures are logged, and the progress is recorded again after the next
+// batch.
+func updateScavengerRun(run *ScavengerRun) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbUpdateScavengerRun)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbUpdateScavengerRun)
+
+	if len(run.Error) > maxScavengerRunErrorLength {
+		run.Error = run.Error[:maxScavengerRunErrorLength]
+	}
+	_, err := gDbPool.Exec(ctx, queryUpdateScavengerRun, run.RunID, run.Status,
+		run.ExpiredFiles, run.OldFileVersions, run.AuditEvents, run.Batches,
+		run.Error, run.isDone(), run.HeldFiles)
+	if err != nil {
+		fsLogger.Error("Failed to record the progress of the scavenger run!",
+			zap.Uint64("Run ID:", run.RunID),
+			zap.String("Status:", run.Status),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetScavengerRun returns the scavenger run with the specified identifier.
+func GetScavengerRun(ctx context.Context, requestID string,
+	runID uint64) (*ScavengerRun, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetScavengerRun)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetScavengerRun)
+
+	var run ScavengerRun
+	err := gDbPool.QueryRow(ctx, queryScavengerRunByID, runID).Scan(&run.RunID,
+		&run.Trigger, &run.DryRun, &run.Status, &run.NodeID,
+		&run.FencingToken, &run.CreatedAt, &run.StartedAt, &run.CompletedAt,
+		&run.ExpiredFiles, &run.OldFileVersions, &run.AuditEvents,
+		&run.Batches, &run.Error, &run.HeldFiles)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		fsLogger.Error("Failed to get the scavenger run from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("Run ID:", runID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	return &run, nil
+}
+
+// Returns true if a scheduled scavenger run was started since the specified
+// time, by this node or another.
+func hasScheduledRunSince(since time.Time) (bool, error) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(),
+		operationDbGetScavengerRun)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetScavengerRun)
+
+	var found bool
+	err := gDbPool.QueryRow(ctx, queryScheduledScavengerRunSince,
+		ScavengerTriggerSchedule, since).Scan(&found)
+	return found, err
+}
@@ -0,0 +1,2 @@
+-- rollback purge jobs table introduced by version 10
+DROP TABLE IF EXISTS purge_jobs;
@@ -0,0 +1,29 @@
+-- Create the purge jobs table. A purge job deletes all files of a tenant, or
+-- of a single device of the tenant if device_id is not empty, along with the
+-- objects stored for them. Jobs are claimed by a node of the service, which
+-- records its progress after every batch, so that jobs interrupted by a
+-- restart are resumed by the next node to claim them.
+CREATE TABLE purge_jobs
+(
+  job_id BIGSERIAL NOT NULL,
+  tenant_id VARCHAR(36) NOT NULL,
+  device_id VARCHAR(36) NOT NULL DEFAULT '',
+  status VARCHAR(16) NOT NULL,
+  phase VARCHAR(16) NOT NULL,
+  node_id VARCHAR(128) NOT NULL DEFAULT '',
+  claim_token BIGINT NOT NULL DEFAULT 0,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  files_deleted BIGINT NOT NULL DEFAULT 0,
+  objects_deleted BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  started_at TIMESTAMP NULL,
+  heartbeat_at TIMESTAMP NULL,
+  completed_at TIMESTAMP NULL,
+  error VARCHAR(256) NOT NULL DEFAULT '',
+  PRIMARY KEY(job_id)
+);
+
+-- Only one job may be active for a tenant or device at a time.
+CREATE UNIQUE INDEX idx_purge_jobs_active ON purge_jobs(tenant_id, device_id)
+  WHERE status IN ('pending', 'running');
+
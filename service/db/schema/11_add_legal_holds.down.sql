@@ -0,0 +1,4 @@
+-- rollback legal holds table introduced by version 11
+ALTER TABLE purge_jobs DROP COLUMN IF EXISTS held_files;
+ALTER TABLE scavenger_runs DROP COLUMN IF EXISTS held_files;
+DROP TABLE IF EXISTS legal_holds;
@@ -0,0 +1,23 @@
+-- Create the legal holds table. A legal hold prevents files from being
+-- deleted, whether by request, by the DB scavenger or by purge jobs. A hold
+-- applies to all files of a tenant if device_id is empty, to all files of a
+-- device if file_id is zero, or otherwise to a single file.
+CREATE TABLE legal_holds
+(
+  hold_id BIGSERIAL NOT NULL,
+  tenant_id VARCHAR(36) NOT NULL,
+  device_id VARCHAR(36) NOT NULL DEFAULT '',
+  file_id BIGINT NOT NULL DEFAULT 0,
+  reason VARCHAR(256) NOT NULL,
+  actor VARCHAR(128) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(hold_id)
+);
+
+CREATE UNIQUE INDEX idx_legal_holds_scope
+  ON legal_holds(tenant_id, device_id, file_id);
+
+-- Record the number of files skipped by scavenger runs and purge jobs
+-- because they are under legal hold.
+ALTER TABLE scavenger_runs ADD COLUMN held_files BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE purge_jobs ADD COLUMN held_files BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- rollback file event notifications introduced by version 12
+DROP TRIGGER IF EXISTS trg_files_status_changed ON files;
+DROP TRIGGER IF EXISTS trg_files_created_deleted ON files;
+DROP FUNCTION IF EXISTS notify_file_event();
@@ -0,0 +1,51 @@
+-- Publish an event on the file_events notification channel when a file is
+-- created, its status changes or it is deleted. Events are delivered to
+-- listeners when the transaction that changed the file commits. Timestamps
+-- are reported in RFC 3339 format and, like all timestamps of the files
+-- table, are interpreted as UTC.
+CREATE OR REPLACE FUNCTION notify_file_event() RETURNS TRIGGER AS $$
+DECLARE
+  event_type TEXT;
+  f files;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    event_type := 'created';
+    f := NEW;
+  ELSIF TG_OP = 'UPDATE' THEN
+    event_type := 'status_changed';
+    f := NEW;
+  ELSE
+    event_type := 'deleted';
+    f := OLD;
+  END IF;
+
+  PERFORM pg_notify('file_events', json_build_object(
+    'type', event_type,
+    'occurred_at', to_char(now() AT TIME ZONE 'UTC',
+      'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+    'file', json_build_object(
+      'file_id', f.file_id,
+      'tenant_id', f.tenant_id,
+      'device_id', f.device_id,
+      'bucket_name', f.bucket_name,
+      'name', f.name,
+      'namespace', f.namespace,
+      'version', f.version,
+      'checksum', f.checksum,
+      'size', COALESCE(f.size, 0),
+      'status', f.status,
+      'created_at', to_char(f.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+      'updated_at', to_char(f.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+    ))::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_files_created_deleted
+  AFTER INSERT OR DELETE ON files
+  FOR EACH ROW EXECUTE FUNCTION notify_file_event();
+
+CREATE TRIGGER trg_files_status_changed
+  AFTER UPDATE OF status ON files
+  FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+  EXECUTE FUNCTION notify_file_event();
@@ -0,0 +1,2 @@
+-- rollback file uploads table introduced by version 13
+DROP TABLE IF EXISTS file_uploads;
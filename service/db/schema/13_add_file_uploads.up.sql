@@ -0,0 +1,23 @@
+-- Create the file uploads table. Files uploaded using the tus resumable upload
+-- protocol are stored as S3 multipart uploads, with a part for each chunk
+-- received. The offset reached by the upload, the ETags of the uploaded parts
+-- and the state of the MD5 digest of the received bytes are recorded so that
+-- the upload can be resumed by any node. A chunk is appended by the request
+-- holding the claim on the upload, which lapses if the request does not
+-- complete within the claim timeout.
+CREATE TABLE file_uploads
+(
+  file_id BIGINT NOT NULL,
+  upload_id VARCHAR(1024) NOT NULL,
+  upload_offset BIGINT NOT NULL DEFAULT 0,
+  part_etags TEXT[] NOT NULL DEFAULT '{}',
+  checksum_state BYTEA,
+  claim_token BIGINT NOT NULL DEFAULT 0,
+  claimed_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(file_id),
+  CONSTRAINT fk_file
+    FOREIGN KEY(file_id)
+      REFERENCES files(file_id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- rollback file versioning introduced by version 3
+DROP TABLE IF EXISTS file_names;
+DROP INDEX IF EXISTS idx_files_name_version;
+ALTER TABLE files DROP COLUMN IF EXISTS version;
+ALTER TABLE files DROP COLUMN IF EXISTS namespace;
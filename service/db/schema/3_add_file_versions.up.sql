@@ -0,0 +1,51 @@
+-- Add logical file keys and versions to the files table. A logical file
+-- is identified by (tenant_id, device_id, namespace, name) and every file
+-- created under that key is assigned the next version number.
+ALTER TABLE files ADD COLUMN namespace VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE files ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- Number the versions of files that were created before versioning was
+-- introduced in creation order.
+UPDATE files SET version = v.rn
+FROM (
+  SELECT file_id, ROW_NUMBER() OVER (
+    PARTITION BY tenant_id, device_id, namespace, name ORDER BY file_id) AS rn
+  FROM files
+) v
+WHERE files.file_id = v.file_id;
+
+CREATE UNIQUE INDEX idx_files_name_version
+  ON files(tenant_id, device_id, namespace, name, version);
+
+-- Create the file names table. It tracks the last allocated version for each
+-- logical file and points to the newest version that has been uploaded.
+CREATE TABLE file_names
+(
+  tenant_id VARCHAR(36) NOT NULL,
+  device_id VARCHAR(36) NOT NULL,
+  namespace VARCHAR(64) NOT NULL DEFAULT '',
+  name VARCHAR(128) NOT NULL,
+  last_version BIGINT NOT NULL DEFAULT 0,
+  latest_file_id BIGINT NULL,
+  latest_version BIGINT NULL,
+  created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(tenant_id, device_id, namespace, name),
+  CONSTRAINT fk_latest_file
+    FOREIGN KEY(latest_file_id)
+      REFERENCES files(file_id) ON DELETE SET NULL
+);
+
+INSERT INTO file_names(tenant_id, device_id, namespace, name, last_version,
+  latest_file_id, latest_version)
+SELECT f.tenant_id, f.device_id, f.namespace, f.name, MAX(f.version),
+  (SELECT u.file_id FROM files u WHERE u.tenant_id = f.tenant_id AND
+    u.device_id = f.device_id AND u.namespace = f.namespace AND
+    u.name = f.name AND u.status = 'uploaded'
+    ORDER BY u.version DESC LIMIT 1),
+  (SELECT u.version FROM files u WHERE u.tenant_id = f.tenant_id AND
+    u.device_id = f.device_id AND u.namespace = f.namespace AND
+    u.name = f.name AND u.status = 'uploaded'
+    ORDER BY u.version DESC LIMIT 1)
+FROM files f
+GROUP BY f.tenant_id, f.device_id, f.namespace, f.name;
@@ -0,0 +1,2 @@
+-- rollback file scans table introduced by version 4
+DROP TABLE IF EXISTS file_scans;
@@ -0,0 +1,18 @@
+-- Create the file scans table. Every verdict reported by the malware
+-- scanning pipeline, and every administrative action taken on the scan
+-- status of a file, is recorded in this table.
+CREATE TABLE file_scans
+(
+  scan_id BIGSERIAL NOT NULL,
+  file_id BIGINT NOT NULL,
+  scanner VARCHAR(64) NOT NULL,
+  verdict VARCHAR(16) NOT NULL,
+  reason VARCHAR(256) NOT NULL DEFAULT '',
+  scanned_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(scan_id),
+  CONSTRAINT fk_scanned_file
+    FOREIGN KEY(file_id)
+      REFERENCES files(file_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_file_scans_file_id ON file_scans(file_id);
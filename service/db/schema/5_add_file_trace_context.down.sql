@@ -0,0 +1,2 @@
+-- rollback trace context column introduced by version 5
+ALTER TABLE files DROP COLUMN IF EXISTS trace_parent;
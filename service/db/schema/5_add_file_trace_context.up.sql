@@ -0,0 +1,3 @@
+-- Record the W3C trace context of the request that created each file, so
+-- that the upload notification for the file can be linked back to it.
+ALTER TABLE files ADD COLUMN trace_parent VARCHAR(64) NOT NULL DEFAULT '';
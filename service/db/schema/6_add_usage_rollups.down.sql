@@ -0,0 +1,2 @@
+-- rollback usage rollups table introduced by version 6
+DROP TABLE IF EXISTS usage_rollups;
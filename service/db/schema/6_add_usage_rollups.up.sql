@@ -0,0 +1,16 @@
+-- Create the usage rollups table. Usage of the service is aggregated per
+-- tenant, device and day.
+CREATE TABLE usage_rollups
+(
+  tenant_id VARCHAR(36) NOT NULL,
+  device_id VARCHAR(36) NOT NULL,
+  usage_date DATE NOT NULL,
+  bytes_uploaded BIGINT NOT NULL DEFAULT 0,
+  files_created BIGINT NOT NULL DEFAULT 0,
+  downloads_issued BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(tenant_id, usage_date, device_id)
+);
+
+-- Create an index to enable queries for the usage of all tenants on a day.
+CREATE INDEX idx_usage_rollups_usage_date ON usage_rollups(usage_date);
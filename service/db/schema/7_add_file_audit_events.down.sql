@@ -0,0 +1,2 @@
+-- rollback file audit events table introduced by version 7
+DROP TABLE IF EXISTS file_audit_events;
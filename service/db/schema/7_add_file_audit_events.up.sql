@@ -0,0 +1,22 @@
+-- Create the file audit events table. Every access to a file and every
+-- administrative action taken on files is recorded in this append-only
+-- table. Events are retained after the file they refer to is deleted.
+CREATE TABLE file_audit_events
+(
+  event_id BIGSERIAL NOT NULL,
+  occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  tenant_id VARCHAR(36) NOT NULL DEFAULT '',
+  actor VARCHAR(64) NOT NULL DEFAULT '',
+  action VARCHAR(16) NOT NULL,
+  file_id BIGINT NULL,
+  request_id VARCHAR(64) NOT NULL DEFAULT '',
+  source_ip VARCHAR(64) NOT NULL DEFAULT '',
+  outcome VARCHAR(16) NOT NULL,
+  PRIMARY KEY(event_id)
+);
+
+-- Create indexes to enable queries for audit events by tenant and by file
+-- over a time range, and for expired audit events by the DB scavenger.
+CREATE INDEX idx_file_audit_events_tenant_id ON file_audit_events(tenant_id, occurred_at);
+CREATE INDEX idx_file_audit_events_file_id ON file_audit_events(file_id, occurred_at);
+CREATE INDEX idx_file_audit_events_occurred_at ON file_audit_events(occurred_at);
@@ -0,0 +1,2 @@
+-- rollback leader leases table introduced by version 8
+DROP TABLE IF EXISTS leader_leases;
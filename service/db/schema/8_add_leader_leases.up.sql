@@ -0,0 +1,13 @@
+-- Create the leader leases table. When the file cache is disabled, the leader
+-- among the nodes of the service is elected using a Postgres advisory lock,
+-- and the lease of the current leader is recorded in this table along with the
+-- fencing token issued to it. Fencing tokens increase with every new leader.
+CREATE TABLE leader_leases
+(
+  name VARCHAR(64) NOT NULL,
+  holder VARCHAR(128) NOT NULL,
+  fencing_token BIGINT NOT NULL,
+  acquired_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  renewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(name)
+);
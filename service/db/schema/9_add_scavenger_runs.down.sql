@@ -0,0 +1,2 @@
+-- rollback scavenger runs table introduced by version 9
+DROP TABLE IF EXISTS scavenger_runs;
@@ -0,0 +1,24 @@
+-- Create the scavenger runs table. Each run of the DB scavenger, whether
+-- scheduled or requested, is recorded along with its progress, so that the
+-- status of a run can be reported by any node of the service.
+CREATE TABLE scavenger_runs
+(
+  run_id BIGSERIAL NOT NULL,
+  trigger VARCHAR(16) NOT NULL,
+  dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+  status VARCHAR(16) NOT NULL,
+  node_id VARCHAR(128) NOT NULL DEFAULT '',
+  fencing_token BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  started_at TIMESTAMP NULL,
+  completed_at TIMESTAMP NULL,
+  expired_files BIGINT NOT NULL DEFAULT 0,
+  old_file_versions BIGINT NOT NULL DEFAULT 0,
+  audit_events BIGINT NOT NULL DEFAULT 0,
+  batches INTEGER NOT NULL DEFAULT 0,
+  error VARCHAR(256) NOT NULL DEFAULT '',
+  PRIMARY KEY(run_id)
+);
+
+-- Create an index to find the scheduled runs started since a given time.
+CREATE INDEX idx_scavenger_runs_started_at ON scavenger_runs(trigger, started_at);
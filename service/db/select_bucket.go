@@ -5,6 +5,7 @@ package db
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -20,20 +21,31 @@ var (
 	fsBucketQueue  bucketQueue
 	queueEnabled   bool
 	soleBucketName string
+
+	// Protects the bucket selector, which is replaced when the configured
+	// buckets are changed.
+	bucketSelectorLock sync.RWMutex
 )
 
 // Initialize a queue of bucket names - the buckets in this queue are used in a
 // round-robin fashion to create new files by the FS service.
 func initBucketSelector(bucketNames *[]string) error {
-	var (
-		b           Bucket
-		bucketCount int
-		err         error
-	)
-
-	// Add all buckets referenced in configuration to the database. Ignore
-	// errors for buckets that already exist (i.e. duplicates).
-	for _, bucket := range *bucketNames {
+	addConfiguredBuckets(*bucketNames)
+	return loadBucketSelector()
+}
+
+// Add newly configured buckets to the database and replace the bucket
+// selector, so that new files are also stored within the new buckets. The
+// current bucket selector is retained if the buckets cannot be listed.
+func updateBucketSelector(bucketNames []string) error {
+	addConfiguredBuckets(bucketNames)
+	return loadBucketSelector()
+}
+
+// Add all buckets referenced in configuration to the database. Ignore errors
+// for buckets that already exist (i.e. duplicates).
+func addConfiguredBuckets(bucketNames []string) {
+	for _, bucket := range bucketNames {
 		newBucket := Bucket{
 			BucketName: bucket,
 			IsArchived: false,
@@ -41,7 +53,7 @@ func initBucketSelector(bucketNames *[]string) error {
 			UpdatedAt:  time.Now(),
 		}
 
-		err = newBucket.AddBucketIfNotExists()
+		err := newBucket.AddBucketIfNotExists()
 		if err != nil {
 			// Ignore errors caused by the presence of duplicate buckets.
 			if err == ErrDuplicateEntry {
@@ -53,6 +65,12 @@ func initBucketSelector(bucketNames *[]string) error {
 			)
 		}
 	}
+}
+
+// Load the buckets configured in the database into a new bucket selector and
+// start using it to select buckets for new files.
+func loadBucketSelector() error {
+	var b Bucket
 
 	// List all buckets currently configured in the database.
 	configuredBuckets, err := b.ListBuckets()
@@ -63,58 +81,75 @@ func initBucketSelector(bucketNames *[]string) error {
 		return err
 	}
 
-	// If no non-archived buckets are available for consumption, fail init.
-	bucketCount = len(*configuredBuckets)
-	switch bucketCount {
+	var activeBuckets []string
+	for _, item := range *configuredBuckets {
+		if !item.IsArchived {
+			activeBuckets = append(activeBuckets, item.BucketName)
+		}
+	}
+
+	// If no non-archived buckets are available for consumption, fail.
+	switch len(activeBuckets) {
 	case 0:
 		fsLogger.Error("No buckets have been configured for the service! Cannot continue")
 		return ErrNoBuckets
 
 	case 1:
-		if (*configuredBuckets)[0].IsArchived {
-			fsLogger.Error("No buckets have been configured for the service! Cannot continue")
-			return ErrNoBuckets
-		}
+		bucketSelectorLock.Lock()
+		previousQueue, previousQueueEnabled := fsBucketQueue, queueEnabled
 		queueEnabled = false
-		soleBucketName = (*configuredBuckets)[0].BucketName
+		soleBucketName = activeBuckets[0]
+		bucketSelectorLock.Unlock()
+
+		if previousQueueEnabled {
+			previousQueue.cancelFunc()
+		}
 		return nil
 
 	default:
-		// Initialize the bucket queue.
-		fsBucketQueue.ctx, fsBucketQueue.cancelFunc = context.WithCancel(
-			context.Background())
-		fsBucketQueue.buckets = make(chan string, len(*configuredBuckets))
+		// Initialize the bucket queue and add all non-archived buckets to it.
+		var queue bucketQueue
+		queue.ctx, queue.cancelFunc = context.WithCancel(context.Background())
+		queue.buckets = make(chan string, len(activeBuckets))
+		for _, bucketName := range activeBuckets {
+			queue.enqueueBucket(bucketName)
+		}
+
+		bucketSelectorLock.Lock()
+		previousQueue, previousQueueEnabled := fsBucketQueue, queueEnabled
+		fsBucketQueue = queue
 		queueEnabled = true
+		bucketSelectorLock.Unlock()
 
-		// Add all non-archived buckets to the queue.
-		for _, item := range *configuredBuckets {
-			if !item.IsArchived {
-				enqueueBucket(item.BucketName)
-			}
+		if previousQueueEnabled {
+			previousQueue.cancelFunc()
 		}
 		return nil
 	}
 }
 
 func shutdownBucketSelector() {
+	bucketSelectorLock.RLock()
+	defer bucketSelectorLock.RUnlock()
+
 	if queueEnabled {
 		fsBucketQueue.cancelFunc()
 	}
 }
 
 // Add the specified bucket name back to the queue.
-func enqueueBucket(bucketName string) {
-	if fsBucketQueue.ctx.Err() == nil {
-		fsBucketQueue.buckets <- bucketName
+func (q *bucketQueue) enqueueBucket(bucketName string) {
+	if q.ctx.Err() == nil {
+		q.buckets <- bucketName
 	}
 }
 
 // Get the next candidate bucket name from the queue.
-func dequeueBucket() string {
+func (q *bucketQueue) dequeueBucket() string {
 	var bucketName string
 
-	if fsBucketQueue.ctx.Err() == nil {
-		bucketName = <-fsBucketQueue.buckets
+	if q.ctx.Err() == nil {
+		bucketName = <-q.buckets
 	}
 	return bucketName
 }
@@ -122,11 +157,14 @@ func dequeueBucket() string {
 // Return the next candidate bucket from the queue that has been selected to
 // create the file.
 func selectBucket() string {
+	bucketSelectorLock.RLock()
+	defer bucketSelectorLock.RUnlock()
+
 	if !queueEnabled {
 		return soleBucketName
 	}
 
-	bucketName := dequeueBucket()
-	enqueueBucket(bucketName)
+	bucketName := fsBucketQueue.dequeueBucket()
+	fsBucketQueue.enqueueBucket(bucketName)
 	return bucketName
 }
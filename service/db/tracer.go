@@ -0,0 +1,53 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const spanDbQuery = "db.query"
+
+// queryTracer creates a span for every query issued to the files database.
+// The span is a child of the span in the context used to issue the query.
+type queryTracer struct{}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn,
+	data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.StartClientSpan(ctx, spanDbQuery,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", data.SQL),
+	)
+	return ctx
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn,
+	data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected",
+		data.CommandTag.RowsAffected()))
+	tracing.EndSpan(span, data.Err)
+}
+
+// Starts a span for the specified database operation and returns a context,
+// bounded by the database operation timeout, in which the queries for the
+// operation are issued. Database operations are not cancelled along with the
+// caller's context. The returned function must be called to release resources
+// and end the span once the operation completes.
+func startOperation(ctx context.Context,
+	operation string) (context.Context, context.CancelFunc) {
+	ctx, span := tracing.StartSpan(ctx, operation,
+		attribute.String("db.operation", operation))
+	ctx, cancelFunc := context.WithTimeout(context.WithoutCancel(ctx),
+		dbOperationTimeout)
+	return ctx, func() {
+		cancelFunc()
+		span.End()
+	}
+}
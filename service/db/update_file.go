@@ -4,21 +4,31 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/cache"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
-	"golang.org/x/net/context"
 )
 
+// Statuses from which files may be quarantined.
+var quarantineAllowedFrom = []string{FileStatusNew, FileStatusScanPending,
+	FileStatusUploaded}
+
 // mark status as uploaded. update size with incoming size
+// if a scan is specified, the scan verdict is recorded along with the status
+// the status is only changed from one of the allowed statuses. notifications
+// for files in other statuses, such as late or redelivered notifications, are
+// ignored.
 // return nil on success
 // return err on error
-func updateFileStatus(id, status string, size int64) error {
+func updateFileStatus(ctx context.Context, id, status string, size int64,
+	allowedFrom []string, scan *FileScan) error {
 	// Check the parameters
 	fileID, err := strconv.ParseUint(id, 10, 64)
 	if err != nil {
@@ -30,7 +40,7 @@ func updateFileStatus(id, status string, size int64) error {
 
 	start := time.Now()
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), dbOperationTimeout)
+	ctx, cancelFunc := startOperation(ctx, operationDbUpdateFile)
 	defer cancelFunc()
 	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
 		operationDbUpdateFile)
@@ -43,18 +53,41 @@ func updateFileStatus(id, status string, size int64) error {
 		return err
 	}
 
-	var retFileID uint64
-	response := tx.QueryRow(ctx, queryUpdateFileStatus, fileID, size, status)
-	err = response.Scan(&retFileID)
+	var updatedFile File
+	var traceParent, previousStatus string
+	var held bool
+	response := tx.QueryRow(ctx, queryUpdateFileStatus, fileID, size, status,
+		allowedFrom)
+	err = response.Scan(&updatedFile.FileID, &updatedFile.TenantID,
+		&updatedFile.DeviceID, &updatedFile.Namespace, &updatedFile.Name,
+		&updatedFile.Version, &updatedFile.UpdatedAt, &traceParent,
+		&previousStatus, &updatedFile.BucketName, &held)
 	if err != nil {
-		rollback(tx, ctx)
 		if errors.Is(err, pgx.ErrNoRows) {
-			fsLogger.Error("File with the specified file ID was not found in the database!",
-				zap.Uint64("File ID: ", fileID),
-				zap.Error(err),
-			)
-			metrics.MetricDatabaseFileNotFoundErrors.Inc()
-			return ErrNotFound
+			// Either the file does not exist or its status does not allow
+			// the change, which is then ignored.
+			var currentStatus string
+			err = tx.QueryRow(ctx, queryFileStatusByID, fileID).Scan(&currentStatus)
+			rollback(tx, ctx)
+			if err == nil {
+				fsLogger.Info("Ignoring a status change not allowed for the file",
+					zap.Uint64("File ID: ", fileID),
+					tracing.TraceID(ctx),
+					zap.String("Current status: ", currentStatus),
+					zap.String("Requested status: ", status),
+				)
+				return nil
+			}
+			if errors.Is(err, pgx.ErrNoRows) {
+				fsLogger.Error("File with the specified file ID was not found in the database!",
+					zap.Uint64("File ID: ", fileID),
+					zap.Error(err),
+				)
+				metrics.MetricDatabaseFileNotFoundErrors.Inc()
+				return ErrNotFound
+			}
+		} else {
+			rollback(tx, ctx)
 		}
 
 		fsLogger.Error("Failed to update the file in the database!",
@@ -64,22 +97,142 @@ func updateFileStatus(id, status string, size int64) error {
 		return ErrInternalError
 	}
 
+	// Link the status change to the request that created the file.
+	tracing.AddLink(ctx, traceParent)
+
+	err = recordStatusChange(ctx, tx, &updatedFile, status, scan)
+	if err != nil {
+		rollback(tx, ctx)
+		metrics.MetricDatabaseUpdateFileFailures.Inc()
+		return ErrInternalError
+	}
+
+	// The first notification received for a new file indicates that the file
+	// was uploaded to storage. Record the upload in the usage rollup for the
+	// tenant. Notifications that are redelivered are not counted again.
+	var usage Usage
+	if previousStatus == FileStatusNew && status != FileStatusNew {
+		usage.BytesUploaded = size
+		err = recordUsage(ctx, tx, updatedFile.TenantID, updatedFile.DeviceID,
+			usage)
+		if err != nil {
+			rollback(tx, ctx)
+			metrics.MetricDatabaseUpdateFileFailures.Inc()
+			return ErrInternalError
+		}
+	}
+
 	commit(tx, ctx)
+	reportUsage(usage)
 	metrics.MetricDatabaseFilesUpdated.Inc()
 
-	// Remove the cache entry on a separate goroutine. The next subsequent
-	// read of this file will refresh the cache entry.
-	go cache.RemoveFile("", fileID)
+	// Record the quarantine of the file by the malware scanner in the file
+	// audit log.
+	if scan != nil && scan.Verdict == ScanVerdictQuarantined {
+		RecordAuditEvent(AuditEvent{
+			TenantID: updatedFile.TenantID,
+			Actor:    scan.Scanner,
+			Action:   AuditActionQuarantine,
+			FileID:   updatedFile.FileID,
+			Outcome:  AuditOutcomeSuccess,
+		})
+	}
+
+	// Invalidate the cache entry and the cached lists of files of the device
+	// before returning, so that the change is visible to subsequent reads.
+	// The next subsequent read of this file will refresh the cache entry.
+	cache.InvalidateFile(ctx, "", fileID, updatedFile.UpdatedAt)
+	cache.InvalidateFileList(ctx, "", updatedFile.TenantID, updatedFile.DeviceID)
+
+	// The object of a file under legal hold is held once it has been stored.
+	if held && previousStatus == FileStatusNew && status != FileStatusNew {
+		MirrorObjectLegalHold(ctx, "", &updatedFile, true)
+	}
 
 	return nil
 }
 
-// mark status = uploaded
-func MarkFileUploaded(id string, size int64) error {
-	return updateFileStatus(id, FileStatusUploaded, size)
+// Records the side effects of changing the status of a file within the
+// specified transaction. The scan verdict, if any, is added to the file scans
+// table and the logical file is pointed at newly uploaded versions.
+func recordStatusChange(ctx context.Context, tx pgx.Tx, updatedFile *File,
+	status string, scan *FileScan) error {
+	if scan != nil {
+		_, err := tx.Exec(ctx, queryInsertFileScan, updatedFile.FileID,
+			scan.Scanner, scan.Verdict, scan.Reason)
+		if err != nil {
+			fsLogger.Error("Failed to record the scan verdict in the database!",
+				zap.Uint64("File ID: ", updatedFile.FileID),
+				zap.String("Verdict: ", scan.Verdict),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+
+	// Once a version of the file has been uploaded, point the logical file at
+	// it unless a newer version has already been uploaded.
+	if status == FileStatusUploaded {
+		_, err := tx.Exec(ctx, queryUpdateLatestFileVersion, updatedFile.TenantID,
+			updatedFile.DeviceID, updatedFile.Namespace, updatedFile.Name,
+			updatedFile.FileID, updatedFile.Version)
+		if err != nil {
+			fsLogger.Error("Failed to update the latest version of the file in the database!",
+				zap.Uint64("File ID: ", updatedFile.FileID),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mark status = uploaded. Only new files are marked uploaded.
+func MarkFileUploaded(ctx context.Context, id string, size int64) error {
+	return updateFileStatus(ctx, id, FileStatusUploaded, size,
+		[]string{FileStatusNew}, nil)
 }
 
 // make status = quarantined
-func MarkFileQuarantined(id string, size int64) error {
-	return updateFileStatus(id, FileStatusQuarantined, size)
+func MarkFileQuarantined(ctx context.Context, id string, size int64) error {
+	return updateFileStatus(ctx, id, FileStatusQuarantined, size,
+		quarantineAllowedFrom, nil)
+}
+
+// mark status = scan_pending. The file has been uploaded to storage but the
+// malware scanning pipeline has not yet reported a verdict for it. Only new
+// files are marked pending a scan.
+func MarkFileScanPending(ctx context.Context, id string, size int64) error {
+	return updateFileStatus(ctx, id, FileStatusScanPending, size,
+		[]string{FileStatusNew}, nil)
+}
+
+// MarkFileScanned records the verdict reported by the specified scanner and
+// marks the file uploaded (clean) or quarantined accordingly. Quarantined
+// files are only released by an administrator, so a clean verdict does not
+// release them.
+func MarkFileScanned(ctx context.Context, id string, size int64, scanner string, verdict string) error {
+	var status string
+	var allowedFrom []string
+
+	switch verdict {
+	case ScanVerdictClean:
+		status = FileStatusUploaded
+		allowedFrom = []string{FileStatusNew, FileStatusScanPending}
+	case ScanVerdictQuarantined:
+		status = FileStatusQuarantined
+		allowedFrom = quarantineAllowedFrom
+	default:
+		fsLogger.Error("Unsupported scan verdict specified!",
+			zap.String("File ID: ", id),
+			zap.String("Verdict: ", verdict),
+		)
+		return ErrInvalidRequest
+	}
+
+	return updateFileStatus(ctx, id, status, size, allowedFrom, &FileScan{
+		Scanner: scanner,
+		Verdict: verdict,
+	})
 }
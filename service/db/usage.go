@@ -0,0 +1,256 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	// Number of tenants, with the highest usage for the current day, whose
+	// usage is reported as metrics.
+	topTenantUsageCount = 10
+
+	// Interval at which the usage metrics for the top tenants are refreshed.
+	topTenantUsageRefreshInterval = time.Minute * 5
+)
+
+var (
+	usageReporterCtx        context.Context
+	usageReporterCancelFunc context.CancelFunc
+	usageReporterDone       chan bool
+)
+
+// Usage of the service by a tenant (or a device within the tenant) on a day.
+type Usage struct {
+	// The day on which the usage was recorded.
+	Date time.Time `json:"date"`
+
+	// Number of bytes uploaded to storage.
+	BytesUploaded int64 `json:"bytes_uploaded"`
+
+	// Number of files created.
+	FilesCreated int64 `json:"files_created"`
+
+	// Number of download URLs issued.
+	DownloadsIssued int64 `json:"downloads_issued"`
+}
+
+// Usage of the service by a tenant on the current day.
+type TenantUsage struct {
+	TenantID string `json:"tenant_id"`
+	Usage
+}
+
+// executor is implemented by both the connection pool and transactions, so
+// that usage can be recorded as part of the operation that incurred it.
+type executor interface {
+	Exec(ctx context.Context, sql string,
+		arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Adds the specified usage to the usage rollup of the tenant and device for
+// the current day.
+func recordUsage(ctx context.Context, exec executor, tenantID, deviceID string,
+	usage Usage) error {
+	_, err := exec.Exec(ctx, queryRecordUsage, tenantID, deviceID,
+		usage.BytesUploaded, usage.FilesCreated, usage.DownloadsIssued)
+	if err != nil {
+		fsLogger.Error("Failed to record usage in the database!",
+			zap.String("Tenant ID: ", tenantID),
+			zap.String("Device ID: ", deviceID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		metrics.MetricUsageRecordFailures.Inc()
+		return err
+	}
+	return nil
+}
+
+// Reports the specified usage to the usage metrics. This must be called once
+// the operation that incurred the usage has been committed.
+func reportUsage(usage Usage) {
+	if usage.BytesUploaded > 0 {
+		metrics.MetricUsageBytesUploaded.Add(float64(usage.BytesUploaded))
+	}
+	if usage.FilesCreated > 0 {
+		metrics.MetricUsageFilesCreated.Add(float64(usage.FilesCreated))
+	}
+	if usage.DownloadsIssued > 0 {
+		metrics.MetricUsageDownloadsIssued.Add(float64(usage.DownloadsIssued))
+	}
+}
+
+// RecordDownload - record that a download URL was issued to the specified
+// device. Failures to record usage are not surfaced to the caller.
+func RecordDownload(ctx context.Context, requestID, tenantID, deviceID string) {
+	RecordDownloads(ctx, requestID, tenantID, deviceID, 1)
+}
+
+// RecordDownloads - record that the specified number of download URLs were
+// issued to the specified device. Failures to record usage are not surfaced
+// to the caller.
+func RecordDownloads(ctx context.Context, requestID, tenantID, deviceID string,
+	count int64) {
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbRecordUsage)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbRecordUsage)
+
+	usage := Usage{DownloadsIssued: count}
+	if recordUsage(ctx, gDbPool, tenantID, deviceID, usage) != nil {
+		fsLogger.Error("Failed to record the download in the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+		)
+		return
+	}
+	reportUsage(usage)
+}
+
+// GetTenantUsage - retrieve the daily usage of the specified tenant between
+// the specified days (inclusive). If a device is specified, only the usage of
+// that device is returned.
+func GetTenantUsage(ctx context.Context, requestID, tenantID, deviceID string,
+	from, to time.Time) ([]Usage, error) {
+	var usages []Usage
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(ctx, operationDbGetUsage)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetUsage)
+
+	response, err := gDbPool.Query(ctx, queryTenantUsage, tenantID, deviceID,
+		from, to)
+	if err != nil {
+		fsLogger.Error("Failed to get the usage of the tenant from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.String("Tenant ID: ", tenantID),
+			zap.Error(err),
+		)
+		return nil, ErrInternalError
+	}
+	defer response.Close()
+
+	for response.Next() {
+		var usage Usage
+		err = response.Scan(&usage.Date, &usage.BytesUploaded,
+			&usage.FilesCreated, &usage.DownloadsIssued)
+		if err != nil {
+			fsLogger.Error("Failed to get the usage of the tenant from the database!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(ctx),
+				zap.String("Tenant ID: ", tenantID),
+				zap.Error(err),
+			)
+			return nil, ErrInternalError
+		}
+		usages = append(usages, usage)
+	}
+
+	if response.Err() != nil {
+		fsLogger.Error("Failed reading the usage of the tenant from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Error(response.Err()),
+		)
+		return nil, ErrInternalError
+	}
+
+	return usages, nil
+}
+
+// Retrieve the usage for the current day of the tenants with the highest
+// usage.
+func getTopTenantUsage(limit int) ([]TenantUsage, error) {
+	var usages []TenantUsage
+	start := time.Now()
+
+	ctx, cancelFunc := startOperation(context.Background(), operationDbGetUsage)
+	defer cancelFunc()
+	defer metrics.ReportLatencyMetric(metrics.MetricDatabaseLatency, start,
+		operationDbGetUsage)
+
+	response, err := gDbPool.Query(ctx, queryTopTenantUsage, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Close()
+
+	for response.Next() {
+		var usage TenantUsage
+		err = response.Scan(&usage.TenantID, &usage.BytesUploaded,
+			&usage.FilesCreated, &usage.DownloadsIssued)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, response.Err()
+}
+
+// Start the periodic goroutine that reports the usage of the tenants with the
+// highest usage for the current day as metrics. Only a bounded number of
+// tenants are reported to bound the cardinality of the metrics.
+func startUsageReporter() {
+	usageReporterCtx, usageReporterCancelFunc = context.WithCancel(
+		context.Background())
+	usageReporterDone = make(chan bool, 1)
+	go runUsageReporter()
+}
+
+func runUsageReporter() {
+	ticker := time.NewTicker(topTenantUsageRefreshInterval)
+	for {
+		refreshTopTenantUsage()
+
+		select {
+		case <-ticker.C:
+			continue
+
+		case <-usageReporterCtx.Done():
+			fsLogger.Info("Usage reporter has received shutdown signal and is stopping!")
+			ticker.Stop()
+			usageReporterDone <- true
+			return
+		}
+	}
+}
+
+// Stop the usage reporter goroutine and wait for it to be done.
+func stopUsageReporter() {
+	if usageReporterCancelFunc != nil {
+		usageReporterCancelFunc()
+		<-usageReporterDone
+	}
+}
+
+func refreshTopTenantUsage() {
+	usages, err := getTopTenantUsage(topTenantUsageCount)
+	if err != nil {
+		fsLogger.Error("Failed to get the usage of the top tenants from the database!",
+			zap.Error(err),
+		)
+		return
+	}
+
+	// Tenants that are no longer amongst the top tenants are removed.
+	metrics.MetricUsageTopTenants.Reset()
+	for _, usage := range usages {
+		metrics.ReportTenantUsage(usage.TenantID, usage.BytesUploaded,
+			usage.FilesCreated, usage.DownloadsIssued)
+	}
+}
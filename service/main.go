@@ -11,6 +11,7 @@ import (
 	"github.com/HPInc/krypton-fs/service/notification"
 	"github.com/HPInc/krypton-fs/service/rest"
 	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
 )
 
 // main loads config, creates the servers and starts them if needed
@@ -21,22 +22,28 @@ func main() {
 
 	// Read and parse the configuration file.
 	if !config.Load(false) {
-		panic("config load failed.")
+		config.GetLogger().Fatal("Failed to load the configuration! Fix the problems reported above and restart the service.")
 	}
 
 	logger := config.GetLogger()
 	metrics.RegisterPrometheusMetrics()
 
+	// Initialize distributed tracing before any of the traced components.
+	logger.Info("Initializing tracing")
+	err := tracing.Init(logger, &config.Settings.Tracing)
+	if err != nil {
+		panic(err)
+	}
+
 	// Initialize the connection to the files database and connect to the
 	// files cache.
 	logger.Info("Initializing database")
-	err := db.Init(logger, &config.Settings.Database, &config.Settings.Cache,
+	err = db.Init(logger, &config.Settings.Database, &config.Settings.Cache,
 		&config.Settings.Storage.BucketNames)
 	if err != nil {
 		panic(err)
 	}
 	logger.Info("Database successfully initialized")
-	defer db.Shutdown()
 
 	// Initialize the connection to the storage system.
 	logger.Info("Initializing storage")
@@ -45,19 +52,68 @@ func main() {
 		panic(err)
 	}
 	logger.Info("Storage successfully initialized")
-	defer storage.Shutdown()
 
 	// Initialize notification queue for storage notifications.
 	logger.Info("Initializing notification")
-	err = notification.Init(&config.Settings.Notification, logger)
+	err = notification.Init(&config.Settings.Notification,
+		&config.Settings.Scanning, logger)
 	if err != nil {
 		panic(err)
 	}
 	logger.Info("Notification successfully initialized")
-	defer notification.Shutdown()
+
+	// Apply the settings that can be changed without restarting the service
+	// when the configuration is reloaded, on SIGHUP or when the configuration
+	// file changes. New buckets are verified before they are used.
+	config.RegisterReloadHandler(config.ReloadHandler{
+		Name: "storage",
+		Check: func(settings *config.Config) error {
+			return storage.CheckSettings(&settings.Storage)
+		},
+		Apply: func(settings *config.Config) error {
+			return storage.UpdateSettings(&settings.Storage)
+		},
+	})
+	config.RegisterReloadHandler(config.ReloadHandler{
+		Name: "database",
+		Apply: func(settings *config.Config) error {
+			return db.UpdateSettings(&settings.Database,
+				settings.Storage.BucketNames)
+		},
+	})
+	config.RegisterReloadHandler(config.ReloadHandler{
+		Name: "rest",
+		Apply: func(settings *config.Config) error {
+			rest.UpdateSettings(settings)
+			return nil
+		},
+	})
+	config.StartWatcher()
 
 	// Initialize the REST server and start serving requests to the files
-	// service.
+	// service. This returns once the service has been asked to terminate and
+	// the requests in flight have been drained.
 	logger.Info("Starting rest server")
 	rest.Init(logger, &config.Settings)
+
+	// Stop reloading the configuration, then shut down the remaining
+	// components in order. The notification subscriber finishes processing
+	// its current message before storage and the database (which it depends
+	// on) are shut down.
+	config.StopWatcher()
+
+	logger.Info("Shutting down notification")
+	notification.Shutdown()
+
+	logger.Info("Shutting down storage")
+	storage.Shutdown()
+
+	logger.Info("Shutting down database")
+	db.Shutdown()
+
+	// Flush spans recorded during shutdown.
+	logger.Info("Shutting down tracing")
+	tracing.Shutdown()
+
+	logger.Info("Shutdown complete")
 }
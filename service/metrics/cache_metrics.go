@@ -51,4 +51,113 @@ var (
 			Name: "fs_cache_del_file_failures",
 			Help: "Total number of failed cache delete device operations",
 		})
+
+	// Total number of cache hits, partitioned by the type of cache entry.
+	MetricCacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_cache_hits",
+			Help: "Total number of cache hits, by type of cache entry",
+		},
+		[]string{"cache"},
+	)
+
+	// Total number of cache misses, partitioned by the type of cache entry.
+	MetricCacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_cache_misses",
+			Help: "Total number of cache misses, by type of cache entry",
+		},
+		[]string{"cache"},
+	)
+
+	// Total number of cache misses served by a database lookup shared with
+	// concurrent misses of the same entry, partitioned by the type of cache
+	// entry.
+	MetricCacheCoalescedMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_cache_coalesced_misses",
+			Help: "Total number of cache misses served by a lookup shared with concurrent misses",
+		},
+		[]string{"cache"},
+	)
+
+	// Total number of cache writes rejected because a newer version of the
+	// entry was already cached, partitioned by the type of cache entry.
+	MetricCacheStaleWritesRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_cache_stale_writes_rejected",
+			Help: "Total number of cache writes rejected because a newer version was already cached",
+		},
+		[]string{"cache"},
+	)
+
+	// Whether the cache is degraded and bypassed because it cannot be reached.
+	MetricCacheDegraded = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fs_cache_degraded",
+			Help: "Whether the cache is degraded and bypassed (1) or not (0)",
+		})
+
+	// Total number of cache operations bypassed while the cache is degraded.
+	MetricCacheDegradedBypasses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_cache_degraded_bypasses",
+			Help: "Total number of cache operations bypassed while the cache is degraded",
+		})
+
+	// Number of cache invalidations deferred until the cache recovers.
+	MetricCachePendingInvalidations = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fs_cache_pending_invalidations",
+			Help: "Number of cache invalidations deferred until the cache recovers",
+		})
+
+	// Total number of deferred cache invalidations that were dropped.
+	MetricCacheDroppedInvalidations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_cache_dropped_invalidations",
+			Help: "Total number of deferred cache invalidations that were dropped",
+		})
+
+	// Total number of failed cache set file list operations.
+	MetricCacheSetFileListFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_cache_set_file_list_failures",
+			Help: "Total number of failed cache set file list operations",
+		})
+
+	// Total number of failed cache get file list operations.
+	MetricCacheGetFileListFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_cache_get_file_list_failures",
+			Help: "Total number of failed cache get file list operations",
+		})
+
+	// Total number of failed cache operations on device generations.
+	MetricCacheDeviceGenerationFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_cache_device_generation_failures",
+			Help: "Total number of failed cache operations on device generations",
+		})
 )
+
+// Collectors for the cache metrics, registered with Prometheus.
+var cacheMetrics = []prometheus.Collector{
+	MetricCacheLatency,
+	MetricCacheSetFileFailures,
+	MetricCacheGetFileFailures,
+	MetricCacheGetFileCacheHits,
+	MetricCacheGetFileCacheMisses,
+	MetricCacheDelFileFailures,
+	MetricCacheHits,
+	MetricCacheMisses,
+	MetricCacheCoalescedMisses,
+	MetricCacheStaleWritesRejected,
+	MetricCacheDegraded,
+	MetricCacheDegradedBypasses,
+	MetricCachePendingInvalidations,
+	MetricCacheDroppedInvalidations,
+	MetricCacheSetFileListFailures,
+	MetricCacheGetFileListFailures,
+	MetricCacheDeviceGenerationFailures,
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Total number of successful reloads of the configuration.
+	MetricConfigReloads = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_config_reloads",
+			Help: "Total number of successful reloads of the configuration",
+		})
+
+	// Total number of reloads of the configuration that were rejected.
+	MetricConfigReloadFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_config_reload_failures",
+			Help: "Total number of reloads of the configuration that were rejected",
+		})
+)
+
+// Collectors for the configuration metrics, registered with Prometheus.
+var configMetrics = []prometheus.Collector{
+	MetricConfigReloads,
+	MetricConfigReloadFailures,
+}
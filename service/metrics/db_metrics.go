@@ -59,6 +59,20 @@ var (
 			Help: "Total number of expired files that were scavenged",
 		})
 
+	// Total number of errors scavenging old file versions.
+	MetricScavengeOldFileVersionFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_scavenge_old_versions_failures",
+			Help: "Total number of errors scavenging old file versions",
+		})
+
+	// Total number of old file versions that were scavenged.
+	MetricScavengeOldFileVersions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_scavenge_old_versions",
+			Help: "Total number of old file versions that were scavenged",
+		})
+
 	// Total number of errors scavenging tombstoned files.
 	MetricScavengeTombstonedFileFailures = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -121,4 +135,227 @@ var (
 			Name: "fs_db_files_deleted",
 			Help: "Total number of delete file database operations",
 		})
+
+	// Total number of audit events written to the database.
+	MetricAuditEventsRecorded = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_audit_events_recorded",
+			Help: "Total number of audit events written to the database",
+		})
+
+	// Total number of audit events dropped because the audit queue was full.
+	MetricAuditEventsDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_audit_events_dropped",
+			Help: "Total number of audit events dropped because the audit queue was full",
+		})
+
+	// Total number of failures to write audit events to the database.
+	MetricAuditEventWriteFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_audit_event_write_failures",
+			Help: "Total number of failures to write audit events to the database",
+		})
+
+	// Total number of expired audit events deleted by the scavenger.
+	MetricScavengeAuditEvents = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_scavenge_audit_events",
+			Help: "Total number of expired audit events deleted by the scavenger",
+		})
+
+	// Total number of failures to delete expired audit events.
+	MetricScavengeAuditEventFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_scavenge_audit_event_failures",
+			Help: "Total number of failures to delete expired audit events",
+		})
+
+	// Whether this node currently holds the leader lease.
+	MetricIsLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fs_db_is_leader",
+			Help: "Whether this node currently holds the leader lease (1) or not (0)",
+		})
+
+	// Number of times this node was elected leader.
+	MetricLeaderElections = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_leader_elections",
+			Help: "Total number of times this node acquired the leader lease",
+		})
+
+	// Number of times this node lost the leader lease before releasing it.
+	MetricLeaderLeasesLost = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_leader_leases_lost",
+			Help: "Total number of times this node lost the leader lease before releasing it",
+		})
+
+	// Number of times the fencing token of this node was found to be stale.
+	MetricLeaderFencingRejections = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_leader_fencing_rejections",
+			Help: "Total number of batches of work abandoned because the fencing token was stale",
+		})
+
+	// Whether a scavenger run is in progress on this node.
+	MetricScavengerRunInProgress = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fs_db_scavenger_run_in_progress",
+			Help: "Whether a scavenger run is in progress on this node (1) or not (0)",
+		})
+
+	// Number of batches of rows deleted by the scavenger, partitioned by phase.
+	MetricScavengerBatches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_db_scavenger_batches",
+			Help: "Total number of batches of rows deleted by the scavenger",
+		},
+		[]string{"phase"},
+	)
+
+	// Number of rows deleted by each phase of the current scavenger run, or
+	// the number that would be deleted for a dry run.
+	MetricScavengerRunProgress = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "fs_db_scavenger_run_progress",
+			Help: "Number of rows deleted by each phase of the current scavenger run",
+		},
+		[]string{"phase"},
+	)
+
+	// Number of scavenger runs that stopped because their time budget was
+	// exhausted.
+	MetricScavengerBudgetExhausted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_scavenger_budget_exhausted",
+			Help: "Total number of scavenger runs that exhausted their time budget",
+		})
+
+	// Number of purge jobs running on this node.
+	MetricPurgeJobsInProgress = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fs_db_purge_jobs_in_progress",
+			Help: "Number of purge jobs running on this node",
+		})
+
+	// Total number of purge jobs completed by this node.
+	MetricPurgeJobsCompleted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_purge_jobs_completed",
+			Help: "Total number of purge jobs completed by this node",
+		})
+
+	// Total number of failed attempts to run purge jobs.
+	MetricPurgeJobFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_purge_job_failures",
+			Help: "Total number of failed attempts to run purge jobs",
+		})
+
+	// Total number of files tombstoned by purge jobs.
+	MetricPurgedFiles = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_purged_files",
+			Help: "Total number of files tombstoned by purge jobs",
+		})
+
+	// Total number of storage objects deleted by purge jobs.
+	MetricPurgedObjects = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_purged_objects",
+			Help: "Total number of storage objects deleted by purge jobs",
+		})
+
+	// Total number of legal holds placed.
+	MetricLegalHoldsPlaced = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_legal_holds_placed",
+			Help: "Total number of legal holds placed on tenants, devices and files",
+		})
+
+	// Number of watchers of file events.
+	MetricFileEventWatchers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "fs_db_file_event_watchers",
+			Help: "Number of watchers of file events",
+		})
+
+	// Total number of file events dropped because a watcher did not keep up.
+	MetricFileEventsDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_file_events_dropped",
+			Help: "Total number of file events dropped because a watcher did not keep up",
+		})
+
+	// Total number of errors listening for file events.
+	MetricFileEventListenerErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_file_event_listener_errors",
+			Help: "Total number of errors listening for file events",
+		})
+
+	// Total number of legal holds released.
+	MetricLegalHoldsReleased = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_db_legal_holds_released",
+			Help: "Total number of legal holds released",
+		})
+
+	// Total number of files that were not deleted because they are under
+	// legal hold, partitioned by the operation that skipped them.
+	MetricHeldFilesSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_db_held_files_skipped",
+			Help: "Total number of files not deleted because they are under legal hold",
+		},
+		[]string{"operation"},
+	)
 )
+
+// Collectors for the database metrics, registered with Prometheus.
+var databaseMetrics = []prometheus.Collector{
+	MetricDatabaseLatency,
+	MetricDatabaseCommitErrors,
+	MetricDatabaseRollbackErrors,
+	MetricScavengerRuns,
+	MetricAbandonedScavengerRun,
+	MetricScavengeExpiredFileFailures,
+	MetricScavengeExpiredFiles,
+	MetricScavengeOldFileVersionFailures,
+	MetricScavengeOldFileVersions,
+	MetricScavengeTombstonedFileFailures,
+	MetricScavengeTombstonedFiles,
+	MetricDatabaseFileNotFoundErrors,
+	MetricDatabaseGetFileFailures,
+	MetricDatabaseUpdateFileFailures,
+	MetricDatabaseDeleteFileFailures,
+	MetricDatabaseFilesRetrieved,
+	MetricDatabaseFilesUpdated,
+	MetricDatabaseFilesDeleted,
+	MetricAuditEventsRecorded,
+	MetricAuditEventsDropped,
+	MetricAuditEventWriteFailures,
+	MetricScavengeAuditEvents,
+	MetricScavengeAuditEventFailures,
+	MetricIsLeader,
+	MetricLeaderElections,
+	MetricLeaderLeasesLost,
+	MetricLeaderFencingRejections,
+	MetricScavengerRunInProgress,
+	MetricScavengerBatches,
+	MetricScavengerRunProgress,
+	MetricScavengerBudgetExhausted,
+	MetricPurgeJobsInProgress,
+	MetricPurgeJobsCompleted,
+	MetricPurgeJobFailures,
+	MetricPurgedFiles,
+	MetricPurgedObjects,
+	MetricLegalHoldsPlaced,
+	MetricLegalHoldsReleased,
+	MetricHeldFilesSkipped,
+	MetricFileEventWatchers,
+	MetricFileEventsDropped,
+	MetricFileEventListenerErrors,
+}
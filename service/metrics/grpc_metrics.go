@@ -0,0 +1,52 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// gRPC call processing latency is partitioned by the gRPC method. Latency
+	// of streaming calls covers the duration of the stream.
+	MetricGrpcLatency = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "fs_grpc_latency_milliseconds",
+			Help:       "A latency histogram for gRPC calls served by FS",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{"method"},
+	)
+
+	// Number of gRPC calls completed by FS, partitioned by the method and the
+	// status code of the call.
+	MetricGrpcResponses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_grpc_responses",
+			Help: "Total number of gRPC calls completed by FS by method and status code",
+		},
+		[]string{"method", "code"},
+	)
+
+	// Number of gRPC calls rejected because they did not specify a valid app
+	// token of an allowed app.
+	MetricGrpcUnauthenticatedCalls = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_grpc_unauthenticated_calls",
+			Help: "Total number of gRPC calls without a valid app token of an allowed app",
+		})
+
+	// Number of file events sent to gRPC watchers.
+	MetricGrpcFileEventsSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_grpc_file_events_sent",
+			Help: "Total number of file events sent to gRPC watchers",
+		})
+)
+
+// Collectors for the gRPC metrics, registered with Prometheus.
+var grpcMetrics = []prometheus.Collector{
+	MetricGrpcLatency,
+	MetricGrpcResponses,
+	MetricGrpcUnauthenticatedCalls,
+	MetricGrpcFileEventsSent,
+}
@@ -4,6 +4,7 @@
 package metrics
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -18,8 +19,31 @@ func ReportLatencyMetric(metric *prometheus.SummaryVec,
 	metric.WithLabelValues(label).Observe(float64(duration.Milliseconds()))
 }
 
+// StatusClass returns the class (2xx, 4xx, 5xx etc.) of the specified HTTP
+// status code, used to label metrics with a bounded set of values.
+func StatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
+
+// RegisterPrometheusMetrics registers all metrics reported by FS with the
+// default Prometheus registry.
 func RegisterPrometheusMetrics() {
-	prometheus.MustRegister(MetricRestLatency)
-	prometheus.MustRegister(MetricDatabaseLatency)
-	prometheus.MustRegister(MetricCacheLatency)
+	registerMetrics(prometheus.DefaultRegisterer)
+}
+
+func registerMetrics(registerer prometheus.Registerer) {
+	for _, collectors := range [][]prometheus.Collector{
+		restMetrics,
+		grpcMetrics,
+		databaseMetrics,
+		cacheMetrics,
+		queueMetrics,
+		usageMetrics,
+		configMetrics,
+	} {
+		registerer.MustRegister(collectors...)
+	}
 }
@@ -0,0 +1,56 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Check that all metrics can be registered, which fails if the names of any
+// two metrics collide.
+func TestRegisterMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registerMetrics(registry)
+
+	MetricCreateFileResponses.Inc()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather registered metrics: %v", err)
+	}
+
+	found := false
+	for _, family := range families {
+		if family.GetName() == "fs_rest_create_file_requests" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Counter fs_rest_create_file_requests was not registered")
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected string
+	}{
+		{200, "2xx"},
+		{201, "2xx"},
+		{302, "3xx"},
+		{404, "4xx"},
+		{503, "5xx"},
+		{0, "unknown"},
+		{600, "unknown"},
+	}
+
+	for _, tc := range tests {
+		got := StatusClass(tc.status)
+		if got != tc.expected {
+			t.Fatalf("StatusClass(%d): Expected: %s, Got: %s",
+				tc.status, tc.expected, got)
+		}
+	}
+}
@@ -27,3 +27,10 @@ var (
 			Help: "Total number of errors parsing file upload notifications",
 		})
 )
+
+// Collectors for the queue metrics, registered with Prometheus.
+var queueMetrics = []prometheus.Collector{
+	MetricUploadNotificationsProcessed,
+	MetricUploadNotificationProcessingErrors,
+	MetricUploadNotificationParsingErrors,
+}
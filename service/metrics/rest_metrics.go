@@ -17,6 +17,26 @@ var (
 		[]string{"method"},
 	)
 
+	// Number of REST responses sent by FS, partitioned by the route and the
+	// class (2xx, 4xx, 5xx etc.) of the response status code.
+	MetricRestResponses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_responses",
+			Help: "Total number of REST responses sent by FS by route and status class",
+		},
+		[]string{"route", "status_class"},
+	)
+
+	// Number of REST requests rejected because they do not conform to the
+	// API specification, by route.
+	MetricRequestValidationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_request_validation_failures",
+			Help: "Total number of REST requests that failed validation against the API specification by route",
+		},
+		[]string{"route"},
+	)
+
 	// Number of REST requests received by FS.
 	MetricRequestCount = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:        "fs_rest_requests",
@@ -80,6 +100,41 @@ var (
 			Help: "Total number of successful get file requests served by FS",
 		})
 
+	// Number of internal errors encountered when processing get file by name requests.
+	MetricGetFileByNameInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_file_by_name_internal_errors",
+			Help: "Total number of internal errors encountered processing get file by name requests",
+		})
+
+	// Number of bad get file by name requests encountered.
+	MetricGetFileByNameBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_file_by_name_bad_requests",
+			Help: "Total number of bad get file by name requests",
+		})
+
+	// Number of get file by name requests where no uploaded version was found.
+	MetricGetFileByNameNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_file_by_name_not_found_errors",
+			Help: "Total number of get file by name requests where file was not found",
+		})
+
+	// Number of unauthorized requests encountered for get_file_by_name.
+	MetricGetFileByNameUnauthorizedRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_file_by_name_unauthorized_requests",
+			Help: "Total number of get file by name unauthorized requests",
+		})
+
+	// Number of successful get file by name requests served.
+	MetricGetFileByNameResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_file_by_name_requests",
+			Help: "Total number of successful get file by name requests served by FS",
+		})
+
 	// Number of internal errors encountered when processing list file requests.
 	MetricListFilesInternalErrors = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -129,6 +184,14 @@ var (
 			Help: "Total number of delete file requests where file was not found",
 		})
 
+	// Number of delete file requests rejected because the file is under legal
+	// hold.
+	MetricDeleteFileLegalHoldErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_delete_file_legal_hold_errors",
+			Help: "Total number of delete file requests rejected because the file is under legal hold",
+		})
+
 	// Number of successful delete file requests served.
 	MetricDeleteFileResponses = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -191,4 +254,486 @@ var (
 			Name: "fs_rest_get_file_unauthorized_requests",
 			Help: "Total number of get file unauthorized requests",
 		})
+
+	// Number of internal errors encountered when processing scan action requests.
+	MetricScanActionInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_scan_action_internal_errors",
+			Help: "Total number of internal errors encountered processing scan action requests",
+		})
+
+	// Number of bad scan action requests encountered.
+	MetricScanActionBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_scan_action_bad_requests",
+			Help: "Total number of bad scan action requests",
+		})
+
+	// Number of scan action requests where the requested file was not found.
+	MetricScanActionNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_scan_action_not_found_errors",
+			Help: "Total number of scan action requests where file was not found",
+		})
+
+	// Number of scan action requests not allowed for the current file status.
+	MetricScanActionNotAllowedErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_scan_action_not_allowed_errors",
+			Help: "Total number of scan action requests not allowed for the file status",
+		})
+
+	// Number of successful scan action requests served.
+	MetricScanActionResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_scan_action_requests",
+			Help: "Total number of successful scan action requests served by FS",
+		})
+
+	// Number of signed url requests refused because the file was not scanned.
+	MetricGetSignedUrlUnscannedErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_signed_url_unscanned_errors",
+			Help: "Total number of get signed url requests where file was not yet scanned",
+		})
+
+	// Number of internal errors encountered when processing get usage requests.
+	MetricGetUsageInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_usage_internal_errors",
+			Help: "Total number of internal errors encountered processing get usage requests",
+		})
+
+	// Number of bad get usage requests encountered.
+	MetricGetUsageBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_usage_bad_requests",
+			Help: "Total number of bad get usage requests",
+		})
+
+	// Number of successful get usage requests served.
+	MetricGetUsageResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_usage_requests",
+			Help: "Total number of successful get usage requests served by FS",
+		})
+
+	// Number of internal errors encountered when processing list audit events
+	// requests.
+	MetricListAuditEventsInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_list_audit_events_internal_errors",
+			Help: "Total number of internal errors encountered processing list audit events requests",
+		})
+
+	// Number of bad list audit events requests encountered.
+	MetricListAuditEventsBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_list_audit_events_bad_requests",
+			Help: "Total number of bad list audit events requests",
+		})
+
+	// Number of successful list audit events requests served.
+	MetricListAuditEventsResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_list_audit_events_requests",
+			Help: "Total number of successful list audit events requests served by FS",
+		})
+
+	// Number of bad log level requests encountered.
+	MetricLogLevelBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_log_level_bad_requests",
+			Help: "Total number of bad log level requests",
+		})
+
+	// Number of successful log level requests served.
+	MetricLogLevelResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_log_level_requests",
+			Help: "Total number of successful log level requests served by FS",
+		})
+
+	// Number of internal errors encountered when processing leader status
+	// requests.
+	MetricLeaderStatusInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_leader_status_internal_errors",
+			Help: "Total number of internal errors encountered processing leader status requests",
+		})
+
+	// Number of successful leader status requests served.
+	MetricLeaderStatusResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_leader_status_requests",
+			Help: "Total number of successful leader status requests served by FS",
+		})
+
+	// Number of bad requests to run the database scavenger.
+	MetricRunScavengerBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_run_scavenger_bad_requests",
+			Help: "Total number of bad requests to run the database scavenger",
+		})
+
+	// Number of internal errors encountered when processing requests to run
+	// the database scavenger.
+	MetricRunScavengerInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_run_scavenger_internal_errors",
+			Help: "Total number of internal errors encountered processing requests to run the database scavenger",
+		})
+
+	// Number of successful requests to run the database scavenger.
+	MetricRunScavengerResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_run_scavenger_requests",
+			Help: "Total number of successful requests to run the database scavenger served by FS",
+		})
+
+	// Number of bad get scavenger run requests.
+	MetricGetScavengerRunBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_scavenger_run_bad_requests",
+			Help: "Total number of bad get scavenger run requests",
+		})
+
+	// Number of get scavenger run requests for runs that were not found.
+	MetricGetScavengerRunNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_scavenger_run_not_found_errors",
+			Help: "Total number of get scavenger run requests for runs that were not found",
+		})
+
+	// Number of internal errors encountered when processing get scavenger run
+	// requests.
+	MetricGetScavengerRunInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_scavenger_run_internal_errors",
+			Help: "Total number of internal errors encountered processing get scavenger run requests",
+		})
+
+	// Number of successful get scavenger run requests served.
+	MetricGetScavengerRunResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_scavenger_run_requests",
+			Help: "Total number of successful get scavenger run requests served by FS",
+		})
+
+	// Number of bad purge requests.
+	MetricPurgeBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_purge_bad_requests",
+			Help: "Total number of bad purge requests",
+		})
+
+	// Number of internal errors encountered when processing purge requests.
+	MetricPurgeInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_purge_internal_errors",
+			Help: "Total number of internal errors encountered processing purge requests",
+		})
+
+	// Number of successful purge requests served.
+	MetricPurgeResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_purge_requests",
+			Help: "Total number of successful purge requests served by FS",
+		})
+
+	// Number of bad get purge job requests.
+	MetricGetPurgeJobBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_purge_job_bad_requests",
+			Help: "Total number of bad get purge job requests",
+		})
+
+	// Number of get purge job requests for jobs that were not found.
+	MetricGetPurgeJobNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_purge_job_not_found_errors",
+			Help: "Total number of get purge job requests for jobs that were not found",
+		})
+
+	// Number of internal errors encountered when processing get purge job
+	// requests.
+	MetricGetPurgeJobInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_purge_job_internal_errors",
+			Help: "Total number of internal errors encountered processing get purge job requests",
+		})
+
+	// Number of successful get purge job requests served.
+	MetricGetPurgeJobResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_get_purge_job_requests",
+			Help: "Total number of successful get purge job requests served by FS",
+		})
+
+	// Number of bad legal hold requests.
+	MetricLegalHoldBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_legal_hold_bad_requests",
+			Help: "Total number of bad legal hold requests",
+		})
+
+	// Number of legal hold requests for holds or files that were not found.
+	MetricLegalHoldNotFoundErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_legal_hold_not_found_errors",
+			Help: "Total number of legal hold requests for holds or files that were not found",
+		})
+
+	// Number of internal errors encountered when processing legal hold
+	// requests.
+	MetricLegalHoldInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_legal_hold_internal_errors",
+			Help: "Total number of internal errors encountered processing legal hold requests",
+		})
+
+	// Number of successful legal hold requests served.
+	MetricLegalHoldResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_legal_hold_requests",
+			Help: "Total number of successful legal hold requests served by FS",
+		})
+
+	// Number of failures to mirror legal holds to the object lock of the
+	// objects of held files.
+	MetricLegalHoldMirrorFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_legal_hold_mirror_failures",
+			Help: "Total number of failures to mirror legal holds to storage object lock",
+		})
+
+	// Number of bad batch create files requests.
+	MetricBatchCreateFilesBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_create_files_bad_requests",
+			Help: "Total number of bad batch create files requests",
+		})
+
+	// Number of batch create files requests with an invalid device token.
+	MetricBatchCreateFilesUnauthorizedRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_create_files_unauthorized_requests",
+			Help: "Total number of unauthorized batch create files requests",
+		})
+
+	// Number of internal errors encountered when processing batch create
+	// files requests.
+	MetricBatchCreateFilesInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_create_files_internal_errors",
+			Help: "Total number of internal errors encountered processing batch create files requests",
+		})
+
+	// Number of successful batch create files requests served.
+	MetricBatchCreateFilesResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_create_files_requests",
+			Help: "Total number of successful batch create files requests served by FS",
+		})
+
+	// Number of bad batch signed URL requests.
+	MetricBatchSignedUrlBadRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_signed_url_bad_requests",
+			Help: "Total number of bad batch signed URL requests",
+		})
+
+	// Number of internal errors encountered when processing batch signed URL
+	// requests.
+	MetricBatchSignedUrlInternalErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_signed_url_internal_errors",
+			Help: "Total number of internal errors encountered processing batch signed URL requests",
+		})
+
+	// Number of successful batch signed URL requests served.
+	MetricBatchSignedUrlResponses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_signed_url_requests",
+			Help: "Total number of successful batch signed URL requests served by FS",
+		})
+
+	// Number of items of batch requests processed, by route and by the error
+	// code of the items that failed ("ok" for items that succeeded).
+	MetricBatchItems = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_batch_items",
+			Help: "Total number of items of batch requests processed by route and outcome",
+		},
+		[]string{"route", "code"},
+	)
+
+	// Number of one-time redirect URLs issued.
+	MetricRedirectUrlsIssued = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_redirect_urls_issued",
+			Help: "Total number of one-time redirect URLs issued by FS",
+		})
+
+	// Number of attempts to redeem one-time redirect URLs, by the error code
+	// of the attempts that failed ("ok" for URLs that were redeemed).
+	MetricRedirectUrlRedemptions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_redirect_url_redemptions",
+			Help: "Total number of attempts to redeem one-time redirect URLs by outcome",
+		},
+		[]string{"code"},
+	)
+
+	// Number of resumable uploads started.
+	MetricResumableUploadsCreated = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_resumable_uploads_created",
+			Help: "Total number of resumable uploads started with FS",
+		})
+
+	// Number of requests to append chunks to resumable uploads, by the error
+	// code of the requests that failed ("ok" for chunks that were appended).
+	MetricResumableUploadChunks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_resumable_upload_chunks",
+			Help: "Total number of requests to append chunks to resumable uploads by outcome",
+		},
+		[]string{"code"},
+	)
+
+	// Number of bytes appended to resumable uploads.
+	MetricResumableUploadBytes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_rest_resumable_upload_bytes",
+			Help: "Total number of bytes appended to resumable uploads",
+		})
+
+	// Number of resumable uploads completed, by the error code of the uploads
+	// whose checksum could not be verified ("ok" for verified uploads).
+	MetricResumableUploadsCompleted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_resumable_uploads_completed",
+			Help: "Total number of resumable uploads completed by outcome",
+		},
+		[]string{"code"},
+	)
+
+	// Number of files uploaded and downloaded through the content proxy, by
+	// the error code of the requests that failed ("ok" for files that were
+	// transferred).
+	MetricProxyUploads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_proxy_uploads",
+			Help: "Total number of files uploaded through the content proxy by outcome",
+		},
+		[]string{"code"},
+	)
+	MetricProxyDownloads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_proxy_downloads",
+			Help: "Total number of files downloaded through the content proxy by outcome",
+		},
+		[]string{"code"},
+	)
+
+	// Number of bytes transferred through the content proxy, by direction
+	// ("upload" or "download").
+	MetricProxyBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fs_rest_proxy_bytes",
+			Help: "Total number of bytes transferred through the content proxy by direction",
+		},
+		[]string{"direction"},
+	)
 )
+
+// Collectors for the REST metrics, registered with Prometheus.
+var restMetrics = []prometheus.Collector{
+	MetricRestLatency,
+	MetricRestResponses,
+	MetricRequestValidationFailures,
+	MetricRequestCount,
+	MetricCreateFileInternalErrors,
+	MetricCreateFileBadRequests,
+	MetricCreateFileResponses,
+	MetricGetFileInternalErrors,
+	MetricGetFileBadRequests,
+	MetricGetFileNotFoundErrors,
+	MetricGetFileInvalidAccessErrors,
+	MetricGetFileResponses,
+	MetricGetFileByNameInternalErrors,
+	MetricGetFileByNameBadRequests,
+	MetricGetFileByNameNotFoundErrors,
+	MetricGetFileByNameUnauthorizedRequests,
+	MetricGetFileByNameResponses,
+	MetricListFilesInternalErrors,
+	MetricListFilesBadRequests,
+	MetricListFilesNotFoundErrors,
+	MetricListFilesResponses,
+	MetricDeleteFileInternalErrors,
+	MetricDeleteFileBadRequests,
+	MetricDeleteFileNotFoundErrors,
+	MetricDeleteFileLegalHoldErrors,
+	MetricDeleteFileResponses,
+	MetricGetSignedUrlInternalErrors,
+	MetricGetSignedUrlBadRequests,
+	MetricGetSignedUrlFileNotFoundErrors,
+	MetricGetSignedUrlForbiddenErrors,
+	MetricGetSignedUrlResponses,
+	MetricCreateFileUnauthorizedRequests,
+	MetricCreateFileUnSupportedMediaTypeRequests,
+	MetricGetFileUnauthorizedRequests,
+	MetricScanActionInternalErrors,
+	MetricScanActionBadRequests,
+	MetricScanActionNotFoundErrors,
+	MetricScanActionNotAllowedErrors,
+	MetricScanActionResponses,
+	MetricGetSignedUrlUnscannedErrors,
+	MetricGetUsageInternalErrors,
+	MetricGetUsageBadRequests,
+	MetricGetUsageResponses,
+	MetricListAuditEventsInternalErrors,
+	MetricListAuditEventsBadRequests,
+	MetricListAuditEventsResponses,
+	MetricLogLevelBadRequests,
+	MetricLogLevelResponses,
+	MetricLeaderStatusInternalErrors,
+	MetricLeaderStatusResponses,
+	MetricRunScavengerBadRequests,
+	MetricRunScavengerInternalErrors,
+	MetricRunScavengerResponses,
+	MetricGetScavengerRunBadRequests,
+	MetricGetScavengerRunNotFoundErrors,
+	MetricGetScavengerRunInternalErrors,
+	MetricGetScavengerRunResponses,
+	MetricPurgeBadRequests,
+	MetricPurgeInternalErrors,
+	MetricPurgeResponses,
+	MetricGetPurgeJobBadRequests,
+	MetricGetPurgeJobNotFoundErrors,
+	MetricGetPurgeJobInternalErrors,
+	MetricGetPurgeJobResponses,
+	MetricLegalHoldBadRequests,
+	MetricLegalHoldNotFoundErrors,
+	MetricLegalHoldInternalErrors,
+	MetricLegalHoldResponses,
+	MetricLegalHoldMirrorFailures,
+	MetricBatchCreateFilesBadRequests,
+	MetricBatchCreateFilesUnauthorizedRequests,
+	MetricBatchCreateFilesInternalErrors,
+	MetricBatchCreateFilesResponses,
+	MetricBatchSignedUrlBadRequests,
+	MetricBatchSignedUrlInternalErrors,
+	MetricBatchSignedUrlResponses,
+	MetricBatchItems,
+	MetricRedirectUrlsIssued,
+	MetricRedirectUrlRedemptions,
+	MetricResumableUploadsCreated,
+	MetricResumableUploadChunks,
+	MetricResumableUploadBytes,
+	MetricResumableUploadsCompleted,
+	MetricProxyUploads,
+	MetricProxyDownloads,
+	MetricProxyBytes,
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	// Types of usage reported for the top tenants.
+	usageTypeBytesUploaded   = "bytes_uploaded"
+	usageTypeFilesCreated    = "files_created"
+	usageTypeDownloadsIssued = "downloads_issued"
+)
+
+var (
+	// Total number of bytes uploaded to storage.
+	MetricUsageBytesUploaded = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_usage_bytes_uploaded",
+			Help: "Total number of bytes uploaded to storage",
+		})
+
+	// Total number of files created.
+	MetricUsageFilesCreated = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_usage_files_created",
+			Help: "Total number of files created",
+		})
+
+	// Total number of download URLs issued.
+	MetricUsageDownloadsIssued = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_usage_downloads_issued",
+			Help: "Total number of download URLs issued",
+		})
+
+	// Total number of failures recording usage in the usage rollups.
+	MetricUsageRecordFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "fs_usage_record_failures",
+			Help: "Total number of failures recording usage in the usage rollups",
+		})
+
+	// Usage for the current day of the tenants with the highest usage. Only
+	// a bounded number of tenants are reported, to bound the cardinality of
+	// the metric.
+	MetricUsageTopTenants = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "fs_usage_top_tenants",
+			Help: "Usage for the current day of the tenants with the highest usage",
+		},
+		[]string{"tenant_id", "type"},
+	)
+)
+
+// ReportTenantUsage reports the usage of the specified tenant for the current
+// day to the top tenants usage metric.
+func ReportTenantUsage(tenantID string, bytesUploaded, filesCreated,
+	downloadsIssued int64) {
+	MetricUsageTopTenants.WithLabelValues(tenantID,
+		usageTypeBytesUploaded).Set(float64(bytesUploaded))
+	MetricUsageTopTenants.WithLabelValues(tenantID,
+		usageTypeFilesCreated).Set(float64(filesCreated))
+	MetricUsageTopTenants.WithLabelValues(tenantID,
+		usageTypeDownloadsIssued).Set(float64(downloadsIssued))
+}
+
+// Collectors for the usage metrics, registered with Prometheus.
+var usageMetrics = []prometheus.Collector{
+	MetricUsageBytesUploaded,
+	MetricUsageFilesCreated,
+	MetricUsageDownloadsIssued,
+	MetricUsageRecordFailures,
+	MetricUsageTopTenants,
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package notification
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const sqsDataTypeString = "String"
+
+// messageAttributeCarrier adapts SQS message attributes for use as a carrier
+// of W3C trace context, so that the trace context of the producer of a
+// message is propagated to its consumer.
+type messageAttributeCarrier map[string]types.MessageAttributeValue
+
+func (c messageAttributeCarrier) Get(key string) string {
+	value, ok := c[key]
+	if !ok || value.StringValue == nil {
+		return ""
+	}
+	return *value.StringValue
+}
+
+func (c messageAttributeCarrier) Set(key string, value string) {
+	c[key] = types.MessageAttributeValue{
+		DataType:    aws.String(sqsDataTypeString),
+		StringValue: aws.String(value),
+	}
+}
+
+func (c messageAttributeCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
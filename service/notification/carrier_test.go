@@ -0,0 +1,62 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Check that the trace context injected into the attributes of a queue
+// message is extracted by the consumer of the message.
+func TestMessageAttributeTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	producer := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}))
+
+	attributes := messageAttributeCarrier{}
+	tracing.Inject(producer, attributes)
+	if attributes.Get("traceparent") == "" {
+		t.Fatalf("Trace context was not added to the message attributes")
+	}
+
+	// Simulate the receipt of the message by the consumer.
+	receiptHandle := "123"
+	msgs := []types.Message{
+		{
+			Body:              &S3_EVENT_JSON,
+			ReceiptHandle:     &receiptHandle,
+			MessageAttributes: attributes,
+		},
+	}
+	un := getUploadNotification(msgs)
+	if un == nil {
+		t.Fatalf("Could not parse s3 upload event")
+	}
+
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(),
+		messageAttributeCarrier(un.MessageAttributes))
+	found := trace.SpanContextFromContext(ctx)
+	if found.TraceID() != traceID {
+		t.Fatalf("Bad trace ID. Expected: %s, Got: %s",
+			traceID, found.TraceID())
+	}
+	if found.SpanID() != spanID {
+		t.Fatalf("Bad span ID. Expected: %s, Got: %s",
+			spanID, found.SpanID())
+	}
+}
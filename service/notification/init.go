@@ -12,6 +12,7 @@ import (
 	"github.com/HPInc/krypton-fs/service/config"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	smithyendpoints "github.com/aws/smithy-go/endpoints"
 	"go.uber.org/zap"
 )
@@ -20,8 +21,13 @@ var (
 	// Structured logging using Uber Zap.
 	fsLogger *zap.Logger
 
-	// Global context for the package.
-	gCtx context.Context
+	// Global context for the package. It is cancelled when the package is
+	// shut down to stop the upload notification subscriber.
+	gCtx        context.Context
+	gCancelFunc context.CancelFunc
+
+	// Signalled by the subscriber goroutine once it has stopped.
+	subscriberDone chan struct{}
 
 	// Connection to the upload notification queue.
 	gSQS *sqs.Client
@@ -29,27 +35,43 @@ var (
 	// queue url
 	queueUrl string
 
+	// url of the queue to which rescan requests are sent
+	rescanQueueUrl string
+
 	// settings
 	notificationSettings *config.Notification
+	scanSettings         *config.Scanning
 
 	ErrNoUploadRecord = errors.New(
 		"could not find upload record entry in notification")
 	ErrVerificationFile = errors.New(
 		"ignore verification file uploaded to bucket")
-	ErrUnexpectedFile = errors.New("unexpected file uploaded to bucket")
+	ErrUnexpectedFile    = errors.New("unexpected file uploaded to bucket")
+	ErrUnknownScanStatus = errors.New(
+		"unknown scan status specified in notification")
+	ErrRescanNotConfigured = errors.New(
+		"no rescan queue has been configured")
+	ErrNotInitialized = errors.New("notification is not initialized")
 )
 
 const (
 	awsOperationTimeout     = time.Second * 5
 	awsSqsVisibilityTimeout = 60
+
+	// Names of the spans created for queue operations.
+	spanProcessUploadNotification = "notification.ProcessUpload"
+	spanDeleteMessage             = "notification.DeleteMessage"
+	spanSendRescanRequest         = "notification.SendRescanRequest"
 )
 
-func Init(settings *config.Notification, logger *zap.Logger) error {
+func Init(settings *config.Notification, scanning *config.Scanning,
+	logger *zap.Logger) error {
 	var err error
 	fsLogger = logger
 	notificationSettings = settings
+	scanSettings = scanning
 
-	gCtx = context.Background()
+	gCtx, gCancelFunc = context.WithCancel(context.Background())
 	ctx, cancelFunc := context.WithTimeout(gCtx, awsOperationTimeout)
 	defer cancelFunc()
 
@@ -61,7 +83,16 @@ func Init(settings *config.Notification, logger *zap.Logger) error {
 		return err
 	}
 
+	// Resolve the queue to which rescan requests are sent, if configured.
+	if scanning.RescanQueueName != "" {
+		rescanQueueUrl, err = getQueueUrl(ctx, scanning.RescanQueueName)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Start watching the queue for file upload events.
+	subscriberDone = make(chan struct{})
 	go checkUploadNotifications()
 
 	return nil
@@ -109,23 +140,51 @@ func newSQS(ctx context.Context, settings *config.Notification) error {
 	}
 
 	// Get URL of queue
+	queueUrl, err = getQueueUrl(ctx, settings.Name)
+	return err
+}
+
+// getQueueUrl returns the URL of the queue with the specified name.
+func getQueueUrl(ctx context.Context, name string) (string, error) {
 	urlResult, err := gSQS.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
-		QueueName: &settings.Name,
+		QueueName: &name,
 	})
 	if err != nil {
 		fsLogger.Error("Failed to get the queue URL!",
+			zap.String("Queue name:", name),
 			zap.Error(err),
 		)
-		return err
+		return "", err
 	}
-	queueUrl = *urlResult.QueueUrl
+	return *urlResult.QueueUrl, nil
+}
 
-	return nil
+// Ping checks whether the upload notification queue can be reached.
+func Ping(ctx context.Context) error {
+	if gSQS == nil {
+		return ErrNotInitialized
+	}
+	_, err := gSQS.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl: &queueUrl,
+		AttributeNames: []types.QueueAttributeName{
+			types.QueueAttributeNameApproximateNumberOfMessages,
+		},
+	})
+	return err
 }
 
+// Shutdown stops the upload notification queue subscriber. It waits for the
+// notification currently being processed, if any, to be completed and
+// acknowledged so that queue messages are not left half-processed.
 func Shutdown() {
 	fsLogger.Info("HP FS: signalling shutdown to upload notification queue subscriber")
-	if gCtx != nil {
-		gCtx.Done()
+	if gCancelFunc == nil {
+		return
+	}
+	gCancelFunc()
+
+	if subscriberDone != nil {
+		<-subscriberDone
 	}
+	fsLogger.Info("HP FS: upload notification queue subscriber has stopped")
 }
@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/HPInc/krypton-fs/service/tracing"
+
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"go.uber.org/zap"
@@ -26,6 +28,7 @@ func getUploadNotification(msgs []types.Message) *UploadNotification {
 		return nil
 	}
 	un.ReceiptHandle = *msgs[0].ReceiptHandle
+	un.MessageAttributes = msgs[0].MessageAttributes
 	return &un
 }
 
@@ -51,13 +54,21 @@ func receiveMessage() (*UploadNotification, error) {
 	return getUploadNotification(msgResult.Messages), nil
 }
 
-func deleteMessage(receiptHandle string) error {
-	ctx, cancelFunc := context.WithTimeout(gCtx, awsOperationTimeout)
+// deleteMessage acknowledges a processed message. It is not bound to the
+// package context so that messages processed during shutdown are still
+// acknowledged.
+func deleteMessage(ctx context.Context, receiptHandle string) error {
+	ctx, span := tracing.StartClientSpan(ctx, spanDeleteMessage)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(context.WithoutCancel(ctx),
+		awsOperationTimeout)
 	defer cancelFunc()
 
 	_, err := gSQS.DeleteMessage(ctx, &sqs.DeleteMessageInput{
 		QueueUrl:      &queueUrl,
 		ReceiptHandle: &receiptHandle,
 	})
+	tracing.SetError(span, err)
 	return err
 }
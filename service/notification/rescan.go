@@ -0,0 +1,80 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// RescanRequest is the message sent to the malware scanning pipeline to
+// request a rescan of a file.
+type RescanRequest struct {
+	RequestID string `json:"request_id"`
+	FileID    uint64 `json:"file_id"`
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+}
+
+// RequestRescan asks the malware scanning pipeline to rescan the specified
+// file by sending a message to the configured rescan queue. The verdict is
+// reported back through the upload notification queue.
+func RequestRescan(ctx context.Context, requestID string, file *db.File) error {
+	if rescanQueueUrl == "" {
+		return ErrRescanNotConfigured
+	}
+
+	body, err := json.Marshal(RescanRequest{
+		RequestID: requestID,
+		FileID:    file.FileID,
+		Bucket:    file.BucketName,
+		Key: storage.GetObjectName(file.TenantID, file.DeviceID,
+			file.FileID),
+	})
+	if err != nil {
+		fsLogger.Error("Failed to marshal the rescan request!",
+			zap.String("Request ID:", requestID),
+			zap.Error(err))
+		return err
+	}
+
+	ctx, span := tracing.StartSpan(ctx, spanSendRescanRequest,
+		attribute.Int64("file.id", int64(file.FileID)),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+
+	// Propagate the trace context to the scanning pipeline, so that the
+	// verdict it reports can be linked back to this request.
+	attributes := messageAttributeCarrier{}
+	tracing.Inject(ctx, attributes)
+
+	message := string(body)
+	_, err = gSQS.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          &rescanQueueUrl,
+		MessageBody:       &message,
+		MessageAttributes: attributes,
+	})
+	if err != nil {
+		fsLogger.Error("Failed to send the rescan request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", file.FileID),
+			zap.Error(err))
+		tracing.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
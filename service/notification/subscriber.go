@@ -4,6 +4,7 @@
 package notification
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -11,7 +12,9 @@ import (
 	"github.com/HPInc/krypton-fs/service/config"
 	"github.com/HPInc/krypton-fs/service/db"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -19,7 +22,9 @@ type UploadedFile struct {
 	id            string
 	size          int64
 	scanStatus    string
+	scanner       string
 	receiptHandle string
+	attributes    messageAttributeCarrier
 }
 
 const (
@@ -29,12 +34,15 @@ const (
 	scanStatusNone        = ""
 	scanStatusClean       = "clean"
 	scanStatusQuarantined = "quarantined"
+	scanStatusPending     = "pending"
 )
 
 // check for upload notifications
 // when a notification is found, look up the metadata record
 // and mark as uploaded
 func checkUploadNotifications() {
+	defer close(subscriberDone)
+
 	for {
 		if gCtx.Err() != nil {
 			fsLogger.Info(
@@ -43,6 +51,10 @@ func checkUploadNotifications() {
 		}
 		// Parse the received file upload notification.
 		file, err := getUploadedFile()
+		if err != nil && gCtx.Err() != nil {
+			// The receive was interrupted by shutdown.
+			continue
+		}
 		if err != nil && err != ErrVerificationFile {
 			fsLogger.Error(" Failed to parse the upload notification message!",
 				zap.Error(err))
@@ -50,11 +62,18 @@ func checkUploadNotifications() {
 			continue
 		}
 		if file != nil {
-			// Process the file upload notification message.
-			err = processUploadNotification(file, file.receiptHandle)
+			// Process the file upload notification message. The processing is
+			// traced as part of the trace of the producer of the message.
+			ctx, span := tracing.StartConsumerSpan(context.Background(),
+				spanProcessUploadNotification, file.attributes,
+				attribute.String("file.id", file.id),
+				attribute.String("file.scan_status", file.scanStatus),
+			)
+			err = processUploadNotification(ctx, file, file.receiptHandle)
 			if err != nil {
 				metrics.MetricUploadNotificationProcessingErrors.Inc()
 			}
+			tracing.EndSpan(span, err)
 		}
 	}
 }
@@ -92,7 +111,9 @@ func getUploadedFile() (*UploadedFile, error) {
 		id:            keyParts[2],
 		size:          un.Records[0].Storage.Object.Size,
 		scanStatus:    un.Records[0].ScanStatus,
+		scanner:       un.Records[0].Scanner,
 		receiptHandle: un.ReceiptHandle,
+		attributes:    un.MessageAttributes,
 	}, nil
 }
 
@@ -103,24 +124,53 @@ func getUploadedFile() (*UploadedFile, error) {
 // queue configured amount of reads (see queue configuration for specifics),
 // it will be moved to the corresponding dead-letter. In this case,
 // fs-notification-dead-letters is where you will find such entries.
-func processUploadNotification(uf *UploadedFile, receiptHandle string) error {
+func processUploadNotification(ctx context.Context, uf *UploadedFile,
+	receiptHandle string) error {
 	var err error
 	defer common.TimeIt(fsLogger, time.Now(), "processUploadNotification")
 
+	scanner := uf.scanner
+	if scanner == "" {
+		scanner = scanSettings.DefaultScannerName
+	}
+
 	switch uf.scanStatus {
 	case scanStatusNone:
-		fsLogger.Info("Empty scan status, marking file as clean",
-			zap.String("file_id", uf.id),
-			zap.Int64("file_size", uf.size))
-		uf.scanStatus = scanStatusClean
-	// Mark the file mentioned in the notification uploaded in the database.
+		// The notification was raised by storage before the file was scanned.
+		// If scanning is enabled, hold the file until a verdict is received.
+		if scanSettings.Enabled {
+			fsLogger.Info("Empty scan status, marking file as pending scan",
+				zap.String("file_id", uf.id),
+				tracing.TraceID(ctx),
+				zap.Int64("file_size", uf.size))
+			uf.scanStatus = scanStatusPending
+			err = db.MarkFileScanPending(ctx, uf.id, uf.size)
+		} else {
+			fsLogger.Info("Empty scan status, marking file as clean",
+				zap.String("file_id", uf.id),
+				tracing.TraceID(ctx),
+				zap.Int64("file_size", uf.size))
+			uf.scanStatus = scanStatusClean
+			err = db.MarkFileUploaded(ctx, uf.id, uf.size)
+		}
+	// Record the verdict and mark the file uploaded or quarantined.
 	case scanStatusClean:
-		err = db.MarkFileUploaded(uf.id, uf.size)
+		err = db.MarkFileScanned(ctx, uf.id, uf.size, scanner, db.ScanVerdictClean)
 	case scanStatusQuarantined:
-		err = db.MarkFileQuarantined(uf.id, uf.size)
+		err = db.MarkFileScanned(ctx, uf.id, uf.size, scanner, db.ScanVerdictQuarantined)
+	default:
+		// Leave the message in the queue. It will be moved to the dead-letter
+		// queue after the configured number of retries.
+		fsLogger.Error("Unknown scan status in upload notification!",
+			zap.String("file_id", uf.id),
+			tracing.TraceID(ctx),
+			zap.String("scan_status", uf.scanStatus))
+		return ErrUnknownScanStatus
 	}
 	if err != nil {
-		fsLogger.Error("Failed to mark file uploaded in the database!",
+		fsLogger.Error("Failed to update file status in the database!",
+			zap.String("file_id", uf.id),
+			tracing.TraceID(ctx),
 			zap.Error(err))
 		return err
 	}
@@ -128,15 +178,17 @@ func processUploadNotification(uf *UploadedFile, receiptHandle string) error {
 	metrics.MetricUploadNotificationsProcessed.Inc()
 
 	// Acknowledge the message by deleting it from the notification queue.
-	err = deleteMessage(receiptHandle)
+	err = deleteMessage(ctx, receiptHandle)
 	if err != nil {
 		fsLogger.Error("Failed to delete notification from queue after processing it!",
+			tracing.TraceID(ctx),
 			zap.Error(err))
 		return err
 	}
 
 	fsLogger.Info("File upload notification",
 		zap.String("file_id", uf.id),
+		tracing.TraceID(ctx),
 		zap.String("scan_status", uf.scanStatus),
 	)
 	return nil
@@ -149,7 +201,7 @@ func handleTestFile(key, handle string) bool {
 	}
 	// test files will not be processed. if there is a delete
 	// error, let it go to audit after retries
-	if err := deleteMessage(handle); err != nil && err != ErrVerificationFile {
+	if err := deleteMessage(context.Background(), handle); err != nil && err != ErrVerificationFile {
 		fsLogger.Error("Error deleting test file message",
 			zap.Error(err))
 	}
@@ -3,9 +3,15 @@
 
 package notification
 
+import "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
 type UploadNotification struct {
 	ReceiptHandle string   `json:"-"`
 	Records       []Record `json:"records"`
+
+	// Attributes of the queue message, which carry the trace context of the
+	// producer of the notification, if any.
+	MessageAttributes map[string]types.MessageAttributeValue `json:"-"`
 }
 
 type Record struct {
@@ -19,4 +25,5 @@ type Record struct {
 		} `json:"object"`
 	} `json:"s3"`
 	ScanStatus string `json:"scan_status"`
+	Scanner    string `json:"scanner"`
 }
@@ -0,0 +1,270 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// Number of audit events returned if a limit is not specified.
+	defaultAuditEventsLimit = 100
+
+	// Period of audit events queried if the start of the period is not
+	// specified.
+	defaultAuditPeriod = time.Hour * 24 * 7
+)
+
+var (
+	// Proxies whose X-Forwarded-For hops are trusted by getClientIP.
+	trustedProxies []netip.Prefix
+
+	ErrInvalidAuditFilter = errors.New("invalid audit event filter specified")
+)
+
+// newAuditEvent creates an audit event for the specified action taken by the
+// request. Handlers fill in the actor, tenant and file as they become known
+// and record the event once the response has been sent.
+func newAuditEvent(r *http.Request, action string) *db.AuditEvent {
+	return &db.AuditEvent{
+		Actor:     r.Header.Get(headerActor),
+		Action:    action,
+		RequestID: r.Header.Get(headerRequestID),
+		SourceIP:  getSourceIP(r),
+	}
+}
+
+// getAuditFileID returns the file identifier specified in the path of the
+// request, or zero if it is not a valid file identifier.
+func getAuditFileID(fileID string) uint64 {
+	id, err := strconv.ParseUint(fileID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// recordAuditEvent queues the specified audit event for writing to the file
+// audit log. The outcome of the action is derived from the status code of the
+// response sent to the caller.
+func recordAuditEvent(w http.ResponseWriter, event *db.AuditEvent) {
+	status := http.StatusOK
+	if recorder, ok := w.(*statusRecorder); ok {
+		status = recorder.status
+	}
+	event.Outcome = getAuditOutcome(status)
+	db.RecordAuditEvent(*event)
+}
+
+// getAuditOutcome maps the status code of a response to the outcome recorded
+// in the file audit log.
+func getAuditOutcome(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return db.AuditOutcomeSuccess
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return db.AuditOutcomeDenied
+	case status == http.StatusNotFound:
+		return db.AuditOutcomeNotFound
+	case status < http.StatusInternalServerError:
+		return db.AuditOutcomeRejected
+	default:
+		return db.AuditOutcomeFailure
+	}
+}
+
+// getSourceIP returns the IP address of the client that sent the request, as
+// recorded in the audit log. If the request was forwarded by a proxy, the
+// originating client is used. The client can forge the X-Forwarded-For
+// header, so access checks must use getClientIP instead.
+func getSourceIP(r *http.Request) string {
+	if forwardedFor := r.Header.Get(headerForwardedFor); forwardedFor != "" {
+		client, _, _ := strings.Cut(forwardedFor, ",")
+		return strings.TrimSpace(client)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// getClientIP returns the IP address of the client that sent the request, for
+// use in access checks. Only the X-Forwarded-For hops added by trusted proxies
+// are believed: the right-most hop that was not added by a trusted proxy is
+// the client. Requests that do not come from a trusted proxy are attributed
+// to their peer address.
+func getClientIP(r *http.Request) string {
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values(headerForwardedFor),
+		","), ",")
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(client); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			break
+		}
+		client = hop
+	}
+	return client
+}
+
+// Returns whether the address is one of the trusted proxies.
+func isTrustedProxy(address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies returns the CIDR ranges of the configured trusted
+// proxies. Addresses are treated as ranges of a single address.
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(),
+				addr.Unmap().BitLen()))
+		} else if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		}
+	}
+	return prefixes
+}
+
+// Lists the audit events recorded for file access and administrative actions,
+// most recent first. Events may be filtered by tenant, file and time range
+// using the tenant_id, file_id, from and to query parameters. The period
+// defaults to the last 7 days.
+func ListAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	filter, err := getAuditEventFilter(r.FormValue(paramTenantID),
+		r.FormValue(paramAuditFile), r.FormValue(paramFrom), r.FormValue(paramTo),
+		r.FormValue(paramLimit), time.Now().UTC())
+	if err != nil {
+		fsLogger.Error("An invalid audit event filter was specified in the request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricListAuditEventsBadRequests.Inc()
+		return
+	}
+
+	events, err := db.ListAuditEvents(r.Context(), requestID, filter)
+	if err != nil {
+		fsLogger.Error("Failed to get audit events from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricListAuditEventsInternalErrors.Inc()
+		return
+	}
+
+	response := common.AuditEventsResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Count:        len(events),
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, common.AuditEventInformation{
+			EventID:    event.EventID,
+			OccurredAt: event.OccurredAt,
+			TenantID:   event.TenantID,
+			Actor:      event.Actor,
+			Action:     event.Action,
+			FileID:     event.FileID,
+			RequestID:  event.RequestID,
+			SourceIP:   event.SourceIP,
+			Outcome:    event.Outcome,
+		})
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		metrics.MetricListAuditEventsInternalErrors.Inc()
+	}
+
+	metrics.MetricListAuditEventsResponses.Inc()
+}
+
+// getAuditEventFilter parses the filter for audit event queries. The from and
+// to timestamps are in RFC 3339 format. The period ends now if the end is not
+// specified, and covers the default period if the start is not specified.
+func getAuditEventFilter(tenantID, fileID, fromValue, toValue, limitValue string,
+	now time.Time) (*db.AuditEventFilter, error) {
+	var err error
+	filter := db.AuditEventFilter{
+		TenantID: tenantID,
+		To:       now,
+		Limit:    defaultAuditEventsLimit,
+	}
+
+	if tenantID != "" && !isValidUUID(tenantID) {
+		return nil, ErrInvalidAuditFilter
+	}
+
+	if fileID != "" {
+		filter.FileID, err = strconv.ParseUint(fileID, 10, 64)
+		if err != nil || filter.FileID == 0 {
+			return nil, ErrInvalidAuditFilter
+		}
+	}
+
+	if toValue != "" {
+		filter.To, err = time.Parse(time.RFC3339, toValue)
+		if err != nil {
+			return nil, ErrInvalidAuditFilter
+		}
+	}
+
+	filter.From = filter.To.Add(-defaultAuditPeriod)
+	if fromValue != "" {
+		filter.From, err = time.Parse(time.RFC3339, fromValue)
+		if err != nil {
+			return nil, ErrInvalidAuditFilter
+		}
+	}
+	filter.From, filter.To = filter.From.UTC(), filter.To.UTC()
+	if filter.From.After(filter.To) {
+		return nil, ErrInvalidAuditFilter
+	}
+
+	if limitValue != "" {
+		filter.Limit, err = strconv.Atoi(limitValue)
+		if err != nil || filter.Limit <= 0 ||
+			filter.Limit > db.MaxAuditEventsPerQuery {
+			return nil, ErrInvalidAuditFilter
+		}
+	}
+
+	return &filter, nil
+}
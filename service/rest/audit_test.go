@@ -0,0 +1,161 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/db"
+)
+
+func TestGetAuditEventFilter(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 10, 30, 0, 0, time.UTC)
+	tenantID := "fe6671ca-78de-4b19-9cd1-9e5247c2379e"
+
+	tests := []struct {
+		name          string
+		tenantID      string
+		fileID        string
+		from          string
+		to            string
+		limit         string
+		expectedFrom  string
+		expectedTo    string
+		expectedFile  uint64
+		expectedLimit int
+		isValid       bool
+	}{
+		{"default filter", "", "", "", "", "",
+			"2025-03-08T10:30:00Z", "2025-03-15T10:30:00Z", 0, 100, true},
+		{"tenant and file", tenantID, "42", "", "", "",
+			"2025-03-08T10:30:00Z", "2025-03-15T10:30:00Z", 42, 100, true},
+		{"explicit period", "", "", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", "10",
+			"2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", 0, 10, true},
+		{"period in other zone", "", "", "2025-01-01T02:00:00+02:00", "", "",
+			"2025-01-01T00:00:00Z", "2025-03-15T10:30:00Z", 0, 100, true},
+		{"invalid tenant", "tenant", "", "", "", "", "", "", 0, 0, false},
+		{"invalid file", "", "abc", "", "", "", "", "", 0, 0, false},
+		{"zero file", "", "0", "", "", "", "", "", 0, 0, false},
+		{"invalid start", "", "", "2025-01-01", "", "", "", "", 0, 0, false},
+		{"start after end", "", "", "2025-02-01T00:00:00Z", "2025-01-01T00:00:00Z", "",
+			"", "", 0, 0, false},
+		{"zero limit", "", "", "", "", "0", "", "", 0, 0, false},
+		{"limit too large", "", "", "", "", "1001", "", "", 0, 0, false},
+	}
+
+	for _, tc := range tests {
+		filter, err := getAuditEventFilter(tc.tenantID, tc.fileID, tc.from,
+			tc.to, tc.limit, now)
+		if (err == nil) != tc.isValid {
+			t.Fatalf("%s: Expected valid: %v, Got error: %v",
+				tc.name, tc.isValid, err)
+		}
+		if !tc.isValid {
+			continue
+		}
+		if filter.From.Format(time.RFC3339) != tc.expectedFrom {
+			t.Fatalf("%s: Bad start. Expected: %s, Got: %s",
+				tc.name, tc.expectedFrom, filter.From.Format(time.RFC3339))
+		}
+		if filter.To.Format(time.RFC3339) != tc.expectedTo {
+			t.Fatalf("%s: Bad end. Expected: %s, Got: %s",
+				tc.name, tc.expectedTo, filter.To.Format(time.RFC3339))
+		}
+		if filter.FileID != tc.expectedFile {
+			t.Fatalf("%s: Bad file. Expected: %d, Got: %d",
+				tc.name, tc.expectedFile, filter.FileID)
+		}
+		if filter.Limit != tc.expectedLimit {
+			t.Fatalf("%s: Bad limit. Expected: %d, Got: %d",
+				tc.name, tc.expectedLimit, filter.Limit)
+		}
+	}
+}
+
+func TestGetAuditOutcome(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected string
+	}{
+		{http.StatusOK, db.AuditOutcomeSuccess},
+		{http.StatusCreated, db.AuditOutcomeSuccess},
+		{http.StatusNoContent, db.AuditOutcomeSuccess},
+		{http.StatusBadRequest, db.AuditOutcomeRejected},
+		{http.StatusUnauthorized, db.AuditOutcomeDenied},
+		{http.StatusForbidden, db.AuditOutcomeDenied},
+		{http.StatusNotFound, db.AuditOutcomeNotFound},
+		{http.StatusConflict, db.AuditOutcomeRejected},
+		{http.StatusInternalServerError, db.AuditOutcomeFailure},
+	}
+
+	for _, tc := range tests {
+		outcome := getAuditOutcome(tc.status)
+		if outcome != tc.expected {
+			t.Fatalf("Bad outcome for status %d. Expected: %s, Got: %s",
+				tc.status, tc.expected, outcome)
+		}
+	}
+}
+
+func TestGetSourceIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{"remote address", "10.1.2.3:52000", "", "10.1.2.3"},
+		{"ipv6 remote address", "[2001:db8::1]:52000", "", "2001:db8::1"},
+		{"forwarded request", "10.1.2.3:52000", "203.0.113.7", "203.0.113.7"},
+		{"forwarded by proxies", "10.1.2.3:52000", "203.0.113.7, 10.0.0.1", "203.0.113.7"},
+	}
+
+	for _, tc := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/files/1", nil)
+		r.RemoteAddr = tc.remoteAddr
+		if tc.forwardedFor != "" {
+			r.Header.Set(headerForwardedFor, tc.forwardedFor)
+		}
+		sourceIP := getSourceIP(r)
+		if sourceIP != tc.expected {
+			t.Fatalf("%s: Bad source IP. Expected: %s, Got: %s",
+				tc.name, tc.expected, sourceIP)
+		}
+	}
+}
+
+func TestGetClientIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{"remote address", "10.1.2.3:52000", "", "10.1.2.3"},
+		{"untrusted peer", "198.51.100.9:52000", "203.0.113.7", "198.51.100.9"},
+		{"trusted proxy", "10.1.2.3:52000", "203.0.113.7", "203.0.113.7"},
+		{"forged hop", "10.1.2.3:52000", "203.0.113.7, 198.51.100.9", "198.51.100.9"},
+		{"trusted hops", "10.1.2.3:52000", "203.0.113.7, 192.0.2.1", "203.0.113.7"},
+		{"only trusted hops", "10.1.2.3:52000", "10.0.0.1", "10.0.0.1"},
+		{"invalid hop", "10.1.2.3:52000", "203.0.113.7, client", "client"},
+	}
+
+	trustedProxies = parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	t.Cleanup(func() { trustedProxies = nil })
+	for _, tc := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/files/1", nil)
+		r.RemoteAddr = tc.remoteAddr
+		if tc.forwardedFor != "" {
+			r.Header.Set(headerForwardedFor, tc.forwardedFor)
+		}
+		clientIP := getClientIP(r)
+		if clientIP != tc.expected {
+			t.Fatalf("%s: Bad client IP. Expected: %s, Got: %s",
+				tc.name, tc.expected, clientIP)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// Number of files that can be created or signed in a single batch
+	// request, unless configured otherwise.
+	defaultMaxBatchSize = 100
+
+	// Names of the batch routes, used to label batch item metrics.
+	routeNameBatchCreateFiles   = "BatchCreateFiles"
+	routeNameBatchGetSignedUrls = "BatchGetSignedUrls"
+
+	// Label of the batch item metrics for items that succeeded.
+	batchItemSucceeded = "ok"
+)
+
+// Largest number of files in a batch request.
+var maxBatchSize = defaultMaxBatchSize
+
+// Creates records for a batch of new files in the database and returns signed
+// URLs for the caller to upload the files to storage (S3). Each file is
+// validated separately and invalid files are reported in the results of the
+// batch, while the valid files are created in a single transaction.
+func BatchCreateFilesHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionCreate)
+	var results []common.BatchFileResult
+	defer func() { recordBatchAuditEvents(w, auditEvent, results, nil) }()
+
+	// Check if the contents of the POST were provided using JSON encoding.
+	if r.Header.Get(headerContentType) != contentTypeJson {
+		fsLogger.Error("BatchCreateFiles POST request does not have JSON encoding!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendUnsupportedMediaTypeResponse(w)
+		metrics.MetricBatchCreateFilesBadRequests.Inc()
+		return
+	}
+
+	// validate device token and get values
+	deviceInfo, err := getDeviceInfoFromToken(r)
+	if err != nil {
+		fsLogger.Info("BatchCreateFiles token validation error",
+			zap.Error(err))
+		sendUnauthorizedErrorResponse(w, err)
+		metrics.MetricBatchCreateFilesUnauthorizedRequests.Inc()
+		return
+	}
+	auditEvent.Actor = deviceInfo.DeviceID
+	auditEvent.TenantID = deviceInfo.TenantID
+
+	// Extract and unmarshal the batch create files request.
+	var request common.BatchCreateFilesRequest
+	payload, err := getRequestPayload(r)
+	if err == nil {
+		err = json.Unmarshal(payload, &request)
+	}
+	if err != nil {
+		fsLogger.Error("Failed to read the batch create files request payload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
+		metrics.MetricBatchCreateFilesBadRequests.Inc()
+		return
+	}
+
+	if !isValidBatchSize(len(request.Files)) {
+		fsLogger.Error("Invalid number of files in batch create files request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Int("Files:", len(request.Files)),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidBatchSize, nil)
+		metrics.MetricBatchCreateFilesBadRequests.Inc()
+		return
+	}
+
+	// Validate each file of the batch. The tenant and device of the files
+	// are those of the token.
+	batchResults := make([]common.BatchFileResult, len(request.Files))
+	validFiles := make([]common.CreateFileRequest, 0, len(request.Files))
+	validIndexes := make([]int, 0, len(request.Files))
+	for i := range request.Files {
+		file := &request.Files[i]
+		file.TenantID = deviceInfo.TenantID
+		file.DeviceID = deviceInfo.DeviceID
+		batchResults[i].Index = i
+
+		if file.Size < minFileLength {
+			batchResults[i].Error = newBatchItemError(ErrorCodeInvalidFileSize, nil)
+			continue
+		}
+		if err = validateCreateFileRequest(requestID, file); err != nil {
+			batchResults[i].Error = newBatchItemError(
+				errorCodeOf(err, ErrorCodeBadRequest), nil)
+			continue
+		}
+		validFiles = append(validFiles, *file)
+		validIndexes = append(validIndexes, i)
+	}
+
+	// Create entries for the valid files in the database.
+	if len(validFiles) > 0 {
+		createdFiles, err := db.CreateFiles(r.Context(), requestID, validFiles)
+		if err != nil {
+			fsLogger.Error("Failed to create entries for the files in the database!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+				zap.Error(err),
+			)
+			sendErrorResponseForError(w, err, ErrorCodeInternalError)
+			metrics.MetricBatchCreateFilesInternalErrors.Inc()
+			return
+		}
+
+		// Issue a pre-signed URL for each created file, which the client can
+		// use to upload the file to storage, or the content URL of the file
+		// if the tenant uses the content proxy.
+		for j := range createdFiles {
+			createdFile := &createdFiles[j]
+			result := &batchResults[validIndexes[j]]
+
+			file := newFileInformation(createdFile)
+			err = setFileUploadUrl(r.Context(), &file, createdFile,
+				time.Duration(validFiles[j].UrlDurationSeconds)*time.Second)
+			if err != nil {
+				fsLogger.Error("Failed to generate a signed URL for the file!",
+					zap.String("Request ID:", requestID),
+					tracing.TraceID(r.Context()),
+					zap.Uint64("File ID:", createdFile.FileID),
+					zap.Error(err),
+				)
+				result.Error = newBatchItemError(ErrorCodeInternalError,
+					map[string]string{
+						detailFileID: strconv.FormatUint(createdFile.FileID, 10),
+					})
+				continue
+			}
+			result.File = &file
+		}
+	}
+	results = batchResults
+
+	err = sendJsonResponse(w, http.StatusOK,
+		newBatchFilesResponse(requestID, routeNameBatchCreateFiles, results))
+	if err != nil {
+		metrics.MetricBatchCreateFilesInternalErrors.Inc()
+	}
+
+	metrics.MetricBatchCreateFilesResponses.Inc()
+}
+
+// Gets presigned GET URLs to download a batch of existing files. Files that
+// do not exist or may not be downloaded are reported in the results of the
+// batch.
+func BatchGetSignedUrlsHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionSignGet)
+	var results []common.BatchFileResult
+	var request common.BatchSignedUrlRequest
+	defer func() {
+		recordBatchAuditEvents(w, auditEvent, results, request.FileIDs)
+	}()
+
+	payload, err := getRequestPayload(r)
+	if err == nil {
+		err = json.Unmarshal(payload, &request)
+	}
+	if err != nil {
+		fsLogger.Error("Failed to read the batch signed URL request payload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
+		metrics.MetricBatchSignedUrlBadRequests.Inc()
+		return
+	}
+
+	if !isValidBatchSize(len(request.FileIDs)) {
+		fsLogger.Error("Invalid number of files in batch signed URL request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Int("Files:", len(request.FileIDs)),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidBatchSize, nil)
+		metrics.MetricBatchSignedUrlBadRequests.Inc()
+		return
+	}
+
+	duration, err := toUrlDuration(request.UrlDurationSeconds)
+	if err != nil {
+		fsLogger.Error("An invalid signed URL duration was specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidUrlDuration, nil)
+		metrics.MetricBatchSignedUrlBadRequests.Inc()
+		return
+	}
+
+	// Retrieve information about the files with a single database query.
+	foundFiles, err := db.GetFiles(r.Context(), requestID, request.FileIDs)
+	if err != nil {
+		sendInternalServerErrorResponse(w)
+		metrics.MetricBatchSignedUrlInternalErrors.Inc()
+		return
+	}
+
+	type device struct {
+		tenantID string
+		deviceID string
+	}
+	downloads := make(map[device]int64)
+	batchResults := make([]common.BatchFileResult, len(request.FileIDs))
+	for i, fileID := range request.FileIDs {
+		result := &batchResults[i]
+		result.Index = i
+		details := map[string]string{
+			detailFileID: strconv.FormatUint(fileID, 10),
+		}
+
+		foundFile, ok := foundFiles[fileID]
+		switch {
+		case !ok:
+			result.Error = newBatchItemError(ErrorCodeNotFound, details)
+			continue
+		case foundFile.Status == db.FileStatusQuarantined:
+			result.Error = newBatchItemError(ErrorCodeFileQuarantined, details)
+			continue
+		case isBlockedUnscannedDownload(foundFile.Status, config.AccessMethodGet):
+			result.Error = newBatchItemError(ErrorCodeFileNotScanned, details)
+			continue
+		}
+
+		file := newFileInformation(&foundFile)
+		err = setFileSignedUrl(r.Context(), &file, &foundFile,
+			config.AccessMethodGet, duration)
+		if err != nil {
+			fsLogger.Error("Failed to generate a signed URL for the file!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+				zap.Uint64("File ID:", fileID),
+				zap.Error(err),
+			)
+			result.Error = newBatchItemError(ErrorCodeInternalError, details)
+			continue
+		}
+		result.File = &file
+		downloads[device{foundFile.TenantID, foundFile.DeviceID}]++
+	}
+	results = batchResults
+
+	// Record the downloads in the usage rollups of the tenants on a separate
+	// goroutine, which outlives the request.
+	go func(ctx context.Context) {
+		for d, count := range downloads {
+			db.RecordDownloads(ctx, requestID, d.tenantID, d.deviceID, count)
+		}
+	}(context.WithoutCancel(r.Context()))
+
+	err = sendJsonResponse(w, http.StatusOK,
+		newBatchFilesResponse(requestID, routeNameBatchGetSignedUrls, results))
+	if err != nil {
+		metrics.MetricBatchSignedUrlInternalErrors.Inc()
+	}
+
+	metrics.MetricBatchSignedUrlResponses.Inc()
+}
+
+// isValidBatchSize returns true if a batch request may contain the specified
+// number of items.
+func isValidBatchSize(count int) bool {
+	return count > 0 && count <= maxBatchSize
+}
+
+// newBatchItemError returns the error reported for an item of a batch request
+// that failed with the specified error code.
+func newBatchItemError(code string,
+	details map[string]string) *common.BatchItemError {
+	return &common.BatchItemError{
+		Code:    code,
+		Message: errorCatalogue[code].message,
+		Details: details,
+	}
+}
+
+// newBatchFilesResponse returns the response to a batch request with the
+// specified results, and reports the outcome of each item of the batch.
+func newBatchFilesResponse(requestID string, route string,
+	results []common.BatchFileResult) common.BatchFilesResponse {
+	response := common.BatchFilesResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Results:      results,
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			response.Failed++
+			metrics.MetricBatchItems.WithLabelValues(route, result.Error.Code).Inc()
+			continue
+		}
+		response.Succeeded++
+		metrics.MetricBatchItems.WithLabelValues(route, batchItemSucceeded).Inc()
+	}
+	return response
+}
+
+// newFileInformation returns the information about the specified file sent
+// in responses.
+func newFileInformation(file *db.File) common.FileInformation {
+	return common.FileInformation{
+		FileID:    file.FileID,
+		TenantID:  file.TenantID,
+		DeviceID:  file.DeviceID,
+		Name:      file.Name,
+		Namespace: file.Namespace,
+		Version:   file.Version,
+		Checksum:  file.Checksum,
+		Size:      file.Size,
+		Status:    file.Status,
+		CreatedAt: file.CreatedAt,
+		UpdatedAt: file.UpdatedAt,
+	}
+}
+
+// recordBatchAuditEvents queues an audit event for each item of a batch
+// request, with the outcome of the item. The file of an item is that of its
+// result or, if the item failed, the requested file, if any. If the request
+// failed as a whole, a single event is recorded for the request.
+func recordBatchAuditEvents(w http.ResponseWriter, event *db.AuditEvent,
+	results []common.BatchFileResult, fileIDs []uint64) {
+	if results == nil {
+		recordAuditEvent(w, event)
+		return
+	}
+
+	for i, result := range results {
+		itemEvent := *event
+		itemEvent.Outcome = db.AuditOutcomeSuccess
+		if i < len(fileIDs) {
+			itemEvent.FileID = fileIDs[i]
+		}
+		if result.File != nil {
+			itemEvent.FileID = result.File.FileID
+			itemEvent.TenantID = result.File.TenantID
+		}
+		if result.Error != nil {
+			itemEvent.Outcome = getAuditOutcome(
+				errorCatalogue[result.Error.Code].status)
+		}
+		db.RecordAuditEvent(itemEvent)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/common"
+)
+
+// validate the number of items allowed in batch requests
+func TestBatchSize(t *testing.T) {
+	m := map[int]testTableResult{
+		0:                {`empty batch`, false},
+		1:                {`single item`, true},
+		maxBatchSize:     {`largest batch`, true},
+		maxBatchSize + 1: {`batch too large`, false},
+	}
+
+	for k, v := range m {
+		if isValidBatchSize(k) != v.result {
+			t.Fatalf("Batch size validation error: %s, expected: %v, got: %v",
+				v.desc, v.result, !v.result)
+		}
+	}
+}
+
+// validate the counts and item errors of batch responses
+func TestBatchFilesResponse(t *testing.T) {
+	results := []common.BatchFileResult{
+		{Index: 0, File: &common.FileInformation{FileID: 1}},
+		{Index: 1, Error: newBatchItemError(ErrorCodeInvalidFileName, nil)},
+		{Index: 2, Error: newBatchItemError(ErrorCodeNotFound,
+			map[string]string{detailFileID: "3"})},
+	}
+
+	response := newBatchFilesResponse("request", routeNameBatchCreateFiles,
+		results)
+	if response.Succeeded != 1 || response.Failed != 2 {
+		t.Fatalf("Batch response counts error, expected: 1/2, got: %d/%d",
+			response.Succeeded, response.Failed)
+	}
+	itemError := response.Results[1].Error
+	if itemError.Code != ErrorCodeInvalidFileName ||
+		itemError.Message != errorCatalogue[ErrorCodeInvalidFileName].message {
+		t.Fatalf("Batch item error mismatch, got: %+v", itemError)
+	}
+}
@@ -10,10 +10,9 @@ import (
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/common"
-	"github.com/HPInc/krypton-fs/service/config"
 	"github.com/HPInc/krypton-fs/service/db"
 	"github.com/HPInc/krypton-fs/service/metrics"
-	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -22,6 +21,8 @@ const (
 	minFileNameLength = 1
 	maxFileNameLength = 127
 
+	maxNamespaceLength = 63
+
 	minChecksumLength = 3
 	maxChecksumLength = 25
 
@@ -32,11 +33,14 @@ const (
 // the caller to upload the file to storage (S3).
 func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionCreate)
+	defer recordAuditEvent(w, auditEvent)
 
 	// Check if the contents of the POST were provided using JSON encoding.
 	if r.Header.Get(headerContentType) != contentTypeJson {
 		fsLogger.Error("CreateFile POST request does not have JSON encoding!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 		)
 		sendUnsupportedMediaTypeResponse(w)
 		metrics.MetricCreateFileUnSupportedMediaTypeRequests.Inc()
@@ -48,19 +52,22 @@ func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		fsLogger.Info("CreateFile token validation error",
 			zap.Error(err))
-		sendUnauthorizedErrorResponse(w)
+		sendUnauthorizedErrorResponse(w, err)
 		metrics.MetricCreateFileUnauthorizedRequests.Inc()
 		return
 	}
+	auditEvent.Actor = deviceInfo.DeviceID
+	auditEvent.TenantID = deviceInfo.TenantID
 
 	// Extract the create file request payload.
 	payload, err := getRequestPayload(r)
 	if err != nil {
 		fsLogger.Error("Failed to read the create file request payload",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
-		sendBadRequestErrorResponse(w)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
 		metrics.MetricCreateFileBadRequests.Inc()
 		return
 	}
@@ -71,9 +78,10 @@ func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		fsLogger.Error("Failed to unmarshall the create file request",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
-		sendBadRequestErrorResponse(w)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
 		metrics.MetricCreateFileBadRequests.Inc()
 		return
 	}
@@ -81,10 +89,10 @@ func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	if request.Size < minFileLength {
 		fsLogger.Error("Invalid file size in create file request",
 			zap.String("Request ID", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Int64("Size", request.Size),
-			zap.Error(err),
 		)
-		sendBadRequestErrorResponse(w)
+		sendErrorResponse(w, ErrorCodeInvalidFileSize, nil)
 		metrics.MetricCreateFileBadRequests.Inc()
 		return
 	}
@@ -94,24 +102,26 @@ func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	request.DeviceID = deviceInfo.DeviceID
 
 	// Validate the create file request.
-	if !isValidCreateFileRequest(requestID, &request) {
-		sendBadRequestErrorResponse(w)
+	if err = validateCreateFileRequest(requestID, &request); err != nil {
+		sendErrorResponseForError(w, err, ErrorCodeBadRequest)
 		metrics.MetricCreateFileBadRequests.Inc()
 		return
 	}
 
 	// Create an entry for the file in the database. This process will yield
 	// a unique sequence number (ID) for the file.
-	createdFile, err := db.CreateFile(requestID, &request)
+	createdFile, err := db.CreateFile(r.Context(), requestID, &request)
 	if err != nil {
 		fsLogger.Error("Failed to create an entry for the file in the database!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
-		sendInternalServerErrorResponse(w)
+		sendErrorResponseForError(w, err, ErrorCodeInternalError)
 		metrics.MetricCreateFileInternalErrors.Inc()
 		return
 	}
+	auditEvent.FileID = createdFile.FileID
 
 	response := common.CommonFileResponse{
 		File: common.FileInformation{
@@ -119,6 +129,8 @@ func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 			TenantID:  createdFile.TenantID,
 			DeviceID:  createdFile.DeviceID,
 			Name:      createdFile.Name,
+			Namespace: createdFile.Namespace,
+			Version:   createdFile.Version,
 			Checksum:  createdFile.Checksum,
 			Size:      createdFile.Size,
 			CreatedAt: createdFile.CreatedAt,
@@ -129,16 +141,15 @@ func CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Issue a pre-signed URL corresponding to this file ID. The pre-signed URL
-	// can be used by the client to upload the file to storage.
-	response.File.SignedUrl, err = storage.Provider.GetSignedUrl(
-		createdFile.BucketName,
-		storage.GetObjectName(request.TenantID, request.DeviceID, createdFile.FileID),
-		config.AccessMethodPut,
-		request.Checksum,
-		request.Size)
+	// can be used by the client to upload the file to storage. Clients of
+	// tenants that use the content proxy upload the file through the service
+	// instead.
+	err = setFileUploadUrl(r.Context(), &response.File, createdFile,
+		time.Duration(request.UrlDurationSeconds)*time.Second)
 	if err != nil {
 		fsLogger.Error("Failed to generate a signed URL for the file!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendInternalServerErrorResponse(w)
@@ -160,16 +171,16 @@ func isValidUUID(u string) bool {
 }
 
 // Validate the create file request. Invalid requests are failed with an HTTP bad
-// request error.
-func isValidCreateFileRequest(requestID string,
-	request *common.CreateFileRequest) bool {
+// request error, with the error code of the returned error.
+func validateCreateFileRequest(requestID string,
+	request *common.CreateFileRequest) error {
 	// Validate that the tenant ID specified is a valid UUID.
 	if !isValidUUID(request.TenantID) {
 		fsLogger.Error("Invalid tenant id",
 			zap.String("Request ID", requestID),
 			zap.String("Tenant ID", request.TenantID),
 		)
-		return false
+		return ErrInvalidTenantID
 	}
 
 	// Validate that the device ID specified is a valid UUID.
@@ -178,7 +189,7 @@ func isValidCreateFileRequest(requestID string,
 			zap.String("Request ID", requestID),
 			zap.String("Device ID", request.DeviceID),
 		)
-		return false
+		return ErrInvalidDeviceID
 	}
 
 	// Ensure the request provided a valid file name.
@@ -187,7 +198,16 @@ func isValidCreateFileRequest(requestID string,
 			zap.String("Request ID", requestID),
 			zap.String("File name", request.Name),
 		)
-		return false
+		return ErrInvalidFileName
+	}
+
+	// The namespace is optional but must be valid if specified.
+	if !isValidNamespace(request.Namespace) {
+		fsLogger.Error("Invalid namespace",
+			zap.String("Request ID", requestID),
+			zap.String("Namespace", request.Namespace),
+		)
+		return ErrInvalidNamespace
 	}
 
 	// Ensure the request specified a non-empty checksum for the file.
@@ -196,9 +216,19 @@ func isValidCreateFileRequest(requestID string,
 			zap.String("Request ID", requestID),
 			zap.String("Checksum", request.Checksum),
 		)
-		return false
+		return ErrInvalidChecksum
+	}
+
+	// The duration of the upload URL is optional but must be valid if
+	// specified.
+	if _, err := toUrlDuration(request.UrlDurationSeconds); err != nil {
+		fsLogger.Error("Invalid signed URL duration",
+			zap.String("Request ID", requestID),
+			zap.Int64("Duration", request.UrlDurationSeconds),
+		)
+		return err
 	}
-	return true
+	return nil
 }
 
 // validate file name
@@ -210,6 +240,17 @@ func isValidFileName(name string) bool {
 	return fileNameRegex.MatchString(name)
 }
 
+// validate namespace. An empty namespace is valid and is the default.
+func isValidNamespace(namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+	if len(namespace) > maxNamespaceLength {
+		return false
+	}
+	return fileNameRegex.MatchString(namespace)
+}
+
 // validate checksum
 func isValidChecksum(checksum string) bool {
 	checksumLength := len(checksum)
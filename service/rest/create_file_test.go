@@ -64,3 +64,23 @@ func TestChecksumValidation(t *testing.T) {
 		}
 	}
 }
+
+// validate namespace
+func TestNamespaceValidation(t *testing.T) {
+	m := map[string]testTableResult{
+		``:                                      {`empty namespace is the default`, true},
+		`logs`:                                  {`simple namespace`, true},
+		`diag.v2_x-y`:                           {`. _ - are allowed`, true},
+		`a/b`:                                   {`/ is not allowed`, false},
+		strings.Repeat(`a`, maxNamespaceLength): {`maximum allowed length`, true},
+		strings.Repeat(`a`, maxNamespaceLength+1): {`too long`, false},
+	}
+
+	for k, v := range m {
+		if isValidNamespace(k) != v.result {
+			t.Fatalf(
+				"Namespace validation error: %s - %s, expected: %v, got: %v",
+				k, v.desc, v.result, !v.result)
+		}
+	}
+}
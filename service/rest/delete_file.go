@@ -8,43 +8,62 @@ import (
 
 	"github.com/HPInc/krypton-fs/service/db"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"go.uber.org/zap"
 )
 
 func DeleteFileHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionDelete)
+	defer recordAuditEvent(w, auditEvent)
 
 	// Retrieve the specified file identifier.
 	fileID, err := getPathVariable(r, paramFileID, true)
 	if err != nil {
 		fsLogger.Error("The required file id path variable was not specified in the request",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 		)
 		sendBadRequestErrorResponse(w)
 		metrics.MetricDeleteFileBadRequests.Inc()
 		return
 	}
+	auditEvent.FileID = getAuditFileID(fileID)
 
 	// Delete the specified file from the database.
-	err = db.DeleteFile(requestID, fileID)
+	deletedFile, err := db.DeleteFile(r.Context(), requestID, fileID)
 	if err != nil {
 		if err == db.ErrNotFound {
 			fsLogger.Error("No file with the requested file ID was found in the database",
 				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
 			)
 			sendNotFoundErrorResponse(w)
 			metrics.MetricDeleteFileNotFoundErrors.Inc()
 			return
 		}
+		if err == db.ErrLegalHold {
+			fsLogger.Error("The requested file is under legal hold and cannot be deleted",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+			)
+			sendErrorResponse(w, ErrorCodeLegalHold, map[string]string{
+				detailFileID: fileID,
+			})
+			metrics.MetricDeleteFileLegalHoldErrors.Inc()
+			return
+		}
 
 		fsLogger.Error("Failed to delete the specified file from the database!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendInternalServerErrorResponse(w)
 		metrics.MetricDeleteFileInternalErrors.Inc()
 		return
 	}
+	auditEvent.TenantID = deletedFile.TenantID
 
 	err = sendJsonResponse(w, http.StatusNoContent, nil)
 	if err != nil {
@@ -0,0 +1,190 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/db"
+)
+
+// Machine-readable error codes returned in error responses. Clients depend on
+// these codes, so existing codes must not be changed or reused.
+const (
+	ErrorCodeBadRequest           = "bad_request"
+	ErrorCodeInvalidPayload       = "invalid_payload"
+	ErrorCodeInvalidTenantID      = "invalid_tenant_id"
+	ErrorCodeInvalidDeviceID      = "invalid_device_id"
+	ErrorCodeInvalidFileName      = "invalid_file_name"
+	ErrorCodeInvalidNamespace     = "invalid_namespace"
+	ErrorCodeInvalidChecksum      = "invalid_checksum"
+	ErrorCodeInvalidFileSize      = "invalid_file_size"
+	ErrorCodeInvalidBatchSize     = "invalid_batch_size"
+	ErrorCodeInvalidUrlDuration   = "invalid_url_duration"
+	ErrorCodeInvalidChunk         = "invalid_chunk"
+	ErrorCodeUnauthorized         = "unauthorized"
+	ErrorCodeMissingAuthorization = "missing_authorization"
+	ErrorCodeInvalidToken         = "invalid_token"
+	ErrorCodeForbidden            = "forbidden"
+	ErrorCodeFileQuarantined      = "file_quarantined"
+	ErrorCodeFileNotScanned       = "file_not_scanned"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeMethodNotAllowed     = "method_not_allowed"
+	ErrorCodeConflict             = "conflict"
+	ErrorCodeOperationNotAllowed  = "operation_not_allowed"
+	ErrorCodeLegalHold            = "legal_hold"
+	ErrorCodeUploadOffsetMismatch = "upload_offset_mismatch"
+	ErrorCodeUploadInProgress     = "upload_in_progress"
+	ErrorCodeChecksumMismatch     = "checksum_mismatch"
+	ErrorCodeUnsupportedVersion   = "unsupported_version"
+	ErrorCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrorCodeUnsupportedChunkType = "unsupported_chunk_type"
+	ErrorCodeInternalError        = "internal_error"
+	ErrorCodeServiceUnavailable   = "service_unavailable"
+)
+
+// Keys of the details included in error responses.
+const (
+	detailFileID = "file_id"
+)
+
+// Interval after which clients may retry requests that failed because the
+// service is unavailable.
+const serviceUnavailableRetryAfter = 30 * time.Second
+
+// Status code of responses to resumable uploads whose checksum does not match
+// the checksum of the file, as defined by the tus protocol.
+const statusChecksumMismatch = 460
+
+// errorDefinition describes the response sent for an error code.
+type errorDefinition struct {
+	// HTTP status code of the response.
+	status int
+
+	// Message describing the error.
+	message string
+
+	// If set, the interval after which the request may be retried.
+	retryAfter time.Duration
+}
+
+// Catalogue of the error codes returned by the service.
+var errorCatalogue = map[string]errorDefinition{
+	ErrorCodeBadRequest: {http.StatusBadRequest,
+		"The request is invalid.", 0},
+	ErrorCodeInvalidPayload: {http.StatusBadRequest,
+		"The request payload could not be read or parsed.", 0},
+	ErrorCodeInvalidTenantID: {http.StatusBadRequest,
+		"A valid tenant ID was not specified.", 0},
+	ErrorCodeInvalidDeviceID: {http.StatusBadRequest,
+		"A valid device ID was not specified.", 0},
+	ErrorCodeInvalidFileName: {http.StatusBadRequest,
+		"The specified file name is invalid.", 0},
+	ErrorCodeInvalidNamespace: {http.StatusBadRequest,
+		"The specified namespace is invalid.", 0},
+	ErrorCodeInvalidChecksum: {http.StatusBadRequest,
+		"The specified checksum is not a valid base64 encoded digest.", 0},
+	ErrorCodeInvalidFileSize: {http.StatusBadRequest,
+		"The specified file size is invalid.", 0},
+	ErrorCodeInvalidBatchSize: {http.StatusBadRequest,
+		"The batch is empty or contains more items than allowed.", 0},
+	ErrorCodeInvalidUrlDuration: {http.StatusBadRequest,
+		"The requested signed URL duration is invalid.", 0},
+	ErrorCodeInvalidChunk: {http.StatusBadRequest,
+		"The offset or size of the chunk of the upload is invalid.", 0},
+	ErrorCodeUnauthorized: {http.StatusUnauthorized,
+		"The request is not authorized.", 0},
+	ErrorCodeMissingAuthorization: {http.StatusUnauthorized,
+		"The request does not specify a bearer token.", 0},
+	ErrorCodeInvalidToken: {http.StatusUnauthorized,
+		"The specified bearer token is invalid.", 0},
+	ErrorCodeForbidden: {http.StatusForbidden,
+		"The requested operation is forbidden.", 0},
+	ErrorCodeFileQuarantined: {http.StatusForbidden,
+		"The file has been quarantined by malware scanning.", 0},
+	ErrorCodeFileNotScanned: {http.StatusForbidden,
+		"The file has not yet been scanned for malware.", 0},
+	ErrorCodeNotFound: {http.StatusNotFound,
+		"The requested resource was not found.", 0},
+	ErrorCodeMethodNotAllowed: {http.StatusMethodNotAllowed,
+		"The requested method is not allowed for the resource.", 0},
+	ErrorCodeConflict: {http.StatusConflict,
+		"The request conflicts with the current state of the resource.", 0},
+	ErrorCodeOperationNotAllowed: {http.StatusConflict,
+		"The requested operation is not allowed in the current state of the resource.", 0},
+	ErrorCodeLegalHold: {http.StatusConflict,
+		"The file is under legal hold.", 0},
+	ErrorCodeUploadOffsetMismatch: {http.StatusConflict,
+		"The offset of the chunk does not match the offset of the upload.", 0},
+	ErrorCodeUploadInProgress: {http.StatusLocked,
+		"Another chunk is being appended to the upload.", 0},
+	ErrorCodeChecksumMismatch: {statusChecksumMismatch,
+		"The uploaded file does not match its checksum and was discarded.", 0},
+	ErrorCodeUnsupportedVersion: {http.StatusPreconditionFailed,
+		"The requested version of the upload protocol is not supported.", 0},
+	ErrorCodeUnsupportedMediaType: {http.StatusUnsupportedMediaType,
+		"The request payload must be JSON encoded.", 0},
+	ErrorCodeUnsupportedChunkType: {http.StatusUnsupportedMediaType,
+		"Chunks must be sent with the application/offset+octet-stream content type.", 0},
+	ErrorCodeInternalError: {http.StatusInternalServerError,
+		"An internal error occurred while processing the request.", 0},
+	ErrorCodeServiceUnavailable: {http.StatusServiceUnavailable,
+		"The service is temporarily unavailable.", serviceUnavailableRetryAfter},
+}
+
+// Error codes of the sentinel errors returned by the rest and db packages.
+var sentinelErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrUrlDecode, ErrorCodeBadRequest},
+	{ErrPathVariableMissing, ErrorCodeBadRequest},
+	{ErrPayloadRead, ErrorCodeInvalidPayload},
+	{ErrInvalidTenantID, ErrorCodeInvalidTenantID},
+	{ErrInvalidDeviceID, ErrorCodeInvalidDeviceID},
+	{ErrInvalidFileName, ErrorCodeInvalidFileName},
+	{ErrInvalidNamespace, ErrorCodeInvalidNamespace},
+	{ErrInvalidChecksum, ErrorCodeInvalidChecksum},
+	{ErrInvalidFileSize, ErrorCodeInvalidFileSize},
+	{ErrInvalidUrlDuration, ErrorCodeInvalidUrlDuration},
+	{ErrInvalidResponseHeaders, ErrorCodeBadRequest},
+	{ErrInvalidRedirectUrlOptions, ErrorCodeBadRequest},
+	{ErrRedirectUrlsDisabled, ErrorCodeBadRequest},
+	{ErrInvalidLegalHold, ErrorCodeBadRequest},
+	{ErrInvalidChunk, ErrorCodeInvalidChunk},
+	{ErrInvalidUploadMetadata, ErrorCodeBadRequest},
+	{ErrChecksumMismatch, ErrorCodeChecksumMismatch},
+	{ErrNoAuthorizationHeader, ErrorCodeMissingAuthorization},
+	{ErrNoBearerTokenSpecified, ErrorCodeMissingAuthorization},
+	{ErrInvalidToken, ErrorCodeInvalidToken},
+	{ErrInvalidTokenHeaderKid, ErrorCodeInvalidToken},
+	{ErrInvalidTokenHeaderSigningAlg, ErrorCodeInvalidToken},
+	{ErrInvalidIssuerClaim, ErrorCodeInvalidToken},
+	{ErrInvalidAudienceClaim, ErrorCodeInvalidToken},
+	{ErrInvalidSubjectClaim, ErrorCodeInvalidToken},
+	{ErrInvalidTypeClaim, ErrorCodeInvalidToken},
+	{ErrAuthz, ErrorCodeForbidden},
+	{db.ErrInvalidRequest, ErrorCodeBadRequest},
+	{db.ErrNotFound, ErrorCodeNotFound},
+	{db.ErrDuplicateEntry, ErrorCodeConflict},
+	{db.ErrNotAllowed, ErrorCodeOperationNotAllowed},
+	{db.ErrLegalHold, ErrorCodeLegalHold},
+	{db.ErrUploadOffsetMismatch, ErrorCodeUploadOffsetMismatch},
+	{db.ErrUploadClaimed, ErrorCodeUploadInProgress},
+	{db.ErrNoBuckets, ErrorCodeServiceUnavailable},
+	{db.ErrInternalError, ErrorCodeInternalError},
+}
+
+// errorCodeOf returns the error code of the specified error, or the fallback
+// code if the error does not have one.
+func errorCodeOf(err error, fallback string) string {
+	for _, entry := range sentinelErrorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return fallback
+}
@@ -21,4 +21,13 @@ var (
 	ErrInvalidTypeClaim             = errors.New("specified token contains an invalid typ claim")
 	ErrNoAuthorizationHeader        = errors.New("request does not have an authorization header")
 	ErrNoBearerTokenSpecified       = errors.New("authorization header does not contain a bearer token")
+	ErrInvalidTenantID              = errors.New("invalid tenant id specified")
+	ErrInvalidDeviceID              = errors.New("invalid device id specified")
+	ErrInvalidFileName              = errors.New("invalid file name specified")
+	ErrInvalidNamespace             = errors.New("invalid namespace specified")
+	ErrInvalidChecksum              = errors.New("invalid checksum specified")
+	ErrInvalidFileSize              = errors.New("invalid file size specified")
+	ErrInvalidUrlDuration           = errors.New("invalid signed url duration specified")
+	ErrInvalidResponseHeaders       = errors.New("invalid response header overrides specified")
+	ErrInvalidLegalHold             = errors.New("invalid legal hold specified")
 )
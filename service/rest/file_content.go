@@ -0,0 +1,373 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// Devices of some tenants cannot reach storage, because their network only
+// allows access to the files service. For these tenants, the content proxy
+// streams the contents of files between the devices and storage, and
+// CreateFile returns the content URL of the file instead of a signed upload
+// URL. Devices upload and download the contents at the content URL with their
+// device token, and the service verifies the size and checksum of the
+// contents as they are streamed.
+const (
+	// Format of the URL at which the contents of a file are served, from the
+	// base URL of the service and the file ID.
+	fileContentUrlFormat = "%s/api/v1/files/%d/content"
+
+	// Headers describing the contents of a file.
+	headerContentLength = "Content-Length"
+	headerContentMD5    = "Content-MD5"
+
+	// Default time allowed to transfer a file through the content proxy.
+	defaultProxyTransferTimeout = 600 * time.Second
+
+	// Labels of the content proxy metrics.
+	proxyTransferSucceeded = "ok"
+	proxyDirectionUpload   = "upload"
+	proxyDirectionDownload = "download"
+)
+
+// contentProxy - content proxy settings, with defaults applied. The tenants
+// are replaced when the configuration is reloaded.
+var contentProxy atomic.Pointer[contentProxySettings]
+
+// contentProxySettings - content proxy settings.
+type contentProxySettings struct {
+	// Tenants whose files are transferred through the service, by lower case
+	// tenant ID.
+	tenantIDs map[string]bool
+
+	// Time allowed to transfer a file.
+	transferTimeout time.Duration
+}
+
+// byteCounter - counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// Applies the content proxy settings of the service.
+func updateContentProxy(settings *config.ContentProxy) {
+	proxy := contentProxySettings{
+		tenantIDs:       make(map[string]bool, len(settings.TenantIDs)),
+		transferTimeout: defaultProxyTransferTimeout,
+	}
+	for _, tenantID := range settings.TenantIDs {
+		proxy.tenantIDs[strings.ToLower(tenantID)] = true
+	}
+	if settings.TransferTimeoutSeconds > 0 {
+		proxy.transferTimeout = time.Duration(
+			settings.TransferTimeoutSeconds) * time.Second
+	}
+	contentProxy.Store(&proxy)
+}
+
+// Returns whether the files of the tenant are transferred through the
+// content proxy.
+func isProxiedTenant(tenantID string) bool {
+	proxy := contentProxy.Load()
+	return proxy != nil && proxy.tenantIDs[strings.ToLower(tenantID)]
+}
+
+// getFileContentUrl returns the URL at which the contents of the file are
+// transferred through the content proxy.
+func getFileContentUrl(fileID uint64) string {
+	return fmt.Sprintf(fileContentUrlFormat, redirectBaseUrl, fileID)
+}
+
+// setFileUploadUrl sets the URL at which the device uploads a new file in the
+// information returned about the file. Devices of proxied tenants upload the
+// file to its content URL, which does not expire, and other devices upload it
+// to storage with a signed URL that is valid for the requested duration.
+func setFileUploadUrl(ctx context.Context, info *common.FileInformation,
+	file *db.File, duration time.Duration) error {
+	if isProxiedTenant(file.TenantID) {
+		info.SignedUrl = getFileContentUrl(file.FileID)
+		return nil
+	}
+	return setFileSignedUrl(ctx, info, file, config.AccessMethodPut, duration)
+}
+
+// PutFileContentHandler uploads the contents of a new file through the
+// content proxy. The contents are streamed to storage, and the file is marked
+// uploaded once they have been stored and verified.
+func PutFileContentHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionUpload)
+	defer recordAuditEvent(w, auditEvent)
+
+	code := putFileContent(w, r, requestID, auditEvent)
+	if code == "" {
+		metrics.MetricProxyUploads.WithLabelValues(proxyTransferSucceeded).Inc()
+		return
+	}
+	metrics.MetricProxyUploads.WithLabelValues(code).Inc()
+	sendErrorResponse(w, code, nil)
+}
+
+// Stores the contents in the body of the request as the contents of the
+// file. Returns the error code sent to the caller if the contents cannot be
+// stored.
+func putFileContent(w http.ResponseWriter, r *http.Request, requestID string,
+	auditEvent *db.AuditEvent) string {
+	foundFile, code := getProxiedFile(r, requestID, auditEvent)
+	if code != "" {
+		return code
+	}
+	if foundFile.Status != db.FileStatusNew {
+		return ErrorCodeOperationNotAllowed
+	}
+
+	// The contents must have the size and checksum specified when the file
+	// was created.
+	if r.ContentLength != foundFile.Size {
+		fsLogger.Error("The size of the uploaded contents does not match the size of the file",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+			zap.Int64("Size:", r.ContentLength),
+		)
+		return ErrorCodeInvalidFileSize
+	}
+	contentMD5 := r.Header.Get(headerContentMD5)
+	if contentMD5 != "" && contentMD5 != foundFile.Checksum {
+		return ErrorCodeChecksumMismatch
+	}
+
+	timeout := contentProxy.Load().transferTimeout
+	controller := http.NewResponseController(w)
+	deadline := time.Now().Add(timeout)
+	_ = controller.SetReadDeadline(deadline)
+	_ = controller.SetWriteDeadline(deadline)
+
+	// Storage rejects contents that do not match the checksum. The checksum
+	// is also computed as the contents are streamed, to tell those contents
+	// apart from other failures.
+	digest := md5.New()
+	var received byteCounter
+	objectName := storage.GetObjectName(foundFile.TenantID, foundFile.DeviceID,
+		foundFile.FileID)
+	err := storage.Provider.PutObject(r.Context(), foundFile.BucketName,
+		objectName, io.TeeReader(r.Body, io.MultiWriter(digest, &received)),
+		foundFile.Size, foundFile.Checksum, timeout)
+	metrics.MetricProxyBytes.WithLabelValues(proxyDirectionUpload).Add(
+		float64(received))
+
+	ctx := context.WithoutCancel(r.Context())
+	checksum := base64.StdEncoding.EncodeToString(digest.Sum(nil))
+	if int64(received) == foundFile.Size && checksum != foundFile.Checksum {
+		fsLogger.Error("The uploaded contents do not match the checksum of the file!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", foundFile.FileID),
+		)
+		if err == nil {
+			_ = storage.Provider.DeleteObject(ctx, foundFile.BucketName,
+				objectName)
+		}
+		return ErrorCodeChecksumMismatch
+	}
+	if err != nil {
+		return ErrorCodeInternalError
+	}
+
+	if err = markFileStored(ctx, foundFile); err != nil {
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+	fsLogger.Info("Uploaded the contents of the file through the content proxy.",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.Uint64("File ID:", foundFile.FileID),
+	)
+	w.WriteHeader(http.StatusOK)
+	return ""
+}
+
+// GetFileContentHandler downloads the contents of a file through the content
+// proxy. The contents are streamed from storage, and the download is aborted
+// if they do not match the size and checksum of the file.
+func GetFileContentHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionDownload)
+	defer recordAuditEvent(w, auditEvent)
+
+	code := getFileContent(w, r, requestID, auditEvent)
+	if code == "" {
+		metrics.MetricProxyDownloads.WithLabelValues(
+			proxyTransferSucceeded).Inc()
+		return
+	}
+	metrics.MetricProxyDownloads.WithLabelValues(code).Inc()
+	sendErrorResponse(w, code, nil)
+}
+
+// Sends the contents of the file in the body of the response. Returns the
+// error code sent to the caller if the contents cannot be sent.
+func getFileContent(w http.ResponseWriter, r *http.Request, requestID string,
+	auditEvent *db.AuditEvent) string {
+	foundFile, code := getProxiedFile(r, requestID, auditEvent)
+	if code != "" {
+		return code
+	}
+	switch {
+	case foundFile.Status == db.FileStatusNew:
+		return ErrorCodeNotFound
+	case foundFile.Status == db.FileStatusQuarantined:
+		return ErrorCodeFileQuarantined
+	case isBlockedUnscannedDownload(foundFile.Status, config.AccessMethodGet):
+		return ErrorCodeFileNotScanned
+	}
+
+	timeout := contentProxy.Load().transferTimeout
+	contents, size, err := storage.Provider.GetObject(r.Context(),
+		foundFile.BucketName, storage.GetObjectName(foundFile.TenantID,
+			foundFile.DeviceID, foundFile.FileID), timeout)
+	if err != nil {
+		return ErrorCodeInternalError
+	}
+	defer contents.Close()
+	if size != foundFile.Size {
+		fsLogger.Error("The stored contents do not match the size of the file!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+			zap.Int64("Size:", size),
+		)
+		return ErrorCodeInternalError
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+	w.Header().Set(headerContentType, defaultContentType)
+	w.Header().Set(headerContentLength, strconv.FormatInt(size, 10))
+	w.Header().Set(headerContentMD5, foundFile.Checksum)
+	w.Header().Set(headerCacheControl, "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	digest := md5.New()
+	sent, err := io.Copy(w, io.TeeReader(contents, digest))
+	metrics.MetricProxyBytes.WithLabelValues(proxyDirectionDownload).Add(
+		float64(sent))
+	checksum := base64.StdEncoding.EncodeToString(digest.Sum(nil))
+	if err != nil || sent != size || checksum != foundFile.Checksum {
+		fsLogger.Error("Failed to send the contents of the file through the content proxy!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+			zap.Int64("Bytes sent:", sent),
+			zap.Bool("Checksum matched:", checksum == foundFile.Checksum),
+			zap.Error(err),
+		)
+		metrics.MetricProxyDownloads.WithLabelValues(
+			ErrorCodeInternalError).Inc()
+
+		// The status has been sent, so abort the response to keep the device
+		// from accepting contents that were not verified.
+		panic(http.ErrAbortHandler)
+	}
+
+	// Record the download in the usage rollup for the tenant on a separate
+	// goroutine.
+	go db.RecordDownload(context.WithoutCancel(r.Context()), requestID,
+		foundFile.TenantID, foundFile.DeviceID)
+	return ""
+}
+
+// Returns the file whose contents are transferred by the request, which must
+// belong to the device of the device token of the request and to a tenant
+// whose files are transferred through the content proxy. Returns the error
+// code sent to the caller otherwise.
+func getProxiedFile(r *http.Request, requestID string,
+	auditEvent *db.AuditEvent) (*db.File, string) {
+	fileID, err := getPathVariable(r, paramFileID, true)
+	if err != nil {
+		return nil, ErrorCodeBadRequest
+	}
+	auditEvent.FileID = getAuditFileID(fileID)
+
+	foundFile, code := getDeviceFile(r, requestID, fileID, auditEvent)
+	if code != "" {
+		return nil, code
+	}
+	if !isProxiedTenant(foundFile.TenantID) {
+		fsLogger.Info("Refusing to transfer a file of a tenant that does not use the content proxy",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+		)
+		return nil, ErrorCodeForbidden
+	}
+	return foundFile, ""
+}
+
+// Returns the file with the specified identifier, which must belong to the
+// device of the device token of the request. Returns the error code sent to
+// the caller if the file does not belong to the caller.
+func getDeviceFile(r *http.Request, requestID string, fileID string,
+	auditEvent *db.AuditEvent) (*db.File, string) {
+	deviceInfo, err := getDeviceInfoFromToken(r)
+	if err != nil {
+		fsLogger.Info("Device token validation error",
+			zap.String("Request ID:", requestID),
+			zap.Error(err))
+		return nil, errorCodeOf(err, ErrorCodeUnauthorized)
+	}
+	auditEvent.Actor = deviceInfo.DeviceID
+	auditEvent.TenantID = deviceInfo.TenantID
+
+	foundFile, err := db.GetFile(r.Context(), requestID, fileID)
+	if err != nil {
+		return nil, errorCodeOf(err, ErrorCodeInternalError)
+	}
+	if !isFileOfDevice(foundFile, deviceInfo) {
+		fsLogger.Info("Refusing access to a file of another device",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+		)
+		return nil, ErrorCodeForbidden
+	}
+	return foundFile, ""
+}
+
+// Returns whether the file belongs to the device identified by the token.
+func isFileOfDevice(file *db.File, deviceInfo *DeviceInfo) bool {
+	return strings.EqualFold(file.TenantID, deviceInfo.TenantID) &&
+		strings.EqualFold(file.DeviceID, deviceInfo.DeviceID)
+}
+
+// Marks a file whose contents were stored by the service uploaded, or pending
+// a scan if malware scanning is enabled, as the storage notification for the
+// file would. If the file cannot be marked, the storage notification will
+// mark it.
+func markFileStored(ctx context.Context, file *db.File) error {
+	id := strconv.FormatUint(file.FileID, 10)
+	if scanSettings.Enabled {
+		return db.MarkFileScanPending(ctx, id, file.Size)
+	}
+	return db.MarkFileUploaded(ctx, id, file.Size)
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+)
+
+// validate the selection of tenants that use the content proxy
+func TestContentProxyTenants(t *testing.T) {
+	type tenantTest struct {
+		tenantID string
+		proxied  bool
+	}
+	tests := map[string]tenantTest{
+		`proxied tenant`:       {testUUID, true},
+		`tenant is case-blind`: {"9B2C8C1E-4A44-4D9C-9A5F-2D8F3F5D6A01", true},
+		`other tenant`:         {"5f1d7c2a-2b3e-4c6d-8e9f-0a1b2c3d4e5f", false},
+		`empty tenant`:         {"", false},
+	}
+
+	updateContentProxy(&config.ContentProxy{TenantIDs: []string{testUUID}})
+	t.Cleanup(func() { updateContentProxy(&config.ContentProxy{}) })
+	for desc, v := range tests {
+		if isProxiedTenant(v.tenantID) != v.proxied {
+			t.Fatalf("Content proxy tenant mismatch: %s, expected: %v",
+				desc, v.proxied)
+		}
+	}
+	if contentProxy.Load().transferTimeout != defaultProxyTransferTimeout {
+		t.Fatalf("Content proxy transfer timeout mismatch, got: %v",
+			contentProxy.Load().transferTimeout)
+	}
+
+	updateContentProxy(&config.ContentProxy{TransferTimeoutSeconds: 30})
+	if isProxiedTenant(testUUID) ||
+		contentProxy.Load().transferTimeout != 30*time.Second {
+		t.Fatalf("Content proxy settings were not updated")
+	}
+}
+
+// validate that files of proxied tenants are uploaded to their content URL
+func TestFileUploadUrl(t *testing.T) {
+	redirectBaseUrl = "https://files.test"
+	updateContentProxy(&config.ContentProxy{TenantIDs: []string{testUUID}})
+	t.Cleanup(func() {
+		redirectBaseUrl = ""
+		updateContentProxy(&config.ContentProxy{})
+	})
+
+	var info common.FileInformation
+	file := db.File{FileID: 42, TenantID: testUUID, DeviceID: testUUID}
+	err := setFileUploadUrl(context.Background(), &info, &file, 0)
+	if err != nil {
+		t.Fatalf("Failed to set the upload URL: %v", err)
+	}
+	if info.SignedUrl != "https://files.test/api/v1/files/42/content" ||
+		info.SignedUrlExpiresAt != nil {
+		t.Fatalf("Upload URL mismatch, got: %s", info.SignedUrl)
+	}
+
+	// Files of other tenants are uploaded with a signed URL, which cannot be
+	// issued without storage.
+	file.TenantID = "5f1d7c2a-2b3e-4c6d-8e9f-0a1b2c3d4e5f"
+	info = common.FileInformation{}
+	if err = setFileUploadUrl(context.Background(), &info, &file, 0); err == nil {
+		t.Fatalf("Issued a signed upload URL without storage: %s",
+			info.SignedUrl)
+	}
+}
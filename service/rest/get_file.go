@@ -10,6 +10,7 @@ import (
 	"github.com/HPInc/krypton-fs/service/common"
 	"github.com/HPInc/krypton-fs/service/db"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"go.uber.org/zap"
 )
 
@@ -17,34 +18,41 @@ import (
 // database.
 func GetFileHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionGet)
+	defer recordAuditEvent(w, auditEvent)
 
 	// Retrieve the specified file identifier.
 	fileID, err := getPathVariable(r, paramFileID, true)
 	if err != nil {
 		fsLogger.Error("The required file id path variable was not specified in the request",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 		)
 		sendBadRequestErrorResponse(w)
 		metrics.MetricGetFileBadRequests.Inc()
 		return
 	}
+	auditEvent.FileID = getAuditFileID(fileID)
 
 	// validate device token
 	info, err := getDeviceInfoFromToken(r)
 	if err != nil {
 		fsLogger.Info("GetFile token validation error",
 			zap.Error(err))
-		sendUnauthorizedErrorResponse(w)
+		sendUnauthorizedErrorResponse(w, err)
 		metrics.MetricGetFileUnauthorizedRequests.Inc()
 		return
 	}
+	auditEvent.Actor = info.DeviceID
+	auditEvent.TenantID = info.TenantID
 
 	// Retrieve information about the file corresponding to this ID.
-	foundFile, err := db.GetFile(requestID, fileID)
+	foundFile, err := db.GetFile(r.Context(), requestID, fileID)
 	if err != nil {
 		if err == db.ErrNotFound {
 			fsLogger.Error("No file with the requested file ID was found in the database",
 				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
 			)
 			sendNotFoundErrorResponse(w)
 			metrics.MetricGetFileNotFoundErrors.Inc()
@@ -53,6 +61,7 @@ func GetFileHandler(w http.ResponseWriter, r *http.Request) {
 
 		fsLogger.Error("Failed to read information about file from the database!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendInternalServerErrorResponse(w)
@@ -65,6 +74,7 @@ func GetFileHandler(w http.ResponseWriter, r *http.Request) {
 		foundFile.DeviceID != info.DeviceID {
 		fsLogger.Error("Attempted file read does not match auth!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.String("Token Tenant ID:", info.TenantID),
 			zap.String("Token Device ID:", info.DeviceID),
 			zap.String("File Tenant ID:", foundFile.TenantID),
@@ -85,6 +95,8 @@ func GetFileHandler(w http.ResponseWriter, r *http.Request) {
 			TenantID:  foundFile.TenantID,
 			DeviceID:  foundFile.DeviceID,
 			Name:      foundFile.Name,
+			Namespace: foundFile.Namespace,
+			Version:   foundFile.Version,
 			Checksum:  foundFile.Checksum,
 			Size:      foundFile.Size,
 			Status:    foundFile.Status,
@@ -0,0 +1,111 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// Retrieves information about the newest uploaded version of the file with
+// the specified name (and optional namespace) belonging to the calling device.
+func GetFileByNameHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionGet)
+	defer recordAuditEvent(w, auditEvent)
+
+	// Retrieve the specified file name.
+	name, err := getPathVariable(r, paramFileName, true)
+	if err != nil || !isValidFileName(name) {
+		fsLogger.Error("A valid file name path variable was not specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidFileName, nil)
+		metrics.MetricGetFileByNameBadRequests.Inc()
+		return
+	}
+
+	namespace := r.FormValue(paramNamespace)
+	if !isValidNamespace(namespace) {
+		fsLogger.Error("An invalid namespace was specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Namespace:", namespace),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidNamespace, nil)
+		metrics.MetricGetFileByNameBadRequests.Inc()
+		return
+	}
+
+	// validate device token
+	info, err := getDeviceInfoFromToken(r)
+	if err != nil {
+		fsLogger.Info("GetFileByName token validation error",
+			zap.Error(err))
+		sendUnauthorizedErrorResponse(w, err)
+		metrics.MetricGetFileByNameUnauthorizedRequests.Inc()
+		return
+	}
+	auditEvent.Actor = info.DeviceID
+	auditEvent.TenantID = info.TenantID
+
+	// Resolve the name to the newest uploaded version of the file. Lookups are
+	// scoped to the tenant and device in the token.
+	foundFile, err := db.GetLatestFileByName(r.Context(), requestID, info.TenantID,
+		info.DeviceID, namespace, name)
+	if err != nil {
+		if err == db.ErrNotFound {
+			fsLogger.Error("No uploaded file with the requested name was found in the database",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+			)
+			sendNotFoundErrorResponse(w)
+			metrics.MetricGetFileByNameNotFoundErrors.Inc()
+			return
+		}
+
+		fsLogger.Error("Failed to read information about file from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricGetFileByNameInternalErrors.Inc()
+		return
+	}
+	auditEvent.FileID = foundFile.FileID
+
+	response := common.CommonFileResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		File: common.FileInformation{
+			FileID:    foundFile.FileID,
+			TenantID:  foundFile.TenantID,
+			DeviceID:  foundFile.DeviceID,
+			Name:      foundFile.Name,
+			Namespace: foundFile.Namespace,
+			Version:   foundFile.Version,
+			Checksum:  foundFile.Checksum,
+			Size:      foundFile.Size,
+			Status:    foundFile.Status,
+			CreatedAt: foundFile.CreatedAt,
+			UpdatedAt: foundFile.UpdatedAt,
+		},
+	}
+
+	// JSON encode and return information about the file.
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		metrics.MetricGetFileByNameInternalErrors.Inc()
+	}
+
+	metrics.MetricGetFileByNameResponses.Inc()
+}
@@ -4,38 +4,72 @@
 package rest
 
 import (
+	"context"
+	"mime"
 	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
 	"github.com/HPInc/krypton-fs/service/db"
 	"github.com/HPInc/krypton-fs/service/metrics"
 	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"go.uber.org/zap"
 )
 
+const (
+	// Longest duration of signed URLs that callers may request. Requested
+	// durations are further limited by the configured maximum duration.
+	maxUrlDurationSeconds = 7 * 24 * 60 * 60
+
+	// Content type of downloaded files whose type cannot be inferred from
+	// their name.
+	defaultContentType = "application/octet-stream"
+)
+
+var (
+	// Dispositions with which files can be downloaded.
+	supportedDispositions = []string{"attachment", "inline"}
+
+	// Cache-Control directives that may be set on downloads, such as
+	// "private, max-age=3600".
+	cacheControlRegex = regexp.MustCompile(`^[a-zA-Z0-9=, -]{1,128}$`)
+)
+
 // GetSignedUrlHandler gets a presigned GET/PUT/HEAD URL to perform an operation
 // on the existing file store resource assuming that the resource does exist,
 // ie. was previously created by POST using presigned URL.
 func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r,
+		getSignedUrlAuditAction(r.FormValue(paramMethod)))
+	defer recordAuditEvent(w, auditEvent)
 
 	// Retrieve the specified file identifier.
 	fileID, err := getPathVariable(r, "id", true)
 	if err != nil {
 		fsLogger.Error("The required file id path variable was not specified in the request",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 		)
 		sendBadRequestErrorResponse(w)
 		metrics.MetricGetSignedUrlBadRequests.Inc()
 		return
 	}
+	auditEvent.FileID = getAuditFileID(fileID)
 
 	// Extract parameters from the request.
 	err = r.ParseForm()
 	if err != nil {
 		fsLogger.Error("Failed to parse the request form!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendBadRequestErrorResponse(w)
@@ -47,6 +81,49 @@ func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 	if method == "" {
 		fsLogger.Error("The required HTTP method was not specified in the request",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricGetSignedUrlBadRequests.Inc()
+		return
+	}
+
+	duration, err := getRequestedUrlDuration(r.Form.Get(paramDuration))
+	if err != nil {
+		fsLogger.Error("An invalid signed URL duration was specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidUrlDuration, nil)
+		metrics.MetricGetSignedUrlBadRequests.Inc()
+		return
+	}
+
+	// Response headers can only be overridden for downloads.
+	disposition := r.Form.Get(paramDisposition)
+	cacheControl := r.Form.Get(paramCacheControl)
+	if err = validateResponseHeaders(method, disposition, cacheControl); err != nil {
+		fsLogger.Error("Invalid response header overrides were specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricGetSignedUrlBadRequests.Inc()
+		return
+	}
+
+	// A one-time redirect URL, optionally restricted to a client IP address
+	// or device, may be requested instead of a signed URL.
+	redirectOptions, err := getRedirectUrlOptions(r)
+	if err == nil {
+		err = validateRedirectUrlOptions(method, redirectOptions)
+	}
+	if err != nil {
+		fsLogger.Error("Invalid one-time URL options were specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
 		)
 		sendBadRequestErrorResponse(w)
 		metrics.MetricGetSignedUrlBadRequests.Inc()
@@ -54,11 +131,12 @@ func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Retrieve information about the file corresponding to this ID.
-	foundFile, err := db.GetFile(requestID, fileID)
+	foundFile, err := db.GetFile(r.Context(), requestID, fileID)
 	if err != nil {
 		if err == db.ErrNotFound {
 			fsLogger.Error("No file with the requested file ID was found in the database",
 				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
 			)
 			sendNotFoundErrorResponse(w)
 			metrics.MetricGetSignedUrlFileNotFoundErrors.Inc()
@@ -67,18 +145,34 @@ func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 
 		fsLogger.Error("Failed to read information about file from the database!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendInternalServerErrorResponse(w)
 		metrics.MetricGetSignedUrlInternalErrors.Inc()
 		return
 	}
+	auditEvent.TenantID = foundFile.TenantID
+
 	// if file status is quarantined, return 403
 	if foundFile.Status == db.FileStatusQuarantined {
-		err = sendJsonResponse(w, http.StatusForbidden, nil)
-		if err != nil {
-			metrics.MetricGetSignedUrlForbiddenErrors.Inc()
-		}
+		sendErrorResponse(w, ErrorCodeFileQuarantined, map[string]string{
+			detailFileID: fileID,
+		})
+		metrics.MetricGetSignedUrlForbiddenErrors.Inc()
+		return
+	}
+
+	// If configured, refuse to issue download URLs for files that have not
+	// yet been scanned by the malware scanning pipeline.
+	if isBlockedUnscannedDownload(foundFile.Status, method) {
+		fsLogger.Info("Refusing to issue a download URL for an unscanned file",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+		)
+		sendErrorResponse(w, ErrorCodeFileNotScanned, nil)
+		metrics.MetricGetSignedUrlUnscannedErrors.Inc()
 		return
 	}
 
@@ -88,17 +182,40 @@ func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 		FileName:     foundFile.Name,
 	}
 
+	// Issue a one-time redirect URL if requested. The download is recorded
+	// when the URL is redeemed.
+	if redirectOptions.oneTime {
+		response.SignedUrl, response.ExpiresAt, err = issueRedirectUrl(
+			r.Context(), requestID, foundFile, redirectOptions, disposition,
+			cacheControl, duration)
+		if err != nil {
+			fsLogger.Error("Failed to issue a one-time redirect URL for the file!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+				zap.Error(err),
+			)
+			sendErrorResponse(w, getRedirectUrlErrorCode(err), nil)
+			metrics.MetricGetSignedUrlInternalErrors.Inc()
+			return
+		}
+
+		err = sendJsonResponse(w, http.StatusOK, response)
+		if err != nil {
+			metrics.MetricGetSignedUrlInternalErrors.Inc()
+		}
+		metrics.MetricGetSignedUrlResponses.Inc()
+		return
+	}
+
 	// Generate a signed URL for the file - the signed URL generated corresponds
 	// to the requested HTTP method.
-	response.SignedUrl, err = storage.Provider.GetSignedUrl(
-		foundFile.BucketName,
-		storage.GetObjectName(foundFile.TenantID, foundFile.DeviceID, foundFile.FileID),
-		method,
-		foundFile.Checksum,
-		foundFile.Size)
+	response.SignedUrl, response.ExpiresAt, err = getFileSignedUrl(r.Context(),
+		foundFile, method, duration, getResponseHeaders(foundFile.Name,
+			disposition, cacheControl))
 	if err != nil {
 		fsLogger.Error("Failed to generate a signed URL for the file!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendInternalServerErrorResponse(w)
@@ -106,6 +223,13 @@ func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Record the download in the usage rollup for the tenant on a separate
+	// goroutine.
+	if strings.EqualFold(method, config.AccessMethodGet) {
+		go db.RecordDownload(r.Context(), requestID, foundFile.TenantID,
+			foundFile.DeviceID)
+	}
+
 	// JSON encode and return information about the file.
 	err = sendJsonResponse(w, http.StatusOK, response)
 	if err != nil {
@@ -114,3 +238,128 @@ func GetSignedUrlHandler(w http.ResponseWriter, r *http.Request) {
 
 	metrics.MetricGetSignedUrlResponses.Inc()
 }
+
+// getFileSignedUrl returns a signed URL for the specified method on the stored
+// object of the file, and the time at which it expires. The URL is valid for
+// the requested duration, if any, within the limits configured for the tenant.
+func getFileSignedUrl(ctx context.Context, file *db.File, method string,
+	duration time.Duration, headers *storage.ResponseHeaders) (string,
+	time.Time, error) {
+	return storage.GetSignedUrl(ctx, &storage.SignedUrlRequest{
+		BucketName: file.BucketName,
+		TenantID:   file.TenantID,
+		DeviceID:   file.DeviceID,
+		FileID:     file.FileID,
+		Method:     method,
+		Checksum:   file.Checksum,
+		Size:       file.Size,
+		Duration:   duration,
+		Headers:    headers,
+	})
+}
+
+// setFileSignedUrl sets the signed URL for the specified method, and the time
+// at which it expires, in the information returned about a file.
+func setFileSignedUrl(ctx context.Context, info *common.FileInformation,
+	file *db.File, method string, duration time.Duration) error {
+	signedUrl, expiresAt, err := getFileSignedUrl(ctx, file, method, duration,
+		nil)
+	if err != nil {
+		return err
+	}
+	info.SignedUrl = signedUrl
+	info.SignedUrlExpiresAt = &expiresAt
+	return nil
+}
+
+// validateResponseHeaders checks the response headers that the caller asked
+// to override in responses to downloads using the signed URL.
+func validateResponseHeaders(method string, disposition string,
+	cacheControl string) error {
+	if disposition == "" && cacheControl == "" {
+		return nil
+	}
+	if !strings.EqualFold(method, config.AccessMethodGet) {
+		return ErrInvalidResponseHeaders
+	}
+	if disposition != "" && !slices.Contains(supportedDispositions,
+		strings.ToLower(disposition)) {
+		return ErrInvalidResponseHeaders
+	}
+	if cacheControl != "" && !cacheControlRegex.MatchString(cacheControl) {
+		return ErrInvalidResponseHeaders
+	}
+	return nil
+}
+
+// getResponseHeaders returns the headers overridden in responses to downloads
+// of the file with the specified name, or nil if none are overridden. With a
+// disposition, the file is downloaded with its name and with a content type
+// inferred from the extension of the name.
+func getResponseHeaders(name string, disposition string,
+	cacheControl string) *storage.ResponseHeaders {
+	if disposition == "" && cacheControl == "" {
+		return nil
+	}
+
+	headers := &storage.ResponseHeaders{CacheControl: cacheControl}
+	if disposition != "" {
+		headers.ContentDisposition = mime.FormatMediaType(
+			strings.ToLower(disposition), map[string]string{"filename": name})
+		headers.ContentType = mime.TypeByExtension(filepath.Ext(name))
+		if headers.ContentType == "" {
+			headers.ContentType = defaultContentType
+		}
+	}
+	return headers
+}
+
+// getRequestedUrlDuration returns the duration of signed URLs requested by
+// the caller in seconds, or zero if no duration was requested.
+func getRequestedUrlDuration(seconds string) (time.Duration, error) {
+	if seconds == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidUrlDuration
+	}
+	return toUrlDuration(value)
+}
+
+// toUrlDuration converts a duration of signed URLs requested by the caller in
+// seconds. Zero means that no duration was requested.
+func toUrlDuration(seconds int64) (time.Duration, error) {
+	if seconds < 0 || seconds > maxUrlDurationSeconds {
+		return 0, ErrInvalidUrlDuration
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// getSignedUrlAuditAction returns the action recorded in the file audit log
+// for a request for a signed URL for the specified method.
+func getSignedUrlAuditAction(method string) string {
+	switch {
+	case strings.EqualFold(method, config.AccessMethodPut):
+		return db.AuditActionSignPut
+	case strings.EqualFold(method, config.AccessMethodHead):
+		return db.AuditActionSignHead
+	default:
+		return db.AuditActionSignGet
+	}
+}
+
+// isBlockedUnscannedDownload returns true if a download of a file with the
+// specified status must be refused by the configured scanning policy. Only
+// files that were found clean, and are therefore uploaded, can be downloaded.
+// New files may already be in storage before their upload notification is
+// processed, so they are blocked too.
+func isBlockedUnscannedDownload(status string, method string) bool {
+	if !scanSettings.BlockUnscannedDownloads {
+		return false
+	}
+	if !strings.EqualFold(method, config.AccessMethodGet) {
+		return false
+	}
+	return status != db.FileStatusUploaded
+}
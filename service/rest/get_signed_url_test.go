@@ -0,0 +1,107 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+)
+
+// validate the policy for blocking downloads of unscanned files
+func TestUnscannedDownloadPolicy(t *testing.T) {
+	type policyTest struct {
+		block  bool
+		status string
+		method string
+		result bool
+	}
+	tests := map[string]policyTest{
+		`policy disabled`:         {false, db.FileStatusScanPending, config.AccessMethodGet, false},
+		`pending get is blocked`:  {true, db.FileStatusScanPending, config.AccessMethodGet, true},
+		`method is case-blind`:    {true, db.FileStatusScanPending, "GET", true},
+		`pending head is allowed`: {true, db.FileStatusScanPending, config.AccessMethodHead, false},
+		`uploaded get is allowed`: {true, db.FileStatusUploaded, config.AccessMethodGet, false},
+		`new file put is allowed`: {true, db.FileStatusNew, config.AccessMethodPut, false},
+		`new file get is blocked`: {true, db.FileStatusNew, config.AccessMethodGet, true},
+		`new file get unblocked`:  {false, db.FileStatusNew, config.AccessMethodGet, false},
+	}
+
+	for desc, v := range tests {
+		scanSettings = &config.Scanning{BlockUnscannedDownloads: v.block}
+		if isBlockedUnscannedDownload(v.status, v.method) != v.result {
+			t.Fatalf("Unscanned download policy error: %s, expected: %v, got: %v",
+				desc, v.result, !v.result)
+		}
+	}
+}
+
+// validate the parsing of signed URL durations requested by callers
+func TestRequestedUrlDuration(t *testing.T) {
+	type durationTest struct {
+		seconds string
+		result  time.Duration
+		valid   bool
+	}
+	tests := map[string]durationTest{
+		`not requested`:     {"", 0, true},
+		`zero duration`:     {"0", 0, true},
+		`requested minutes`: {"300", 5 * time.Minute, true},
+		`longest duration`:  {"604800", 7 * 24 * time.Hour, true},
+		`too long`:          {"604801", 0, false},
+		`negative duration`: {"-1", 0, false},
+		`not a number`:      {"5m", 0, false},
+	}
+
+	for desc, v := range tests {
+		duration, err := getRequestedUrlDuration(v.seconds)
+		if (err == nil) != v.valid || duration != v.result {
+			t.Fatalf("Requested URL duration error: %s, expected: %v, got: %v (%v)",
+				desc, v.result, duration, err)
+		}
+	}
+}
+
+// validate the response headers that can be overridden on downloads
+func TestResponseHeaders(t *testing.T) {
+	type headersTest struct {
+		method       string
+		disposition  string
+		cacheControl string
+		valid        bool
+	}
+	tests := map[string]headersTest{
+		`no overrides`:           {config.AccessMethodPut, "", "", true},
+		`attachment`:             {config.AccessMethodGet, "attachment", "", true},
+		`inline is case-blind`:   {"GET", "Inline", "", true},
+		`cache control`:          {config.AccessMethodGet, "", "private, max-age=3600", true},
+		`unknown disposition`:    {config.AccessMethodGet, "save", "", false},
+		`header injection`:       {config.AccessMethodGet, "", "no-cache\r\nX-Test: 1", false},
+		`overrides on an upload`: {config.AccessMethodPut, "attachment", "", false},
+	}
+
+	for desc, v := range tests {
+		err := validateResponseHeaders(v.method, v.disposition, v.cacheControl)
+		if (err == nil) != v.valid {
+			t.Fatalf("Response header validation error: %s, expected: %v, got: %v",
+				desc, v.valid, err)
+		}
+	}
+
+	headers := getResponseHeaders("report 1.pdf", "Attachment", "no-store")
+	if headers.ContentDisposition != `attachment; filename="report 1.pdf"` ||
+		headers.ContentType != "application/pdf" ||
+		headers.CacheControl != "no-store" {
+		t.Fatalf("Response headers mismatch, got: %+v", headers)
+	}
+	headers = getResponseHeaders("data", "inline", "")
+	if headers.ContentType != defaultContentType {
+		t.Fatalf("Default content type mismatch, got: %s", headers.ContentType)
+	}
+	if getResponseHeaders("data", "", "") != nil {
+		t.Fatalf("Expected no response headers without overrides")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// Format of the days specified in usage requests.
+	usageDateFormat = "2006-01-02"
+
+	// Number of days reported if the start of the period is not specified.
+	defaultUsagePeriodDays = 30
+
+	// Maximum number of days that can be reported by a single request.
+	maxUsagePeriodDays = 366
+)
+
+var (
+	ErrInvalidUsagePeriod = errors.New("invalid usage period specified")
+)
+
+// Reports the daily usage of the service by the specified tenant. The usage
+// of a single device may be requested using the device_id query parameter.
+// The period is specified using the from and to query parameters (inclusive)
+// and defaults to the last 30 days.
+func GetUsageHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	// Retrieve the specified tenant identifier.
+	tenantID, err := getPathVariable(r, paramTenantID, true)
+	if err != nil || !isValidUUID(tenantID) {
+		fsLogger.Error("A valid tenant was not specified in the request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidTenantID, nil)
+		metrics.MetricGetUsageBadRequests.Inc()
+		return
+	}
+
+	deviceID := r.FormValue(paramDeviceID)
+	if deviceID != "" && !isValidUUID(deviceID) {
+		fsLogger.Error("An invalid device was specified in the request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Device ID:", deviceID),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidDeviceID, nil)
+		metrics.MetricGetUsageBadRequests.Inc()
+		return
+	}
+
+	from, to, err := getUsagePeriod(r.FormValue(paramFrom),
+		r.FormValue(paramTo), time.Now().UTC())
+	if err != nil {
+		fsLogger.Error("An invalid usage period was specified in the request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricGetUsageBadRequests.Inc()
+		return
+	}
+
+	usages, err := db.GetTenantUsage(r.Context(), requestID, tenantID,
+		deviceID, from, to)
+	if err != nil {
+		fsLogger.Error("Failed to get the usage of the tenant from the database!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricGetUsageInternalErrors.Inc()
+		return
+	}
+
+	response := common.UsageResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		TenantID:     tenantID,
+		DeviceID:     deviceID,
+		From:         from.Format(usageDateFormat),
+		To:           to.Format(usageDateFormat),
+	}
+	for _, usage := range usages {
+		response.Days = append(response.Days, common.UsageInformation{
+			Date:            usage.Date.Format(usageDateFormat),
+			BytesUploaded:   usage.BytesUploaded,
+			FilesCreated:    usage.FilesCreated,
+			DownloadsIssued: usage.DownloadsIssued,
+		})
+		response.Total.BytesUploaded += usage.BytesUploaded
+		response.Total.FilesCreated += usage.FilesCreated
+		response.Total.DownloadsIssued += usage.DownloadsIssued
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		metrics.MetricGetUsageInternalErrors.Inc()
+	}
+
+	metrics.MetricGetUsageResponses.Inc()
+}
+
+// getUsagePeriod parses the days between which usage is reported. The period
+// ends today if the end is not specified, and covers the default number of
+// days if the start is not specified.
+func getUsagePeriod(fromValue, toValue string, now time.Time) (time.Time,
+	time.Time, error) {
+	var err error
+
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toValue != "" {
+		to, err = time.Parse(usageDateFormat, toValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidUsagePeriod
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultUsagePeriodDays - 1))
+	if fromValue != "" {
+		from, err = time.Parse(usageDateFormat, fromValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidUsagePeriod
+		}
+	}
+
+	if from.After(to) || to.Sub(from) >= maxUsagePeriodDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidUsagePeriod
+	}
+	return from, to, nil
+}
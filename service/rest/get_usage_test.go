@@ -0,0 +1,51 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetUsagePeriod(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		from         string
+		to           string
+		expectedFrom string
+		expectedTo   string
+		isValid      bool
+	}{
+		{"default period", "", "", "2025-02-14", "2025-03-15", true},
+		{"explicit period", "2025-01-01", "2025-01-31", "2025-01-01", "2025-01-31", true},
+		{"single day", "2025-01-01", "2025-01-01", "2025-01-01", "2025-01-01", true},
+		{"end only", "", "2025-01-30", "2025-01-01", "2025-01-30", true},
+		{"start only", "2025-03-01", "", "2025-03-01", "2025-03-15", true},
+		{"start after end", "2025-02-01", "2025-01-01", "", "", false},
+		{"period too long", "2024-01-01", "2025-01-01", "", "", false},
+		{"invalid start", "01/01/2025", "", "", "", false},
+		{"invalid end", "", "2025-13-01", "", "", false},
+	}
+
+	for _, tc := range tests {
+		from, to, err := getUsagePeriod(tc.from, tc.to, now)
+		if (err == nil) != tc.isValid {
+			t.Fatalf("%s: Expected valid: %v, Got error: %v",
+				tc.name, tc.isValid, err)
+		}
+		if !tc.isValid {
+			continue
+		}
+		if from.Format(usageDateFormat) != tc.expectedFrom {
+			t.Fatalf("%s: Bad start. Expected: %s, Got: %s",
+				tc.name, tc.expectedFrom, from.Format(usageDateFormat))
+		}
+		if to.Format(usageDateFormat) != tc.expectedTo {
+			t.Fatalf("%s: Bad end. Expected: %s, Got: %s",
+				tc.name, tc.expectedTo, to.Format(usageDateFormat))
+		}
+	}
+}
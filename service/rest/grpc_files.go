@@ -0,0 +1,487 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/rpc/fspb"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Domain of the error codes reported in the details of gRPC errors.
+const grpcErrorDomain = "krypton-fs"
+
+// fsGrpcFileService implements the FileService gRPC service. Requests are
+// validated and served like the corresponding REST requests.
+type fsGrpcFileService struct {
+	fspb.UnimplementedFileServiceServer
+
+	// Closed when the gRPC service is shut down.
+	stopping <-chan struct{}
+}
+
+// Creates a record for a new file on behalf of the specified device and
+// returns a signed URL for the device to upload the file to storage. Devices
+// of tenants that use the content proxy upload the file to its content URL
+// instead.
+func (s *fsGrpcFileService) CreateFile(ctx context.Context,
+	in *fspb.CreateFileRequest) (resp *fspb.CreateFileResponse, err error) {
+	call := getGrpcCall(ctx)
+	auditEvent := newGrpcAuditEvent(call, db.AuditActionCreate)
+	defer recordGrpcAuditEvent(auditEvent, &err)
+	auditEvent.TenantID = in.TenantId
+
+	request := common.CreateFileRequest{
+		Name:      in.Name,
+		Namespace: in.Namespace,
+		TenantID:  in.TenantId,
+		DeviceID:  in.DeviceId,
+		Checksum:  in.Checksum,
+		Size:      in.Size,
+
+		UrlDurationSeconds: in.UrlDurationSeconds,
+	}
+	if request.Size < minFileLength {
+		return nil, newGrpcError(ErrorCodeInvalidFileSize, nil)
+	}
+	if err = validateCreateFileRequest(call.requestID, &request); err != nil {
+		return nil, newGrpcErrorFor(err, ErrorCodeBadRequest)
+	}
+
+	createdFile, err := db.CreateFile(ctx, call.requestID, &request)
+	if err != nil {
+		fsLogger.Error("Failed to create an entry for the file in the database!",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, newGrpcErrorFor(err, ErrorCodeInternalError)
+	}
+	auditEvent.FileID = createdFile.FileID
+
+	resp, err = newGrpcCreateFileResponse(ctx, createdFile,
+		time.Duration(request.UrlDurationSeconds)*time.Second)
+	if err != nil {
+		fsLogger.Error("Failed to generate a signed URL for the file!",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, newGrpcError(ErrorCodeInternalError, nil)
+	}
+	return resp, nil
+}
+
+// Gets information about the specified file.
+func (s *fsGrpcFileService) GetFile(ctx context.Context,
+	in *fspb.GetFileRequest) (resp *fspb.GetFileResponse, err error) {
+	call := getGrpcCall(ctx)
+	auditEvent := newGrpcAuditEvent(call, db.AuditActionGet)
+	defer recordGrpcAuditEvent(auditEvent, &err)
+	auditEvent.FileID = in.FileId
+
+	foundFile, err := getGrpcFile(ctx, call, in.FileId)
+	if err != nil {
+		return nil, err
+	}
+	auditEvent.TenantID = foundFile.TenantID
+
+	return &fspb.GetFileResponse{File: newGrpcFile(foundFile)}, nil
+}
+
+// Streams information about the files of the specified device.
+func (s *fsGrpcFileService) ListFiles(in *fspb.ListFilesRequest,
+	stream grpc.ServerStreamingServer[fspb.File]) (err error) {
+	ctx := stream.Context()
+	call := getGrpcCall(ctx)
+	auditEvent := newGrpcAuditEvent(call, db.AuditActionList)
+	defer recordGrpcAuditEvent(auditEvent, &err)
+	auditEvent.TenantID = in.TenantId
+
+	if !isValidUUID(in.TenantId) {
+		return newGrpcError(ErrorCodeInvalidTenantID, nil)
+	}
+	if !isValidUUID(in.DeviceId) {
+		return newGrpcError(ErrorCodeInvalidDeviceID, nil)
+	}
+
+	foundFiles, _, err := db.ListFilesForDevice(ctx, call.requestID,
+		in.TenantId, in.DeviceId)
+	if err != nil {
+		fsLogger.Error("Failed to list files matching the requested filter in the database!",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return newGrpcError(ErrorCodeInternalError, nil)
+	}
+
+	for i := range foundFiles {
+		if err = stream.Send(newGrpcFile(&foundFiles[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deletes the specified file.
+func (s *fsGrpcFileService) DeleteFile(ctx context.Context,
+	in *fspb.DeleteFileRequest) (resp *fspb.DeleteFileResponse, err error) {
+	call := getGrpcCall(ctx)
+	auditEvent := newGrpcAuditEvent(call, db.AuditActionDelete)
+	defer recordGrpcAuditEvent(auditEvent, &err)
+	auditEvent.FileID = in.FileId
+
+	fileID := strconv.FormatUint(in.FileId, 10)
+	deletedFile, err := db.DeleteFile(ctx, call.requestID, fileID)
+	if err != nil {
+		fsLogger.Error("Failed to delete the specified file from the database!",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", in.FileId),
+			zap.Error(err),
+		)
+		return nil, newGrpcErrorFor(err, ErrorCodeInternalError,
+			map[string]string{detailFileID: fileID})
+	}
+	auditEvent.TenantID = deletedFile.TenantID
+
+	return &fspb.DeleteFileResponse{File: newGrpcFile(deletedFile)}, nil
+}
+
+// Gets a signed URL to perform the specified HTTP method on the stored object
+// of the specified file. The same policies as for REST requests apply to
+// quarantined and unscanned files.
+func (s *fsGrpcFileService) GetSignedUrl(ctx context.Context,
+	in *fspb.GetSignedUrlRequest) (resp *fspb.GetSignedUrlResponse, err error) {
+	call := getGrpcCall(ctx)
+	auditEvent := newGrpcAuditEvent(call, getSignedUrlAuditAction(in.Method))
+	defer recordGrpcAuditEvent(auditEvent, &err)
+	auditEvent.FileID = in.FileId
+
+	if !isSignableMethod(in.Method) {
+		return nil, newGrpcError(ErrorCodeBadRequest, nil)
+	}
+	duration, err := toUrlDuration(in.UrlDurationSeconds)
+	if err != nil {
+		return nil, newGrpcError(ErrorCodeInvalidUrlDuration, nil)
+	}
+	err = validateResponseHeaders(in.Method, in.Disposition, in.CacheControl)
+	if err != nil {
+		return nil, newGrpcError(ErrorCodeBadRequest, nil)
+	}
+	redirectOptions := &redirectUrlOptions{
+		oneTime:            in.OneTime,
+		clientIP:           in.ClientIp,
+		requireDeviceToken: in.RequireDeviceToken,
+	}
+	if err = validateRedirectUrlOptions(in.Method, redirectOptions); err != nil {
+		return nil, newGrpcErrorFor(err, ErrorCodeBadRequest)
+	}
+
+	foundFile, err := getGrpcFile(ctx, call, in.FileId)
+	if err != nil {
+		return nil, err
+	}
+	auditEvent.TenantID = foundFile.TenantID
+
+	details := map[string]string{detailFileID: strconv.FormatUint(in.FileId, 10)}
+	if foundFile.Status == db.FileStatusQuarantined {
+		return nil, newGrpcError(ErrorCodeFileQuarantined, details)
+	}
+	if isBlockedUnscannedDownload(foundFile.Status, in.Method) {
+		return nil, newGrpcError(ErrorCodeFileNotScanned, details)
+	}
+
+	// Issue a one-time redirect URL if requested. The download is recorded
+	// when the URL is redeemed.
+	if redirectOptions.oneTime {
+		redirectUrl, expiresAt, err := issueRedirectUrl(ctx, call.requestID,
+			foundFile, redirectOptions, in.Disposition, in.CacheControl, duration)
+		if err != nil {
+			fsLogger.Error("Failed to issue a one-time redirect URL for the file!",
+				zap.String("Request ID:", call.requestID),
+				tracing.TraceID(ctx),
+				zap.Error(err),
+			)
+			return nil, newGrpcError(getRedirectUrlErrorCode(err), nil)
+		}
+		return &fspb.GetSignedUrlResponse{
+			SignedUrl:    redirectUrl,
+			FileName:     foundFile.Name,
+			UrlExpiresAt: timestamppb.New(expiresAt),
+		}, nil
+	}
+
+	signedUrl, expiresAt, err := getFileSignedUrl(ctx, foundFile, in.Method,
+		duration, getResponseHeaders(foundFile.Name, in.Disposition,
+			in.CacheControl))
+	if err != nil {
+		fsLogger.Error("Failed to generate a signed URL for the file!",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		return nil, newGrpcError(ErrorCodeInternalError, nil)
+	}
+
+	if strings.EqualFold(in.Method, config.AccessMethodGet) {
+		go db.RecordDownload(ctx, call.requestID, foundFile.TenantID,
+			foundFile.DeviceID)
+	}
+
+	return &fspb.GetSignedUrlResponse{
+		SignedUrl:    signedUrl,
+		FileName:     foundFile.Name,
+		UrlExpiresAt: timestamppb.New(expiresAt),
+	}, nil
+}
+
+// Streams events for files of the specified tenant and device until the call
+// is cancelled by the caller or the service is shut down.
+func (s *fsGrpcFileService) WatchFileEvents(in *fspb.WatchFileEventsRequest,
+	stream grpc.ServerStreamingServer[fspb.FileEvent]) error {
+	ctx := stream.Context()
+
+	if in.TenantId != "" && !isValidUUID(in.TenantId) {
+		return newGrpcError(ErrorCodeInvalidTenantID, nil)
+	}
+	if in.DeviceId != "" && (in.TenantId == "" || !isValidUUID(in.DeviceId)) {
+		return newGrpcError(ErrorCodeInvalidDeviceID, nil)
+	}
+
+	watchCtx, cancelFunc := context.WithCancel(ctx)
+	defer cancelFunc()
+	events := db.WatchFileEvents(watchCtx, db.FileEventFilter{
+		TenantID: in.TenantId,
+		DeviceID: in.DeviceId,
+	})
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return newGrpcError(ErrorCodeServiceUnavailable, nil)
+			}
+			err := stream.Send(&fspb.FileEvent{
+				Type:       newGrpcFileEventType(event.Type),
+				File:       newGrpcFile(&event.File),
+				OccurredAt: timestamppb.New(event.OccurredAt),
+			})
+			if err != nil {
+				return err
+			}
+			metrics.MetricGrpcFileEventsSent.Inc()
+
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+
+		case <-s.stopping:
+			return newGrpcError(ErrorCodeServiceUnavailable, nil)
+		}
+	}
+}
+
+// Retrieves the specified file from the database.
+func getGrpcFile(ctx context.Context, call *grpcCall,
+	fileID uint64) (*db.File, error) {
+	foundFile, err := db.GetFile(ctx, call.requestID,
+		strconv.FormatUint(fileID, 10))
+	if err != nil {
+		fsLogger.Error("Failed to read information about file from the database!",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", fileID),
+			zap.Error(err),
+		)
+		return nil, newGrpcErrorFor(err, ErrorCodeInternalError)
+	}
+	return foundFile, nil
+}
+
+// isSignableMethod returns true if signed URLs can be issued for the specified
+// HTTP method.
+func isSignableMethod(method string) bool {
+	for _, signable := range []string{config.AccessMethodGet,
+		config.AccessMethodPut, config.AccessMethodHead} {
+		if strings.EqualFold(method, signable) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the response to a request to create the specified file, with the
+// URL at which the device uploads the file. Content URLs do not expire, so no
+// expiry is returned for them.
+func newGrpcCreateFileResponse(ctx context.Context, file *db.File,
+	duration time.Duration) (*fspb.CreateFileResponse, error) {
+	var info common.FileInformation
+	if err := setFileUploadUrl(ctx, &info, file, duration); err != nil {
+		return nil, err
+	}
+
+	resp := &fspb.CreateFileResponse{
+		File:      newGrpcFile(file),
+		SignedUrl: info.SignedUrl,
+	}
+	if info.SignedUrlExpiresAt != nil {
+		resp.UrlExpiresAt = timestamppb.New(*info.SignedUrlExpiresAt)
+	}
+	return resp, nil
+}
+
+func newGrpcFile(file *db.File) *fspb.File {
+	grpcFile := &fspb.File{
+		FileId:    file.FileID,
+		TenantId:  file.TenantID,
+		DeviceId:  file.DeviceID,
+		Name:      file.Name,
+		Namespace: file.Namespace,
+		Version:   file.Version,
+		Checksum:  file.Checksum,
+		Size:      file.Size,
+		Status:    file.Status,
+	}
+	if !file.CreatedAt.IsZero() {
+		grpcFile.CreatedAt = timestamppb.New(file.CreatedAt)
+	}
+	if !file.UpdatedAt.IsZero() {
+		grpcFile.UpdatedAt = timestamppb.New(file.UpdatedAt)
+	}
+	return grpcFile
+}
+
+func newGrpcFileEventType(eventType string) fspb.FileEvent_Type {
+	switch eventType {
+	case db.FileEventCreated:
+		return fspb.FileEvent_TYPE_CREATED
+	case db.FileEventStatusChanged:
+		return fspb.FileEvent_TYPE_STATUS_CHANGED
+	case db.FileEventDeleted:
+		return fspb.FileEvent_TYPE_DELETED
+	default:
+		return fspb.FileEvent_TYPE_UNSPECIFIED
+	}
+}
+
+// newGrpcAuditEvent creates an audit event for the specified action taken by
+// a gRPC call. The app that made the call is recorded as the actor.
+func newGrpcAuditEvent(call *grpcCall, action string) *db.AuditEvent {
+	return &db.AuditEvent{
+		Actor:     call.appID,
+		Action:    action,
+		RequestID: call.requestID,
+		SourceIP:  call.sourceIP,
+	}
+}
+
+// recordGrpcAuditEvent queues the specified audit event for writing to the
+// file audit log. The outcome is derived from the error returned by the call.
+func recordGrpcAuditEvent(event *db.AuditEvent, err *error) {
+	event.Outcome = getAuditOutcome(httpStatusOfGrpcCode(status.Code(*err)))
+	db.RecordAuditEvent(*event)
+}
+
+// newGrpcError returns the gRPC error for the specified error code. The error
+// code and the specified details are included in the error as ErrorInfo.
+func newGrpcError(code string, details map[string]string) error {
+	definition, ok := errorCatalogue[code]
+	if !ok {
+		code = ErrorCodeInternalError
+		definition = errorCatalogue[code]
+	}
+
+	st := status.New(grpcCodeOf(code, definition.status), definition.message)
+	errorDetails := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   code,
+		Domain:   grpcErrorDomain,
+		Metadata: details,
+	}}
+	if definition.retryAfter > 0 {
+		errorDetails = append(errorDetails, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(definition.retryAfter),
+		})
+	}
+	if withDetails, err := st.WithDetails(errorDetails...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// newGrpcErrorFor returns the gRPC error for the error code of the specified
+// error, or for the fallback code if the error does not have one.
+func newGrpcErrorFor(err error, fallback string,
+	details ...map[string]string) error {
+	var errorDetails map[string]string
+	if len(details) > 0 {
+		errorDetails = details[0]
+	}
+	return newGrpcError(errorCodeOf(err, fallback), errorDetails)
+}
+
+// grpcCodeOf maps the HTTP status of an error code to a gRPC status code.
+func grpcCodeOf(code string, httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed:
+		return codes.Unimplemented
+	case http.StatusConflict:
+		if code == ErrorCodeConflict {
+			return codes.AlreadyExists
+		}
+		return codes.FailedPrecondition
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// httpStatusOfGrpcCode maps a gRPC status code to the HTTP status from which
+// the outcome of an action is derived.
+func httpStatusOfGrpcCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.Canceled:
+		return http.StatusRequestTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
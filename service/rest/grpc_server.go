@@ -0,0 +1,281 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/rpc/fspb"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// gRPC metadata keys. The request ID uses the same name as the REST
+	// request header.
+	metadataAuthorization = "authorization"
+	metadataRequestID     = headerRequestID
+)
+
+// Represents the FS gRPC service, which serves internal service-to-service
+// calls alongside the FS REST service.
+type fsGrpcService struct {
+	// gRPC server serving the FS gRPC endpoint.
+	server *grpc.Server
+
+	// Listener on which the gRPC server accepts connections.
+	listener net.Listener
+
+	// Port on which the gRPC server is available.
+	port int
+
+	// Closed when the service is shut down, to end streaming calls that do
+	// not otherwise complete.
+	stopping chan struct{}
+}
+
+// grpcCall holds information about a gRPC call that is available to the
+// handlers from the context of the call.
+type grpcCall struct {
+	// Request ID specified by the caller or generated for the call.
+	requestID string
+
+	// The app that made the call, as authenticated by its app token.
+	appID string
+
+	// The IP address from which the call was received.
+	sourceIP string
+}
+
+type grpcCallKey struct{}
+
+// Creates a new instance of the FS gRPC service, listening on the specified
+// port.
+func newFsGrpcService(port int) (*fsGrpcService, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fsGrpcService{
+		listener: listener,
+		port:     port,
+		stopping: make(chan struct{}),
+	}
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(grpcStreamInterceptor),
+	)
+	fspb.RegisterFileServiceServer(s.server,
+		&fsGrpcFileService{stopping: s.stopping})
+	return s, nil
+}
+
+// Starts serving gRPC calls. Fatal errors are reported on the specified error
+// channel.
+func (s *fsGrpcService) startServing(errChannel chan error) {
+	err := s.server.Serve(s.listener)
+	if err == nil || errors.Is(err, grpc.ErrServerStopped) {
+		return
+	}
+	fsLogger.Error("Received a fatal error from the gRPC server",
+		zap.Error(err),
+	)
+	errChannel <- err
+}
+
+// Stops accepting new calls, ends streaming calls and waits for in-flight
+// calls to complete, up to the specified timeout.
+func (s *fsGrpcService) shutdown(timeout time.Duration) {
+	close(s.stopping)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	fsLogger.Info("Draining in-flight calls to the FS gRPC service ...",
+		zap.Duration("Shutdown timeout: ", timeout),
+	)
+	select {
+	case <-stopped:
+		fsLogger.Info("Shut down the FS gRPC service!")
+	case <-time.After(timeout):
+		fsLogger.Error("Failed to drain in-flight gRPC calls before the shutdown timeout!")
+		s.server.Stop()
+	}
+}
+
+// Intercepts unary gRPC calls to authenticate the caller, trace and log the
+// call and report metrics.
+func grpcUnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, call, finish := startGrpcCall(ctx, info.FullMethod)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, call.requestID))
+
+	var resp interface{}
+	err := authenticateGrpcCall(ctx, call)
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	finish(err)
+	return resp, err
+}
+
+// grpcServerStream overrides the context of a server stream with the context
+// of the call.
+type grpcServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *grpcServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// Intercepts streaming gRPC calls to authenticate the caller, trace and log
+// the call and report metrics.
+func grpcStreamInterceptor(srv interface{}, stream grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, call, finish := startGrpcCall(stream.Context(), info.FullMethod)
+	_ = stream.SetHeader(metadata.Pairs(metadataRequestID, call.requestID))
+
+	err := authenticateGrpcCall(ctx, call)
+	if err == nil {
+		err = handler(srv, &grpcServerStream{ServerStream: stream, ctx: ctx})
+	}
+	finish(err)
+	return err
+}
+
+// Starts tracing the specified gRPC call and adds information about the call
+// to its context. The returned function must be called with the result of the
+// call once it completes.
+func startGrpcCall(ctx context.Context, fullMethod string) (context.Context,
+	*grpcCall, func(error)) {
+	start := time.Now()
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	// Extract the request ID if specified, else create a new request ID.
+	call := &grpcCall{requestID: getMetadataValue(md, metadataRequestID)}
+	if call.requestID == "" {
+		call.requestID = uuid.NewString()
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		call.sourceIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(call.sourceIP); err == nil {
+			call.sourceIP = host
+		}
+	}
+
+	// Start a span for the call. If the caller specified a W3C trace context,
+	// the span continues the caller's trace.
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracing.StartServerSpan(ctx, method,
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", fullMethod),
+		attribute.String("fs.request_id", call.requestID),
+	)
+	ctx = context.WithValue(ctx, grpcCallKey{}, call)
+
+	return ctx, call, func(err error) {
+		code := status.Code(err)
+		metrics.ReportLatencyMetric(metrics.MetricGrpcLatency, start, method)
+		metrics.MetricGrpcResponses.WithLabelValues(method, code.String()).Inc()
+
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+		if code == codes.Internal || code == codes.Unavailable ||
+			code == codes.Unknown {
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+		span.End()
+
+		fsLogger.Debug("-- Served gRPC call --",
+			zap.String("Method: ", fullMethod),
+			zap.String("Request ID: ", call.requestID),
+			zap.String("App ID: ", call.appID),
+			tracing.TraceID(ctx),
+			tracing.SpanID(ctx),
+			zap.String("Code: ", code.String()),
+			zap.String("Duration: ", time.Since(start).String()),
+		)
+	}
+}
+
+// Authenticates the caller of a gRPC call using the app token specified in
+// the authorization metadata of the call.
+func authenticateGrpcCall(ctx context.Context, call *grpcCall) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	accessToken, found := strings.CutPrefix(
+		getMetadataValue(md, metadataAuthorization), "Bearer ")
+	if !found {
+		metrics.MetricGrpcUnauthenticatedCalls.Inc()
+		return newGrpcError(ErrorCodeMissingAuthorization, nil)
+	}
+
+	claims, err := validateAppToken(accessToken)
+	if err != nil {
+		fsLogger.Info("gRPC app token validation error",
+			zap.String("Request ID:", call.requestID),
+			tracing.TraceID(ctx),
+			zap.Error(err),
+		)
+		metrics.MetricGrpcUnauthenticatedCalls.Inc()
+		return newGrpcError(errorCodeOf(err, ErrorCodeInvalidToken), nil)
+	}
+	call.appID = claims.Subject
+	return nil
+}
+
+// Returns information about the gRPC call from its context.
+func getGrpcCall(ctx context.Context) *grpcCall {
+	if call, ok := ctx.Value(grpcCallKey{}).(*grpcCall); ok {
+		return call
+	}
+	return &grpcCall{}
+}
+
+func getMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// metadataCarrier adapts gRPC metadata to the carrier used to propagate trace
+// context.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	return getMetadataValue(metadata.MD(c), key)
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
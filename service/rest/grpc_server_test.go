@@ -0,0 +1,237 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/rpc/fspb"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testTokenIssuer = "https://auth.test"
+	testTokenKid    = "grpc-test-key"
+	testAppID       = "test-app"
+)
+
+// start the gRPC service on an in-memory listener and return a client
+func newGrpcTestClient(t *testing.T) (fspb.FileServiceClient, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate a signing key: %v", err)
+	}
+	signingKeys[testTokenKid] = &key.PublicKey
+	authConfig.Store(&config.Auth{
+		Issuer:        testTokenIssuer,
+		AllowedAppIds: []string{testAppID},
+	})
+
+	listener := bufconn.Listen(1024 * 1024)
+	s := &fsGrpcService{listener: listener, stopping: make(chan struct{})}
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(grpcStreamInterceptor),
+	)
+	fspb.RegisterFileServiceServer(s.server,
+		&fsGrpcFileService{stopping: s.stopping})
+	go s.startServing(make(chan error, 1))
+	t.Cleanup(func() { s.shutdown(time.Second) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to connect to the gRPC service: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return fspb.NewFileServiceClient(conn), key
+}
+
+// sign a token of the specified type issued to the specified subject
+func newTestToken(t *testing.T, key *rsa.PrivateKey, tokenType string,
+	subject string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, TokenClaims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testTokenIssuer,
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	token.Header["kid"] = testTokenKid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign a token: %v", err)
+	}
+	return signed
+}
+
+// get the reason of the ErrorInfo included in a gRPC error
+func getGrpcErrorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+// validate authentication and request validation of gRPC calls
+func TestGrpcCalls(t *testing.T) {
+	client, key := newGrpcTestClient(t)
+	appToken := newTestToken(t, key, appType, testAppID)
+
+	type grpcTest struct {
+		token  string
+		call   func(ctx context.Context) error
+		code   codes.Code
+		reason string
+	}
+	getFile := func(ctx context.Context) error {
+		_, err := client.GetFile(ctx, &fspb.GetFileRequest{FileId: 1})
+		return err
+	}
+	tests := map[string]grpcTest{
+		`missing token`: {"", getFile,
+			codes.Unauthenticated, ErrorCodeMissingAuthorization},
+		`device token`: {newTestToken(t, key, deviceType, testAppID), getFile,
+			codes.Unauthenticated, ErrorCodeInvalidToken},
+		`app not allowed`: {newTestToken(t, key, appType, "other-app"), getFile,
+			codes.PermissionDenied, ErrorCodeForbidden},
+		`invalid file size`: {appToken, func(ctx context.Context) error {
+			_, err := client.CreateFile(ctx, &fspb.CreateFileRequest{})
+			return err
+		}, codes.InvalidArgument, ErrorCodeInvalidFileSize},
+		`invalid tenant`: {appToken, func(ctx context.Context) error {
+			stream, err := client.ListFiles(ctx,
+				&fspb.ListFilesRequest{TenantId: "tenant"})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.InvalidArgument, ErrorCodeInvalidTenantID},
+		`invalid method`: {appToken, func(ctx context.Context) error {
+			_, err := client.GetSignedUrl(ctx,
+				&fspb.GetSignedUrlRequest{FileId: 1, Method: "POST"})
+			return err
+		}, codes.InvalidArgument, ErrorCodeBadRequest},
+		`device without tenant`: {appToken, func(ctx context.Context) error {
+			stream, err := client.WatchFileEvents(ctx,
+				&fspb.WatchFileEventsRequest{DeviceId: uuid.NewString()})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.InvalidArgument, ErrorCodeInvalidDeviceID},
+	}
+
+	for desc, v := range tests {
+		ctx := context.Background()
+		if v.token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx,
+				metadataAuthorization, "Bearer "+v.token)
+		}
+		err := v.call(ctx)
+		if status.Code(err) != v.code || getGrpcErrorReason(err) != v.reason {
+			t.Fatalf("gRPC call error: %s, expected: %s (%s), got: %v",
+				desc, v.code, v.reason, err)
+		}
+	}
+}
+
+// validate that the request ID of a gRPC call is returned to the caller
+func TestGrpcRequestID(t *testing.T) {
+	client, _ := newGrpcTestClient(t)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		metadataRequestID, "grpc-request-id")
+	_, err := client.GetFile(ctx, &fspb.GetFileRequest{}, grpc.Header(&header))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected an unauthenticated call, got: %v", err)
+	}
+	if got := getMetadataValue(header, metadataRequestID); got != "grpc-request-id" {
+		t.Fatalf("Request ID error, expected: grpc-request-id, got: %s", got)
+	}
+}
+
+// validate the mapping of error codes to gRPC status codes
+func TestGrpcCodeOf(t *testing.T) {
+	type codeTest struct {
+		code   string
+		status int
+		result codes.Code
+	}
+	tests := map[string]codeTest{
+		`bad request`:     {ErrorCodeBadRequest, http.StatusBadRequest, codes.InvalidArgument},
+		`unauthenticated`: {ErrorCodeInvalidToken, http.StatusUnauthorized, codes.Unauthenticated},
+		`forbidden`:       {ErrorCodeForbidden, http.StatusForbidden, codes.PermissionDenied},
+		`not found`:       {ErrorCodeNotFound, http.StatusNotFound, codes.NotFound},
+		`conflict`:        {ErrorCodeConflict, http.StatusConflict, codes.AlreadyExists},
+		`other conflict`:  {ErrorCodeFileQuarantined, http.StatusConflict, codes.FailedPrecondition},
+		`unavailable`:     {ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, codes.Unavailable},
+		`internal error`:  {ErrorCodeInternalError, http.StatusInternalServerError, codes.Internal},
+	}
+
+	for desc, v := range tests {
+		if got := grpcCodeOf(v.code, v.status); got != v.result {
+			t.Fatalf("gRPC code error: %s, expected: %s, got: %s",
+				desc, v.result, got)
+		}
+	}
+}
+
+// validate that devices of proxied tenants upload files created over gRPC to
+// their content URL
+func TestGrpcCreateFileUploadUrl(t *testing.T) {
+	redirectBaseUrl = "https://files.test"
+	updateContentProxy(&config.ContentProxy{TenantIDs: []string{testUUID}})
+	t.Cleanup(func() {
+		redirectBaseUrl = ""
+		updateContentProxy(&config.ContentProxy{})
+	})
+
+	file := db.File{FileID: 42, TenantID: testUUID, DeviceID: testUUID}
+	resp, err := newGrpcCreateFileResponse(context.Background(), &file,
+		time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC response: %v", err)
+	}
+	if resp.SignedUrl != "https://files.test/api/v1/files/42/content" ||
+		resp.UrlExpiresAt != nil || resp.File.FileId != file.FileID {
+		t.Fatalf("Upload URL mismatch, got: %s, expires at: %v",
+			resp.SignedUrl, resp.UrlExpiresAt)
+	}
+
+	// Files of other tenants are uploaded with a signed URL, which cannot be
+	// issued without storage.
+	file.TenantID = "5f1d7c2a-2b3e-4c6d-8e9f-0a1b2c3d4e5f"
+	if resp, err = newGrpcCreateFileResponse(context.Background(), &file,
+		time.Minute); err == nil {
+		t.Fatalf("Issued a signed upload URL without storage: %s",
+			resp.SignedUrl)
+	}
+}
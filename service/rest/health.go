@@ -4,14 +4,193 @@
 package rest
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/HPInc/krypton-fs/service/cache"
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/notification"
+	"github.com/HPInc/krypton-fs/service/storage"
 	"go.uber.org/zap"
 )
 
+const (
+	// Time allowed for each dependency to respond to a readiness check.
+	readinessCheckTimeout = (time.Second * 2)
+
+	// Duration for which readiness check results are reused. This prevents
+	// frequent probes from hammering the dependencies.
+	readinessCacheTtl = (time.Second * 5)
+
+	// Health status values.
+	healthStatusUp       = "up"
+	healthStatusDown     = "down"
+	healthStatusDisabled = "disabled"
+	healthStatusReady    = "ready"
+	healthStatusNotReady = "not_ready"
+
+	// Dependency names.
+	dependencyDatabase = "database"
+	dependencyCache    = "cache"
+	dependencyStorage  = "storage"
+	dependencyQueue    = "queue"
+)
+
+// dependencyCheck describes a dependency checked for readiness.
+type dependencyCheck struct {
+	name string
+
+	// Whether readiness fails when the dependency is down. Optional
+	// dependencies are reported but do not affect readiness.
+	required bool
+
+	ping func(ctx context.Context) error
+}
+
+var (
+	// Dependencies checked for readiness. The cache is optional since requests
+	// fall back to the database when it cannot be reached.
+	readinessChecks = []dependencyCheck{
+		{name: dependencyDatabase, required: true, ping: db.Ping},
+		{name: dependencyCache, required: false, ping: cache.Ping},
+		{name: dependencyStorage, required: true, ping: pingStorage},
+		{name: dependencyQueue, required: true, ping: notification.Ping},
+	}
+
+	// Most recent readiness check results.
+	readinessLock      sync.Mutex
+	readinessResult    *common.HealthResponse
+	readinessCheckedAt time.Time
+
+	// Set once the service has started shutting down.
+	shuttingDown atomic.Bool
+)
+
 // GetHealthHandler responds with system health feedback  for K8S
 func GetHealthHandler(w http.ResponseWriter, r *http.Request) {
 	if err := sendJsonResponse(w, http.StatusOK, nil); err != nil {
 		fsLogger.Error("Failed to send health response", zap.Error(err))
 	}
 }
+
+// GetLivenessHandler reports whether the service process is alive. It does
+// not check dependencies so that a dependency outage does not cause
+// Kubernetes to restart healthy pods.
+func GetLivenessHandler(w http.ResponseWriter, r *http.Request) {
+	response := common.HealthResponse{
+		Status:    healthStatusUp,
+		CheckedAt: time.Now(),
+	}
+	if err := sendJsonResponse(w, http.StatusOK, response); err != nil {
+		fsLogger.Error("Failed to send liveness response", zap.Error(err))
+	}
+}
+
+// GetReadinessHandler reports whether the service can serve requests. The
+// service is not ready if a required dependency cannot be reached or if the
+// service is shutting down.
+func GetReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	response := checkReadiness()
+
+	statusCode := http.StatusOK
+	if response.Status != healthStatusReady {
+		statusCode = http.StatusServiceUnavailable
+	}
+	if err := sendJsonResponse(w, statusCode, response); err != nil {
+		fsLogger.Error("Failed to send readiness response", zap.Error(err))
+	}
+}
+
+// Returns the readiness of the service, checking dependencies if the cached
+// results have expired.
+func checkReadiness() common.HealthResponse {
+	if shuttingDown.Load() {
+		return common.HealthResponse{
+			Status:    healthStatusNotReady,
+			CheckedAt: time.Now(),
+		}
+	}
+
+	readinessLock.Lock()
+	defer readinessLock.Unlock()
+
+	if readinessResult != nil &&
+		time.Since(readinessCheckedAt) < readinessCacheTtl {
+		return *readinessResult
+	}
+
+	result := runReadinessChecks(readinessChecks)
+	readinessResult = &result
+	readinessCheckedAt = result.CheckedAt
+	return result
+}
+
+// Checks the specified dependencies concurrently and aggregates the results.
+func runReadinessChecks(checks []dependencyCheck) common.HealthResponse {
+	response := common.HealthResponse{
+		Status:       healthStatusReady,
+		CheckedAt:    time.Now(),
+		Dependencies: make(map[string]common.DependencyHealth, len(checks)),
+	}
+
+	results := make([]common.DependencyHealth, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = checkDependency(&checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, check := range checks {
+		response.Dependencies[check.name] = results[i]
+		if check.required && results[i].Status == healthStatusDown {
+			response.Status = healthStatusNotReady
+		}
+	}
+	return response
+}
+
+// Pings the specified dependency and reports its status and latency.
+func checkDependency(check *dependencyCheck) common.DependencyHealth {
+	ctx, cancelFunc := context.WithTimeout(context.Background(),
+		readinessCheckTimeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	err := check.ping(ctx)
+	result := common.DependencyHealth{
+		Status:    healthStatusUp,
+		Required:  check.required,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+
+	switch {
+	case err == nil:
+	case err == cache.ErrCacheDisabled:
+		result.Status = healthStatusDisabled
+		result.Required = false
+	default:
+		fsLogger.Error("Readiness check failed for dependency!",
+			zap.String("Dependency:", check.name),
+			zap.Error(err),
+		)
+		result.Status = healthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Pings the storage provider.
+func pingStorage(ctx context.Context) error {
+	if storage.Provider == nil {
+		return storage.ErrNotInitialized
+	}
+	return storage.Provider.Ping(ctx)
+}
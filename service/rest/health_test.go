@@ -0,0 +1,54 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/cache"
+)
+
+func pingUp(ctx context.Context) error       { return nil }
+func pingDown(ctx context.Context) error     { return errors.New("unreachable") }
+func pingDisabled(ctx context.Context) error { return cache.ErrCacheDisabled }
+
+// validate aggregation of readiness check results
+func TestReadinessChecks(t *testing.T) {
+	type readinessTest struct {
+		checks []dependencyCheck
+		status string
+	}
+	tests := map[string]readinessTest{
+		`all dependencies up`: {[]dependencyCheck{
+			{name: "a", required: true, ping: pingUp},
+			{name: "b", required: false, ping: pingUp},
+		}, healthStatusReady},
+		`required dependency down`: {[]dependencyCheck{
+			{name: "a", required: true, ping: pingDown},
+			{name: "b", required: false, ping: pingUp},
+		}, healthStatusNotReady},
+		`optional dependency down`: {[]dependencyCheck{
+			{name: "a", required: true, ping: pingUp},
+			{name: "b", required: false, ping: pingDown},
+		}, healthStatusReady},
+		`disabled dependency`: {[]dependencyCheck{
+			{name: "a", required: true, ping: pingUp},
+			{name: "b", required: true, ping: pingDisabled},
+		}, healthStatusReady},
+	}
+
+	for desc, v := range tests {
+		response := runReadinessChecks(v.checks)
+		if response.Status != v.status {
+			t.Fatalf("Readiness check error: %s, expected: %s, got: %s",
+				desc, v.status, response.Status)
+		}
+		if len(response.Dependencies) != len(v.checks) {
+			t.Fatalf("Readiness check error: %s, expected %d dependencies, got: %d",
+				desc, len(v.checks), len(response.Dependencies))
+		}
+	}
+}
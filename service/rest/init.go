@@ -4,11 +4,15 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"regexp"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,8 +25,12 @@ import (
 var (
 	fsLogger             *zap.Logger
 	debugLogRestRequests bool
-	// server settings
-	authConfig *config.Auth
+	// server settings. They may be changed when the configuration is
+	// reloaded.
+	authConfig atomic.Pointer[config.Auth]
+
+	// malware scanning settings
+	scanSettings *config.Scanning
 
 	// file name validation for s3 files. we are doing a restricted character set
 	// than is originally supported by s3
@@ -33,6 +41,10 @@ const (
 	// HTTP server timeouts for the REST endpoint.
 	readTimeout  = (time.Second * 5)
 	writeTimeout = (time.Second * 5)
+
+	// Time allowed for in-flight requests to complete when the REST server
+	// is shut down, unless configured otherwise.
+	defaultShutdownTimeout = (time.Second * 30)
 )
 
 // Represents the FS REST service.
@@ -47,8 +59,14 @@ type fsRestService struct {
 	// Request router
 	router *mux.Router
 
+	// HTTP server serving the REST endpoint.
+	server *http.Server
+
 	// HTTP port on which the REST server is available.
 	port int
+
+	// Time allowed for in-flight requests to complete on shutdown.
+	shutdownTimeout time.Duration
 }
 
 // Creates a new instance of the FS REST service and initalizes the request
@@ -68,20 +86,27 @@ func newFsRestService() *fsRestService {
 	return s
 }
 
-// Starts the HTTP REST server for the FS service and starts serving requests
-// at the REST endpoint.
-func (s *fsRestService) startServing() {
-	// Start the HTTP REST server. http.ListenAndServe() always returns
-	// a non-nil error
-	server := &http.Server{
+// Creates the HTTP server for the REST endpoint.
+func (s *fsRestService) initServer() {
+	s.server = &http.Server{
 		Addr:           fmt.Sprintf(":%d", s.port),
 		Handler:        s.router,
 		ReadTimeout:    readTimeout,
 		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
+}
 
-	err := server.ListenAndServe()
+// Starts the HTTP REST server for the FS service and starts serving requests
+// at the REST endpoint.
+func (s *fsRestService) startServing() {
+	// Start the HTTP REST server. http.ListenAndServe() always returns
+	// a non-nil error. http.ErrServerClosed is returned once the server has
+	// been shut down and is not an error.
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return
+	}
 	fsLogger.Error("Received a fatal error from http.ListenAndServe",
 		zap.Error(err),
 	)
@@ -106,15 +131,66 @@ func (s *fsRestService) awaitTermination() {
 	}
 }
 
+// Stops accepting new requests and waits for in-flight requests to complete,
+// up to the configured shutdown timeout.
+func (s *fsRestService) shutdown() {
+	signal.Stop(s.stopChannel)
+
+	// Fail readiness checks so that load balancers stop routing new requests
+	// to this instance while it drains.
+	shuttingDown.Store(true)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(),
+		s.shutdownTimeout)
+	defer cancelFunc()
+
+	fsLogger.Info("Draining in-flight requests to the FS REST service ...",
+		zap.Duration("Shutdown timeout: ", s.shutdownTimeout),
+	)
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to drain in-flight requests before the shutdown timeout!",
+			zap.Error(err),
+		)
+		_ = s.server.Close()
+		return
+	}
+	fsLogger.Info("Shut down the FS REST service!")
+}
+
+// UpdateSettings applies the REST server settings that can be changed without
+// restarting the service.
+func UpdateSettings(settings *config.Config) {
+	auth := settings.Server.Auth
+	auth.AllowedAppIds = append([]string(nil), auth.AllowedAppIds...)
+	authConfig.Store(&auth)
+	updateContentProxy(&settings.Server.ContentProxy)
+}
+
 // Init initializes the FS REST server and starts serving REST requests at the
-// FS's REST endpoint.
+// FS's REST endpoint. It returns once the service has been asked to terminate
+// and in-flight requests have been drained.
 func Init(logger *zap.Logger, settings *config.Config) {
 	fsLogger = logger
 	debugLogRestRequests = settings.Server.DebugRestRequests
-	authConfig = &settings.Server.Auth
+	validateRequests = settings.Server.ValidateRequests
+	if settings.Server.MaxBatchSize > 0 {
+		maxBatchSize = settings.Server.MaxBatchSize
+	}
+	redirectBaseUrl = strings.TrimSuffix(settings.Server.RedirectBaseUrl, "/")
+	trustedProxies = parseTrustedProxies(settings.Server.TrustedProxies)
+	initResumableUploads(&settings.Server.ResumableUploads)
+	UpdateSettings(settings)
+	scanSettings = &settings.Scanning
 
 	s := newFsRestService()
 	s.port = settings.Server.Port
+	s.shutdownTimeout = defaultShutdownTimeout
+	if settings.Server.ShutdownTimeoutSeconds > 0 {
+		s.shutdownTimeout = time.Duration(settings.Server.ShutdownTimeoutSeconds) *
+			time.Second
+	}
+	s.initServer()
 
 	// Initialize the REST server and listen for REST requests on a separate
 	// goroutine. Report fatal errors via the error channel.
@@ -123,7 +199,32 @@ func Init(logger *zap.Logger, settings *config.Config) {
 		zap.Int("Port: ", s.port),
 	)
 
+	// If enabled, serve gRPC calls from other services alongside the REST
+	// requests.
+	var g *fsGrpcService
+	if settings.Server.GrpcEnabled {
+		var err error
+		g, err = newFsGrpcService(settings.Server.GrpcPort)
+		if err != nil {
+			fsLogger.Fatal("Failed to listen for gRPC calls!",
+				zap.Int("Port: ", settings.Server.GrpcPort),
+				zap.Error(err),
+			)
+		}
+		go g.startServing(s.errChannel)
+		fsLogger.Info("Started the FS gRPC service!",
+			zap.Int("Port: ", g.port),
+		)
+	}
+
 	// Wait for the REST server to be terminated either in response to a system
 	// event (like service shutdown) or a fatal error.
 	s.awaitTermination()
+
+	// Stop accepting requests and drain the requests in flight before the
+	// caller shuts down the components they depend on.
+	s.shutdown()
+	if g != nil {
+		g.shutdown(s.shutdownTimeout)
+	}
 }
@@ -77,7 +77,7 @@ func getSigningKey(token *jwt.Token) (interface{}, error) {
 
 // Retrieve the token signing keys from JWKS endpoint.
 func getJWKSSigningKey() error {
-	url := authConfig.JwksUrl
+	url := authConfig.Load().JwksUrl
 	keys, err := getJwksFromServer(url)
 	if err != nil {
 		return err
@@ -0,0 +1,301 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// Places a legal hold on the specified file, device or tenant. Files under
+// legal hold cannot be deleted by request, by the scavenger or by purge jobs.
+// Holds are also mirrored to the object lock legal hold of the stored objects
+// of the held files, if their bucket has object lock enabled. Holds on
+// tenants and devices are mirrored in the background.
+func PlaceLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionLegalHold)
+	defer recordAuditEvent(w, auditEvent)
+
+	var request common.LegalHoldRequest
+	payload, err := getRequestPayload(r)
+	if err == nil {
+		err = json.Unmarshal(payload, &request)
+	}
+	if err == nil {
+		err = validateLegalHoldRequest(&request)
+	}
+	if err != nil {
+		fsLogger.Error("Failed to read the legal hold request payload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendErrorResponseForError(w, err, ErrorCodeInvalidPayload)
+		metrics.MetricLegalHoldBadRequests.Inc()
+		return
+	}
+	auditEvent.TenantID = request.TenantID
+	auditEvent.FileID = request.FileID
+
+	hold := db.LegalHold{
+		TenantID: request.TenantID,
+		DeviceID: request.DeviceID,
+		FileID:   request.FileID,
+		Reason:   request.Reason,
+		Actor:    request.Actor,
+	}
+	if hold.Actor == "" {
+		hold.Actor = r.Header.Get(headerActor)
+	}
+
+	// Holds on files apply to the tenant and device of the file.
+	var file *db.File
+	if hold.FileID != 0 {
+		file, err = db.GetFile(r.Context(), requestID,
+			strconv.FormatUint(hold.FileID, 10))
+		if err != nil {
+			sendLegalHoldFileError(w, requestID, r.Context(), err)
+			return
+		}
+		if hold.TenantID != "" && hold.TenantID != file.TenantID {
+			fsLogger.Error("The file in the legal hold request belongs to another tenant!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+				zap.Uint64("File ID:", hold.FileID),
+			)
+			sendBadRequestErrorResponse(w)
+			metrics.MetricLegalHoldBadRequests.Inc()
+			return
+		}
+		hold.TenantID = file.TenantID
+		hold.DeviceID = file.DeviceID
+		auditEvent.TenantID = file.TenantID
+	}
+
+	placed, created, err := db.PlaceLegalHold(r.Context(), requestID, &hold)
+	if err != nil {
+		sendInternalServerErrorResponse(w)
+		metrics.MetricLegalHoldInternalErrors.Inc()
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	// The object of a new file is held once it has been stored.
+	objectLock := false
+	if file == nil {
+		db.MirrorLegalHold(requestID, placed, true)
+	} else if file.Status != db.FileStatusNew {
+		objectLock = db.MirrorObjectLegalHold(r.Context(), requestID, file,
+			true)
+	}
+
+	err = sendJsonResponse(w, status,
+		newLegalHoldResponse(requestID, placed, objectLock))
+	if err != nil {
+		metrics.MetricLegalHoldInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricLegalHoldResponses.Inc()
+}
+
+// Lists the legal holds of the tenant specified by the tenant_id query
+// parameter. If the device_id query parameter is specified, only the holds
+// that apply to the files of the device are returned.
+func ListLegalHoldsHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	tenantID := r.FormValue(paramTenantID)
+	deviceID := r.FormValue(paramDeviceID)
+	if !isValidUUID(tenantID) || (deviceID != "" && !isValidUUID(deviceID)) {
+		fsLogger.Error("A valid tenant was not specified in the list legal holds request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidTenantID, nil)
+		metrics.MetricLegalHoldBadRequests.Inc()
+		return
+	}
+
+	holds, err := db.ListLegalHolds(r.Context(), requestID, tenantID, deviceID)
+	if err != nil {
+		sendInternalServerErrorResponse(w)
+		metrics.MetricLegalHoldInternalErrors.Inc()
+		return
+	}
+
+	response := common.LegalHoldsResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Count:        len(holds),
+		Holds:        make([]common.LegalHoldInformation, 0, len(holds)),
+	}
+	for i := range holds {
+		response.Holds = append(response.Holds, newLegalHoldInformation(&holds[i]))
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		metrics.MetricLegalHoldInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricLegalHoldResponses.Inc()
+}
+
+// Returns the specified legal hold.
+func GetLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	holdID, err := getLegalHoldID(r)
+	if err != nil {
+		sendBadRequestErrorResponse(w)
+		metrics.MetricLegalHoldBadRequests.Inc()
+		return
+	}
+
+	hold, err := db.GetLegalHold(r.Context(), requestID, holdID)
+	if err != nil {
+		sendLegalHoldError(w, err)
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK,
+		newLegalHoldResponse(requestID, hold, false))
+	if err != nil {
+		metrics.MetricLegalHoldInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricLegalHoldResponses.Inc()
+}
+
+// Releases the specified legal hold. Files remain held by any other hold that
+// applies to them. The object lock legal holds of the objects of files that
+// are no longer held are removed, in the background for holds on tenants and
+// devices.
+func ReleaseLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionHoldRelease)
+	defer recordAuditEvent(w, auditEvent)
+
+	holdID, err := getLegalHoldID(r)
+	if err != nil {
+		sendBadRequestErrorResponse(w)
+		metrics.MetricLegalHoldBadRequests.Inc()
+		return
+	}
+
+	hold, err := db.ReleaseLegalHold(r.Context(), requestID, holdID)
+	if err != nil {
+		sendLegalHoldError(w, err)
+		return
+	}
+	auditEvent.TenantID = hold.TenantID
+	auditEvent.FileID = hold.FileID
+
+	if hold.FileID == 0 {
+		db.MirrorLegalHold(requestID, hold, false)
+	} else {
+		held, err := db.IsFileHeld(r.Context(), requestID, hold.FileID)
+		if err == nil && !held {
+			file, err := db.GetFile(r.Context(), requestID,
+				strconv.FormatUint(hold.FileID, 10))
+			if err == nil && file.Status != db.FileStatusNew {
+				db.MirrorObjectLegalHold(r.Context(), requestID, file, false)
+			}
+		}
+	}
+
+	err = sendJsonResponse(w, http.StatusOK,
+		newLegalHoldResponse(requestID, hold, false))
+	if err != nil {
+		metrics.MetricLegalHoldInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricLegalHoldResponses.Inc()
+}
+
+// Validates the legal hold request. A hold must specify a reason, and either
+// a file or a tenant.
+func validateLegalHoldRequest(request *common.LegalHoldRequest) error {
+	if request.Reason == "" || len(request.Reason) > db.MaxLegalHoldReasonLength {
+		return ErrInvalidLegalHold
+	}
+	if request.FileID != 0 {
+		return nil
+	}
+	if !isValidUUID(request.TenantID) {
+		return ErrInvalidLegalHold
+	}
+	if request.DeviceID != "" && !isValidUUID(request.DeviceID) {
+		return ErrInvalidLegalHold
+	}
+	return nil
+}
+
+func getLegalHoldID(r *http.Request) (uint64, error) {
+	value, err := getPathVariable(r, paramHoldID, true)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+func sendLegalHoldFileError(w http.ResponseWriter, requestID string,
+	ctx context.Context, err error) {
+	fsLogger.Error("Failed to get the file in the legal hold request!",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.Error(err),
+	)
+	sendLegalHoldError(w, err)
+}
+
+func sendLegalHoldError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrNotFound) {
+		sendNotFoundErrorResponse(w)
+		metrics.MetricLegalHoldNotFoundErrors.Inc()
+		return
+	}
+	sendInternalServerErrorResponse(w)
+	metrics.MetricLegalHoldInternalErrors.Inc()
+}
+
+func newLegalHoldInformation(hold *db.LegalHold) common.LegalHoldInformation {
+	return common.LegalHoldInformation{
+		HoldID:    hold.HoldID,
+		TenantID:  hold.TenantID,
+		DeviceID:  hold.DeviceID,
+		FileID:    hold.FileID,
+		Reason:    hold.Reason,
+		Actor:     hold.Actor,
+		CreatedAt: hold.CreatedAt,
+	}
+}
+
+func newLegalHoldResponse(requestID string, hold *db.LegalHold,
+	objectLock bool) common.LegalHoldResponse {
+	return common.LegalHoldResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Hold:         newLegalHoldInformation(hold),
+		ObjectLock:   objectLock,
+	}
+}
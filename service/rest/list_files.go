@@ -10,6 +10,7 @@ import (
 	"github.com/HPInc/krypton-fs/service/common"
 	"github.com/HPInc/krypton-fs/service/db"
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"go.uber.org/zap"
 )
 
@@ -17,33 +18,40 @@ import (
 // device at a time.
 func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionList)
+	defer recordAuditEvent(w, auditEvent)
 
 	// Extract the tenant ID and the device ID from the request.
 	tenantID := r.FormValue(paramTenantID)
 	if tenantID == "" {
 		fsLogger.Error("No tenant was specified in the request!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 		)
-		sendBadRequestErrorResponse(w)
+		sendErrorResponse(w, ErrorCodeInvalidTenantID, nil)
 		metrics.MetricListFilesBadRequests.Inc()
 		return
 	}
+	auditEvent.TenantID = tenantID
 
 	deviceID := r.FormValue(paramDeviceID)
 	if deviceID == "" {
 		fsLogger.Error("No device was specified in the request!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 		)
-		sendBadRequestErrorResponse(w)
+		sendErrorResponse(w, ErrorCodeInvalidDeviceID, nil)
 		metrics.MetricListFilesBadRequests.Inc()
 		return
 	}
 
 	// Get a list of files matching the requested filter.
-	foundFiles, count, err := db.ListFilesForDevice(tenantID, deviceID)
+	foundFiles, count, err := db.ListFilesForDevice(r.Context(), requestID,
+		tenantID, deviceID)
 	if err != nil {
 		fsLogger.Error("Failed to list files matching the requested filter in the database!",
 			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
 		sendInternalServerErrorResponse(w)
@@ -63,6 +71,8 @@ func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
 			TenantID:  item.TenantID,
 			DeviceID:  item.DeviceID,
 			Name:      item.Name,
+			Namespace: item.Namespace,
+			Version:   item.Version,
 			Checksum:  item.Checksum,
 			Size:      item.Size,
 			Status:    item.Status,
@@ -0,0 +1,84 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// Reports the level at which the service currently logs.
+func GetLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	sendLogLevelResponse(w, r.Header.Get(headerRequestID))
+}
+
+// Changes the level at which the service logs. The change takes effect
+// immediately and lasts until the service is restarted.
+func SetLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	// Extract the log level request payload.
+	var request common.LogLevelRequest
+	payload, err := getRequestPayload(r)
+	if err == nil {
+		err = json.Unmarshal(payload, &request)
+	}
+	if err != nil {
+		fsLogger.Error("Failed to read the log level request payload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
+		metrics.MetricLogLevelBadRequests.Inc()
+		return
+	}
+
+	err = config.SetLogLevel(request.Level)
+	if err != nil {
+		fsLogger.Error("An invalid log level was specified in the request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Log level:", request.Level),
+			zap.Error(err),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricLogLevelBadRequests.Inc()
+		return
+	}
+
+	fsLogger.Info("Changed the log level at the request of the caller",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(r.Context()),
+		zap.String("Actor:", r.Header.Get(headerActor)),
+		zap.String("Log level:", request.Level),
+	)
+	sendLogLevelResponse(w, requestID)
+}
+
+func sendLogLevelResponse(w http.ResponseWriter, requestID string) {
+	response := common.LogLevelResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Level:        config.GetLogLevel(),
+	}
+
+	err := sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		fsLogger.Error("Failed to send the log level response!",
+			zap.String("Request ID:", requestID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	metrics.MetricLogLevelResponses.Inc()
+}
@@ -0,0 +1,553 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// The OpenAPI document describing the REST API of the service. The operation
+// IDs of the document are the names of the registered routes.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+const (
+	// Prefix of references to the schemas of the OpenAPI document.
+	openAPISchemaRefPrefix = "#/components/schemas/"
+
+	// Keys of the details included in request validation error responses.
+	detailField  = "field"
+	detailReason = "reason"
+)
+
+var (
+	// Whether requests are validated against the API specification.
+	validateRequests bool
+
+	// The API specification, loaded from the OpenAPI document.
+	apiSpec = mustLoadOpenAPISpec(openAPIDocument)
+
+	ErrInvalidOpenAPISchema = errors.New("invalid schema in the OpenAPI document")
+)
+
+// openAPISpec is the subset of an OpenAPI 3 document used to validate
+// requests.
+type openAPISpec struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+
+	// Operations of the document, by operation ID.
+	operations map[string]*openAPIOperation
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Parameters  []*openAPIParameter         `json:"parameters"`
+	RequestBody *openAPIRequestBody         `json:"requestBody"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+// openAPISchema is the subset of the schema object supported by the request
+// validator. The x-error-code extension names the error code sent if a value
+// does not conform to the schema.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 string                    `json:"type"`
+	Format               string                    `json:"format"`
+	Pattern              string                    `json:"pattern"`
+	Enum                 []string                  `json:"enum"`
+	MinLength            *int                      `json:"minLength"`
+	MaxLength            *int                      `json:"maxLength"`
+	Minimum              *float64                  `json:"minimum"`
+	Maximum              *float64                  `json:"maximum"`
+	Required             []string                  `json:"required"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Items                *openAPISchema            `json:"items"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties"`
+	Nullable             bool                      `json:"nullable"`
+	ErrorCode            string                    `json:"x-error-code"`
+
+	pattern *regexp.Regexp
+}
+
+// validationError describes why a value does not conform to its schema.
+type validationError struct {
+	field  string
+	reason string
+	code   string
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.field, e.reason)
+}
+
+// Loads the API specification from the specified OpenAPI document. The
+// document is embedded in the service, so failing to load it is fatal.
+func mustLoadOpenAPISpec(document []byte) *openAPISpec {
+	spec, err := loadOpenAPISpec(document)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load the OpenAPI document: %v", err))
+	}
+	return spec
+}
+
+func loadOpenAPISpec(document []byte) (*openAPISpec, error) {
+	var spec openAPISpec
+	err := json.Unmarshal(document, &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.operations = make(map[string]*openAPIOperation)
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			if operation.OperationID == "" {
+				return nil, fmt.Errorf("%s %s: no operation ID", method, path)
+			}
+			spec.operations[operation.OperationID] = operation
+
+			for _, parameter := range operation.Parameters {
+				err = spec.compile(parameter.Schema)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", operation.OperationID, err)
+				}
+			}
+		}
+	}
+	for name, schema := range spec.Components.Schemas {
+		err = spec.compile(schema)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return &spec, nil
+}
+
+// Compiles the patterns of the specified schema and checks its references.
+func (s *openAPISpec) compile(schema *openAPISchema) error {
+	if schema == nil {
+		return ErrInvalidOpenAPISchema
+	}
+	if schema.Ref != "" {
+		_, err := s.resolve(schema)
+		return err
+	}
+
+	var err error
+	if schema.Pattern != "" {
+		schema.pattern, err = regexp.Compile(schema.Pattern)
+		if err != nil {
+			return err
+		}
+	}
+	for _, property := range schema.Properties {
+		if err = s.compile(property); err != nil {
+			return err
+		}
+	}
+	if schema.Items != nil {
+		if err = s.compile(schema.Items); err != nil {
+			return err
+		}
+	}
+	if schema.AdditionalProperties != nil {
+		return s.compile(schema.AdditionalProperties)
+	}
+	return nil
+}
+
+// Returns the schema referenced by the specified schema, or the schema itself
+// if it is not a reference.
+func (s *openAPISpec) resolve(schema *openAPISchema) (*openAPISchema, error) {
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	resolved, ok := s.Components.Schemas[strings.TrimPrefix(schema.Ref,
+		openAPISchemaRefPrefix)]
+	if !ok || !strings.HasPrefix(schema.Ref, openAPISchemaRefPrefix) {
+		return nil, fmt.Errorf("%w: unknown reference %s",
+			ErrInvalidOpenAPISchema, schema.Ref)
+	}
+	return resolved, nil
+}
+
+// requestValidator rejects requests that do not conform to the operation of
+// the API specification for the named route, before they reach the handler.
+func requestValidator(inner http.Handler, name string) http.Handler {
+	operation, ok := apiSpec.operations[name]
+	if !ok {
+		return inner
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validateRequests {
+			inner.ServeHTTP(w, r)
+			return
+		}
+
+		err := apiSpec.validateRequest(operation, r)
+		if err != nil {
+			fsLogger.Error("The request does not conform to the API specification!",
+				zap.String("Request ID:", r.Header.Get(headerRequestID)),
+				tracing.TraceID(r.Context()),
+				zap.String("Route:", name),
+				zap.Error(err),
+			)
+			metrics.MetricRequestValidationFailures.WithLabelValues(name).Inc()
+			sendErrorResponse(w, err.code, map[string]string{
+				detailField:  err.field,
+				detailReason: err.reason,
+			})
+			return
+		}
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// Validates the parameters and the payload of the request against the
+// specified operation.
+func (s *openAPISpec) validateRequest(operation *openAPIOperation,
+	r *http.Request) *validationError {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	for _, parameter := range operation.Parameters {
+		var value string
+		var present bool
+		switch parameter.In {
+		case "path":
+			value, present = vars[parameter.Name]
+		case "query":
+			present = query.Has(parameter.Name)
+			value = query.Get(parameter.Name)
+		case "header":
+			value = r.Header.Get(parameter.Name)
+			present = value != ""
+		default:
+			continue
+		}
+
+		if !present {
+			if parameter.Required {
+				return s.newValidationError(parameter.Schema, parameter.Name,
+					"is required")
+			}
+			continue
+		}
+		err := s.validateParameter(parameter, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if operation.RequestBody != nil {
+		return s.validateRequestBody(operation.RequestBody, r)
+	}
+	return nil
+}
+
+// Validates the string value of a parameter against its schema.
+func (s *openAPISpec) validateParameter(parameter *openAPIParameter,
+	value string) *validationError {
+	schema, err := s.resolve(parameter.Schema)
+	if err != nil {
+		return &validationError{parameter.Name, err.Error(),
+			ErrorCodeInternalError}
+	}
+
+	var typed interface{} = value
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return s.newValidationError(schema, parameter.Name,
+				"must be a number")
+		}
+		typed = json.Number(value)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return s.newValidationError(schema, parameter.Name,
+				"must be a boolean")
+		}
+		typed = b
+	}
+	return s.validateValue(schema, typed, parameter.Name)
+}
+
+// Validates the JSON payload of the request against the schema of the
+// request body.
+func (s *openAPISpec) validateRequestBody(body *openAPIRequestBody,
+	r *http.Request) *validationError {
+	payload, err := getRequestPayload(r)
+	if err != nil {
+		return &validationError{"body", err.Error(), ErrorCodeInvalidPayload}
+	}
+	if len(payload) == 0 && !body.Required {
+		return nil
+	}
+
+	// Payloads are assumed to be JSON encoded if no content type is specified.
+	mediaType := contentTypeJson
+	if value := r.Header.Get(headerContentType); value != "" {
+		mediaType, _, err = mime.ParseMediaType(value)
+		if err != nil {
+			mediaType = value
+		}
+	}
+	content, ok := body.Content[mediaType]
+	if !ok {
+		return &validationError{headerContentType,
+			fmt.Sprintf("%q is not supported", mediaType),
+			ErrorCodeUnsupportedMediaType}
+	}
+	if len(payload) == 0 {
+		return &validationError{"body", "is required", ErrorCodeInvalidPayload}
+	}
+
+	value, err := decodeJSONValue(payload)
+	if err != nil {
+		return &validationError{"body", "is not valid JSON",
+			ErrorCodeInvalidPayload}
+	}
+	return s.validateValue(content.Schema, value, "body")
+}
+
+// Decodes the specified JSON payload, preserving numbers so that integers
+// can be told apart from other numbers.
+func decodeJSONValue(payload []byte) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+// Validates the specified value against the schema. The field names the value
+// in validation errors.
+func (s *openAPISpec) validateValue(schema *openAPISchema, value interface{},
+	field string) *validationError {
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return &validationError{field, err.Error(), ErrorCodeInternalError}
+	}
+
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return s.newValidationError(schema, field, "must not be null")
+	}
+
+	switch schema.Type {
+	case "object":
+		return s.validateObject(schema, value, field)
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return s.newValidationError(schema, field, "must be an array")
+		}
+		if schema.Items == nil {
+			return nil
+		}
+		for i, item := range items {
+			verr := s.validateValue(schema.Items, item,
+				fmt.Sprintf("%s[%d]", field, i))
+			if verr != nil {
+				return verr
+			}
+		}
+		return nil
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return s.newValidationError(schema, field, "must be a string")
+		}
+		return s.validateString(schema, str, field)
+
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return s.newValidationError(schema, field, "must be a number")
+		}
+		return s.validateNumber(schema, number, field)
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return s.newValidationError(schema, field, "must be a boolean")
+		}
+	}
+	return nil
+}
+
+func (s *openAPISpec) validateObject(schema *openAPISchema, value interface{},
+	field string) *validationError {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return s.newValidationError(schema, field, "must be an object")
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			property := schema.Properties[name]
+			if property == nil {
+				property = schema
+			}
+			return s.newValidationError(property, name, "is required")
+		}
+	}
+	for name, propertyValue := range object {
+		property, ok := schema.Properties[name]
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+		if property == nil {
+			continue
+		}
+		verr := s.validateValue(property, propertyValue, name)
+		if verr != nil {
+			if verr.code == ErrorCodeBadRequest && schema.ErrorCode != "" {
+				verr.code = schema.ErrorCode
+			}
+			return verr
+		}
+	}
+	return nil
+}
+
+func (s *openAPISpec) validateString(schema *openAPISchema, value string,
+	field string) *validationError {
+	if schema.MinLength != nil && len(value) < *schema.MinLength {
+		return s.newValidationError(schema, field,
+			fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && len(value) > *schema.MaxLength {
+		return s.newValidationError(schema, field,
+			fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+	}
+	if schema.pattern != nil && !schema.pattern.MatchString(value) {
+		return s.newValidationError(schema, field, "has an invalid format")
+	}
+	if len(schema.Enum) > 0 && !isOneOf(value, schema.Enum) {
+		return s.newValidationError(schema, field,
+			fmt.Sprintf("must be one of %s", strings.Join(schema.Enum, ", ")))
+	}
+
+	var err error
+	switch schema.Format {
+	case "uuid":
+		_, err = uuid.Parse(value)
+	case "date":
+		_, err = time.Parse(usageDateFormat, value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return s.newValidationError(schema, field,
+			fmt.Sprintf("must be a valid %s", schema.Format))
+	}
+	return nil
+}
+
+func (s *openAPISpec) validateNumber(schema *openAPISchema, value json.Number,
+	field string) *validationError {
+	number, err := value.Float64()
+	if err != nil {
+		return s.newValidationError(schema, field, "must be a number")
+	}
+	if schema.Type == "integer" {
+		if _, err = value.Int64(); err != nil {
+			return s.newValidationError(schema, field, "must be an integer")
+		}
+	}
+	if schema.Minimum != nil && number < *schema.Minimum {
+		return s.newValidationError(schema, field,
+			fmt.Sprintf("must be at least %v", *schema.Minimum))
+	}
+	if schema.Maximum != nil && number > *schema.Maximum {
+		return s.newValidationError(schema, field,
+			fmt.Sprintf("must be at most %v", *schema.Maximum))
+	}
+	return nil
+}
+
+// Returns a validation error for the specified field, with the error code of
+// the schema if it has one.
+func (s *openAPISpec) newValidationError(schema *openAPISchema, field string,
+	reason string) *validationError {
+	code := ErrorCodeBadRequest
+	if schema != nil {
+		if resolved, err := s.resolve(schema); err == nil &&
+			resolved.ErrorCode != "" {
+			code = resolved.ErrorCode
+		}
+		if schema.ErrorCode != "" {
+			code = schema.ErrorCode
+		}
+	}
+	return &validationError{field: field, reason: reason, code: code}
+}
+
+func isOneOf(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Serves the OpenAPI document describing the REST API of the service, from
+// which clients of the service can be generated.
+func GetOpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(openAPIDocument); err != nil {
+		fsLogger.Error("Failed to send the OpenAPI document!",
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/config"
+)
+
+// Types of the request and response payloads described by the schemas of the
+// OpenAPI document, by schema name.
+var contractTypes = map[string]interface{}{
+	"ErrorResponse":           common.ErrorResponse{},
+	"FileInformation":         common.FileInformation{},
+	"CreateFileRequest":       common.CreateFileRequest{},
+	"CommonFileResponse":      common.CommonFileResponse{},
+	"ListFilesResponse":       common.ListFilesResponse{},
+	"SignedUrlResponse":       common.SignedUrlResponse{},
+	"ScanActionRequest":       common.ScanActionRequest{},
+	"FileScanInformation":     common.FileScanInformation{},
+	"ListFileScansResponse":   common.ListFileScansResponse{},
+	"DependencyHealth":        common.DependencyHealth{},
+	"HealthResponse":          common.HealthResponse{},
+	"UsageInformation":        common.UsageInformation{},
+	"UsageResponse":           common.UsageResponse{},
+	"AuditEventInformation":   common.AuditEventInformation{},
+	"AuditEventsResponse":     common.AuditEventsResponse{},
+	"LogLevelRequest":         common.LogLevelRequest{},
+	"LogLevelResponse":        common.LogLevelResponse{},
+	"LeaderInformation":       common.LeaderInformation{},
+	"LeaderStatusResponse":    common.LeaderStatusResponse{},
+	"ScavengerRunRequest":     common.ScavengerRunRequest{},
+	"ScavengerRunInformation": common.ScavengerRunInformation{},
+	"ScavengerRunResponse":    common.ScavengerRunResponse{},
+	"PurgeJobInformation":     common.PurgeJobInformation{},
+	"PurgeJobResponse":        common.PurgeJobResponse{},
+	"LegalHoldRequest":        common.LegalHoldRequest{},
+	"LegalHoldInformation":    common.LegalHoldInformation{},
+	"LegalHoldResponse":       common.LegalHoldResponse{},
+	"LegalHoldsResponse":      common.LegalHoldsResponse{},
+	"BatchCreateFilesRequest": common.BatchCreateFilesRequest{},
+	"BatchSignedUrlRequest":   common.BatchSignedUrlRequest{},
+	"BatchItemError":          common.BatchItemError{},
+	"BatchFileResult":         common.BatchFileResult{},
+	"BatchFilesResponse":      common.BatchFilesResponse{},
+}
+
+// Matches the regular expressions of gorilla/mux path variables.
+var pathVariableRegex = regexp.MustCompile(`\{([a-z_]+):[^}]+\}`)
+
+// validate that the OpenAPI document describes exactly the registered routes
+func TestOpenAPIRoutes(t *testing.T) {
+	described := make(map[string]bool)
+	for path, operations := range apiSpec.Paths {
+		for method, operation := range operations {
+			described[strings.ToUpper(method)+" "+path+" "+operation.OperationID] = true
+		}
+	}
+
+	for _, route := range registeredRoutes {
+		path := pathVariableRegex.ReplaceAllString(route.Path, "{$1}")
+		key := route.Method + " " + path + " " + route.Name
+		if !described[key] {
+			t.Fatalf("Route is not described by the OpenAPI document: %s", key)
+		}
+		delete(described, key)
+	}
+	for key := range described {
+		t.Fatalf("The OpenAPI document describes an unknown route: %s", key)
+	}
+}
+
+// validate that the schemas of the OpenAPI document match the payload types
+func TestOpenAPISchemas(t *testing.T) {
+	for name, schema := range apiSpec.Components.Schemas {
+		value, ok := contractTypes[name]
+		if !ok {
+			t.Fatalf("No payload type is known for schema %s", name)
+		}
+		checkSchemaType(t, name, schema, reflect.TypeOf(value))
+	}
+}
+
+func checkSchemaType(t *testing.T, name string, schema *openAPISchema,
+	goType reflect.Type) {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < goType.NumField(); i++ {
+		field := goType.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		fields[tag] = field
+	}
+
+	for property, propertySchema := range schema.Properties {
+		field, ok := fields[property]
+		if !ok {
+			t.Fatalf("%s.%s is not a field of %s", name, property, goType)
+		}
+		resolved, err := apiSpec.resolve(propertySchema)
+		if err != nil {
+			t.Fatalf("%s.%s: %v", name, property, err)
+		}
+		if !isSchemaTypeOf(resolved, field.Type) {
+			t.Fatalf("%s.%s is a %s but %s.%s is a %s", name, property,
+				resolved.Type, goType, field.Name, field.Type)
+		}
+		if propertySchema.Ref != "" {
+			refName := strings.TrimPrefix(propertySchema.Ref, openAPISchemaRefPrefix)
+			if reflect.TypeOf(contractTypes[refName]) != indirect(field.Type) {
+				t.Fatalf("%s.%s refers to %s but %s.%s is a %s", name,
+					property, refName, goType, field.Name, field.Type)
+			}
+		}
+	}
+	for tag, field := range fields {
+		if _, ok := schema.Properties[tag]; !ok {
+			t.Fatalf("%s.%s is not described by schema %s", goType,
+				field.Name, name)
+		}
+	}
+}
+
+func indirect(goType reflect.Type) reflect.Type {
+	if goType.Kind() == reflect.Pointer {
+		return goType.Elem()
+	}
+	return goType
+}
+
+// Returns true if values of the specified type are described by the schema.
+func isSchemaTypeOf(schema *openAPISchema, goType reflect.Type) bool {
+	goType = indirect(goType)
+	if goType == reflect.TypeOf(time.Time{}) {
+		return schema.Type == "string" && schema.Format == "date-time"
+	}
+
+	switch goType.Kind() {
+	case reflect.String:
+		return schema.Type == "string"
+	case reflect.Bool:
+		return schema.Type == "boolean"
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint64:
+		return schema.Type == "integer"
+	case reflect.Slice:
+		return schema.Type == "array"
+	case reflect.Struct, reflect.Map:
+		return schema.Type == "object"
+	}
+	return false
+}
+
+// validate request validation against the OpenAPI document
+func TestRequestValidation(t *testing.T) {
+	type validationTest struct {
+		method string
+		target string
+		body   string
+		status int
+		code   string
+	}
+	tests := map[string]validationTest{
+		`invalid checksum`: {http.MethodPost, "/api/v1/files",
+			`{"name":"a.txt","checksum":"not base64","size":1}`,
+			http.StatusBadRequest, ErrorCodeInvalidChecksum},
+		`invalid file name`: {http.MethodPost, "/api/v1/files",
+			`{"name":"a/b","checksum":"YQ==","size":1}`,
+			http.StatusBadRequest, ErrorCodeInvalidFileName},
+		`missing file size`: {http.MethodPost, "/api/v1/files",
+			`{"name":"a.txt","checksum":"YQ=="}`,
+			http.StatusBadRequest, ErrorCodeInvalidFileSize},
+		`malformed payload`: {http.MethodPost, "/api/v1/files", `{"name":`,
+			http.StatusBadRequest, ErrorCodeInvalidPayload},
+		`missing payload`: {http.MethodPut, "/api/internal/v1/log_level", ``,
+			http.StatusBadRequest, ErrorCodeInvalidPayload},
+		`invalid log level`: {http.MethodPut, "/api/internal/v1/log_level",
+			`{"level":"loud"}`, http.StatusBadRequest, ErrorCodeBadRequest},
+		`missing tenant`: {http.MethodGet,
+			"/api/internal/v1/files?device_id=" + testUUID, ``,
+			http.StatusBadRequest, ErrorCodeInvalidTenantID},
+		`invalid device`: {http.MethodGet,
+			"/api/internal/v1/files?tenant_id=" + testUUID + "&device_id=1", ``,
+			http.StatusBadRequest, ErrorCodeInvalidDeviceID},
+		`invalid method`: {http.MethodGet,
+			"/api/internal/v1/files/1/signed_url?method=post", ``,
+			http.StatusBadRequest, ErrorCodeBadRequest},
+		`negative url duration`: {http.MethodGet,
+			"/api/internal/v1/files/1/signed_url?method=get&url_duration_seconds=-1", ``,
+			http.StatusBadRequest, ErrorCodeInvalidUrlDuration},
+		`url duration too long`: {http.MethodPost, "/api/v1/files",
+			`{"name":"a.txt","checksum":"YQ==","size":1,"url_duration_seconds":604801}`,
+			http.StatusBadRequest, ErrorCodeInvalidUrlDuration},
+		`invalid disposition`: {http.MethodGet,
+			"/api/internal/v1/files/1/signed_url?method=get&disposition=save", ``,
+			http.StatusBadRequest, ErrorCodeBadRequest},
+		`invalid one-time option`: {http.MethodGet,
+			"/api/internal/v1/files/1/signed_url?method=get&one_time=maybe", ``,
+			http.StatusBadRequest, ErrorCodeBadRequest},
+		`invalid limit`: {http.MethodGet, "/api/internal/v1/audit?limit=5000",
+			``, http.StatusBadRequest, ErrorCodeBadRequest},
+		`invalid date`: {http.MethodGet,
+			"/api/internal/v1/usage/" + testUUID + "?from=yesterday", ``,
+			http.StatusBadRequest, ErrorCodeBadRequest},
+		`invalid tenant path`: {http.MethodPost,
+			"/api/internal/v1/purge/tenants/tenant", ``,
+			http.StatusBadRequest, ErrorCodeInvalidTenantID},
+		`invalid batch file id`: {http.MethodPost,
+			"/api/internal/v1/files:batch_signed_url", `{"file_ids":["1"]}`,
+			http.StatusBadRequest, ErrorCodeBadRequest},
+		`empty batch`: {http.MethodPost,
+			"/api/internal/v1/files:batch_signed_url", `{"file_ids":[]}`,
+			http.StatusBadRequest, ErrorCodeInvalidBatchSize},
+	}
+
+	router := newContractTestRouter(t)
+	for desc, test := range tests {
+		w := serveContractRequest(router, test.method, test.target, test.body)
+		response := checkContractResponse(t, desc, test.method, test.target, w)
+		if w.Code != test.status {
+			t.Fatalf("Status mismatch: %s, expected: %d, got: %d",
+				desc, test.status, w.Code)
+		}
+		if code := response["code"]; code != test.code {
+			t.Fatalf("Error code mismatch: %s, expected: %s, got: %v",
+				desc, test.code, code)
+		}
+	}
+}
+
+// validate that handlers respond as described by the OpenAPI document
+func TestContractResponses(t *testing.T) {
+	type contractTest struct {
+		method string
+		target string
+		body   string
+		status int
+	}
+	tests := map[string]contractTest{
+		`liveness`:         {http.MethodGet, "/health/live", ``, http.StatusOK},
+		`openapi`:          {http.MethodGet, "/api/openapi.json", ``, http.StatusOK},
+		`log level`:        {http.MethodGet, "/api/internal/v1/log_level", ``, http.StatusOK},
+		`no token`:         {http.MethodGet, "/api/v1/files/1", ``, http.StatusUnauthorized},
+		`no token by name`: {http.MethodGet, "/api/v1/files/by-name/a.txt", ``, http.StatusUnauthorized},
+		`set log level`: {http.MethodPut, "/api/internal/v1/log_level",
+			`{"level":"debug"}`, http.StatusOK},
+		`unsupported media type`: {http.MethodPost, "/api/v1/files", `a.txt`,
+			http.StatusUnsupportedMediaType},
+		`no token batch`: {http.MethodPost, "/api/v1/files:batch",
+			`{"files":[]}`, http.StatusUnauthorized},
+		`invalid redirect nonce`: {http.MethodGet,
+			"/api/v1/files/redirect/nonce", ``, http.StatusNotFound},
+		`redirect without cache`: {http.MethodGet,
+			"/api/v1/files/redirect/" + strings.Repeat("a", 43), ``,
+			http.StatusServiceUnavailable},
+		`no token upload content`: {http.MethodPut, "/api/v1/files/1/content",
+			`a`, http.StatusUnauthorized},
+		`no token download content`: {http.MethodGet,
+			"/api/v1/files/1/content", ``, http.StatusUnauthorized},
+		`resumable uploads disabled`: {http.MethodOptions, "/api/v1/uploads",
+			``, http.StatusNotFound},
+		`upload without length`: {http.MethodPost, "/api/v1/uploads", ``,
+			http.StatusBadRequest},
+		`upload offset disabled`: {http.MethodHead, "/api/v1/uploads/1", ``,
+			http.StatusNotFound},
+		`chunk without offset`: {http.MethodPatch, "/api/v1/uploads/1", `a`,
+			http.StatusBadRequest},
+		`terminate upload disabled`: {http.MethodDelete, "/api/v1/uploads/1",
+			``, http.StatusNotFound},
+	}
+
+	router := newContractTestRouter(t)
+	for desc, test := range tests {
+		w := serveContractRequest(router, test.method, test.target, test.body)
+		checkContractResponse(t, desc, test.method, test.target, w)
+		if w.Code != test.status {
+			t.Fatalf("Status mismatch: %s, expected: %d, got: %d",
+				desc, test.status, w.Code)
+		}
+	}
+}
+
+const testUUID = "9b2c8c1e-4a44-4d9c-9a5f-2d8f3f5d6a01"
+
+func newContractTestRouter(t *testing.T) http.Handler {
+	config.InitTestLogger()
+	validateRequests = true
+	t.Cleanup(func() { validateRequests = false })
+
+	auth := config.Auth{}
+	authConfig.Store(&auth)
+	return initRequestRouter()
+}
+
+func serveContractRequest(router http.Handler, method string, target string,
+	body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if strings.HasPrefix(body, "{") {
+		r.Header.Set(headerContentType, contentTypeJson)
+	} else if body != "" {
+		r.Header.Set(headerContentType, "text/plain")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// Checks that the status of the response is described by the OpenAPI
+// document for the requested route, and that the response payload conforms
+// to the schema of the response. Returns the decoded payload.
+func checkContractResponse(t *testing.T, desc string, method string,
+	target string, w *httptest.ResponseRecorder) map[string]interface{} {
+	operation := findContractOperation(t, method, target)
+	response, ok := operation.Responses[strconv.Itoa(w.Code)]
+	if !ok {
+		t.Fatalf("%s: status %d is not described for %s", desc, w.Code,
+			operation.OperationID)
+	}
+
+	content, ok := response.Content[contentTypeJson]
+	if !ok {
+		return nil
+	}
+	value, err := decodeJSONValue(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("%s: the response is not valid JSON: %v", desc, err)
+	}
+	if verr := apiSpec.validateValue(content.Schema, value, "response"); verr != nil {
+		t.Fatalf("%s: the response does not conform to the schema: %v", desc, verr)
+	}
+
+	var decoded map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &decoded)
+	return decoded
+}
+
+func findContractOperation(t *testing.T, method string,
+	target string) *openAPIOperation {
+	path := strings.Split(target, "?")[0]
+	for _, route := range registeredRoutes {
+		pattern := pathVariableRegex.ReplaceAllString(route.Path, `[^/]+`)
+		pattern = regexp.MustCompile(`\{[a-z_]+\}`).ReplaceAllString(pattern, `[^/]+`)
+		if route.Method == method && regexp.MustCompile("^"+pattern+"$").MatchString(path) {
+			return apiSpec.operations[route.Name]
+		}
+	}
+	t.Fatalf("No route matches %s %s", method, target)
+	return nil
+}
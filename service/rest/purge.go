@@ -0,0 +1,148 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// Deletes all files of the specified tenant, or of the specified device of the
+// tenant, along with the objects stored for them. The files are deleted by a
+// background job, which is returned so its progress can be followed. If a job
+// is already active for the tenant or device, that job is returned.
+func PurgeRequestHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	// Retrieve the specified tenant and device identifiers.
+	tenantID, err := getPathVariable(r, paramTenantID, true)
+	if err != nil || !isValidUUID(tenantID) {
+		fsLogger.Error("A valid tenant was not specified in the purge request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidTenantID, nil)
+		metrics.MetricPurgeBadRequests.Inc()
+		return
+	}
+
+	deviceID, _ := getPathVariable(r, paramDeviceID, false)
+	if deviceID != "" && !isValidUUID(deviceID) {
+		fsLogger.Error("An invalid device was specified in the purge request!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Device ID:", deviceID),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidDeviceID, nil)
+		metrics.MetricPurgeBadRequests.Inc()
+		return
+	}
+
+	fsLogger.Info("Received a REST request to purge files!",
+		zap.String("Request ID:", requestID),
+		zap.String("Tenant ID:", tenantID),
+		zap.String("Device ID:", deviceID),
+	)
+	job, err := db.StartPurgeJob(r.Context(), requestID, tenantID, deviceID)
+	if err != nil {
+		sendInternalServerErrorResponse(w)
+		metrics.MetricPurgeInternalErrors.Inc()
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/internal/v1/purge/%d",
+		job.JobID))
+	err = sendJsonResponse(w, http.StatusAccepted,
+		newPurgeJobResponse(requestID, job))
+	if err != nil {
+		fsLogger.Error("Failed to send the purge response!",
+			zap.String("Request ID:", requestID),
+			zap.Error(err),
+		)
+		metrics.MetricPurgeInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricPurgeResponses.Inc()
+}
+
+// Reports the status and progress of a purge job. Jobs are recorded in the
+// database, so any node can report on them.
+func GetPurgeJobHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	value, err := getPathVariable(r, paramJobID, true)
+	var jobID uint64
+	if err == nil {
+		jobID, err = strconv.ParseUint(value, 10, 64)
+	}
+	if err != nil {
+		fsLogger.Error("An invalid purge job ID was specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Job ID:", value),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricGetPurgeJobBadRequests.Inc()
+		return
+	}
+
+	job, err := db.GetPurgeJob(r.Context(), requestID, jobID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricGetPurgeJobNotFoundErrors.Inc()
+			return
+		}
+		sendInternalServerErrorResponse(w)
+		metrics.MetricGetPurgeJobInternalErrors.Inc()
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, newPurgeJobResponse(requestID, job))
+	if err != nil {
+		fsLogger.Error("Failed to send the purge job response!",
+			zap.String("Request ID:", requestID),
+			zap.Error(err),
+		)
+		metrics.MetricGetPurgeJobInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricGetPurgeJobResponses.Inc()
+}
+
+func newPurgeJobResponse(requestID string,
+	job *db.PurgeJob) common.PurgeJobResponse {
+	return common.PurgeJobResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Job: common.PurgeJobInformation{
+			JobID:          job.JobID,
+			TenantID:       job.TenantID,
+			DeviceID:       job.DeviceID,
+			Status:         job.Status,
+			Phase:          job.Phase,
+			NodeID:         job.NodeID,
+			Attempts:       job.Attempts,
+			FilesDeleted:   job.FilesDeleted,
+			ObjectsDeleted: job.ObjectsDeleted,
+			HeldFiles:      job.HeldFiles,
+			CreatedAt:      job.CreatedAt,
+			StartedAt:      job.StartedAt,
+			UpdatedAt:      job.HeartbeatAt,
+			CompletedAt:    job.CompletedAt,
+			Error:          job.Error,
+		},
+	}
+}
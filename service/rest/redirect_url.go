@@ -0,0 +1,300 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/cache"
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// A one-time redirect URL points at the files service instead of storage. It
+// carries a random nonce under which the cache holds the file and the
+// restrictions of the URL. When the URL is redeemed, the restrictions are
+// checked, the nonce is removed from the cache, and the caller is redirected
+// to a signed URL that is only valid for a short time.
+const (
+	// Path at which one-time redirect URLs are redeemed.
+	redirectUrlPath = "/api/v1/files/redirect/"
+
+	// Number of random bytes in the nonce of a redirect URL.
+	redirectUrlNonceSize = 32
+
+	// Duration of the signed URL to which callers are redirected.
+	redeemedUrlDuration = time.Minute
+
+	// Label of the redemption metrics for URLs that were redeemed.
+	redirectUrlRedeemed = "ok"
+)
+
+var (
+	// Base URL of one-time redirect URLs. One-time URLs cannot be issued if
+	// it is not configured.
+	redirectBaseUrl string
+
+	// Nonces of redirect URLs, which are base64url encoded without padding.
+	redirectUrlNonceRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+	ErrInvalidRedirectUrlOptions = errors.New("invalid one-time url options specified")
+	ErrRedirectUrlsDisabled      = errors.New("one-time urls are not configured")
+)
+
+// redirectUrlOptions - options of a one-time redirect URL requested by the
+// caller of GetSignedUrl.
+type redirectUrlOptions struct {
+	// Whether a one-time redirect URL is issued instead of a signed URL.
+	oneTime bool
+
+	// If specified, the URL can only be redeemed from this IP address.
+	clientIP string
+
+	// Whether redeeming the URL requires a device token of the device to
+	// which the file belongs.
+	requireDeviceToken bool
+}
+
+// redirectUrlEntry - describes a one-time redirect URL. It is held in the
+// cache until the URL is redeemed or expires.
+type redirectUrlEntry struct {
+	FileID       uint64 `json:"file_id"`
+	Method       string `json:"method"`
+	ClientIP     string `json:"client_ip,omitempty"`
+	DeviceID     string `json:"device_id,omitempty"`
+	Disposition  string `json:"disposition,omitempty"`
+	CacheControl string `json:"cache_control,omitempty"`
+}
+
+// getRedirectUrlOptions returns the options of a one-time redirect URL
+// specified in the parameters of a GetSignedUrl request.
+func getRedirectUrlOptions(r *http.Request) (*redirectUrlOptions, error) {
+	var options redirectUrlOptions
+	var err error
+	if value := r.Form.Get(paramOneTime); value != "" {
+		if options.oneTime, err = strconv.ParseBool(value); err != nil {
+			return nil, ErrInvalidRedirectUrlOptions
+		}
+	}
+	if value := r.Form.Get(paramRequireDeviceToken); value != "" {
+		if options.requireDeviceToken, err = strconv.ParseBool(value); err != nil {
+			return nil, ErrInvalidRedirectUrlOptions
+		}
+	}
+	options.clientIP = r.Form.Get(paramClientIP)
+	return &options, nil
+}
+
+// validateRedirectUrlOptions checks the options of a one-time redirect URL
+// requested for the specified method. One-time URLs can only be issued for
+// downloads, and restrictions can only be specified for one-time URLs.
+func validateRedirectUrlOptions(method string,
+	options *redirectUrlOptions) error {
+	if !options.oneTime {
+		if options.clientIP != "" || options.requireDeviceToken {
+			return ErrInvalidRedirectUrlOptions
+		}
+		return nil
+	}
+	if !strings.EqualFold(method, config.AccessMethodGet) {
+		return ErrInvalidRedirectUrlOptions
+	}
+	if options.clientIP != "" && net.ParseIP(options.clientIP) == nil {
+		return ErrInvalidRedirectUrlOptions
+	}
+	if redirectBaseUrl == "" {
+		return ErrRedirectUrlsDisabled
+	}
+	return nil
+}
+
+// issueRedirectUrl returns a one-time redirect URL to download the file, and
+// the time at which it expires. The URL is valid for as long as a signed URL
+// for the file would be.
+func issueRedirectUrl(ctx context.Context, requestID string, file *db.File,
+	options *redirectUrlOptions, disposition string, cacheControl string,
+	duration time.Duration) (string, time.Time, error) {
+	nonce, err := newRedirectUrlNonce()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	entry := redirectUrlEntry{
+		FileID:       file.FileID,
+		Method:       config.AccessMethodGet,
+		ClientIP:     options.clientIP,
+		Disposition:  disposition,
+		CacheControl: cacheControl,
+	}
+	if options.requireDeviceToken {
+		entry.DeviceID = file.DeviceID
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	lifetime := storage.GetSignedUrlDuration(file.TenantID,
+		config.AccessMethodGet, duration)
+	expiresAt := time.Now().UTC().Add(lifetime).Truncate(time.Second)
+	err = cache.AddRedirectUrl(ctx, requestID, nonce, data, lifetime)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	metrics.MetricRedirectUrlsIssued.Inc()
+	return redirectBaseUrl + redirectUrlPath + nonce, expiresAt, nil
+}
+
+// newRedirectUrlNonce returns a random nonce for a new redirect URL.
+func newRedirectUrlNonce() (string, error) {
+	nonce := make([]byte, redirectUrlNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(nonce), nil
+}
+
+// RedeemRedirectUrlHandler redeems a one-time redirect URL and redirects the
+// caller to a short-lived signed URL to download the file. Each URL can only
+// be redeemed once, from the IP address and by the device it is restricted
+// to, if any.
+func RedeemRedirectUrlHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionRedeem)
+	defer recordAuditEvent(w, auditEvent)
+
+	code := redeemRedirectUrl(w, r, requestID, auditEvent)
+	metrics.MetricRedirectUrlRedemptions.WithLabelValues(code).Inc()
+	if code != redirectUrlRedeemed {
+		sendErrorResponse(w, code, nil)
+	}
+}
+
+// Redeems the redirect URL of the request and redirects the caller to the
+// signed URL of the file. Returns the error code sent to the caller if the URL
+// cannot be redeemed.
+func redeemRedirectUrl(w http.ResponseWriter, r *http.Request,
+	requestID string, auditEvent *db.AuditEvent) string {
+	nonce, err := getPathVariable(r, paramNonce, true)
+	if err != nil || !redirectUrlNonceRegex.MatchString(nonce) {
+		return ErrorCodeNotFound
+	}
+
+	data, err := cache.GetRedirectUrl(r.Context(), requestID, nonce)
+	if err != nil {
+		return getRedirectUrlErrorCode(err)
+	}
+	var entry redirectUrlEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		fsLogger.Error("Failed to unmarshal the redirect URL entry!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		return ErrorCodeInternalError
+	}
+	auditEvent.FileID = entry.FileID
+
+	// Check the restrictions of the URL before redeeming it, so that callers
+	// that are not allowed to redeem the URL cannot use it up.
+	if code := checkRedirectUrlRestrictions(r, &entry, auditEvent); code != "" {
+		fsLogger.Info("Refusing to redeem a restricted redirect URL",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", entry.FileID),
+			zap.String("Error code:", code),
+		)
+		return code
+	}
+	err = cache.RedeemRedirectUrl(r.Context(), requestID, nonce, data)
+	if err != nil {
+		return getRedirectUrlErrorCode(err)
+	}
+
+	// Check that the file can still be downloaded.
+	foundFile, err := db.GetFile(r.Context(), requestID,
+		strconv.FormatUint(entry.FileID, 10))
+	if err != nil {
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+	auditEvent.TenantID = foundFile.TenantID
+	if foundFile.Status == db.FileStatusQuarantined {
+		return ErrorCodeFileQuarantined
+	}
+	if isBlockedUnscannedDownload(foundFile.Status, entry.Method) {
+		return ErrorCodeFileNotScanned
+	}
+
+	signedUrl, _, err := getFileSignedUrl(r.Context(), foundFile, entry.Method,
+		redeemedUrlDuration, getResponseHeaders(foundFile.Name,
+			entry.Disposition, entry.CacheControl))
+	if err != nil {
+		fsLogger.Error("Failed to generate a signed URL for the file!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		return ErrorCodeInternalError
+	}
+
+	// Record the download in the usage rollup for the tenant on a separate
+	// goroutine.
+	go db.RecordDownload(context.WithoutCancel(r.Context()), requestID,
+		foundFile.TenantID, foundFile.DeviceID)
+
+	w.Header().Set(headerCacheControl, "no-store")
+	http.Redirect(w, r, signedUrl, http.StatusFound)
+	return redirectUrlRedeemed
+}
+
+// checkRedirectUrlRestrictions returns the error code sent to the caller if
+// the request is not allowed to redeem the redirect URL, or an empty string.
+func checkRedirectUrlRestrictions(r *http.Request, entry *redirectUrlEntry,
+	auditEvent *db.AuditEvent) string {
+	if entry.ClientIP != "" &&
+		!net.ParseIP(entry.ClientIP).Equal(net.ParseIP(getClientIP(r))) {
+		return ErrorCodeForbidden
+	}
+
+	if entry.DeviceID != "" {
+		deviceInfo, err := getDeviceInfoFromToken(r)
+		if err != nil {
+			return errorCodeOf(err, ErrorCodeUnauthorized)
+		}
+		auditEvent.Actor = deviceInfo.DeviceID
+		if deviceInfo.DeviceID != entry.DeviceID {
+			return ErrorCodeForbidden
+		}
+	}
+	return ""
+}
+
+// getRedirectUrlErrorCode returns the error code sent to the caller if a
+// redirect URL cannot be looked up or redeemed in the cache.
+func getRedirectUrlErrorCode(err error) string {
+	switch {
+	case errors.Is(err, cache.ErrCacheNotFound):
+		return ErrorCodeNotFound
+	case errors.Is(err, cache.ErrCacheDisabled),
+		errors.Is(err, cache.ErrCacheDegraded):
+		return ErrorCodeServiceUnavailable
+	default:
+		return ErrorCodeInternalError
+	}
+}
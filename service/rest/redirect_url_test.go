@@ -0,0 +1,95 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+)
+
+// validate the options of one-time redirect URLs
+func TestRedirectUrlOptions(t *testing.T) {
+	type optionsTest struct {
+		baseUrl string
+		method  string
+		options redirectUrlOptions
+		valid   bool
+	}
+	const baseUrl = "https://files.test"
+	tests := map[string]optionsTest{
+		`signed url`:           {"", config.AccessMethodGet, redirectUrlOptions{}, true},
+		`one-time url`:         {baseUrl, config.AccessMethodGet, redirectUrlOptions{oneTime: true}, true},
+		`method is case-blind`: {baseUrl, "GET", redirectUrlOptions{oneTime: true}, true},
+		`restricted url`: {baseUrl, config.AccessMethodGet, redirectUrlOptions{
+			oneTime: true, clientIP: "2001:db8::1", requireDeviceToken: true}, true},
+		`not configured`:  {"", config.AccessMethodGet, redirectUrlOptions{oneTime: true}, false},
+		`one-time upload`: {baseUrl, config.AccessMethodPut, redirectUrlOptions{oneTime: true}, false},
+		`invalid client ip`: {baseUrl, config.AccessMethodGet, redirectUrlOptions{
+			oneTime: true, clientIP: "client"}, false},
+		`restriction without one-time url`: {baseUrl, config.AccessMethodGet,
+			redirectUrlOptions{clientIP: "192.0.2.1"}, false},
+	}
+
+	for desc, v := range tests {
+		redirectBaseUrl = v.baseUrl
+		err := validateRedirectUrlOptions(v.method, &v.options)
+		if (err == nil) != v.valid {
+			t.Fatalf("One-time URL options error: %s, expected: %v, got: %v",
+				desc, v.valid, err)
+		}
+	}
+	redirectBaseUrl = ""
+}
+
+// validate that the nonces of redirect URLs are unique and well formed
+func TestRedirectUrlNonce(t *testing.T) {
+	nonces := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		nonce, err := newRedirectUrlNonce()
+		if err != nil {
+			t.Fatalf("Failed to generate a nonce: %v", err)
+		}
+		if !redirectUrlNonceRegex.MatchString(nonce) || nonces[nonce] {
+			t.Fatalf("Invalid or duplicate nonce: %s", nonce)
+		}
+		nonces[nonce] = true
+	}
+}
+
+// validate that a forged X-Forwarded-For header cannot satisfy the client IP
+// restriction of a redirect URL
+func TestRedirectUrlClientIP(t *testing.T) {
+	type clientIPTest struct {
+		remoteAddr   string
+		forwardedFor string
+		allowed      bool
+	}
+	const allowedIP = "203.0.113.7"
+	tests := map[string]clientIPTest{
+		`allowed client`:          {allowedIP + ":52000", "", true},
+		`forged forwarded for`:    {"198.51.100.9:52000", allowedIP, false},
+		`forwarded by proxy`:      {"10.1.2.3:52000", allowedIP, true},
+		`forged hop behind proxy`: {"10.1.2.3:52000", allowedIP + ", 198.51.100.9", false},
+	}
+
+	trustedProxies = parseTrustedProxies([]string{"10.0.0.0/8"})
+	t.Cleanup(func() { trustedProxies = nil })
+	for desc, v := range tests {
+		r := httptest.NewRequest(http.MethodGet, redirectUrlPath+"nonce", nil)
+		r.RemoteAddr = v.remoteAddr
+		if v.forwardedFor != "" {
+			r.Header.Set(headerForwardedFor, v.forwardedFor)
+		}
+		code := checkRedirectUrlRestrictions(r,
+			&redirectUrlEntry{FileID: 1, ClientIP: allowedIP}, &db.AuditEvent{})
+		if (code == "") != v.allowed {
+			t.Fatalf("Client IP restriction error: %s, expected: %v, got: %q",
+				desc, v.allowed, code)
+		}
+	}
+}
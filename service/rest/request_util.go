@@ -16,14 +16,37 @@ const (
 	// REST request headers and expected header values.
 	headerContentType         = "Content-Type"
 	headerRequestID           = "request_id"
+	headerActor               = "actor"
+	headerCacheControl        = "Cache-Control"
+	headerForwardedFor        = "X-Forwarded-For"
+	headerRetryAfter          = "Retry-After"
+	headerContentTypeOptions  = "X-Content-Type-Options"
 	contentTypeFormUrlEncoded = "application/x-www-form-urlencoded"
 	contentTypeJson           = "application/json"
 
 	// Request parameters
-	paramTenantID = "tenant_id"
-	paramDeviceID = "device_id"
-	paramFileID   = "id"
-	paramMethod   = "method"
+	paramTenantID  = "tenant_id"
+	paramDeviceID  = "device_id"
+	paramFileID    = "id"
+	paramFileName  = "name"
+	paramMethod    = "method"
+	paramNamespace = "namespace"
+	paramFrom      = "from"
+	paramTo        = "to"
+	paramLimit     = "limit"
+	paramAuditFile = "file_id"
+	paramRunID     = "run_id"
+	paramJobID     = "job_id"
+	paramHoldID    = "hold_id"
+	paramDuration  = "url_duration_seconds"
+
+	paramDisposition  = "disposition"
+	paramCacheControl = "cache_control"
+
+	paramOneTime            = "one_time"
+	paramClientIP           = "client_ip"
+	paramRequireDeviceToken = "require_device_token"
+	paramNonce              = "nonce"
 )
 
 // getPathVariable gets & validates existence of string parameter
@@ -6,31 +6,79 @@ package rest
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/HPInc/krypton-fs/service/common"
 	"go.uber.org/zap"
 )
 
-func sendUnauthorizedErrorResponse(w http.ResponseWriter) {
-	http.Error(w, http.StatusText(http.StatusUnauthorized),
-		http.StatusUnauthorized)
+// Send the unauthorized error response for the specified token validation
+// error.
+func sendUnauthorizedErrorResponse(w http.ResponseWriter, err error) {
+	sendErrorResponse(w, errorCodeOf(err, ErrorCodeUnauthorized), nil)
 }
 
 func sendInternalServerErrorResponse(w http.ResponseWriter) {
-	http.Error(w, http.StatusText(http.StatusInternalServerError),
-		http.StatusInternalServerError)
+	sendErrorResponse(w, ErrorCodeInternalError, nil)
 }
 
 func sendBadRequestErrorResponse(w http.ResponseWriter) {
-	http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	sendErrorResponse(w, ErrorCodeBadRequest, nil)
 }
 
 func sendNotFoundErrorResponse(w http.ResponseWriter) {
-	http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	sendErrorResponse(w, ErrorCodeNotFound, nil)
 }
 
 func sendUnsupportedMediaTypeResponse(w http.ResponseWriter) {
-	http.Error(w, http.StatusText(http.StatusUnsupportedMediaType),
-		http.StatusUnsupportedMediaType)
+	sendErrorResponse(w, ErrorCodeUnsupportedMediaType, nil)
+}
+
+// Send the error response for the specified error. The fallback error code is
+// used if the error does not have an error code.
+func sendErrorResponseForError(w http.ResponseWriter, err error,
+	fallback string) {
+	sendErrorResponse(w, errorCodeOf(err, fallback), nil)
+}
+
+// Send the error response for the specified error code, along with optional
+// details about the error. The response carries the request ID echoed in the
+// response headers by the request logger.
+func sendErrorResponse(w http.ResponseWriter, code string,
+	details map[string]string) {
+	definition, ok := errorCatalogue[code]
+	if !ok {
+		fsLogger.Error("An unknown error code was specified for the response!",
+			zap.String("Error code:", code),
+		)
+		code = ErrorCodeInternalError
+		definition = errorCatalogue[code]
+	}
+
+	response := common.ErrorResponse{
+		Code:      code,
+		Message:   definition.message,
+		RequestID: w.Header().Get(headerRequestID),
+		Details:   details,
+	}
+	if definition.retryAfter > 0 {
+		response.RetryAfter = int(definition.retryAfter / time.Second)
+		w.Header().Set(headerRetryAfter, strconv.Itoa(response.RetryAfter))
+	}
+
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.Header().Set(headerContentTypeOptions, "nosniff")
+	w.WriteHeader(definition.status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(response); err != nil {
+		fsLogger.Error("Failed to encode JSON error response!",
+			zap.String("Error code:", code),
+			zap.Error(err),
+		)
+	}
 }
 
 // JSON encode and send the specified payload & the specified HTTP status code.
@@ -0,0 +1,127 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+)
+
+// validate mapping of sentinel errors to error codes
+func TestErrorCodeOf(t *testing.T) {
+	type errorCodeTest struct {
+		err  error
+		code string
+	}
+	tests := map[string]errorCodeTest{
+		`rest sentinel`:    {ErrInvalidChecksum, ErrorCodeInvalidChecksum},
+		`db sentinel`:      {db.ErrLegalHold, ErrorCodeLegalHold},
+		`wrapped sentinel`: {fmt.Errorf("create: %w", db.ErrNotFound), ErrorCodeNotFound},
+		`token error`:      {ErrInvalidAudienceClaim, ErrorCodeInvalidToken},
+		`unknown error`:    {fmt.Errorf("unknown"), ErrorCodeInternalError},
+	}
+
+	for desc, test := range tests {
+		code := errorCodeOf(test.err, ErrorCodeInternalError)
+		if code != test.code {
+			t.Fatalf("Error code mismatch: %s, expected: %s, got: %s",
+				desc, test.code, code)
+		}
+	}
+}
+
+// validate that every error code in the catalogue is an error status
+func TestErrorCatalogue(t *testing.T) {
+	for _, entry := range sentinelErrorCodes {
+		if _, ok := errorCatalogue[entry.code]; !ok {
+			t.Fatalf("Error code %s of error %v is not in the catalogue",
+				entry.code, entry.err)
+		}
+	}
+	for code, definition := range errorCatalogue {
+		if definition.status < http.StatusBadRequest || definition.message == "" {
+			t.Fatalf("Invalid definition of error code %s", code)
+		}
+	}
+}
+
+// validate the error response envelope
+func TestSendErrorResponse(t *testing.T) {
+	type errorResponseTest struct {
+		code       string
+		details    map[string]string
+		status     int
+		retryAfter string
+	}
+	tests := map[string]errorResponseTest{
+		`bad request`: {ErrorCodeInvalidFileName, nil,
+			http.StatusBadRequest, ""},
+		`with details`: {ErrorCodeLegalHold, map[string]string{detailFileID: "1"},
+			http.StatusConflict, ""},
+		`retry after`: {ErrorCodeServiceUnavailable, nil,
+			http.StatusServiceUnavailable, "30"},
+		`unknown code`: {"unknown", nil,
+			http.StatusInternalServerError, ""},
+	}
+
+	for desc, test := range tests {
+		w := httptest.NewRecorder()
+		w.Header().Set(headerRequestID, "request-1")
+		sendErrorResponse(w, test.code, test.details)
+
+		if w.Code != test.status {
+			t.Fatalf("Status mismatch: %s, expected: %d, got: %d",
+				desc, test.status, w.Code)
+		}
+		if w.Header().Get(headerContentType) != contentTypeJson {
+			t.Fatalf("Error response is not JSON encoded: %s", desc)
+		}
+		if w.Header().Get(headerRetryAfter) != test.retryAfter {
+			t.Fatalf("Retry-After mismatch: %s, expected: %q, got: %q",
+				desc, test.retryAfter, w.Header().Get(headerRetryAfter))
+		}
+
+		var response common.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode error response: %s - %v", desc, err)
+		}
+		if response.Code == "" || response.Message == "" ||
+			response.RequestID != "request-1" ||
+			len(response.Details) != len(test.details) {
+			t.Fatalf("Invalid error response: %s - %+v", desc, response)
+		}
+	}
+}
+
+// validate that unknown routes get error responses and echo the request ID
+func TestUnknownRoute(t *testing.T) {
+	router := initRequestRouter()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+	r.Header.Set(headerRequestID, "request-2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Status mismatch, expected: %d, got: %d",
+			http.StatusNotFound, w.Code)
+	}
+	if w.Header().Get(headerRequestID) != "request-2" {
+		t.Fatalf("The request ID was not echoed in the response headers")
+	}
+
+	var response common.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if response.Code != ErrorCodeNotFound || response.RequestID != "request-2" {
+		t.Fatalf("Invalid error response: %+v", response)
+	}
+}
@@ -0,0 +1,566 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/storage"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+// Files created by CreateFile can be uploaded in chunks using the tus 1.0
+// resumable upload protocol (https://tus.io/protocols/resumable-upload),
+// instead of using the signed upload URL. An upload is started for the file
+// with the creation extension, and each chunk appended to it is stored as a
+// part of a multipart upload in storage. The offset reached by the upload is
+// recorded in the database, so that devices can resume the upload from the
+// last chunk received, on any node. Once the last chunk is received, the
+// checksum of the file is verified and the file is marked uploaded without
+// waiting for the storage notification.
+const (
+	// Version and extensions of the tus protocol supported by the service.
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination"
+
+	// Path at which resumable uploads are served.
+	resumableUploadPath = "/api/v1/uploads/"
+
+	// Headers of the tus protocol.
+	headerTusResumable      = "Tus-Resumable"
+	headerTusVersion        = "Tus-Version"
+	headerTusExtension      = "Tus-Extension"
+	headerUploadOffset      = "Upload-Offset"
+	headerUploadLength      = "Upload-Length"
+	headerUploadDeferLength = "Upload-Defer-Length"
+	headerUploadMetadata    = "Upload-Metadata"
+	headerLocation          = "Location"
+
+	// Content type of the chunks appended to resumable uploads.
+	contentTypeOffsetOctetStream = "application/offset+octet-stream"
+
+	// Key of the upload metadata identifying the file to be uploaded.
+	uploadMetadataFileID = "file_id"
+
+	// Smallest chunk that can be appended to an upload, other than the last
+	// chunk, which is the smallest part of a multipart upload.
+	minUploadChunkSize = 5 << 20
+
+	// Largest number of parts of a multipart upload.
+	maxUploadParts = 10000
+
+	// Defaults for the resumable upload settings.
+	defaultMaxUploadChunkSize = 64 << 20
+	defaultUploadChunkTimeout = 300 * time.Second
+
+	// Time allowed to record a chunk once it has been stored, after which the
+	// claim of the request on the upload lapses.
+	uploadClaimGracePeriod = time.Minute
+
+	// Label of the resumable upload metrics for successful requests.
+	resumableUploadSucceeded = "ok"
+)
+
+var (
+	// Resumable upload settings.
+	resumableUploads resumableUploadSettings
+
+	ErrInvalidChunk          = errors.New("invalid chunk of resumable upload specified")
+	ErrInvalidUploadMetadata = errors.New("invalid resumable upload metadata specified")
+	ErrChecksumMismatch      = errors.New("uploaded file does not match its checksum")
+)
+
+// resumableUploadSettings - resumable upload settings, with defaults applied.
+type resumableUploadSettings struct {
+	enabled      bool
+	maxChunkSize int64
+	chunkTimeout time.Duration
+}
+
+// Applies the resumable upload settings of the service.
+func initResumableUploads(settings *config.ResumableUploads) {
+	resumableUploads = resumableUploadSettings{
+		enabled:      settings.Enabled,
+		maxChunkSize: defaultMaxUploadChunkSize,
+		chunkTimeout: defaultUploadChunkTimeout,
+	}
+	if settings.MaxChunkSizeInMB > 0 {
+		resumableUploads.maxChunkSize = int64(settings.MaxChunkSizeInMB) << 20
+	}
+	if settings.ChunkTimeoutSeconds > 0 {
+		resumableUploads.chunkTimeout = time.Duration(
+			settings.ChunkTimeoutSeconds) * time.Second
+	}
+}
+
+// GetResumableUploadOptionsHandler reports the version and extensions of the
+// tus protocol supported by the service.
+func GetResumableUploadOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	if !resumableUploads.enabled {
+		sendNotFoundErrorResponse(w)
+		return
+	}
+
+	w.Header().Set(headerTusResumable, tusVersion)
+	w.Header().Set(headerTusVersion, tusVersion)
+	w.Header().Set(headerTusExtension, tusExtensions)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateResumableUploadHandler starts a resumable upload of a file created by
+// CreateFile, identified by the file_id key of the upload metadata. Starting
+// an upload of a file that is already being uploaded returns the existing
+// upload, so that devices can retry requests whose response was lost.
+func CreateResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionUpload)
+	defer recordAuditEvent(w, auditEvent)
+
+	if code := createResumableUpload(w, r, requestID, auditEvent); code != "" {
+		sendErrorResponse(w, code, nil)
+	}
+}
+
+// Starts the upload of the file specified in the upload metadata of the
+// request. Returns the error code sent to the caller if the upload cannot be
+// started.
+func createResumableUpload(w http.ResponseWriter, r *http.Request,
+	requestID string, auditEvent *db.AuditEvent) string {
+	if code := checkTusRequest(w, r); code != "" {
+		return code
+	}
+
+	// Uploads of files whose size is not yet known are not supported.
+	metadata, err := parseUploadMetadata(r.Header.Get(headerUploadMetadata))
+	if err != nil || metadata[uploadMetadataFileID] == "" ||
+		r.Header.Get(headerUploadDeferLength) != "" {
+		fsLogger.Error("Invalid resumable upload metadata was specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		return ErrorCodeBadRequest
+	}
+	fileID := metadata[uploadMetadataFileID]
+	auditEvent.FileID = getAuditFileID(fileID)
+
+	foundFile, code := getDeviceFile(r, requestID, fileID, auditEvent)
+	if code != "" {
+		return code
+	}
+	if foundFile.Status != db.FileStatusNew {
+		return ErrorCodeOperationNotAllowed
+	}
+	length, err := strconv.ParseInt(r.Header.Get(headerUploadLength), 10, 64)
+	if err != nil || length != foundFile.Size {
+		return ErrorCodeInvalidFileSize
+	}
+
+	upload, err := startResumableUpload(r.Context(), requestID, foundFile)
+	if err != nil {
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+
+	w.Header().Set(headerLocation, resumableUploadPath+fileID)
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusCreated)
+	return ""
+}
+
+// Returns the resumable upload of the file, starting a multipart upload for
+// it in storage if it is not already being uploaded.
+func startResumableUpload(ctx context.Context, requestID string,
+	file *db.File) (*db.FileUpload, error) {
+	upload, err := db.GetFileUpload(ctx, requestID, file.FileID)
+	if !errors.Is(err, db.ErrNotFound) {
+		return upload, err
+	}
+
+	objectName := storage.GetObjectName(file.TenantID, file.DeviceID,
+		file.FileID)
+	uploadID, err := storage.Provider.CreateMultipartUpload(ctx,
+		file.BucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	upload, created, err := db.CreateFileUpload(ctx, requestID, file.FileID,
+		uploadID)
+	if err != nil || !created {
+		// Another request started the upload first, or the upload could not
+		// be recorded. Abandon the multipart upload started by this request.
+		_ = storage.Provider.AbortMultipartUpload(ctx, file.BucketName,
+			objectName, uploadID)
+		return upload, err
+	}
+
+	fsLogger.Info("Started a resumable upload of the file.",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.Uint64("File ID:", file.FileID),
+	)
+	metrics.MetricResumableUploadsCreated.Inc()
+	return upload, nil
+}
+
+// GetResumableUploadHandler reports the offset from which the upload of a
+// file should be resumed. Files that have been uploaded report their size.
+func GetResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	if code := getResumableUpload(w, r, requestID); code != "" {
+		sendErrorResponse(w, code, nil)
+	}
+}
+
+// Reports the offset reached by the upload of the file. Returns the error code
+// sent to the caller if the upload cannot be found.
+func getResumableUpload(w http.ResponseWriter, r *http.Request,
+	requestID string) string {
+	if code := checkTusRequest(w, r); code != "" {
+		return code
+	}
+	fileID, err := getPathVariable(r, paramFileID, true)
+	if err != nil {
+		return ErrorCodeBadRequest
+	}
+	foundFile, code := getDeviceFile(r, requestID, fileID,
+		&db.AuditEvent{})
+	if code != "" {
+		return code
+	}
+
+	offset := foundFile.Size
+	if foundFile.Status == db.FileStatusNew {
+		upload, err := db.GetFileUpload(r.Context(), requestID,
+			foundFile.FileID)
+		if err != nil {
+			return errorCodeOf(err, ErrorCodeInternalError)
+		}
+		offset = upload.Offset
+	}
+
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(offset, 10))
+	w.Header().Set(headerUploadLength, strconv.FormatInt(foundFile.Size, 10))
+	w.Header().Set(headerCacheControl, "no-store")
+	w.WriteHeader(http.StatusOK)
+	return ""
+}
+
+// AppendResumableUploadHandler appends the chunk in the request body to the
+// upload of a file, at the offset reached by the upload. Each chunk is stored
+// as a part of the multipart upload of the file. Once the last chunk has been
+// stored, the checksum of the file is verified and the upload is completed.
+func AppendResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	code := appendResumableUpload(w, r, requestID)
+	if code == "" {
+		metrics.MetricResumableUploadChunks.WithLabelValues(
+			resumableUploadSucceeded).Inc()
+		return
+	}
+	metrics.MetricResumableUploadChunks.WithLabelValues(code).Inc()
+	sendErrorResponse(w, code, nil)
+}
+
+// Appends the chunk in the body of the request to the upload of the file.
+// Returns the error code sent to the caller if the chunk cannot be appended.
+func appendResumableUpload(w http.ResponseWriter, r *http.Request,
+	requestID string) string {
+	if code := checkTusRequest(w, r); code != "" {
+		return code
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headerContentType))
+	if mediaType != contentTypeOffsetOctetStream {
+		return ErrorCodeUnsupportedChunkType
+	}
+	offset, err := strconv.ParseInt(r.Header.Get(headerUploadOffset), 10, 64)
+	if err != nil {
+		return ErrorCodeInvalidChunk
+	}
+	fileID, err := getPathVariable(r, paramFileID, true)
+	if err != nil {
+		return ErrorCodeBadRequest
+	}
+
+	foundFile, code := getDeviceFile(r, requestID, fileID,
+		&db.AuditEvent{})
+	if code != "" {
+		return code
+	}
+	if foundFile.Status != db.FileStatusNew {
+		return ErrorCodeOperationNotAllowed
+	}
+	upload, err := db.GetFileUpload(r.Context(), requestID, foundFile.FileID)
+	if err != nil {
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+	if err = validateChunk(offset, r.ContentLength, foundFile.Size,
+		len(upload.PartETags)); err != nil {
+		fsLogger.Error("An invalid chunk was appended to the resumable upload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", foundFile.FileID),
+			zap.Int64("Offset:", offset),
+			zap.Int64("Size:", r.ContentLength),
+		)
+		return ErrorCodeInvalidChunk
+	}
+
+	// Allow the chunk to be received and stored within the chunk timeout,
+	// instead of the timeouts of other requests.
+	controller := http.NewResponseController(w)
+	deadline := time.Now().Add(resumableUploads.chunkTimeout)
+	_ = controller.SetReadDeadline(deadline)
+	_ = controller.SetWriteDeadline(deadline.Add(uploadClaimGracePeriod))
+
+	claimToken, err := db.ClaimFileUpload(r.Context(), requestID,
+		foundFile.FileID, offset,
+		resumableUploads.chunkTimeout+uploadClaimGracePeriod)
+	if err != nil {
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+
+	offset, err = appendChunk(r, requestID, foundFile, upload, claimToken,
+		offset)
+	if err != nil {
+		// The claim is released unless the chunk was recorded or the upload
+		// was abandoned, in which case releasing it has no effect.
+		db.ReleaseFileUpload(context.WithoutCancel(r.Context()), requestID,
+			foundFile.FileID, claimToken)
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+	return ""
+}
+
+// Stores the chunk in the body of the request as the next part of the upload,
+// and completes the upload once the last chunk has been stored. Returns the
+// offset reached by the upload. The caller holds the claim on the upload.
+func appendChunk(r *http.Request, requestID string, file *db.File,
+	upload *db.FileUpload, claimToken int64, offset int64) (int64, error) {
+	digest, err := restoreChecksumState(upload.ChecksumState)
+	if err != nil {
+		fsLogger.Error("Failed to restore the checksum state of the upload!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Uint64("File ID:", file.FileID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	objectName := storage.GetObjectName(file.TenantID, file.DeviceID,
+		file.FileID)
+	etag, err := storage.Provider.UploadPart(r.Context(), file.BucketName,
+		objectName, upload.UploadID, int32(len(upload.PartETags)+1),
+		io.TeeReader(r.Body, digest), r.ContentLength,
+		resumableUploads.chunkTimeout)
+	if err != nil {
+		return 0, err
+	}
+	etags := append(upload.PartETags, etag)
+	offset += r.ContentLength
+	metrics.MetricResumableUploadBytes.Add(float64(r.ContentLength))
+
+	// Once the chunk has been stored, it is recorded even if the device
+	// disconnects. The offset of the upload only reaches the size of the file
+	// once the upload has been completed, so that devices do not stop
+	// uploading a file that was not stored.
+	ctx := context.WithoutCancel(r.Context())
+	if offset < file.Size {
+		state, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		return offset, db.AppendFileUploadPart(ctx, requestID, file.FileID,
+			claimToken, etag, offset, state)
+	}
+
+	checksum := base64.StdEncoding.EncodeToString(digest.Sum(nil))
+	if checksum != file.Checksum {
+		fsLogger.Error("The resumable upload does not match the checksum of the file!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(ctx),
+			zap.Uint64("File ID:", file.FileID),
+		)
+		metrics.MetricResumableUploadsCompleted.WithLabelValues(
+			ErrorCodeChecksumMismatch).Inc()
+		if err = abandonResumableUpload(ctx, requestID, file); err != nil {
+			return 0, err
+		}
+		return 0, ErrChecksumMismatch
+	}
+
+	err = storage.Provider.CompleteMultipartUpload(ctx, file.BucketName,
+		objectName, upload.UploadID, etags)
+	if err != nil {
+		return 0, err
+	}
+	if err = completeResumableUpload(ctx, requestID, file); err != nil {
+		return 0, err
+	}
+	metrics.MetricResumableUploadsCompleted.WithLabelValues(
+		resumableUploadSucceeded).Inc()
+	return offset, nil
+}
+
+// Marks the file uploaded, or pending a scan if malware scanning is enabled,
+// and deletes the completed upload.
+func completeResumableUpload(ctx context.Context, requestID string,
+	file *db.File) error {
+	err := markFileStored(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DeleteFileUpload(ctx, requestID, file.FileID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	fsLogger.Info("Completed the resumable upload of the file.",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(ctx),
+		zap.Uint64("File ID:", file.FileID),
+	)
+	return nil
+}
+
+// DeleteResumableUploadHandler abandons the upload of a file and deletes the
+// chunks received so far. The file remains new, and may be uploaded again.
+func DeleteResumableUploadHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, db.AuditActionUploadAbort)
+	defer recordAuditEvent(w, auditEvent)
+
+	if code := deleteResumableUpload(w, r, requestID, auditEvent); code != "" {
+		sendErrorResponse(w, code, nil)
+	}
+}
+
+// Abandons the upload of the file. Returns the error code sent to the caller
+// if the upload cannot be abandoned.
+func deleteResumableUpload(w http.ResponseWriter, r *http.Request,
+	requestID string, auditEvent *db.AuditEvent) string {
+	if code := checkTusRequest(w, r); code != "" {
+		return code
+	}
+	fileID, err := getPathVariable(r, paramFileID, true)
+	if err != nil {
+		return ErrorCodeBadRequest
+	}
+	auditEvent.FileID = getAuditFileID(fileID)
+
+	foundFile, code := getDeviceFile(r, requestID, fileID, auditEvent)
+	if code != "" {
+		return code
+	}
+	err = abandonResumableUpload(r.Context(), requestID, foundFile)
+	if err != nil {
+		return errorCodeOf(err, ErrorCodeInternalError)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return ""
+}
+
+// Deletes the upload of the file and abandons its multipart upload in
+// storage.
+func abandonResumableUpload(ctx context.Context, requestID string,
+	file *db.File) error {
+	upload, err := db.DeleteFileUpload(ctx, requestID, file.FileID)
+	if err != nil {
+		return err
+	}
+
+	// Parts left behind if the multipart upload cannot be aborted are
+	// removed by the lifecycle rules of the bucket.
+	_ = storage.Provider.AbortMultipartUpload(ctx, file.BucketName,
+		storage.GetObjectName(file.TenantID, file.DeviceID, file.FileID),
+		upload.UploadID)
+	return nil
+}
+
+// Checks that resumable uploads are enabled and that the version of the tus
+// protocol requested is supported, and sets the version used in the response.
+// Returns the error code sent to the caller if the request cannot be served.
+func checkTusRequest(w http.ResponseWriter, r *http.Request) string {
+	if !resumableUploads.enabled {
+		return ErrorCodeNotFound
+	}
+
+	w.Header().Set(headerTusResumable, tusVersion)
+	if r.Header.Get(headerTusResumable) != tusVersion {
+		w.Header().Set(headerTusVersion, tusVersion)
+		return ErrorCodeUnsupportedVersion
+	}
+	return ""
+}
+
+// Checks that a chunk of the specified size can be appended at the specified
+// offset to the upload of a file of the specified size, which has the
+// specified number of parts. Every chunk but the last must be large enough to
+// be stored as a part of a multipart upload.
+func validateChunk(offset int64, size int64, fileSize int64, parts int) error {
+	end := offset + size
+	switch {
+	case offset < 0 || size <= 0 || end > fileSize:
+		return ErrInvalidChunk
+	case size > resumableUploads.maxChunkSize:
+		return ErrInvalidChunk
+	case end < fileSize && size < minUploadChunkSize:
+		return ErrInvalidChunk
+	case parts >= maxUploadParts:
+		return ErrInvalidChunk
+	}
+	return nil
+}
+
+// Parses the Upload-Metadata header, which holds comma separated pairs of
+// keys and base64 encoded values. Values may be omitted.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if header == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, ErrInvalidUploadMetadata
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, ErrInvalidUploadMetadata
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// Returns an MD5 digest restored from the specified state, or a new digest if
+// no bytes have been received yet.
+func restoreChecksumState(state []byte) (hash.Hash, error) {
+	digest := md5.New()
+	if len(state) == 0 {
+		return digest, nil
+	}
+	err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(state)
+	return digest, err
+}
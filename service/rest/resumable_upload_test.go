@@ -0,0 +1,154 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HPInc/krypton-fs/service/config"
+)
+
+// validate the chunks that can be appended to resumable uploads
+func TestValidateChunk(t *testing.T) {
+	type chunkTest struct {
+		offset   int64
+		size     int64
+		fileSize int64
+		parts    int
+		valid    bool
+	}
+	const mb = 1 << 20
+	tests := map[string]chunkTest{
+		`first chunk`:         {0, 5 * mb, 20 * mb, 0, true},
+		`last chunk`:          {15 * mb, 5 * mb, 20 * mb, 3, true},
+		`small last chunk`:    {20 * mb, 1, 20*mb + 1, 4, true},
+		`small file`:          {0, 10, 10, 0, true},
+		`largest chunk`:       {0, 64 * mb, 100 * mb, 0, true},
+		`small chunk`:         {0, 5*mb - 1, 20 * mb, 0, false},
+		`empty chunk`:         {0, 0, 20 * mb, 0, false},
+		`negative offset`:     {-1, 5 * mb, 20 * mb, 0, false},
+		`chunk past end`:      {0, 21 * mb, 20 * mb, 0, false},
+		`chunk too large`:     {0, 64*mb + 1, 100 * mb, 0, false},
+		`too many parts`:      {0, 5 * mb, 20 * mb, maxUploadParts, false},
+		`last of many parts`:  {0, 5 * mb, 5 * mb, maxUploadParts - 1, true},
+		`offset beyond size`:  {20 * mb, 1, 20 * mb, 4, false},
+		`small chunk at end`:  {19 * mb, 1, 20 * mb, 3, false},
+		`whole file in chunk`: {0, 20 * mb, 20 * mb, 0, true},
+	}
+
+	initResumableUploads(&config.ResumableUploads{})
+	for desc, v := range tests {
+		err := validateChunk(v.offset, v.size, v.fileSize, v.parts)
+		if (err == nil) != v.valid {
+			t.Fatalf("Chunk validation error: %s, expected: %v, got: %v",
+				desc, v.valid, err)
+		}
+	}
+}
+
+// validate parsing of the Upload-Metadata header
+func TestParseUploadMetadata(t *testing.T) {
+	type metadataTest struct {
+		header   string
+		metadata map[string]string
+		valid    bool
+	}
+	tests := map[string]metadataTest{
+		`no metadata`: {"", map[string]string{}, true},
+		`file id`:     {"file_id MTIz", map[string]string{"file_id": "123"}, true},
+		`several keys`: {"filename YS50eHQ=, file_id MTIz,is_confidential",
+			map[string]string{"filename": "a.txt", "file_id": "123",
+				"is_confidential": ""}, true},
+		`invalid value`: {"file_id 123!", nil, false},
+		`missing key`:   {"file_id MTIz, ", nil, false},
+	}
+
+	for desc, v := range tests {
+		metadata, err := parseUploadMetadata(v.header)
+		if (err == nil) != v.valid {
+			t.Fatalf("Upload metadata error: %s, expected: %v, got: %v",
+				desc, v.valid, err)
+		}
+		if len(metadata) != len(v.metadata) {
+			t.Fatalf("Upload metadata mismatch: %s, expected: %v, got: %v",
+				desc, v.metadata, metadata)
+		}
+		for key, value := range v.metadata {
+			if metadata[key] != value {
+				t.Fatalf("Upload metadata mismatch: %s, expected: %v, got: %v",
+					desc, v.metadata, metadata)
+			}
+		}
+	}
+}
+
+// validate that the checksum of a file is computed across chunks
+func TestRestoreChecksumState(t *testing.T) {
+	chunks := [][]byte{[]byte("first chunk,"), []byte("second chunk,"),
+		[]byte("last chunk")}
+
+	var state []byte
+	for _, chunk := range chunks {
+		digest, err := restoreChecksumState(state)
+		if err != nil {
+			t.Fatalf("Failed to restore the checksum state: %v", err)
+		}
+		digest.Write(chunk)
+		state, err = digest.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("Failed to save the checksum state: %v", err)
+		}
+	}
+
+	digest, _ := restoreChecksumState(state)
+	expected := md5.Sum(bytes.Join(chunks, nil))
+	if !bytes.Equal(digest.Sum(nil), expected[:]) {
+		t.Fatalf("Checksum mismatch, expected: %x, got: %x", expected,
+			digest.Sum(nil))
+	}
+
+	if _, err := restoreChecksumState([]byte("state")); err == nil {
+		t.Fatalf("Restored an invalid checksum state")
+	}
+}
+
+// validate the tus protocol version check
+func TestCheckTusRequest(t *testing.T) {
+	type tusTest struct {
+		enabled bool
+		version string
+		code    string
+	}
+	tests := map[string]tusTest{
+		`supported version`:   {true, tusVersion, ""},
+		`unsupported version`: {true, "0.2.2", ErrorCodeUnsupportedVersion},
+		`missing version`:     {true, "", ErrorCodeUnsupportedVersion},
+		`disabled`:            {false, tusVersion, ErrorCodeNotFound},
+	}
+
+	for desc, v := range tests {
+		initResumableUploads(&config.ResumableUploads{Enabled: v.enabled})
+		r := httptest.NewRequest(http.MethodHead, "/api/v1/uploads/1", nil)
+		if v.version != "" {
+			r.Header.Set(headerTusResumable, v.version)
+		}
+		w := httptest.NewRecorder()
+		code := checkTusRequest(w, r)
+		if code != v.code {
+			t.Fatalf("Tus request error: %s, expected: %q, got: %q",
+				desc, v.code, code)
+		}
+		if code == ErrorCodeUnsupportedVersion &&
+			w.Header().Get(headerTusVersion) != tusVersion {
+			t.Fatalf("Tus request error: %s, supported versions not reported",
+				desc)
+		}
+	}
+	initResumableUploads(&config.ResumableUploads{})
+}
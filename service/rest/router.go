@@ -9,20 +9,65 @@ import (
 	"time"
 
 	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 )
 
+// Names under which requests that do not match a registered route are
+// logged and reported.
+const (
+	routeNameNotFound         = "NotFound"
+	routeNameMethodNotAllowed = "MethodNotAllowed"
+)
+
+// statusRecorder records the status code of the response written by a
+// handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the response writer recorded, so that handlers can use
+// http.ResponseController to extend the deadlines of long-running requests.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func requestLogger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
 		// Extract the request ID if specified, else create a new request ID.
+		// The request ID is echoed in the response headers.
 		if r.Header.Get(headerRequestID) == "" {
 			r.Header.Set(headerRequestID, uuid.NewString())
 		}
+		w.Header().Set(headerRequestID, r.Header.Get(headerRequestID))
+
+		// Start a span for the request. If the caller specified a W3C trace
+		// context, the span continues the caller's trace.
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(),
+			propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.StartServerSpan(ctx, name,
+			attribute.String("http.method", r.Method),
+			attribute.String("http.route", name),
+			attribute.String("fs.request_id", r.Header.Get(headerRequestID)),
+		)
+		defer span.End()
+		r = r.WithContext(ctx)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 
 		// Calculate and report REST latency metric.
 		defer metrics.ReportLatencyMetric(metrics.MetricRestLatency, start,
@@ -37,16 +82,29 @@ func requestLogger(inner http.Handler, name string) http.Handler {
 				return
 			}
 			fsLogger.Debug("+++ New REST request +++",
+				tracing.TraceID(ctx),
 				zap.ByteString("Request", dump),
 			)
 		}
 
-		inner.ServeHTTP(w, r)
+		inner.ServeHTTP(recorder, r)
 		metrics.MetricRequestCount.Inc()
+		metrics.MetricRestResponses.WithLabelValues(name,
+			metrics.StatusClass(recorder.status)).Inc()
+
+		span.SetAttributes(attribute.Int("http.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+
 		fsLogger.Debug("-- Served REST request --",
 			zap.String("Method: ", r.Method),
 			zap.String("Request URI: ", r.RequestURI),
 			zap.String("Route name: ", name),
+			zap.String("Request ID: ", r.Header.Get(headerRequestID)),
+			tracing.TraceID(ctx),
+			tracing.SpanID(ctx),
+			zap.Int("Status: ", recorder.status),
 			zap.String("Duration: ", time.Since(start).String()),
 		)
 	})
@@ -60,6 +118,7 @@ func initRequestRouter() *mux.Router {
 	for _, route := range registeredRoutes {
 		var handler http.Handler
 		handler = route.HandlerFunc
+		handler = requestValidator(handler, route.Name)
 		handler = requestLogger(handler, route.Name)
 
 		router.
@@ -68,5 +127,16 @@ func initRequestRouter() *mux.Router {
 			Name(route.Name).
 			Handler(handler)
 	}
+
+	// Requests for unknown routes, or with methods not supported by a route,
+	// are failed with error responses like those of the handlers.
+	router.NotFoundHandler = requestLogger(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			sendNotFoundErrorResponse(w)
+		}), routeNameNotFound)
+	router.MethodNotAllowedHandler = requestLogger(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			sendErrorResponse(w, ErrorCodeMethodNotAllowed, nil)
+		}), routeNameMethodNotAllowed)
 	return router
 }
@@ -30,6 +30,23 @@ var registeredRoutes = routes{
 		HandlerFunc: GetHealthHandler,
 	},
 
+	// Liveness method. Reports whether the service process is alive.
+	Route{
+		Name:        "GetLiveness",
+		Method:      http.MethodGet,
+		Path:        "/health/live",
+		HandlerFunc: GetLivenessHandler,
+	},
+
+	// Readiness method. Reports whether the dependencies of the service can
+	// be reached and the service can serve requests.
+	Route{
+		Name:        "GetReadiness",
+		Method:      http.MethodGet,
+		Path:        "/health/ready",
+		HandlerFunc: GetReadinessHandler,
+	},
+
 	// Metrics method.
 	Route{
 		Name:        "GetMetrics",
@@ -38,6 +55,14 @@ var registeredRoutes = routes{
 		HandlerFunc: promhttp.Handler().(http.HandlerFunc),
 	},
 
+	// OpenAPI document describing the routes of the service.
+	Route{
+		Name:        "GetOpenAPI",
+		Method:      http.MethodGet,
+		Path:        "/api/openapi.json",
+		HandlerFunc: GetOpenAPIHandler,
+	},
+
 	///////////////////////////////////////////////////////////////////////////
 	//                   External API routes (device facing)                 //
 	///////////////////////////////////////////////////////////////////////////
@@ -52,6 +77,16 @@ var registeredRoutes = routes{
 		HandlerFunc: CreateFileHandler,
 	},
 
+	// Create records for a batch of files in the files database and return
+	// pre-signed URLs that the client can use to upload the files to storage.
+	// Invalid files are reported in the results of the batch.
+	Route{
+		Name:        "BatchCreateFiles",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/files:batch",
+		HandlerFunc: BatchCreateFilesHandler,
+	},
+
 	// Get information about the file corresponding to the specified file ID.
 	Route{
 		Name:        "GetFile",
@@ -60,6 +95,86 @@ var registeredRoutes = routes{
 		HandlerFunc: GetFileHandler,
 	},
 
+	// Get information about the newest uploaded version of the file with the
+	// specified name. An optional namespace may be specified as a query
+	// parameter.
+	Route{
+		Name:        "GetFileByName",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/files/by-name/{name}",
+		HandlerFunc: GetFileByNameHandler,
+	},
+
+	// Redeem a one-time redirect URL issued by GetSignedUrl and redirect the
+	// caller to a short-lived signed URL to download the file.
+	Route{
+		Name:        "RedeemRedirectUrl",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/files/redirect/{nonce}",
+		HandlerFunc: RedeemRedirectUrlHandler,
+	},
+
+	// Upload the contents of a new file through the content proxy, for
+	// tenants whose devices cannot reach storage.
+	Route{
+		Name:        "PutFileContent",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/files/{id:[0-9]+}/content",
+		HandlerFunc: PutFileContentHandler,
+	},
+
+	// Download the contents of a file through the content proxy.
+	Route{
+		Name:        "GetFileContent",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/files/{id:[0-9]+}/content",
+		HandlerFunc: GetFileContentHandler,
+	},
+
+	// Report the version and extensions of the tus resumable upload protocol
+	// supported by the service.
+	Route{
+		Name:        "GetResumableUploadOptions",
+		Method:      http.MethodOptions,
+		Path:        "/api/v1/uploads",
+		HandlerFunc: GetResumableUploadOptionsHandler,
+	},
+
+	// Start a resumable upload of a file created by CreateFile, using the tus
+	// protocol.
+	Route{
+		Name:        "CreateResumableUpload",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/uploads",
+		HandlerFunc: CreateResumableUploadHandler,
+	},
+
+	// Report the offset from which the resumable upload of the specified file
+	// should be resumed.
+	Route{
+		Name:        "GetResumableUpload",
+		Method:      http.MethodHead,
+		Path:        "/api/v1/uploads/{id:[0-9]+}",
+		HandlerFunc: GetResumableUploadHandler,
+	},
+
+	// Append a chunk to the resumable upload of the specified file, and
+	// complete the upload once the last chunk has been appended.
+	Route{
+		Name:        "AppendResumableUpload",
+		Method:      http.MethodPatch,
+		Path:        "/api/v1/uploads/{id:[0-9]+}",
+		HandlerFunc: AppendResumableUploadHandler,
+	},
+
+	// Abandon the resumable upload of the specified file.
+	Route{
+		Name:        "DeleteResumableUpload",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/uploads/{id:[0-9]+}",
+		HandlerFunc: DeleteResumableUploadHandler,
+	},
+
 	///////////////////////////////////////////////////////////////////////////
 	//                   Internal API routes (service facing)                //
 	///////////////////////////////////////////////////////////////////////////
@@ -72,6 +187,82 @@ var registeredRoutes = routes{
 		HandlerFunc: ScavengeRequestHandler,
 	},
 
+	// Reports the status and progress of a run of the database scavenger.
+	Route{
+		Name:        "GetScavengerRun",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/scavenger/{run_id:[0-9]+}",
+		HandlerFunc: GetScavengerRunHandler,
+	},
+
+	// Deletes all files of a tenant, and the objects stored for them, using
+	// a background job.
+	Route{
+		Name:        "PurgeTenant",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/purge/tenants/{tenant_id}",
+		HandlerFunc: PurgeRequestHandler,
+	},
+
+	// Deletes all files of a device, and the objects stored for them, using
+	// a background job.
+	Route{
+		Name:        "PurgeDevice",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/purge/tenants/{tenant_id}/devices/{device_id}",
+		HandlerFunc: PurgeRequestHandler,
+	},
+
+	// Reports the status and progress of a purge job.
+	Route{
+		Name:        "GetPurgeJob",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/purge/{job_id:[0-9]+}",
+		HandlerFunc: GetPurgeJobHandler,
+	},
+
+	// Places a legal hold on a tenant, device or file, which prevents its
+	// files from being deleted.
+	Route{
+		Name:        "PlaceLegalHold",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/legal_holds",
+		HandlerFunc: PlaceLegalHoldHandler,
+	},
+
+	// Lists the legal holds of a tenant.
+	Route{
+		Name:        "ListLegalHolds",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/legal_holds",
+		HandlerFunc: ListLegalHoldsHandler,
+	},
+
+	// Returns the specified legal hold.
+	Route{
+		Name:        "GetLegalHold",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/legal_holds/{hold_id:[0-9]+}",
+		HandlerFunc: GetLegalHoldHandler,
+	},
+
+	// Releases the specified legal hold.
+	Route{
+		Name:        "ReleaseLegalHold",
+		Method:      http.MethodDelete,
+		Path:        "/api/internal/v1/legal_holds/{hold_id:[0-9]+}",
+		HandlerFunc: ReleaseLegalHoldHandler,
+	},
+
+	// Reports the node currently elected leader to run the database
+	// scavenger.
+	Route{
+		Name:        "GetLeaderStatus",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/leader",
+		HandlerFunc: GetLeaderStatusHandler,
+	},
+
 	// Returns information about files matching the requested filter. Scoped
 	// to a single tenant and device.
 	Route{
@@ -97,4 +288,79 @@ var registeredRoutes = routes{
 		Path:        "/api/internal/v1/files/{id:[0-9]+}/signed_url",
 		HandlerFunc: GetSignedUrlHandler,
 	},
+
+	// Produces presigned GET URLs for a batch of existing files. Files that
+	// cannot be downloaded are reported in the results of the batch.
+	Route{
+		Name:        "BatchGetSignedUrls",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/files:batch_signed_url",
+		HandlerFunc: BatchGetSignedUrlsHandler,
+	},
+
+	// Marks the specified file as pending a scan and requests a rescan from
+	// the malware scanning pipeline.
+	Route{
+		Name:        "RescanFile",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/files/{id:[0-9]+}/rescan",
+		HandlerFunc: RescanFileHandler,
+	},
+
+	// Releases the specified quarantined file.
+	Route{
+		Name:        "ReleaseFile",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/files/{id:[0-9]+}/release",
+		HandlerFunc: ReleaseFileHandler,
+	},
+
+	// Confirms the quarantine of the specified quarantined file.
+	Route{
+		Name:        "ConfirmQuarantine",
+		Method:      http.MethodPost,
+		Path:        "/api/internal/v1/files/{id:[0-9]+}/confirm",
+		HandlerFunc: ConfirmQuarantineHandler,
+	},
+
+	// Lists the scan verdicts recorded for the specified file.
+	Route{
+		Name:        "ListFileScans",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/files/{id:[0-9]+}/scans",
+		HandlerFunc: ListFileScansHandler,
+	},
+
+	// Reports the daily usage of the service by the specified tenant.
+	Route{
+		Name:        "GetUsage",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/usage/{tenant_id}",
+		HandlerFunc: GetUsageHandler,
+	},
+
+	// Lists the audit events recorded for file access and administrative
+	// actions, filtered by tenant, file and time range.
+	Route{
+		Name:        "ListAuditEvents",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/audit",
+		HandlerFunc: ListAuditEventsHandler,
+	},
+
+	// Reports the level at which the service logs.
+	Route{
+		Name:        "GetLogLevel",
+		Method:      http.MethodGet,
+		Path:        "/api/internal/v1/log_level",
+		HandlerFunc: GetLogLevelHandler,
+	},
+
+	// Changes the level at which the service logs at runtime.
+	Route{
+		Name:        "SetLogLevel",
+		Method:      http.MethodPut,
+		Path:        "/api/internal/v1/log_level",
+		HandlerFunc: SetLogLevelHandler,
+	},
 }
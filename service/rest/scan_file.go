@@ -0,0 +1,227 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/common"
+	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/notification"
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// Actor recorded for scan actions that do not identify the caller.
+	defaultScanActor = "internal"
+)
+
+// scanActionFunc represents a change to the scan status of a file.
+type scanActionFunc func(ctx context.Context, requestID, id, actor,
+	reason string) (*db.File, error)
+
+// Marks the specified file as pending a scan and asks the malware scanning
+// pipeline to rescan it. The file is only marked pending a scan once the
+// rescan has been requested.
+func RescanFileHandler(w http.ResponseWriter, r *http.Request) {
+	handleScanAction(w, r, "RescanFile", db.AuditActionRescan,
+		func(ctx context.Context, requestID, id, actor,
+			reason string) (*db.File, error) {
+			return db.RequestFileRescan(ctx, requestID, id, actor, reason,
+				func(file *db.File) error {
+					return notification.RequestRescan(ctx, requestID, file)
+				})
+		})
+}
+
+// Releases the specified quarantined file.
+func ReleaseFileHandler(w http.ResponseWriter, r *http.Request) {
+	handleScanAction(w, r, "ReleaseFile", db.AuditActionRelease,
+		db.ReleaseQuarantinedFile)
+}
+
+// Confirms the quarantine of the specified quarantined file.
+func ConfirmQuarantineHandler(w http.ResponseWriter, r *http.Request) {
+	handleScanAction(w, r, "ConfirmQuarantine", db.AuditActionQuarantine,
+		db.ConfirmQuarantinedFile)
+}
+
+func handleScanAction(w http.ResponseWriter, r *http.Request, name string,
+	auditAction string, action scanActionFunc) {
+	requestID := r.Header.Get(headerRequestID)
+	auditEvent := newAuditEvent(r, auditAction)
+	defer recordAuditEvent(w, auditEvent)
+
+	// Retrieve the specified file identifier.
+	fileID, err := getPathVariable(r, paramFileID, true)
+	if err != nil {
+		fsLogger.Error("The required file id path variable was not specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Action:", name),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricScanActionBadRequests.Inc()
+		return
+	}
+	auditEvent.FileID = getAuditFileID(fileID)
+
+	// The request payload is optional. If specified, it identifies the actor
+	// and the reason for the action.
+	var request common.ScanActionRequest
+	payload, err := getRequestPayload(r)
+	if err == nil && len(payload) > 0 {
+		err = json.Unmarshal(payload, &request)
+	}
+	if err != nil {
+		fsLogger.Error("Failed to read the scan action request payload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Action:", name),
+			zap.Error(err),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
+		metrics.MetricScanActionBadRequests.Inc()
+		return
+	}
+	if request.Actor == "" {
+		request.Actor = auditEvent.Actor
+	}
+	if request.Actor == "" {
+		request.Actor = defaultScanActor
+	}
+	auditEvent.Actor = request.Actor
+
+	updatedFile, err := action(r.Context(), requestID, fileID, request.Actor, request.Reason)
+	if err != nil {
+		switch err {
+		case db.ErrNotFound:
+			fsLogger.Error("No file with the requested file ID was found in the database",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+			)
+			sendNotFoundErrorResponse(w)
+			metrics.MetricScanActionNotFoundErrors.Inc()
+
+		case db.ErrNotAllowed:
+			sendErrorResponse(w, ErrorCodeOperationNotAllowed, nil)
+			metrics.MetricScanActionNotAllowedErrors.Inc()
+
+		default:
+			fsLogger.Error("Failed to update the scan status of the file!",
+				zap.String("Request ID:", requestID),
+				tracing.TraceID(r.Context()),
+				zap.String("Action:", name),
+				zap.Error(err),
+			)
+			sendInternalServerErrorResponse(w)
+			metrics.MetricScanActionInternalErrors.Inc()
+		}
+		return
+	}
+	auditEvent.TenantID = updatedFile.TenantID
+
+	fsLogger.Info("Updated the scan status of the file",
+		zap.String("Request ID:", requestID),
+		tracing.TraceID(r.Context()),
+		zap.String("Action:", name),
+		zap.Uint64("File ID:", updatedFile.FileID),
+		zap.String("Actor:", request.Actor),
+		zap.String("Status:", updatedFile.Status),
+	)
+
+	response := common.CommonFileResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		File: common.FileInformation{
+			FileID:    updatedFile.FileID,
+			TenantID:  updatedFile.TenantID,
+			DeviceID:  updatedFile.DeviceID,
+			Name:      updatedFile.Name,
+			Namespace: updatedFile.Namespace,
+			Version:   updatedFile.Version,
+			Checksum:  updatedFile.Checksum,
+			Size:      updatedFile.Size,
+			Status:    updatedFile.Status,
+			CreatedAt: updatedFile.CreatedAt,
+			UpdatedAt: updatedFile.UpdatedAt,
+		},
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		metrics.MetricScanActionInternalErrors.Inc()
+	}
+
+	metrics.MetricScanActionResponses.Inc()
+}
+
+// Lists the scan verdicts recorded for the specified file.
+func ListFileScansHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	// Retrieve the specified file identifier.
+	fileID, err := getPathVariable(r, paramFileID, true)
+	if err != nil {
+		fsLogger.Error("The required file id path variable was not specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricScanActionBadRequests.Inc()
+		return
+	}
+
+	// Ensure the file exists so that callers can distinguish an unknown file
+	// from a file that has not been scanned.
+	foundFile, err := db.GetFile(r.Context(), requestID, fileID)
+	if err != nil {
+		if err == db.ErrNotFound {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricScanActionNotFoundErrors.Inc()
+			return
+		}
+		sendInternalServerErrorResponse(w)
+		metrics.MetricScanActionInternalErrors.Inc()
+		return
+	}
+
+	scans, err := db.ListFileScans(r.Context(), requestID, fileID)
+	if err != nil {
+		fsLogger.Error("Failed to list the scans recorded for the file!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricScanActionInternalErrors.Inc()
+		return
+	}
+
+	response := common.ListFileScansResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		FileID:       foundFile.FileID,
+	}
+	for _, item := range scans {
+		response.Scans = append(response.Scans, common.FileScanInformation{
+			Scanner:   item.Scanner,
+			Verdict:   item.Verdict,
+			Reason:    item.Reason,
+			ScannedAt: item.ScannedAt,
+		})
+	}
+
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		metrics.MetricScanActionInternalErrors.Inc()
+	}
+
+	metrics.MetricScanActionResponses.Inc()
+}
@@ -4,21 +4,186 @@
 package rest
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/HPInc/krypton-fs/service/common"
 	"github.com/HPInc/krypton-fs/service/db"
+	"github.com/HPInc/krypton-fs/service/metrics"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"go.uber.org/zap"
 )
 
+// Records a run of the database scavenger, which starts in the background once
+// this node is elected leader, and returns the run so its progress can be
+// followed. The request payload is optional, and may request a dry run.
 func ScavengeRequestHandler(w http.ResponseWriter, r *http.Request) {
-	// Run the database scavenger.
-	fsLogger.Info("Received a REST request to run the DB scavenger!")
-	go db.RunScavenger()
+	requestID := r.Header.Get(headerRequestID)
 
-	err := sendJsonResponse(w, http.StatusAccepted, nil)
+	var request common.ScavengerRunRequest
+	payload, err := getRequestPayload(r)
+	if err == nil && len(payload) > 0 {
+		err = json.Unmarshal(payload, &request)
+	}
+	if err != nil {
+		fsLogger.Error("Failed to read the scavenger run request payload",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.Error(err),
+		)
+		sendErrorResponse(w, ErrorCodeInvalidPayload, nil)
+		metrics.MetricRunScavengerBadRequests.Inc()
+		return
+	}
+
+	fsLogger.Info("Received a REST request to run the DB scavenger!",
+		zap.String("Request ID:", requestID),
+		zap.Bool("Dry run:", request.DryRun),
+	)
+	run, err := db.StartScavengerRun(r.Context(), requestID, request.DryRun)
+	if err != nil {
+		sendInternalServerErrorResponse(w)
+		metrics.MetricRunScavengerInternalErrors.Inc()
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/internal/v1/scavenger/%d",
+		run.RunID))
+	err = sendJsonResponse(w, http.StatusAccepted,
+		newScavengerRunResponse(requestID, run))
 	if err != nil {
 		fsLogger.Error("Failed to send response to scavenge request",
+			zap.String("Request ID:", requestID),
+			zap.Error(err),
+		)
+		metrics.MetricRunScavengerInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricRunScavengerResponses.Inc()
+}
+
+// Reports the status and progress of a run of the database scavenger. Runs
+// are recorded in the database, so any node can report on them.
+func GetScavengerRunHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	value, err := getPathVariable(r, paramRunID, true)
+	var runID uint64
+	if err == nil {
+		runID, err = strconv.ParseUint(value, 10, 64)
+	}
+	if err != nil {
+		fsLogger.Error("An invalid scavenger run ID was specified in the request",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
+			zap.String("Run ID:", value),
+		)
+		sendBadRequestErrorResponse(w)
+		metrics.MetricGetScavengerRunBadRequests.Inc()
+		return
+	}
+
+	run, err := db.GetScavengerRun(r.Context(), requestID, runID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			sendNotFoundErrorResponse(w)
+			metrics.MetricGetScavengerRunNotFoundErrors.Inc()
+			return
+		}
+		sendInternalServerErrorResponse(w)
+		metrics.MetricGetScavengerRunInternalErrors.Inc()
+		return
+	}
+
+	err = sendJsonResponse(w, http.StatusOK,
+		newScavengerRunResponse(requestID, run))
+	if err != nil {
+		fsLogger.Error("Failed to send the scavenger run response!",
+			zap.String("Request ID:", requestID),
+			zap.Error(err),
+		)
+		metrics.MetricGetScavengerRunInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricGetScavengerRunResponses.Inc()
+}
+
+func newScavengerRunResponse(requestID string,
+	run *db.ScavengerRun) common.ScavengerRunResponse {
+	return common.ScavengerRunResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Run: common.ScavengerRunInformation{
+			RunID:           run.RunID,
+			Trigger:         run.Trigger,
+			DryRun:          run.DryRun,
+			Status:          run.Status,
+			NodeID:          run.NodeID,
+			FencingToken:    run.FencingToken,
+			CreatedAt:       run.CreatedAt,
+			StartedAt:       run.StartedAt,
+			CompletedAt:     run.CompletedAt,
+			ExpiredFiles:    run.ExpiredFiles,
+			OldFileVersions: run.OldFileVersions,
+			AuditEvents:     run.AuditEvents,
+			Batches:         run.Batches,
+			HeldFiles:       run.HeldFiles,
+			Error:           run.Error,
+		},
+	}
+}
+
+// Reports the node currently holding the leader lease, which runs the
+// database scavenger, and whether it is the node serving the request.
+func GetLeaderStatusHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(headerRequestID)
+
+	leader, err := db.GetLeaderStatus(r.Context())
+	if err != nil && !errors.Is(err, db.ErrNoLeader) {
+		fsLogger.Error("Failed to get the status of the leader lease!",
+			zap.String("Request ID:", requestID),
+			tracing.TraceID(r.Context()),
 			zap.Error(err),
 		)
+		sendInternalServerErrorResponse(w)
+		metrics.MetricLeaderStatusInternalErrors.Inc()
+		return
+	}
+
+	response := common.LeaderStatusResponse{
+		RequestID:    requestID,
+		ResponseTime: time.Now(),
+		Backend:      db.GetLeaderBackend(),
+		NodeID:       db.GetLeaderNodeID(),
+	}
+	if leader != nil {
+		response.IsLeader = leader.Holder == response.NodeID
+		response.Leader = &common.LeaderInformation{
+			NodeID:       leader.Holder,
+			FencingToken: leader.FencingToken,
+			AcquiredAt:   leader.AcquiredAt,
+			RenewedAt:    leader.RenewedAt,
+		}
+		if !leader.ExpiresAt.IsZero() {
+			response.Leader.ExpiresAt = &leader.ExpiresAt
+		}
 	}
+
+	err = sendJsonResponse(w, http.StatusOK, response)
+	if err != nil {
+		fsLogger.Error("Failed to send the leader status response!",
+			zap.String("Request ID:", requestID),
+			zap.Error(err),
+		)
+		metrics.MetricLeaderStatusInternalErrors.Inc()
+		return
+	}
+
+	metrics.MetricLeaderStatusResponses.Inc()
 }
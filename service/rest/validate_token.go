@@ -5,6 +5,7 @@ package rest
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -33,12 +34,16 @@ type TokenClaims struct {
 
 // get bearer token and do common validation
 func validateToken(r *http.Request) (*TokenClaims, error) {
-	var claims TokenClaims
-
 	accessToken, err := getBearerToken(r)
 	if err != nil {
 		return nil, err
 	}
+	return parseToken(accessToken)
+}
+
+// verify the signature and issuer of the token and return its claims
+func parseToken(accessToken string) (*TokenClaims, error) {
+	var claims TokenClaims
 
 	token, err := jwt.ParseWithClaims(accessToken, &claims, getSigningKey)
 	if err != nil {
@@ -51,7 +56,7 @@ func validateToken(r *http.Request) (*TokenClaims, error) {
 		return nil, ErrInvalidTokenHeaderSigningAlg
 	}
 
-	if !strings.HasPrefix(claims.Issuer, authConfig.Issuer) {
+	if !strings.HasPrefix(claims.Issuer, authConfig.Load().Issuer) {
 		return nil, ErrInvalidIssuerClaim
 	}
 	return &claims, nil
@@ -72,6 +77,22 @@ func getDeviceInfoFromToken(r *http.Request) (*DeviceInfo, error) {
 	}, nil
 }
 
+// do common validation and check that the app token was issued to one of
+// the allowed apps, for service facing apis
+func validateAppToken(accessToken string) (*TokenClaims, error) {
+	claims, err := parseToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != appType {
+		return nil, ErrInvalidTypeClaim
+	}
+	if !slices.Contains(authConfig.Load().AllowedAppIds, claims.Subject) {
+		return nil, ErrAuthz
+	}
+	return claims, nil
+}
+
 func getBearerToken(r *http.Request) (string, error) {
 	bearerToken := "Bearer "
 	headerAuthorization := "Authorization"
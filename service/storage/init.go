@@ -6,6 +6,7 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/HPInc/krypton-fs/service/config"
 	"github.com/HPInc/krypton-fs/service/storage/s3provider"
@@ -18,7 +19,8 @@ var (
 	Provider StorageProvider
 
 	// Errors
-	ErrNotInitialized = errors.New("storage is not initialized")
+	ErrNotInitialized         = errors.New("storage is not initialized")
+	ErrObjectLockNotSupported = s3provider.ErrObjectLockNotSupported
 )
 
 // Initialize the storage provider used to store files.
@@ -29,6 +31,7 @@ func Init(logger *zap.Logger, storageConfig *config.Storage) error {
 	// When more providers are added, update this logic to pick the right storage
 	// provider based on configuration.
 	Provider = s3provider.NewAwsStorageProvider()
+	updateSignedUrlPolicy(storageConfig)
 
 	return Provider.Init(fsLogger, storageConfig)
 }
@@ -41,6 +44,49 @@ func GetObjectName(tenantID, deviceID string, fileID uint64) string {
 	return fmt.Sprintf("%s/%s/%d", tenantID, deviceID, fileID)
 }
 
-func Shutdown() {
+// GetObjectPrefix returns the prefix shared by the names of all objects
+// stored for the specified device, or for all devices of the tenant if no
+// device is specified.
+func GetObjectPrefix(tenantID, deviceID string) string {
+	if deviceID == "" {
+		return tenantID + "/"
+	}
+	return fmt.Sprintf("%s/%s/", tenantID, deviceID)
+}
+
+// CheckSettings verifies that the buckets in the reloaded storage settings,
+// which are not yet in use by the service, can be used to store files.
+func CheckSettings(storageConfig *config.Storage) error {
+	if Provider == nil {
+		return ErrNotInitialized
+	}
+
+	var newBuckets []string
+	for _, bucket := range storageConfig.BucketNames {
+		if !slices.Contains(config.Settings.Storage.BucketNames, bucket) {
+			newBuckets = append(newBuckets, bucket)
+		}
+	}
+	if len(newBuckets) == 0 {
+		return nil
+	}
+	return Provider.Verify(&newBuckets)
+}
+
+// UpdateSettings applies the reloaded storage settings to the storage
+// provider.
+func UpdateSettings(storageConfig *config.Storage) error {
+	if Provider == nil {
+		return ErrNotInitialized
+	}
+	Provider.UpdateSettings(storageConfig)
+	updateSignedUrlPolicy(storageConfig)
+	return nil
+}
 
+// Shutdown the storage provider.
+func Shutdown() {
+	if Provider != nil {
+		Provider.Shutdown()
+	}
 }
@@ -4,6 +4,10 @@
 package storage
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/HPInc/krypton-fs/service/config"
 	"go.uber.org/zap"
 )
@@ -14,16 +18,74 @@ type StorageProvider interface {
 	// Initialize the storage provider.
 	Init(logger *zap.Logger, storageConfig *config.Storage) error
 
-	// Returns a signed URL configured for the desired type of access (method).
-	GetSignedUrl(bucketName string, objectName string, method string,
-		checksum string, size int64) (string, error)
+	// Returns a signed URL configured for the desired type of access (method),
+	// which is valid for the specified duration. The response headers, if
+	// any, are overridden in responses to GET requests.
+	GetSignedUrl(ctx context.Context, bucketName string, objectName string,
+		method string, checksum string, size int64, expires time.Duration,
+		headers *ResponseHeaders) (string, error)
 
 	// Delete the specified object.
-	DeleteObject(bucketName string, objectName string) error
+	DeleteObject(ctx context.Context, bucketName string, objectName string) error
+
+	// List up to the specified number of objects whose names start with the
+	// specified prefix, in order, after the specified object name.
+	ListObjects(ctx context.Context, bucketName string, prefix string,
+		startAfter string, maxObjects int) ([]string, error)
+
+	// Delete the specified objects.
+	DeleteObjects(ctx context.Context, bucketName string,
+		objectNames []string) error
+
+	// Place or remove a legal hold on the specified object, if the bucket
+	// has object lock enabled. Returns ErrObjectLockNotSupported otherwise.
+	SetObjectLegalHold(ctx context.Context, bucketName string,
+		objectName string, hold bool) error
+
+	// Start a multipart upload of the specified object and return the
+	// identifier of the upload.
+	CreateMultipartUpload(ctx context.Context, bucketName string,
+		objectName string) (string, error)
+
+	// Upload the specified number of bytes read from the body as a part of a
+	// multipart upload, within the specified timeout, and return the ETag of
+	// the part.
+	UploadPart(ctx context.Context, bucketName string, objectName string,
+		uploadID string, partNumber int32, body io.Reader, size int64,
+		timeout time.Duration) (string, error)
+
+	// Assemble the object from the parts of a multipart upload, identified by
+	// their ETags in order.
+	CompleteMultipartUpload(ctx context.Context, bucketName string,
+		objectName string, uploadID string, etags []string) error
+
+	// Abandon a multipart upload and delete the parts uploaded so far.
+	AbortMultipartUpload(ctx context.Context, bucketName string,
+		objectName string, uploadID string) error
+
+	// Store the specified number of bytes read from the body as the contents
+	// of the specified object, within the specified timeout. Storage verifies
+	// the contents against the base64 encoded MD5 checksum.
+	PutObject(ctx context.Context, bucketName string, objectName string,
+		body io.Reader, size int64, checksum string,
+		timeout time.Duration) error
+
+	// Return the contents of the specified object and their size. The
+	// contents must be read within the specified timeout, and closed once
+	// read.
+	GetObject(ctx context.Context, bucketName string, objectName string,
+		timeout time.Duration) (io.ReadCloser, int64, error)
 
 	// Verify storage provider using provider specific operations
 	Verify(buckets *[]string) error
 
+	// Apply the storage settings that can be changed without restarting the
+	// service.
+	UpdateSettings(storageConfig *config.Storage)
+
+	// Check whether the configured buckets can be reached.
+	Ping(ctx context.Context) error
+
 	// Close the provider and cleanup resources.
 	Shutdown()
 }
@@ -6,14 +6,22 @@ package s3provider
 import (
 	"context"
 
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
-func (p *S3StorageProvider) DeleteObject(bucketName string,
+func (p *S3StorageProvider) DeleteObject(ctx context.Context, bucketName string,
 	objectName string) error {
-	ctx, cancelFunc := context.WithTimeout(gCtx, awsOperationTimeout)
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageDeleteObject,
+		attribute.String("storage.bucket", bucketName),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
 	defer cancelFunc()
 	_, err := p.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucketName),
@@ -25,6 +33,7 @@ func (p *S3StorageProvider) DeleteObject(bucketName string,
 			zap.String("Object name:", objectName),
 			zap.Error(err),
 		)
+		tracing.SetError(span, err)
 		return err
 	}
 
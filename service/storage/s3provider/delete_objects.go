@@ -0,0 +1,107 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package s3provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ListObjects lists up to the specified number of objects under the prefix,
+// in lexicographical order, after the specified object name.
+func (p *S3StorageProvider) ListObjects(ctx context.Context, bucketName string,
+	prefix string, startAfter string, maxObjects int) ([]string, error) {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageListObjects,
+		attribute.String("storage.bucket", bucketName),
+	)
+	defer span.End()
+
+	if maxObjects <= 0 || maxObjects > awsMaxObjectsPerRequest {
+		maxObjects = awsMaxObjectsPerRequest
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucketName),
+		Prefix:  aws.String(prefix),
+		MaxKeys: int32(maxObjects),
+	}
+	if startAfter != "" {
+		input.StartAfter = aws.String(startAfter)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	listed, err := p.s3Client.ListObjectsV2(ctx, input)
+	if err != nil {
+		fsLogger.Error("Failed to list the objects under the prefix!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Prefix:", prefix),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return nil, err
+	}
+
+	objectNames := make([]string, 0, len(listed.Contents))
+	for _, object := range listed.Contents {
+		objectNames = append(objectNames, aws.ToString(object.Key))
+	}
+	return objectNames, nil
+}
+
+// DeleteObjects deletes the specified objects with a single request.
+func (p *S3StorageProvider) DeleteObjects(ctx context.Context,
+	bucketName string, objectNames []string) error {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageDeleteObjects,
+		attribute.String("storage.bucket", bucketName),
+		attribute.Int("storage.objects", len(objectNames)),
+	)
+	defer span.End()
+
+	if len(objectNames) > awsMaxObjectsPerRequest {
+		return fmt.Errorf("cannot delete more than %d objects at once",
+			awsMaxObjectsPerRequest)
+	}
+
+	objects := make([]types.ObjectIdentifier, 0, len(objectNames))
+	for _, objectName := range objectNames {
+		objects = append(objects, types.ObjectIdentifier{
+			Key: aws.String(objectName),
+		})
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	deleted, err := p.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucketName),
+		Delete: &types.Delete{
+			Objects: objects,
+			Quiet:   true,
+		},
+	})
+	if err == nil && len(deleted.Errors) > 0 {
+		err = fmt.Errorf("failed to delete %d objects: %s",
+			len(deleted.Errors), aws.ToString(deleted.Errors[0].Message))
+	}
+	if err != nil {
+		fsLogger.Error("Failed to delete the requested objects!",
+			zap.String("Bucket name:", bucketName),
+			zap.Int("Number of objects:", len(objectNames)),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package s3provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Error code returned by S3 for buckets without object lock configuration.
+const errCodeObjectLockNotFound = "ObjectLockConfigurationNotFoundError"
+
+// SetObjectLegalHold mirrors a legal hold to the S3 Object Lock legal hold of
+// the specified object. Returns ErrObjectLockNotSupported if object lock is
+// not enabled for the bucket.
+func (p *S3StorageProvider) SetObjectLegalHold(ctx context.Context,
+	bucketName string, objectName string, hold bool) error {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageSetLegalHold,
+		attribute.String("storage.bucket", bucketName),
+		attribute.Bool("storage.legal_hold", hold),
+	)
+	defer span.End()
+
+	enabled, err := p.isObjectLockEnabled(ctx, bucketName)
+	if err != nil {
+		tracing.SetError(span, err)
+		return err
+	}
+	if !enabled {
+		return ErrObjectLockNotSupported
+	}
+
+	status := types.ObjectLockLegalHoldStatusOff
+	if hold {
+		status = types.ObjectLockLegalHoldStatusOn
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	_, err = p.s3Client.PutObjectLegalHold(ctx, &s3.PutObjectLegalHoldInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(objectName),
+		LegalHold: &types.ObjectLockLegalHold{Status: status},
+	})
+	if err != nil {
+		fsLogger.Error("Failed to set the legal hold of the object!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Bool("Hold:", hold),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// Returns whether object lock is enabled for the specified bucket. Object lock
+// can only be enabled when a bucket is created, so the result is cached.
+func (p *S3StorageProvider) isObjectLockEnabled(ctx context.Context,
+	bucketName string) (bool, error) {
+	if enabled, ok := p.objectLockBuckets.Load(bucketName); ok {
+		return enabled.(bool), nil
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	output, err := p.s3Client.GetObjectLockConfiguration(ctx,
+		&s3.GetObjectLockConfigurationInput{
+			Bucket: aws.String(bucketName),
+		})
+	if err != nil {
+		var apiErr smithy.APIError
+		if !errors.As(err, &apiErr) ||
+			apiErr.ErrorCode() != errCodeObjectLockNotFound {
+			fsLogger.Error("Failed to get the object lock configuration of the bucket!",
+				zap.String("Bucket name:", bucketName),
+				zap.Error(err),
+			)
+			return false, err
+		}
+	}
+
+	enabled := err == nil && output.ObjectLockConfiguration != nil &&
+		output.ObjectLockConfiguration.ObjectLockEnabled ==
+			types.ObjectLockEnabledEnabled
+	p.objectLockBuckets.Store(bucketName, enabled)
+	return enabled, nil
+}
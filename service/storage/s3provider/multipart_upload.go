@@ -0,0 +1,165 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package s3provider
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// CreateMultipartUpload starts a multipart upload of the specified object and
+// returns the identifier of the upload.
+func (p *S3StorageProvider) CreateMultipartUpload(ctx context.Context,
+	bucketName string, objectName string) (string, error) {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageCreateUpload,
+		attribute.String("storage.bucket", bucketName),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	output, err := p.s3Client.CreateMultipartUpload(ctx,
+		&s3.CreateMultipartUploadInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectName),
+		})
+	if err != nil {
+		fsLogger.Error("Failed to start a multipart upload of the object!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return "", err
+	}
+
+	return aws.ToString(output.UploadId), nil
+}
+
+// UploadPart uploads the specified number of bytes read from the body as a
+// part of a multipart upload, and returns the ETag of the part. The body is
+// streamed to storage, so the upload is allowed the specified timeout rather
+// than the timeout of other storage operations.
+func (p *S3StorageProvider) UploadPart(ctx context.Context, bucketName string,
+	objectName string, uploadID string, partNumber int32, body io.Reader,
+	size int64, timeout time.Duration) (string, error) {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageUploadPart,
+		attribute.String("storage.bucket", bucketName),
+		attribute.Int("storage.part_number", int(partNumber)),
+		attribute.Int64("storage.part_size", size),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		timeout)
+	defer cancelFunc()
+
+	// The body cannot be rewound to compute its hash before it is sent, so
+	// the payload is not signed. The contents of the upload are verified
+	// using the checksum of the file once all parts have been uploaded.
+	output, err := p.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(objectName),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    partNumber,
+		Body:          body,
+		ContentLength: size,
+	}, s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware))
+	if err != nil {
+		fsLogger.Error("Failed to upload a part of the multipart upload!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Int32("Part number:", partNumber),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return "", err
+	}
+
+	return aws.ToString(output.ETag), nil
+}
+
+// CompleteMultipartUpload assembles the object from the parts of a multipart
+// upload, identified by their ETags in order.
+func (p *S3StorageProvider) CompleteMultipartUpload(ctx context.Context,
+	bucketName string, objectName string, uploadID string,
+	etags []string) error {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageCompleteUpload,
+		attribute.String("storage.bucket", bucketName),
+		attribute.Int("storage.parts", len(etags)),
+	)
+	defer span.End()
+
+	parts := make([]types.CompletedPart, 0, len(etags))
+	for i, etag := range etags {
+		parts = append(parts, types.CompletedPart{
+			ETag:       aws.String(etag),
+			PartNumber: int32(i + 1),
+		})
+	}
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	_, err := p.s3Client.CompleteMultipartUpload(ctx,
+		&s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bucketName),
+			Key:             aws.String(objectName),
+			UploadId:        aws.String(uploadID),
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+	if err != nil {
+		fsLogger.Error("Failed to complete the multipart upload!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Int("Parts:", len(parts)),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload abandons a multipart upload and deletes the parts
+// uploaded so far.
+func (p *S3StorageProvider) AbortMultipartUpload(ctx context.Context,
+	bucketName string, objectName string, uploadID string) error {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageAbortUpload,
+		attribute.String("storage.bucket", bucketName),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
+	defer cancelFunc()
+	_, err := p.s3Client.AbortMultipartUpload(ctx,
+		&s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucketName),
+			Key:      aws.String(objectName),
+			UploadId: aws.String(uploadID),
+		})
+	if err != nil {
+		fsLogger.Error("Failed to abort the multipart upload!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
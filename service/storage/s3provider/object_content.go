@@ -0,0 +1,109 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package s3provider
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/tracing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// objectReader - the contents of an object being downloaded, which end the
+// download when closed.
+type objectReader struct {
+	io.ReadCloser
+	cancelFunc context.CancelFunc
+	span       trace.Span
+}
+
+func (o *objectReader) Close() error {
+	err := o.ReadCloser.Close()
+	o.cancelFunc()
+	o.span.End()
+	return err
+}
+
+// PutObject stores the specified number of bytes read from the body as the
+// contents of the specified object. Storage verifies the contents against
+// the base64 encoded MD5 checksum. The body is streamed to storage, so the
+// upload is allowed the specified timeout rather than the timeout of other
+// storage operations.
+func (p *S3StorageProvider) PutObject(ctx context.Context, bucketName string,
+	objectName string, body io.Reader, size int64, checksum string,
+	timeout time.Duration) error {
+	ctx, span := tracing.StartClientSpan(ctx, spanStoragePutObject,
+		attribute.String("storage.bucket", bucketName),
+		attribute.Int64("storage.object_size", size),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		timeout)
+	defer cancelFunc()
+
+	// The body cannot be rewound to compute its hash before it is sent, so
+	// the payload is not signed. Its integrity is verified by storage using
+	// the checksum instead.
+	_, err := p.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(objectName),
+		Body:          body,
+		ContentLength: size,
+		ContentMD5:    aws.String(checksum),
+	}, s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware))
+	if err != nil {
+		fsLogger.Error("Failed to store the contents of the object!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Int64("Size:", size),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetObject returns the contents of the specified object and their size. The
+// contents must be read within the specified timeout, and closed once read.
+func (p *S3StorageProvider) GetObject(ctx context.Context, bucketName string,
+	objectName string, timeout time.Duration) (io.ReadCloser, int64, error) {
+	ctx, span := tracing.StartClientSpan(ctx, spanStorageGetObject,
+		attribute.String("storage.bucket", bucketName),
+	)
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		timeout)
+	output, err := p.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(objectName),
+	})
+	if err != nil {
+		fsLogger.Error("Failed to get the contents of the object!",
+			zap.String("Bucket name:", bucketName),
+			zap.String("Object name:", objectName),
+			zap.Error(err),
+		)
+		tracing.SetError(span, err)
+		cancelFunc()
+		span.End()
+		return nil, 0, err
+	}
+
+	// The download ends once the caller closes the contents.
+	return &objectReader{
+		ReadCloser: output.Body,
+		cancelFunc: cancelFunc,
+		span:       span,
+	}, output.ContentLength, nil
+}
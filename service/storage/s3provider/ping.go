@@ -0,0 +1,28 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package s3provider
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Ping checks whether each of the configured buckets can be reached.
+func (p *S3StorageProvider) Ping(ctx context.Context) error {
+	if p.s3Client == nil {
+		return ErrInvalidClient
+	}
+
+	for _, bucketName := range p.getBucketNames() {
+		_, err := p.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{
+			Bucket: aws.String(bucketName),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
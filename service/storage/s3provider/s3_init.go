@@ -6,6 +6,8 @@ package s3provider
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	fsconfig "github.com/HPInc/krypton-fs/service/config"
@@ -25,15 +27,41 @@ var (
 	ErrInvalidPresignClient     = errors.New("presign client creation failed")
 	ErrBucketsNotConfigured     = errors.New("no buckets configured")
 	ErrBucketVerificationFailed = errors.New("bucket verification failed")
+	ErrObjectLockNotSupported   = errors.New("object lock is not enabled for the bucket")
 
-	// Global context for the package.
-	gCtx context.Context
+	// Global context for the package. It is cancelled when the provider is
+	// shut down to abort outstanding storage operations.
+	gCtx        context.Context
+	gCancelFunc context.CancelFunc
 )
 
 const (
 	awsOperationTimeout     = time.Second * 5
 	awsSqsVisibilityTimeout = 60
 	awsRetryMaxAttempts     = 5
+
+	// Duration of the signed URL used to upload a test file when verifying
+	// buckets.
+	verifySignedUrlDuration = time.Minute
+
+	// Names of the spans created for storage operations.
+	spanStorageSignedUrl     = "storage.GetSignedUrl"
+	spanStorageDeleteObject  = "storage.DeleteObject"
+	spanStorageListObjects   = "storage.ListObjects"
+	spanStorageDeleteObjects = "storage.DeleteObjects"
+	spanStorageSetLegalHold  = "storage.SetObjectLegalHold"
+
+	spanStorageCreateUpload   = "storage.CreateMultipartUpload"
+	spanStorageUploadPart     = "storage.UploadPart"
+	spanStorageCompleteUpload = "storage.CompleteMultipartUpload"
+	spanStorageAbortUpload    = "storage.AbortMultipartUpload"
+
+	spanStoragePutObject = "storage.PutObject"
+	spanStorageGetObject = "storage.GetObject"
+
+	// Maximum number of objects that can be listed or deleted by a single
+	// request.
+	awsMaxObjectsPerRequest = 1000
 )
 
 // S3StorageProvider - represents a storage provider for AWS S3 that implements
@@ -45,8 +73,13 @@ type S3StorageProvider struct {
 	// Presign url client
 	presignClient *s3.PresignClient
 
-	// The duration for which the generated signed URL is valid.
-	signedUrlDuration time.Duration
+	// Names of the buckets configured for the service. They may be changed
+	// when the configuration is reloaded.
+	bucketNames atomic.Pointer[[]string]
+
+	// Whether object lock is enabled for each bucket used to mirror legal
+	// holds, by bucket name.
+	objectLockBuckets sync.Map
 }
 
 // NewAwsStorageProvider creates a new instance of the AWS S3 storage provider.
@@ -58,7 +91,7 @@ func NewAwsStorageProvider() *S3StorageProvider {
 // S3 storage.
 func (p *S3StorageProvider) Init(logger *zap.Logger, storageConfig *fsconfig.Storage) error {
 	fsLogger = logger
-	gCtx = context.Background()
+	gCtx, gCancelFunc = context.WithCancel(context.Background())
 	ctx, cancelFunc := context.WithTimeout(gCtx, awsOperationTimeout)
 	defer cancelFunc()
 
@@ -95,14 +128,27 @@ func (p *S3StorageProvider) Init(logger *zap.Logger, storageConfig *fsconfig.Sto
 		return err
 	}
 
-	// Determine the lifetime/duration of signed URLs from the configuration
-	// file.
-	p.signedUrlDuration = time.Duration(storageConfig.SignedUrlDurationInMinutes) *
-		time.Minute
+	// Determine the buckets used to store files from the configuration file.
+	p.UpdateSettings(storageConfig)
 
 	return p.Verify(&storageConfig.BucketNames)
 }
 
+// UpdateSettings applies the storage settings that can be changed without
+// restarting the service.
+func (p *S3StorageProvider) UpdateSettings(storageConfig *fsconfig.Storage) {
+	bucketNames := append([]string(nil), storageConfig.BucketNames...)
+	p.bucketNames.Store(&bucketNames)
+}
+
+// Returns the names of the buckets configured for the service.
+func (p *S3StorageProvider) getBucketNames() []string {
+	if bucketNames := p.bucketNames.Load(); bucketNames != nil {
+		return *bucketNames
+	}
+	return nil
+}
+
 // define a custom retry
 func retryFunc() aws.Retryer {
 	return retry.AddWithMaxAttempts(retry.NewStandard(),
@@ -110,5 +156,7 @@ func retryFunc() aws.Retryer {
 }
 
 func (p *S3StorageProvider) Shutdown() {
-
+	if gCancelFunc != nil {
+		gCancelFunc()
+	}
 }
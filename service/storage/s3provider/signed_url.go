@@ -6,31 +6,59 @@ package s3provider
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/tracing"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
-// Returns a signed URL configured for the desired type of access (method).
-func (p *S3StorageProvider) GetSignedUrl(bucketName string, objectName string,
-	method string, checksum string, size int64) (string, error) {
+// ResponseHeaders - headers that storage returns, instead of those stored with
+// the object, in responses to downloads using a signed URL. Empty headers are
+// not overridden.
+type ResponseHeaders struct {
+	ContentDisposition string
+	ContentType        string
+	CacheControl       string
+}
+
+// Returns a signed URL configured for the desired type of access (method),
+// which is valid for the specified duration. The response headers, if any,
+// are overridden in responses to GET requests.
+func (p *S3StorageProvider) GetSignedUrl(ctx context.Context, bucketName string,
+	objectName string, method string, checksum string, size int64,
+	expires time.Duration, headers *ResponseHeaders) (string, error) {
 	var signedUrlRequest *v4.PresignedHTTPRequest
 	var err error
 
-	ctx, cancelFunc := context.WithTimeout(gCtx, awsOperationTimeout)
+	ctx, span := tracing.StartSpan(ctx, spanStorageSignedUrl,
+		attribute.String("storage.bucket", bucketName),
+		attribute.String("storage.method", method),
+	)
+	defer span.End()
+
+	ctx, cancelFunc := context.WithTimeout(tracing.WithSpanFrom(gCtx, ctx),
+		awsOperationTimeout)
 	defer cancelFunc()
 
 	switch strings.ToLower(method) {
 	case config.AccessMethodGet:
+		input := &s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectName),
+		}
+		if headers != nil {
+			input.ResponseContentDisposition = optionalString(headers.ContentDisposition)
+			input.ResponseContentType = optionalString(headers.ContentType)
+			input.ResponseCacheControl = optionalString(headers.CacheControl)
+		}
 		signedUrlRequest, err = p.presignClient.PresignGetObject(
-			ctx, &s3.GetObjectInput{
-				Bucket: aws.String(bucketName),
-				Key:    aws.String(objectName),
-			}, func(opts *s3.PresignOptions) {
-				opts.Expires = p.signedUrlDuration
+			ctx, input, func(opts *s3.PresignOptions) {
+				opts.Expires = expires
 			})
 
 	case config.AccessMethodHead:
@@ -39,7 +67,7 @@ func (p *S3StorageProvider) GetSignedUrl(bucketName string, objectName string,
 				Bucket: aws.String(bucketName),
 				Key:    aws.String(objectName),
 			}, func(opts *s3.PresignOptions) {
-				opts.Expires = p.signedUrlDuration
+				opts.Expires = expires
 			})
 
 	case config.AccessMethodPut:
@@ -50,13 +78,14 @@ func (p *S3StorageProvider) GetSignedUrl(bucketName string, objectName string,
 				ContentMD5:    aws.String(checksum),
 				ContentLength: size,
 			}, func(opts *s3.PresignOptions) {
-				opts.Expires = p.signedUrlDuration
+				opts.Expires = expires
 			})
 
 	default:
 		fsLogger.Error("Invalid request method specified!",
 			zap.String("Method specified:", method),
 		)
+		tracing.SetError(span, ErrInvalidMethod)
 		return "", ErrInvalidMethod
 	}
 
@@ -67,8 +96,17 @@ func (p *S3StorageProvider) GetSignedUrl(bucketName string, objectName string,
 			zap.String("Method:", method),
 			zap.Error(err),
 		)
+		tracing.SetError(span, err)
 		return "", err
 	}
 
 	return signedUrlRequest.URL, nil
 }
+
+// Returns a pointer to the specified string, or nil if it is empty.
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
@@ -57,8 +57,8 @@ func (p *S3StorageProvider) uploadFile(bucket, name string) error {
 	fsLogger.Info("s3 verification: uploading file",
 		zap.String("bucket", bucket),
 		zap.String("file", name))
-	url, err := p.GetSignedUrl(bucket, name, config.AccessMethodPut,
-		TestFileChecksum, TestFileSize)
+	url, err := p.GetSignedUrl(context.Background(), bucket, name, config.AccessMethodPut,
+		TestFileChecksum, TestFileSize, verifySignedUrlDuration, nil)
 	if err != nil {
 		fsLogger.Error("Error creating signed url",
 			zap.Error(err))
@@ -70,7 +70,7 @@ func (p *S3StorageProvider) uploadFile(bucket, name string) error {
 
 // delete the uploaded file
 func (p *S3StorageProvider) deleteFile(bucket, name string) error {
-	err := p.DeleteObject(bucket, name)
+	err := p.DeleteObject(context.Background(), bucket, name)
 	if err != nil {
 		fsLogger.Error("Failed to delete the uploaded file!",
 			zap.Error(err),
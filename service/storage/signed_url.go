@@ -0,0 +1,136 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"github.com/HPInc/krypton-fs/service/storage/s3provider"
+)
+
+// Durations of signed URLs resolved from the storage settings. They may be
+// changed when the configuration is reloaded.
+type signedUrlPolicy struct {
+	// Duration of signed URLs for methods without a configured duration.
+	defaultDuration time.Duration
+
+	// Durations of signed URLs by access method.
+	methodDurations map[string]time.Duration
+
+	// Durations of signed URLs by tenant ID and access method, overriding
+	// the method durations.
+	tenantDurations map[string]map[string]time.Duration
+
+	// Longest duration callers may request, if any.
+	maxDuration time.Duration
+}
+
+var gSignedUrlPolicy atomic.Pointer[signedUrlPolicy]
+
+// ResponseHeaders - headers overridden in responses to downloads using a
+// signed URL.
+type ResponseHeaders = s3provider.ResponseHeaders
+
+// SignedUrlRequest describes a signed URL to be generated for the stored
+// object of a file.
+type SignedUrlRequest struct {
+	BucketName string
+	TenantID   string
+	DeviceID   string
+	FileID     uint64
+	Method     string
+	Checksum   string
+	Size       int64
+
+	// Duration requested by the caller. If zero, the duration configured for
+	// the tenant and method is used.
+	Duration time.Duration
+
+	// Headers overridden in responses to downloads, if any.
+	Headers *ResponseHeaders
+}
+
+// GetSignedUrl returns a signed URL for the stored object of a file and the
+// time at which the URL expires.
+func GetSignedUrl(ctx context.Context, request *SignedUrlRequest) (string,
+	time.Time, error) {
+	if Provider == nil {
+		return "", time.Time{}, ErrNotInitialized
+	}
+
+	duration := GetSignedUrlDuration(request.TenantID, request.Method,
+		request.Duration)
+	expiresAt := time.Now().UTC().Add(duration).Truncate(time.Second)
+	signedUrl, err := Provider.GetSignedUrl(ctx, request.BucketName,
+		GetObjectName(request.TenantID, request.DeviceID, request.FileID),
+		request.Method, request.Checksum, request.Size, duration,
+		request.Headers)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedUrl, expiresAt, nil
+}
+
+// GetSignedUrlDuration returns the duration for which a signed URL for the
+// specified method is valid for the specified tenant. The tenant duration for
+// the method is used if configured, else the method duration, else the
+// signed URL duration. A duration requested by the caller replaces it, but
+// cannot exceed the maximum duration or, if none is configured, the duration
+// that would otherwise be used.
+func GetSignedUrlDuration(tenantID string, method string,
+	requested time.Duration) time.Duration {
+	policy := gSignedUrlPolicy.Load()
+	if policy == nil {
+		return requested
+	}
+
+	method = strings.ToLower(method)
+	duration, ok := policy.tenantDurations[strings.ToLower(tenantID)][method]
+	if !ok {
+		duration, ok = policy.methodDurations[method]
+		if !ok {
+			duration = policy.defaultDuration
+		}
+	}
+	if requested <= 0 {
+		return duration
+	}
+
+	maxDuration := policy.maxDuration
+	if maxDuration == 0 {
+		maxDuration = duration
+	}
+	return min(requested, maxDuration)
+}
+
+// Resolves the durations of signed URLs from the storage settings.
+func updateSignedUrlPolicy(storageConfig *config.Storage) {
+	policy := &signedUrlPolicy{
+		defaultDuration: minutes(storageConfig.SignedUrlDurationInMinutes),
+		methodDurations: toDurations(storageConfig.SignedUrlMethodDurations),
+		tenantDurations: make(map[string]map[string]time.Duration,
+			len(storageConfig.SignedUrlTenantDurations)),
+		maxDuration: minutes(storageConfig.SignedUrlMaxDurationInMinutes),
+	}
+	for tenantID, durations := range storageConfig.SignedUrlTenantDurations {
+		policy.tenantDurations[strings.ToLower(tenantID)] = toDurations(durations)
+	}
+	gSignedUrlPolicy.Store(policy)
+}
+
+func toDurations(durations map[string]int) map[string]time.Duration {
+	result := make(map[string]time.Duration, len(durations))
+	for method, duration := range durations {
+		result[method] = minutes(duration)
+	}
+	return result
+}
+
+func minutes(duration int) time.Duration {
+	return time.Duration(duration) * time.Minute
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/HPInc/krypton-fs/service/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// Name of the instrumentation scope used for spans created by the service.
+	tracerName = "github.com/HPInc/krypton-fs/service"
+
+	// Name under which the service reports its spans.
+	serviceName = "krypton-fs"
+
+	tracingShutdownTimeout = time.Second * 5
+)
+
+var (
+	fsLogger *zap.Logger
+
+	// Tracer used to create spans. Until tracing is initialized, this is
+	// backed by the no-op global tracer provider.
+	tracer trace.Tracer = otel.Tracer(tracerName)
+
+	// Tracer provider used to create and export spans.
+	tracerProvider *sdktrace.TracerProvider
+)
+
+// Init initializes distributed tracing for the service. W3C trace context
+// propagation is always enabled so that trace context received from callers
+// is forwarded to downstream services, even if spans are not exported.
+func Init(logger *zap.Logger, tracingConfig *config.Tracing) error {
+	fsLogger = logger
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	// Spans are created even if they are not exported so that trace IDs are
+	// available for logging and propagation to downstream services.
+	providerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(tracingConfig.SampleRatio))),
+	}
+
+	if tracingConfig.Enabled {
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(tracingConfig.Endpoint),
+		}
+		if tracingConfig.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), options...)
+		if err != nil {
+			fsLogger.Error("Failed to create the trace exporter!",
+				zap.String("Endpoint:", tracingConfig.Endpoint),
+				zap.Error(err),
+			)
+			return err
+		}
+		providerOptions = append(providerOptions, sdktrace.WithBatcher(exporter))
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(providerOptions...)
+	otel.SetTracerProvider(tracerProvider)
+	tracer = tracerProvider.Tracer(tracerName)
+
+	fsLogger.Info("Tracing successfully initialized",
+		zap.Bool("Span export enabled:", tracingConfig.Enabled),
+	)
+	return nil
+}
+
+// Shutdown flushes any spans that have not yet been exported and stops the
+// trace exporter.
+func Shutdown() {
+	if tracerProvider == nil {
+		return
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(),
+		tracingShutdownTimeout)
+	defer cancelFunc()
+
+	err := tracerProvider.Shutdown(ctx)
+	if err != nil {
+		fsLogger.Error("Failed to shutdown the tracer provider!",
+			zap.Error(err),
+		)
+	}
+}
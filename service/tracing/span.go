@@ -0,0 +1,122 @@
+// Copyright 2025 HP Development Company, L.P.
+// SPDX-License-Identifier: MIT
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const headerTraceParent = "traceparent"
+
+// StartSpan starts a new span as a child of the span in the specified context,
+// if any. The caller must end the returned span.
+func StartSpan(ctx context.Context, name string,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// StartServerSpan starts a span for a request received by the service.
+func StartServerSpan(ctx context.Context, name string,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...),
+		trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// StartClientSpan starts a span for a call made by the service to one of its
+// dependencies.
+func StartClientSpan(ctx context.Context, name string,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...),
+		trace.WithSpanKind(trace.SpanKindClient))
+}
+
+// StartConsumerSpan starts a span for a message received from a queue. The
+// span continues the trace of the producer, whose context is extracted from
+// the specified carrier.
+func StartConsumerSpan(ctx context.Context, name string,
+	carrier propagation.TextMapCarrier,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...),
+		trace.WithSpanKind(trace.SpanKindConsumer))
+}
+
+// SetError records the error, if any, on the span and marks the span failed.
+func SetError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// EndSpan records the error, if any, on the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	SetError(span, err)
+	span.End()
+}
+
+// WithSpanFrom returns a copy of parent that carries the span in ctx. This is
+// used to trace work that is bound to a different lifetime than the request,
+// such as cache operations that are cancelled when the service shuts down.
+func WithSpanFrom(parent context.Context, ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(parent,
+		trace.SpanContextFromContext(ctx))
+}
+
+// Inject writes the trace context in the specified context to the carrier.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// TraceParent returns the W3C traceparent of the span in the specified context
+// so that it can be persisted and linked to later. It returns an empty string
+// if the context does not carry a span.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get(headerTraceParent)
+}
+
+// AddLink links the span in the specified context to the span identified by
+// the W3C traceparent. Invalid or empty traceparent values are ignored.
+func AddLink(ctx context.Context, traceParent string) {
+	if traceParent == "" {
+		return
+	}
+
+	linked := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(
+		context.Background(),
+		propagation.MapCarrier{headerTraceParent: traceParent}))
+	if !linked.IsValid() {
+		return
+	}
+	trace.SpanFromContext(ctx).AddLink(trace.Link{SpanContext: linked})
+}
+
+// TraceID returns a log field containing the trace ID of the span in the
+// specified context. The field is empty if the context does not carry a span.
+func TraceID(ctx context.Context) zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return zap.Skip()
+	}
+	return zap.String("Trace ID:", spanContext.TraceID().String())
+}
+
+// SpanID returns a log field containing the ID of the span in the specified
+// context. The field is empty if the context does not carry a span.
+func SpanID(ctx context.Context) zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasSpanID() {
+		return zap.Skip()
+	}
+	return zap.String("Span ID:", spanContext.SpanID().String())
+}